@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Placements of the folder and rule group context of Grafana alerts.
+const (
+	contextMessage = "message"
+	contextTitle   = "title"
+	contextNone    = "none"
+)
+
+// validateContext checks grafana.contextPlacement.
+func (g *GrafanaConfig) validateContext() error {
+	switch g.ContextPlacement {
+	case "", contextMessage, contextTitle, contextNone:
+		return nil
+	}
+	return fmt.Errorf("invalid grafana.contextPlacement %q, expected message, title or none", g.ContextPlacement)
+}
+
+// alertContext returns the values of the context labels of an alert, e.g.
+// "Infrastructure / CPU" for its folder and rule group, or "" if it has
+// none. Labels hidden by the label filter are left out.
+func (c *Config) alertContext(alert GrafanaAlert) string {
+	if c.Grafana.ContextPlacement == contextNone {
+		return ""
+	}
+	var parts []string
+	for _, name := range c.Grafana.ContextLabels {
+		if value := strings.TrimSpace(alert.Labels[name]); value != "" && c.Labels.allows(name) {
+			parts = append(parts, value)
+		}
+	}
+	return strings.Join(parts, " / ")
+}
+
+// commonContext returns the context shared by all alerts, or "" if they
+// differ.
+func (c *Config) commonContext(alerts []GrafanaAlert) string {
+	if len(alerts) == 0 {
+		return ""
+	}
+	context := c.alertContext(alerts[0])
+	for _, alert := range alerts[1:] {
+		if c.alertContext(alert) != context {
+			return ""
+		}
+	}
+	return context
+}
+
+// contextInMessage reports whether the context is rendered in the message
+// body.
+func (c *Config) contextInMessage() bool {
+	return c.Grafana.ContextPlacement == "" || c.Grafana.ContextPlacement == contextMessage
+}
+
+// contextLabel reports whether the label is rendered as part of the context
+// in the message body instead of the list of labels.
+func (c *Config) contextLabel(name string) bool {
+	if !c.contextInMessage() {
+		return false
+	}
+	for _, label := range c.Grafana.ContextLabels {
+		if label == name {
+			return true
+		}
+	}
+	return false
+}
+
+// withContext adds the context to a title if it is placed in titles.
+func (c *Config) withContext(context, title string) string {
+	if context == "" || c.Grafana.ContextPlacement != contextTitle {
+		return title
+	}
+	return "[" + context + "] " + title
+}
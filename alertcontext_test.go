@@ -0,0 +1,57 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func contextGrafanaPayload() map[string]interface{} {
+	payload := grafanaNotification(grafanaAlert("firing", "alertname", "HighCPU", "grafana_folder", "Infrastructure", "rule_group", "CPU"))
+	payload["title"] = "[FIRING:1] HighCPU"
+	return payload
+}
+
+func TestWebhookForwarderPlugin_GrafanaContextInMessage(t *testing.T) {
+	p, mockHandler := newTestPlugin(t, defaultConfig())
+
+	postWebhook(p, contextGrafanaPayload())
+	msg := mockHandler.sentMessages[0]
+	assert.Equal(t, "[FIRING:1] HighCPU", msg.Title)
+	assert.Equal(t, "📁 Infrastructure / CPU\n\n- **alertname**: HighCPU", msg.Message)
+}
+
+func TestWebhookForwarderPlugin_GrafanaContextInTitle(t *testing.T) {
+	config := defaultConfig()
+	config.Grafana.ContextPlacement = contextTitle
+	p, mockHandler := newTestPlugin(t, config)
+
+	postWebhook(p, contextGrafanaPayload())
+	msg := mockHandler.sentMessages[0]
+	assert.Equal(t, "[Infrastructure / CPU] [FIRING:1] HighCPU", msg.Title)
+	assert.Contains(t, msg.Message, "- **grafana_folder**: Infrastructure")
+
+	config.Grafana.SplitAlerts = true
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+	postWebhook(p, contextGrafanaPayload())
+	assert.Equal(t, "[Infrastructure / CPU] [FIRING] HighCPU", mockHandler.sentMessages[1].Title)
+}
+
+func TestConfig_AlertContext(t *testing.T) {
+	config := defaultConfig()
+	folder := GrafanaAlert{Labels: map[string]string{"grafana_folder": "Infrastructure"}}
+	group := GrafanaAlert{Labels: map[string]string{"grafana_folder": "Infrastructure", "rule_group": "CPU"}}
+	assert.Equal(t, "Infrastructure", config.alertContext(folder))
+	assert.Equal(t, "Infrastructure / CPU", config.alertContext(group))
+	assert.Equal(t, "", config.commonContext([]GrafanaAlert{folder, group}))
+	assert.Equal(t, "Infrastructure", config.commonContext([]GrafanaAlert{folder, folder}))
+
+	config.Labels.Exclude = []string{"rule_group"}
+	assert.Equal(t, "Infrastructure", config.alertContext(group))
+
+	config.Grafana.ContextPlacement = contextNone
+	assert.Equal(t, "", config.alertContext(group))
+
+	config.Grafana.ContextPlacement = "footer"
+	assert.Error(t, config.validate())
+}
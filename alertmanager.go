@@ -0,0 +1,340 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gotify/plugin-api"
+)
+
+// AlertmanagerConfig holds options for Prometheus Alertmanager webhooks.
+type AlertmanagerConfig struct {
+	// NotifyOnResolved forwards resolved alerts. When false they are
+	// acknowledged but not sent to the user.
+	NotifyOnResolved bool `yaml:"notifyOnResolved"`
+	// SplitAlerts sends one message per alert. When false a notification is
+	// sent as a single message listing all of its alerts.
+	SplitAlerts bool `yaml:"splitAlerts"`
+	// SeverityLabel names the alert label holding the severity, and
+	// SeverityPriorities maps its values to priorities for firing alerts.
+	SeverityLabel      string         `yaml:"severityLabel"`
+	SeverityPriorities map[string]int `yaml:"severityPriorities"`
+}
+
+// validate checks the Alertmanager options.
+func (a *AlertmanagerConfig) validate() error {
+	for severity, priority := range a.SeverityPriorities {
+		if priority < 1 || priority > 10 {
+			return fmt.Errorf("invalid alertmanager.severityPriorities.%s: priority must be between 1 and 10", severity)
+		}
+	}
+	return nil
+}
+
+// isAlertmanagerPayload detects notifications of the Prometheus Alertmanager
+// webhook receiver (payload version 4). Grafana uses the same layout with
+// version 1 and adds the orgId.
+func isAlertmanagerPayload(body map[string]interface{}) bool {
+	if _, hasAlerts := body["alerts"]; !hasAlerts || stringField(body, "version") != "4" {
+		return false
+	}
+	_, hasOrg := body["orgId"]
+	return !hasOrg
+}
+
+// alertPriority derives the priority of an alert from its severity while
+// firing, falling back to its status.
+func (a *AlertmanagerConfig) alertPriority(alert GrafanaAlert, status string) int {
+	if status == "firing" && a.SeverityLabel != "" {
+		severity := strings.ToLower(alert.Labels[a.SeverityLabel])
+		for name, priority := range a.SeverityPriorities {
+			if strings.ToLower(name) == severity {
+				return priority
+			}
+		}
+	}
+	return grafanaPriority(status, "")
+}
+
+// handleAlertmanagerWebhook forwards the alerts of an Alertmanager
+// notification, one message per alert unless splitting is disabled.
+func (p *WebhookForwarderPlugin) handleAlertmanagerWebhook(c *gin.Context, rawBody map[string]interface{}) {
+	var webhook GrafanaWebhook
+	decodePayload(rawBody, &webhook)
+	config := p.getConfig()
+	profile := sourceProfile(c, config, "alertmanager")
+
+	// Acknowledge resolved alerts without forwarding them if configured
+	alerts := make([]GrafanaAlert, 0, len(webhook.Alerts))
+	for _, alert := range webhook.Alerts {
+		if webhook.alertStatus(alert) != "resolved" || config.Alertmanager.NotifyOnResolved {
+			alerts = append(alerts, alert)
+		}
+	}
+	if len(alerts) == 0 && (len(webhook.Alerts) > 0 || webhook.Status == "resolved") {
+		p.skipMessage(c, "alertmanager", skipFiltered, "Resolved alerts are not forwarded")
+		return
+	}
+	webhook.Alerts = alerts
+
+	truncated := intField(rawBody, "truncatedAlerts")
+	if !config.Alertmanager.SplitAlerts || len(alerts) == 0 {
+		p.forwardMessage(c, "alertmanager", config.alertmanagerMessage(profile, webhook, truncated))
+		return
+	}
+
+	messages := make([]plugin.Message, 0, len(alerts))
+	for _, alert := range alerts {
+		single := webhook
+		single.Status = webhook.alertStatus(alert)
+		single.Alerts = []GrafanaAlert{alert}
+		messages = append(messages, config.alertmanagerMessage(profile, single, 0))
+	}
+	p.forwardMessages(c, "alertmanager", messages)
+}
+
+// forwardRuleAlerts forwards an Alertmanager notification of known rules
+// rendered by the service formatter, applying the alertmanager settings:
+// resolved alerts are dropped unless alertmanager.notifyOnResolved is set,
+// and with alertmanager.splitAlerts every alert is sent on its own.
+func (p *WebhookForwarderPlugin) forwardRuleAlerts(c *gin.Context, formatter *payloadFormatter, body map[string]interface{}) {
+	config := p.getConfig()
+	status := stringField(body, "status")
+	var alerts []interface{}
+	for _, alert := range mapSlice(body["alerts"]) {
+		if ruleAlertStatus(alert, status) != "resolved" || config.Alertmanager.NotifyOnResolved {
+			alerts = append(alerts, alert)
+		}
+	}
+	if len(alerts) == 0 {
+		p.skipMessage(c, formatter.source, skipFiltered, "Resolved alerts are not forwarded")
+		return
+	}
+
+	if !config.Alertmanager.SplitAlerts || len(alerts) == 1 {
+		filtered := withExtra(body, "alerts", alerts)
+		p.forwardMessage(c, formatter.source, p.detectedMessage(c, formatter, filtered))
+		return
+	}
+	messages := make([]plugin.Message, 0, len(alerts))
+	for _, alert := range alerts {
+		single := withExtra(body, "alerts", []interface{}{alert})
+		single["status"] = ruleAlertStatus(alert.(map[string]interface{}), status)
+		messages = append(messages, p.detectedMessage(c, formatter, single))
+	}
+	p.forwardMessages(c, formatter.source, messages)
+}
+
+// ruleAlertStatus returns the status of an alert of a notification, falling
+// back to the status of the notification.
+func ruleAlertStatus(alert map[string]interface{}, status string) string {
+	if alertStatus := stringField(alert, "status"); alertStatus != "" {
+		return alertStatus
+	}
+	return status
+}
+
+// labelsAlert converts the labels of a raw alert for the severity priority
+// lookup.
+func labelsAlert(labels map[string]interface{}) GrafanaAlert {
+	alert := GrafanaAlert{Labels: make(map[string]string, len(labels))}
+	for name, value := range labels {
+		alert.Labels[name] = looseString(value)
+	}
+	return alert
+}
+
+// forwardMessages sends the messages of a split notification and writes a
+// single response summarising the result.
+func (p *WebhookForwarderPlugin) forwardMessages(c *gin.Context, source string, messages []plugin.Message) {
+	sent := 0
+	for _, msg := range messages {
+		switch err := p.deliverMessage(c, msg); err {
+		case nil:
+			sent++
+		case errQuietHours:
+		default:
+			p.writeDeliveryError(c, source, err)
+			return
+		}
+	}
+	if sent == 0 {
+		p.skipMessage(c, source, skipMuted, "Messages dropped during quiet hours")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": fmt.Sprintf("%d message(s) forwarded successfully", sent),
+		"type":    source,
+		"count":   sent,
+	})
+}
+
+// alertmanagerMessage builds the message for the alerts of a notification:
+// a single alert is titled with its status and name, several alerts are
+// counted by status and rendered one section each. truncated is the number
+// of alerts Alertmanager left out of the payload.
+func (c *Config) alertmanagerMessage(profile *SourceConfig, webhook GrafanaWebhook, truncated int) plugin.Message {
+	var title, message string
+	priority := 0
+	for _, alert := range webhook.Alerts {
+		if p := c.Alertmanager.alertPriority(alert, webhook.alertStatus(alert)); p > priority {
+			priority = p
+		}
+	}
+	switch len(webhook.Alerts) {
+	case 0:
+		title = c.defaultTitle(profile, "Alertmanager: "+webhook.Status)
+		message = "Alert notification from Alertmanager"
+		priority = grafanaPriority(webhook.Status, "")
+	case 1:
+		alert := webhook.Alerts[0]
+		title = fmt.Sprintf("[%s] %s", strings.ToUpper(webhook.alertStatus(alert)), alertName(alert, c.defaultTitle(profile, "Alertmanager Alert")))
+		message = c.alertmanagerAlertBody(webhook, alert)
+	default:
+		title = summaryTitle(webhook)
+		sections := make([]string, 0, len(webhook.Alerts))
+		for _, alert := range webhook.Alerts {
+			heading := fmt.Sprintf("[%s] %s", strings.ToUpper(webhook.alertStatus(alert)), alertName(alert, "Alert"))
+			sections = append(sections, "### "+heading+"\n"+c.alertmanagerAlertBody(webhook, alert))
+		}
+		message = strings.Join(sections, "\n\n")
+	}
+	if truncated > 0 {
+		message += fmt.Sprintf("\n\n_%d more alert(s) truncated by Alertmanager_", truncated)
+	}
+
+	if profile.Title != "" {
+		title = profile.Title
+	}
+	if profile.Priority > 0 {
+		priority = profile.Priority
+	}
+	title, message = c.renderProfileTemplates(profile, c.Labels.filterGrafanaLabels(webhook), title, message)
+
+	extras := map[string]interface{}{
+		"source":          "alertmanager",
+		"client::display": map[string]interface{}{"contentType": "text/markdown"},
+	}
+	for key, value := range map[string]string{
+		"status":      webhook.Status,
+		"receiver":    webhook.Receiver,
+		"groupKey":    webhook.GroupKey,
+		"externalURL": webhook.ExternalURL,
+	} {
+		if value != "" {
+			extras[key] = value
+		}
+	}
+	click := webhook.ExternalURL
+	if len(webhook.Alerts) == 1 {
+		alert := webhook.Alerts[0]
+		extras["fingerprint"] = alertFingerprint(alert)
+		if alertname := alert.Labels["alertname"]; alertname != "" {
+			extras["alertname"] = alertname
+		}
+		if alert.GeneratorURL != "" {
+			extras["generatorURL"] = alert.GeneratorURL
+			click = alert.GeneratorURL
+		}
+	} else if len(webhook.Alerts) > 1 {
+		alerts := make([]interface{}, 0, len(webhook.Alerts))
+		for _, alert := range webhook.Alerts {
+			alerts = append(alerts, webhook.alertCorrelation(alert))
+		}
+		extras["alerts"] = alerts
+	}
+	if runbookURL := runbookURL(webhook.Alerts...); runbookURL != "" {
+		extras["runbookURL"] = runbookURL
+	}
+	if click != "" {
+		extras["client::notification"] = map[string]interface{}{
+			"click": map[string]interface{}{"url": click},
+		}
+	}
+
+	return plugin.Message{
+		Title:    title,
+		Message:  message,
+		Priority: priority,
+		Extras:   extras,
+	}
+}
+
+// alertmanagerAlertBody renders an Alertmanager alert as markdown: the
+// summary and description annotations, its labels and timestamps, links to
+// the Prometheus expression and runbook, and for firing alerts a link to
+// silence the alert in Alertmanager.
+func (c *Config) alertmanagerAlertBody(webhook GrafanaWebhook, alert GrafanaAlert) string {
+	var paragraphs []string
+	if annotations := c.grafanaAnnotations(alert); annotations != "" {
+		paragraphs = append(paragraphs, annotations)
+	}
+
+	var items []string
+	labels := c.Labels.filter(alert.Labels)
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		items = append(items, fmt.Sprintf("- **%s**: %s", name, labels[name]))
+	}
+	if started := c.formatTimestamp(alert.StartsAt); started != "" {
+		items = append(items, "- **Started**: "+started)
+	}
+	if webhook.alertStatus(alert) == "resolved" {
+		if ended := c.formatTimestamp(alert.EndsAt); ended != "" {
+			if duration, ok := timestampDuration(alert.StartsAt, alert.EndsAt); ok {
+				ended += " (was firing for " + humanizeDuration(duration) + ")"
+			}
+			items = append(items, "- **Ended**: "+ended)
+		}
+	}
+	if len(items) > 0 {
+		paragraphs = append(paragraphs, strings.Join(items, "\n"))
+	}
+
+	var links []string
+	if alert.GeneratorURL != "" {
+		links = append(links, fmt.Sprintf("[Source](%s)", alert.GeneratorURL))
+	}
+	if link := c.runbookLink(alert); link != "" {
+		links = append(links, link)
+	}
+	if len(links) > 0 {
+		paragraphs = append(paragraphs, strings.Join(links, " | "))
+	}
+	if silence := alertmanagerSilenceURL(webhook, alert); silence != "" && webhook.alertStatus(alert) != "resolved" {
+		paragraphs = append(paragraphs, fmt.Sprintf("[🔕 Silence this alert](%s)", silence))
+	}
+
+	if len(paragraphs) == 0 {
+		return "Alert notification from Alertmanager"
+	}
+	return strings.Join(paragraphs, "\n\n")
+}
+
+// alertmanagerSilenceURL links to the Alertmanager UI form creating a
+// silence matching the labels of the alert, or "" without an external URL.
+func alertmanagerSilenceURL(webhook GrafanaWebhook, alert GrafanaAlert) string {
+	if webhook.ExternalURL == "" || len(alert.Labels) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(alert.Labels))
+	for name := range alert.Labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	matchers := make([]string, 0, len(names))
+	for _, name := range names {
+		matchers = append(matchers, fmt.Sprintf("%s=%q", name, alert.Labels[name]))
+	}
+	filter := "{" + strings.Join(matchers, ",") + "}"
+	return strings.TrimSuffix(webhook.ExternalURL, "/") + "/#/silences/new?filter=" + url.QueryEscape(filter)
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func alertmanagerPayload() map[string]interface{} {
+	return map[string]interface{}{
+		"version":         "4",
+		"status":          "firing",
+		"receiver":        "gotify",
+		"groupKey":        "{}:{alertname=\"HostDown\"}",
+		"truncatedAlerts": 0,
+		"externalURL":     "http://alertmanager:9093",
+		"groupLabels":     map[string]interface{}{"alertname": "HostDown"},
+		"alerts": []interface{}{
+			map[string]interface{}{
+				"status":       "firing",
+				"labels":       map[string]interface{}{"alertname": "HostDown", "instance": "db1", "severity": "critical"},
+				"annotations":  map[string]interface{}{"summary": "db1 is down"},
+				"startsAt":     "2024-05-01T12:00:00Z",
+				"endsAt":       "0001-01-01T00:00:00Z",
+				"generatorURL": "http://prometheus:9090/graph?g0.expr=up+%3D%3D+0",
+				"fingerprint":  "f1",
+			},
+			map[string]interface{}{
+				"status":      "resolved",
+				"labels":      map[string]interface{}{"alertname": "HostDown", "instance": "db2"},
+				"startsAt":    "2024-05-01T11:00:00Z",
+				"endsAt":      "2024-05-01T11:30:00Z",
+				"fingerprint": "f2",
+			},
+		},
+	}
+}
+
+func TestIsAlertmanagerPayload(t *testing.T) {
+	assert.True(t, isAlertmanagerPayload(alertmanagerPayload()))
+
+	grafana := alertmanagerPayload()
+	grafana["version"] = "1"
+	assert.False(t, isAlertmanagerPayload(grafana))
+	grafana["version"] = "4"
+	grafana["orgId"] = 1
+	assert.False(t, isAlertmanagerPayload(grafana))
+}
+
+func TestWebhookForwarderPlugin_Alertmanager(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+	config := defaultConfig()
+	config.Timezone = "UTC"
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+
+	w := postWebhook(p, alertmanagerPayload())
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"count":2`)
+	assert.Len(t, mockHandler.sentMessages, 2)
+
+	firing := mockHandler.sentMessages[0]
+	assert.Equal(t, "[FIRING] HostDown", firing.Title)
+	assert.Equal(t, 10, firing.Priority)
+	assert.Equal(t, "db1 is down\n\n- **alertname**: HostDown\n- **instance**: db1\n- **severity**: critical\n- **Started**: 2024-05-01 12:00:00 UTC\n\n[Source](http://prometheus:9090/graph?g0.expr=up+%3D%3D+0)\n\n[🔕 Silence this alert](http://alertmanager:9093/#/silences/new?filter=%7Balertname%3D%22HostDown%22%2Cinstance%3D%22db1%22%2Cseverity%3D%22critical%22%7D)", firing.Message)
+	assert.Equal(t, "alertmanager", firing.Extras["source"])
+	assert.Equal(t, "f1", firing.Extras["fingerprint"])
+	assert.Equal(t, map[string]interface{}{
+		"click": map[string]interface{}{"url": "http://prometheus:9090/graph?g0.expr=up+%3D%3D+0"},
+	}, firing.Extras["client::notification"])
+
+	resolved := mockHandler.sentMessages[1]
+	assert.Equal(t, "[RESOLVED] HostDown", resolved.Title)
+	assert.Equal(t, 3, resolved.Priority)
+	assert.Contains(t, resolved.Message, "- **Ended**: 2024-05-01 11:30:00 UTC (was firing for 30m)")
+	assert.NotContains(t, resolved.Message, "Silence")
+}
+
+func TestWebhookForwarderPlugin_AlertmanagerGrouped(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+	config := defaultConfig()
+	config.Alertmanager.SplitAlerts = false
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+
+	payload := alertmanagerPayload()
+	payload["truncatedAlerts"] = 3
+	postWebhook(p, payload)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	msg := mockHandler.sentMessages[0]
+	assert.Equal(t, "1 firing, 1 resolved — HostDown", msg.Title)
+	assert.Equal(t, 10, msg.Priority)
+	assert.Contains(t, msg.Message, "### [FIRING] HostDown\ndb1 is down")
+	assert.Contains(t, msg.Message, "### [RESOLVED] HostDown\n")
+	assert.Contains(t, msg.Message, "_3 more alert(s) truncated by Alertmanager_")
+	assert.Len(t, msg.Extras["alerts"], 2)
+
+	// Resolved alerts can be acknowledged without forwarding them
+	config.Alertmanager.NotifyOnResolved = false
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+	payload = alertmanagerPayload()
+	payload["status"] = "resolved"
+	payload["alerts"] = payload["alerts"].([]interface{})[1:]
+	w := postWebhook(p, payload)
+	assert.Contains(t, w.Body.String(), "Resolved alerts are not forwarded")
+	assert.Len(t, mockHandler.sentMessages, 1)
+
+	config.Alertmanager.SeverityPriorities = map[string]int{"critical": 11}
+	assert.Error(t, config.validate())
+}
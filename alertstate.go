@@ -0,0 +1,250 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"time"
+)
+
+// alertStateRetention is how long alerts that are no longer reported by
+// Grafana are remembered.
+const alertStateRetention = 7 * 24 * time.Hour
+
+// alertGroupState is the tracked state of a Grafana alert group, keyed by
+// alert fingerprint.
+type alertGroupState struct {
+	Alerts map[string]*alertState `json:"alerts"`
+}
+
+// alertState is the tracked state of a single alert.
+type alertState struct {
+	// Status is the status of the last notification sent for the alert.
+	Status string `json:"status"`
+	// Notified is when the last notification was sent.
+	Notified time.Time `json:"notified"`
+	// Seen is when the alert was last reported by Grafana.
+	Seen time.Time `json:"seen"`
+	// Observed is the last reported status, Changes the recent times it
+	// changed and Flapping whether it is currently considered flapping.
+	Observed string      `json:"observed,omitempty"`
+	Changes  []time.Time `json:"changes,omitempty"`
+	Flapping bool        `json:"flapping,omitempty"`
+	// Fired is set while the alert has fired since the group was last clear.
+	Fired bool `json:"fired,omitempty"`
+	// FiringSince is when the alert started firing, Escalated is set once
+	// it has been escalated and Alert holds the alert to escalate.
+	FiringSince time.Time     `json:"firingSince"`
+	Escalated   bool          `json:"escalated,omitempty"`
+	Alert       *GrafanaAlert `json:"alert,omitempty"`
+}
+
+// alertObservation is the result of recording the alerts of a webhook.
+type alertObservation struct {
+	// flapping holds the flapping state of each alert by fingerprint.
+	flapping map[string]flapState
+	// repeated holds the fingerprints of alerts whose status is unchanged
+	// since the previous webhook.
+	repeated map[string]bool
+	// cleared is the number of alerts that fired in the group if the
+	// webhook resolved its last firing alert, otherwise 0.
+	cleared int
+}
+
+// alertStatus returns the status of an alert, defaulting to the status of
+// the notification.
+func (w GrafanaWebhook) alertStatus(alert GrafanaAlert) string {
+	if alert.Status != "" {
+		return alert.Status
+	}
+	return w.Status
+}
+
+// groupKey identifies the alert group of a notification, using Grafana's
+// groupKey or otherwise the group labels.
+func (w GrafanaWebhook) groupKey() string {
+	if w.GroupKey != "" {
+		return w.GroupKey
+	}
+	return labelsKey(w.GroupLabels)
+}
+
+// alertFingerprint identifies an alert, using Grafana's fingerprint or
+// otherwise a hash of the alert labels.
+func alertFingerprint(alert GrafanaAlert) string {
+	if alert.Fingerprint != "" {
+		return alert.Fingerprint
+	}
+	sum := sha256.Sum256([]byte(labelsKey(alert.Labels)))
+	return hex.EncodeToString(sum[:8])
+}
+
+// alertCorrelation identifies an alert for automations that correlate
+// firing and resolved notifications: its fingerprint, status and the UID of
+// the Grafana alert rule if known.
+func (w GrafanaWebhook) alertCorrelation(alert GrafanaAlert) map[string]interface{} {
+	correlation := map[string]interface{}{
+		"fingerprint": alertFingerprint(alert),
+		"status":      w.alertStatus(alert),
+	}
+	if uid := alert.Labels["__alert_rule_uid__"]; uid != "" {
+		correlation["ruleUID"] = uid
+	}
+	return correlation
+}
+
+// labelsKey renders labels in a stable order.
+func labelsKey(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for name, value := range labels {
+		pairs = append(pairs, name+"="+value)
+	}
+	sort.Strings(pairs)
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// alertGroup returns the tracked state of an alert group, or nil if the
+// group is unknown.
+func (p *WebhookForwarderPlugin) alertGroup(key string) *alertGroupState {
+	storage, err := p.loadStorage()
+	if err != nil {
+		return nil
+	}
+	return storage.AlertGroups[key]
+}
+
+// isDuplicate reports whether a notification with the given status was
+// already sent for the alert within window.
+func (g *alertGroupState) isDuplicate(fingerprint, status string, now time.Time, window time.Duration) bool {
+	if g == nil || window <= 0 {
+		return false
+	}
+	state, ok := g.Alerts[fingerprint]
+	return ok && state.Status == status && now.Sub(state.Notified) < window
+}
+
+// observeAlerts records the reported status of every alert of a webhook,
+// detecting flapping alerts and groups whose last firing alert resolved.
+func (p *WebhookForwarderPlugin) observeAlerts(g *GrafanaConfig, webhook GrafanaWebhook, now time.Time) alertObservation {
+	observation := alertObservation{flapping: make(map[string]flapState), repeated: make(map[string]bool)}
+	if !g.tracksAlerts() || len(webhook.Alerts) == 0 {
+		return observation
+	}
+	key := webhook.groupKey()
+	_ = p.updateStorage(func(storage *pluginStorage) {
+		group := storage.alertGroup(key)
+		resolved := false
+		for _, alert := range webhook.Alerts {
+			fingerprint := alertFingerprint(alert)
+			state := group.alert(fingerprint)
+			status := webhook.alertStatus(alert)
+			observation.repeated[fingerprint] = state.Observed == status
+			if state.Observed != "" && state.Observed != status {
+				state.Changes = append(state.Changes, now)
+				resolved = resolved || status == "resolved"
+			}
+			state.Observed = status
+			state.Seen = now
+			if status == "firing" {
+				state.Fired = true
+				if state.FiringSince.IsZero() {
+					state.FiringSince = alertStart(alert, now)
+				}
+				if g.Escalation.after > 0 {
+					alert := alert
+					state.Alert = &alert
+				}
+			} else {
+				state.FiringSince, state.Escalated, state.Alert = time.Time{}, false, nil
+			}
+			if g.Flapping.Threshold > 0 {
+				observation.flapping[fingerprint] = g.Flapping.evaluate(state, now)
+			}
+		}
+		if g.AllClear && resolved {
+			observation.cleared = group.clear()
+		}
+		storage.pruneAlertGroups(now)
+	})
+	return observation
+}
+
+// clear checks whether no alert of the group is firing anymore. If so, it
+// returns the number of alerts that fired since the group was last clear
+// and starts over; otherwise it returns 0.
+func (g *alertGroupState) clear() int {
+	fired := 0
+	for _, state := range g.Alerts {
+		if state.Observed == "firing" {
+			return 0
+		}
+		if state.Fired {
+			fired++
+		}
+	}
+	for _, state := range g.Alerts {
+		state.Fired = false
+	}
+	return fired
+}
+
+// recordNotified stores that notifications were sent for the alerts of a
+// group and forgets alerts that have not been seen for a long time.
+func (p *WebhookForwarderPlugin) recordNotified(webhook GrafanaWebhook, alerts []GrafanaAlert, now time.Time) {
+	if len(alerts) == 0 {
+		return
+	}
+	key := webhook.groupKey()
+	_ = p.updateStorage(func(storage *pluginStorage) {
+		group := storage.alertGroup(key)
+		for _, alert := range alerts {
+			state := group.alert(alertFingerprint(alert))
+			state.Status = webhook.alertStatus(alert)
+			state.Notified = now
+			state.Seen = now
+		}
+		storage.pruneAlertGroups(now)
+	})
+}
+
+// alertGroup returns the state of an alert group, adding it if needed.
+func (s *pluginStorage) alertGroup(key string) *alertGroupState {
+	if s.AlertGroups == nil {
+		s.AlertGroups = make(map[string]*alertGroupState)
+	}
+	group := s.AlertGroups[key]
+	if group == nil {
+		group = &alertGroupState{}
+		s.AlertGroups[key] = group
+	}
+	if group.Alerts == nil {
+		group.Alerts = make(map[string]*alertState)
+	}
+	return group
+}
+
+// alert returns the state of an alert, adding it if needed.
+func (g *alertGroupState) alert(fingerprint string) *alertState {
+	state := g.Alerts[fingerprint]
+	if state == nil {
+		state = &alertState{}
+		g.Alerts[fingerprint] = state
+	}
+	return state
+}
+
+// pruneAlertGroups removes alerts that have not been seen within the
+// retention period, and groups without alerts.
+func (s *pluginStorage) pruneAlertGroups(now time.Time) {
+	for key, group := range s.AlertGroups {
+		for fingerprint, state := range group.Alerts {
+			if now.Sub(state.Seen) > alertStateRetention {
+				delete(group.Alerts, fingerprint)
+			}
+		}
+		if len(group.Alerts) == 0 {
+			delete(s.AlertGroups, key)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// dedupGrafanaPayload builds a notification of the HighCPU group with the
+// alert of web1 followed by alerts.
+func dedupGrafanaPayload(status string, alerts ...map[string]interface{}) map[string]interface{} {
+	web1 := grafanaAlert(status, "alertname", "HighCPU", "instance", "web1")
+	web1["fingerprint"] = "a1"
+	payload := grafanaNotification(append([]map[string]interface{}{web1}, alerts...)...)
+	payload["groupKey"] = "{}:{alertname=\"HighCPU\"}"
+	return payload
+}
+
+func TestWebhookForwarderPlugin_GrafanaDedup(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	defer func() { timeNow = time.Now }()
+	timeNow = func() time.Time { return now }
+
+	config := defaultConfig()
+	config.Grafana.DedupWindow = "1h"
+	config.ResponseCodes.Duplicate = http.StatusAccepted
+	p, mockHandler := newTestPlugin(t, config)
+
+	assert.Equal(t, http.StatusOK, postWebhook(p, dedupGrafanaPayload("firing")).Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+
+	// The repeated firing notification is suppressed
+	now = now.Add(30 * time.Minute)
+	w := postWebhook(p, dedupGrafanaPayload("firing"))
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+
+	// A new alert in the group is notified
+	web2 := grafanaAlert("firing", "alertname", "HighCPU", "instance", "web2")
+	web2["fingerprint"] = "b2"
+	postWebhook(p, dedupGrafanaPayload("firing", web2))
+	assert.Len(t, mockHandler.sentMessages, 2)
+
+	// State changes are notified
+	postWebhook(p, dedupGrafanaPayload("resolved"))
+	assert.Len(t, mockHandler.sentMessages, 3)
+
+	// Repeats are notified again after the window
+	postWebhook(p, dedupGrafanaPayload("resolved"))
+	assert.Len(t, mockHandler.sentMessages, 3)
+	now = now.Add(2 * time.Hour)
+	postWebhook(p, dedupGrafanaPayload("resolved"))
+	assert.Len(t, mockHandler.sentMessages, 4)
+}
+
+func TestWebhookForwarderPlugin_GrafanaDedupSplitAlerts(t *testing.T) {
+	config := defaultConfig()
+	config.Grafana.DedupWindow = "4h"
+	config.Grafana.SplitAlerts = true
+	p, mockHandler := newTestPlugin(t, config)
+
+	postWebhook(p, dedupGrafanaPayload("firing"))
+	assert.Len(t, mockHandler.sentMessages, 1)
+
+	// Only the new alert is sent
+	payload := dedupGrafanaPayload("firing", grafanaAlert("firing", "alertname", "HighCPU", "instance", "web2"))
+	postWebhook(p, payload)
+	assert.Len(t, mockHandler.sentMessages, 2)
+	assert.Contains(t, mockHandler.sentMessages[1].Message, "web2")
+
+	w := postWebhook(p, payload)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Duplicate notification suppressed")
+	assert.Len(t, mockHandler.sentMessages, 2)
+}
+
+func TestWebhookForwarderPlugin_GrafanaStateChangesOnly(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	defer func() { timeNow = time.Now }()
+	timeNow = func() time.Time { return now }
+
+	config := defaultConfig()
+	config.Grafana.StateChangesOnly = true
+	config.Grafana.NotifyOnResolved = false
+	p, mockHandler := newTestPlugin(t, config)
+
+	postWebhook(p, dedupGrafanaPayload("firing"))
+	assert.Len(t, mockHandler.sentMessages, 1)
+
+	// Repeats are suppressed without a time limit
+	now = now.Add(24 * time.Hour)
+	w := postWebhook(p, dedupGrafanaPayload("firing"))
+	assert.Contains(t, w.Body.String(), "Duplicate notification suppressed")
+	assert.Len(t, mockHandler.sentMessages, 1)
+
+	// Firing again after a resolve that was not forwarded is a transition
+	postWebhook(p, dedupGrafanaPayload("resolved"))
+	postWebhook(p, dedupGrafanaPayload("firing"))
+	assert.Len(t, mockHandler.sentMessages, 2)
+	postWebhook(p, dedupGrafanaPayload("firing"))
+	assert.Len(t, mockHandler.sentMessages, 2)
+}
+
+func TestPluginStorage_PruneAlertGroups(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	storage := pluginStorage{AlertGroups: map[string]*alertGroupState{
+		"old": {Alerts: map[string]*alertState{"a": {Status: "firing", Seen: now.Add(-8 * 24 * time.Hour)}}},
+		"new": {Alerts: map[string]*alertState{
+			"a": {Status: "firing", Seen: now.Add(-time.Hour)},
+			"b": {Status: "resolved", Seen: now.Add(-30 * 24 * time.Hour)},
+		}},
+	}}
+	storage.pruneAlertGroups(now)
+
+	assert.NotContains(t, storage.AlertGroups, "old")
+	assert.Len(t, storage.AlertGroups["new"].Alerts, 1)
+}
+
+func TestAlertFingerprint(t *testing.T) {
+	assert.Equal(t, "abc", alertFingerprint(GrafanaAlert{Fingerprint: "abc"}))
+	a := alertFingerprint(GrafanaAlert{Labels: map[string]string{"a": "1", "b": "2"}})
+	b := alertFingerprint(GrafanaAlert{Labels: map[string]string{"b": "2", "a": "1"}})
+	assert.Equal(t, a, b)
+	assert.NotEqual(t, a, alertFingerprint(GrafanaAlert{Labels: map[string]string{"a": "1"}}))
+
+	config := defaultConfig()
+	config.Grafana.DedupWindow = "soon"
+	assert.Error(t, config.validate())
+}
+
+func TestWebhookForwarderPlugin_GrafanaCorrelationExtras(t *testing.T) {
+	config := defaultConfig()
+	p, mockHandler := newTestPlugin(t, config)
+
+	rule := grafanaAlert("resolved", "alertname", "HighCPU", "__alert_rule_uid__", "rule-1")
+	rule["fingerprint"] = "b2"
+	payload := dedupGrafanaPayload("firing", rule)
+	postWebhook(p, payload)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	extras := mockHandler.sentMessages[0].Extras
+	assert.Equal(t, "{}:{alertname=\"HighCPU\"}", extras["groupKey"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"fingerprint": "a1", "status": "firing"},
+		map[string]interface{}{"fingerprint": "b2", "status": "resolved", "ruleUID": "rule-1"},
+	}, extras["alerts"])
+
+	config.Grafana.SplitAlerts = true
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+	postWebhook(p, payload)
+	assert.Len(t, mockHandler.sentMessages, 3)
+	assert.Equal(t, "a1", mockHandler.sentMessages[1].Extras["fingerprint"])
+	assert.Equal(t, "b2", mockHandler.sentMessages[2].Extras["fingerprint"])
+	assert.Equal(t, "rule-1", mockHandler.sentMessages[2].Extras["ruleUID"])
+	assert.Equal(t, "{}:{alertname=\"HighCPU\"}", mockHandler.sentMessages[2].Extras["groupKey"])
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/gotify/plugin-api"
+)
+
+// allClearMessage summarises a group whose alerts are all resolved, sent
+// instead of the individual resolved notifications.
+func (c *Config) allClearMessage(webhook GrafanaWebhook, fired int) plugin.Message {
+	name := webhook.groupName()
+	title := fmt.Sprintf("All %d alerts in group %s resolved", fired, name)
+	if fired == 1 {
+		title = fmt.Sprintf("The alert in group %s resolved", name)
+	}
+	if name == "" {
+		title = fmt.Sprintf("All %d alerts resolved", fired)
+	}
+
+	extras := map[string]interface{}{
+		"source":   "grafana",
+		"status":   "resolved",
+		"allClear": true,
+	}
+	if notification := c.Grafana.notificationExtras(webhook, webhook.Alerts...); notification != nil {
+		extras["client::notification"] = notification
+	}
+
+	return plugin.Message{
+		Title:    c.Grafana.withStatusEmoji("resolved", "", title),
+		Message:  "No alerts of the group are firing anymore.",
+		Priority: 3,
+		Extras:   extras,
+	}
+}
+
+// grafanaAnnouncements returns the messages announcing flapping alerts and
+// cleared groups, and the alerts that still need a regular notification.
+func (c *Config) grafanaAnnouncements(webhook GrafanaWebhook, observation alertObservation) ([]plugin.Message, []GrafanaAlert) {
+	started, remaining := partitionFlapping(webhook.Alerts, observation.flapping)
+	var announcements []plugin.Message
+	if len(started) > 0 {
+		announcements = append(announcements, c.flappingMessage(webhook, started))
+	}
+
+	if c.Grafana.AllClear {
+		var firing []GrafanaAlert
+		for _, alert := range remaining {
+			if webhook.alertStatus(alert) != "resolved" {
+				firing = append(firing, alert)
+			}
+		}
+		remaining = firing
+		if observation.cleared > 0 {
+			announcements = append(announcements, c.allClearMessage(webhook, observation.cleared))
+		}
+	}
+	return announcements, remaining
+}
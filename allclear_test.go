@@ -0,0 +1,82 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// allClearPayload builds a notification of the node-alerts group with an
+// alert per status, on node1, node2 and so on.
+func allClearPayload(statuses ...string) map[string]interface{} {
+	alerts := make([]map[string]interface{}, 0, len(statuses))
+	for i, status := range statuses {
+		instance := string(rune('1' + i))
+		alert := grafanaAlert(status, "alertname", "NodeDown", "instance", "node"+instance)
+		alert["fingerprint"] = "n" + instance
+		alerts = append(alerts, alert)
+	}
+	payload := grafanaNotification(alerts...)
+	payload["groupKey"] = "{}:{alertname=\"node-alerts\"}"
+	payload["groupLabels"] = map[string]interface{}{"alertname": "node-alerts"}
+	return payload
+}
+
+func TestWebhookForwarderPlugin_GrafanaAllClear(t *testing.T) {
+	config := defaultConfig()
+	config.Grafana.AllClear = true
+	p, mockHandler := newTestPlugin(t, config)
+
+	postWebhook(p, allClearPayload("firing", "firing"))
+	assert.Len(t, mockHandler.sentMessages, 1)
+
+	// Resolved alerts of a group that is still firing are not forwarded
+	w := postWebhook(p, allClearPayload("firing", "resolved"))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 2)
+	assert.NotContains(t, mockHandler.sentMessages[1].Message, "node2")
+
+	// Grafana may repeat the resolved alert without the firing one
+	payload := allClearPayload("firing", "resolved")
+	payload["status"] = "resolved"
+	payload["alerts"] = payload["alerts"].([]interface{})[1:]
+	w = postWebhook(p, payload)
+	assert.Contains(t, w.Body.String(), "summarised")
+	assert.Len(t, mockHandler.sentMessages, 2)
+
+	// The last resolved alert sends a single summary
+	postWebhook(p, allClearPayload("resolved", "resolved"))
+	assert.Len(t, mockHandler.sentMessages, 3)
+	msg := mockHandler.sentMessages[2]
+	assert.Equal(t, "All 2 alerts in group node-alerts resolved", msg.Title)
+	assert.Equal(t, 3, msg.Priority)
+	assert.Equal(t, true, msg.Extras["allClear"])
+
+	// A new incident starts counting again
+	postWebhook(p, allClearPayload("firing"))
+	postWebhook(p, allClearPayload("resolved"))
+	assert.Len(t, mockHandler.sentMessages, 5)
+	assert.Equal(t, "The alert in group node-alerts resolved", mockHandler.sentMessages[4].Title)
+}
+
+func TestWebhookForwarderPlugin_GrafanaAllClearSplitAlerts(t *testing.T) {
+	config := defaultConfig()
+	config.Grafana.AllClear = true
+	config.Grafana.SplitAlerts = true
+	config.Grafana.DedupWindow = "1h"
+	p, mockHandler := newTestPlugin(t, config)
+
+	postWebhook(p, allClearPayload("firing", "firing", "firing"))
+	assert.Len(t, mockHandler.sentMessages, 3)
+
+	w := postWebhook(p, allClearPayload("resolved", "firing", "resolved"))
+	assert.Contains(t, w.Body.String(), "Duplicate")
+	assert.Len(t, mockHandler.sentMessages, 3)
+
+	w = postWebhook(p, allClearPayload("resolved", "resolved", "resolved"))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "1 message(s) forwarded")
+	assert.Len(t, mockHandler.sentMessages, 4)
+	assert.Equal(t, "All 3 alerts in group node-alerts resolved", mockHandler.sentMessages[3].Title)
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/gotify/plugin-api"
+)
+
+// isArgoCDPayload detects notifications of Argo CD's notification engine.
+// Webhook templates either send flat fields like {"app": "guestbook",
+// "syncStatus": "Synced", "healthStatus": "Healthy"} or the application
+// object itself as "app".
+func isArgoCDPayload(body map[string]interface{}) bool {
+	if app, ok := body["app"].(map[string]interface{}); ok {
+		status, _ := app["status"].(map[string]interface{})
+		return hasAnyField(status, "sync", "health")
+	}
+	return stringField(body, "app", "application") != "" &&
+		hasAnyField(body, "syncStatus", "healthStatus", "sync_status", "health_status")
+}
+
+// argoCDApp holds the state of an Argo CD application.
+type argoCDApp struct {
+	name, project, sync, health, phase, revision, message, url string
+}
+
+// formatArgoCDPayload renders an Argo CD application notification. Failed
+// syncs and degraded applications get priority 8.
+func formatArgoCDPayload(body map[string]interface{}, _ *Config) plugin.Message {
+	app := argoCDApplication(body)
+	summary, priority := argoCDSummary(app)
+	title := "Argo CD: " + app.name + " " + summary
+
+	var paragraphs, lines []string
+	if text := strings.TrimSpace(app.message); text != "" {
+		paragraphs = append(paragraphs, text)
+	}
+	for _, field := range []struct{ label, value string }{
+		{"Sync", app.sync},
+		{"Health", app.health},
+		{"Operation", app.phase},
+		{"Revision", shortSHA(app.revision)},
+		{"Project", app.project},
+	} {
+		if field.value != "" {
+			lines = append(lines, field.label+": "+field.value)
+		}
+	}
+	if len(lines) > 0 {
+		paragraphs = append(paragraphs, strings.Join(lines, "\n"))
+	}
+	message := strings.Join(paragraphs, "\n\n")
+	if message == "" {
+		message = title
+	}
+
+	extras := map[string]interface{}{
+		"source": "argocd",
+		"app":    app.name,
+	}
+	for key, value := range map[string]string{
+		"syncStatus":   app.sync,
+		"healthStatus": app.health,
+		"revision":     app.revision,
+	} {
+		if value != "" {
+			extras[key] = value
+		}
+	}
+	if app.url != "" {
+		extras["client::notification"] = map[string]interface{}{
+			"click": map[string]interface{}{"url": app.url},
+		}
+	}
+
+	return plugin.Message{
+		Title:    title,
+		Message:  message,
+		Priority: priority,
+		Extras:   extras,
+	}
+}
+
+// argoCDApplication reads the application state from flat template fields
+// or from the application object.
+func argoCDApplication(body map[string]interface{}) argoCDApp {
+	app := argoCDApp{
+		name:     stringField(body, "app", "application"),
+		project:  stringField(body, "project"),
+		sync:     stringField(body, "syncStatus", "sync_status"),
+		health:   stringField(body, "healthStatus", "health_status"),
+		phase:    stringField(body, "operationPhase", "operation_phase", "phase"),
+		revision: stringField(body, "revision"),
+		message:  stringField(body, "message", "text"),
+		url:      stringField(body, "url", "appUrl", "app_url"),
+	}
+	object, ok := body["app"].(map[string]interface{})
+	if !ok {
+		return app
+	}
+	metadata, _ := object["metadata"].(map[string]interface{})
+	spec, _ := object["spec"].(map[string]interface{})
+	status, _ := object["status"].(map[string]interface{})
+	sync, _ := status["sync"].(map[string]interface{})
+	health, _ := status["health"].(map[string]interface{})
+	operation, _ := status["operationState"].(map[string]interface{})
+
+	app.name = stringField(metadata, "name")
+	if app.project == "" {
+		app.project = stringField(spec, "project")
+	}
+	app.sync = stringField(sync, "status")
+	app.health = stringField(health, "status")
+	app.phase = stringField(operation, "phase")
+	app.revision = stringField(sync, "revision")
+	if app.message == "" {
+		app.message = stringField(operation, "message")
+		if app.health == "Degraded" && stringField(health, "message") != "" {
+			app.message = stringField(health, "message")
+		}
+	}
+	return app
+}
+
+// argoCDSummary describes the state of an application and derives its
+// priority, checking the worst states first.
+func argoCDSummary(app argoCDApp) (string, int) {
+	switch {
+	case app.phase == "Failed" || app.phase == "Error":
+		return "sync failed", 8
+	case app.health == "Degraded":
+		return "degraded", 8
+	case app.health == "Missing":
+		return "missing", 7
+	case app.phase == "Running":
+		return "syncing", 3
+	case app.sync == "OutOfSync":
+		return "out of sync", 5
+	case app.health == "Progressing":
+		return "progressing", 3
+	case app.health == "Suspended":
+		return "suspended", 4
+	case app.phase == "Succeeded" || app.sync == "Synced":
+		return "synced", 3
+	}
+	return "status unknown", 5
+}
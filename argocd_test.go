@@ -0,0 +1,74 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gotify/plugin-api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_ArgoCDTemplate(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	w := postWebhook(p, map[string]interface{}{
+		"app":            "guestbook",
+		"project":        "default",
+		"syncStatus":     "Synced",
+		"healthStatus":   "Degraded",
+		"operationPhase": "Succeeded",
+		"revision":       "4e1a8c0d2b7f9e3a",
+		"url":            "https://argocd.example.com/applications/guestbook",
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title:    "Argo CD: guestbook degraded",
+		Message:  "Sync: Synced\nHealth: Degraded\nOperation: Succeeded\nRevision: 4e1a8c0\nProject: default",
+		Priority: 8,
+		Extras: map[string]interface{}{
+			"source":       "argocd",
+			"app":          "guestbook",
+			"syncStatus":   "Synced",
+			"healthStatus": "Degraded",
+			"revision":     "4e1a8c0d2b7f9e3a",
+			"client::notification": map[string]interface{}{
+				"click": map[string]interface{}{"url": "https://argocd.example.com/applications/guestbook"},
+			},
+		},
+	}, mockHandler.sentMessages[0])
+}
+
+func TestWebhookForwarderPlugin_ArgoCDApplication(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	app := func(phase, health string) map[string]interface{} {
+		return map[string]interface{}{
+			"app": map[string]interface{}{
+				"metadata": map[string]interface{}{"name": "shop"},
+				"spec":     map[string]interface{}{"project": "prod"},
+				"status": map[string]interface{}{
+					"sync":   map[string]interface{}{"status": "Synced", "revision": "a1b2c3d4e5"},
+					"health": map[string]interface{}{"status": health},
+					"operationState": map[string]interface{}{
+						"phase":   phase,
+						"message": "one or more objects failed to apply",
+					},
+				},
+			},
+		}
+	}
+
+	postWebhook(p, app("Failed", "Healthy"))
+	postWebhook(p, app("Succeeded", "Healthy"))
+
+	assert.Len(t, mockHandler.sentMessages, 2)
+	assert.Equal(t, "Argo CD: shop sync failed", mockHandler.sentMessages[0].Title)
+	assert.Equal(t, "one or more objects failed to apply\n\nSync: Synced\nHealth: Healthy\nOperation: Failed\nRevision: a1b2c3d\nProject: prod", mockHandler.sentMessages[0].Message)
+	assert.Equal(t, 8, mockHandler.sentMessages[0].Priority)
+	assert.Equal(t, "Argo CD: shop synced", mockHandler.sentMessages[1].Title)
+	assert.Equal(t, 3, mockHandler.sentMessages[1].Priority)
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gotify/plugin-api"
+)
+
+// arrEventTitles describes the event types of Sonarr, Radarr, Lidarr and
+// Readarr webhooks by the verb used in the title.
+var arrEventTitles = map[string]string{
+	"Grab":                      "Grabbed",
+	"Download":                  "Downloaded",
+	"Rename":                    "Renamed",
+	"SeriesAdd":                 "Added",
+	"MovieAdded":                "Added",
+	"ArtistAdd":                 "Added",
+	"AuthorAdded":               "Added",
+	"SeriesDelete":              "Deleted",
+	"MovieDelete":               "Deleted",
+	"ArtistDelete":              "Deleted",
+	"AuthorDelete":              "Deleted",
+	"EpisodeFileDelete":         "File deleted",
+	"MovieFileDelete":           "File deleted",
+	"TrackRetag":                "Retagged",
+	"ManualInteractionRequired": "Manual interaction required",
+}
+
+// isArrPayload detects webhook connections of the *arr apps, which share a
+// schema with an eventType and the series, movie, artist or author.
+func isArrPayload(body map[string]interface{}) bool {
+	eventType := stringField(body, "eventType")
+	if eventType == "" {
+		return false
+	}
+	if hasAnyField(body, "series", "movie", "artist", "author") {
+		return true
+	}
+	switch eventType {
+	case "Health", "HealthRestored":
+		return hasFields(body, "level", "message")
+	case "ApplicationUpdate", "Test":
+		return hasAnyField(body, "instanceName", "applicationUrl")
+	}
+	return false
+}
+
+// formatArrPayload renders an *arr event with the action and media in the
+// title, e.g. "Downloaded: The Office S02E05", and the episodes, quality
+// and download client as message. Health issues get priority 8 (errors) or
+// 7 (warnings), downloads 5, grabs 3.
+func formatArrPayload(body map[string]interface{}, _ *Config) plugin.Message {
+	eventType := stringField(body, "eventType")
+	app := stringField(body, "instanceName")
+	if app == "" {
+		app = arrApp(body)
+	}
+
+	var title string
+	var lines []string
+	priority := 4
+	link := stringField(body, "applicationUrl")
+	switch eventType {
+	case "Health":
+		title = fmt.Sprintf("%s health issue", app)
+		if check := stringField(body, "type"); check != "" {
+			title += ": " + check
+		}
+		lines = append(lines, stringField(body, "message"))
+		priority = 7
+		if strings.EqualFold(stringField(body, "level"), "error") {
+			priority = 8
+		}
+		if wiki := stringField(body, "wikiUrl"); wiki != "" {
+			link = wiki
+		}
+	case "HealthRestored":
+		title = fmt.Sprintf("%s health issue resolved", app)
+		if check := stringField(body, "type"); check != "" {
+			title += ": " + check
+		}
+		lines = append(lines, stringField(body, "message"))
+		priority = 3
+	case "ApplicationUpdate":
+		title = fmt.Sprintf("%s updated", app)
+		if version := stringField(body, "newVersion"); version != "" {
+			title += " to " + version
+		}
+		lines = append(lines, stringField(body, "message"))
+	case "Test":
+		title = fmt.Sprintf("%s test notification", app)
+		priority = 2
+	default:
+		verb, ok := arrEventTitles[eventType]
+		if !ok {
+			verb = eventType
+		}
+		if eventType == "Download" && body["isUpgrade"] == true {
+			verb = "Upgraded"
+		}
+		title = verb + ": " + arrMedia(body)
+		lines = arrDetails(body)
+		switch eventType {
+		case "Grab":
+			priority = 3
+		case "Download":
+			priority = 5
+		case "ManualInteractionRequired":
+			priority = 7
+		}
+	}
+
+	var nonEmpty []string
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" {
+			nonEmpty = append(nonEmpty, line)
+		}
+	}
+	message := strings.Join(nonEmpty, "\n")
+	if message == "" {
+		message = title
+	}
+
+	extras := map[string]interface{}{
+		"source":    "arr",
+		"app":       app,
+		"eventType": eventType,
+	}
+	if link != "" {
+		extras["client::notification"] = map[string]interface{}{
+			"click": map[string]interface{}{"url": link},
+		}
+	}
+
+	return plugin.Message{
+		Title:    title,
+		Message:  message,
+		Priority: priority,
+		Extras:   extras,
+	}
+}
+
+// arrApp names the app of a payload without instanceName by its media.
+func arrApp(body map[string]interface{}) string {
+	switch {
+	case hasAnyField(body, "series"):
+		return "Sonarr"
+	case hasAnyField(body, "movie"):
+		return "Radarr"
+	case hasAnyField(body, "artist"):
+		return "Lidarr"
+	case hasAnyField(body, "author"):
+		return "Readarr"
+	}
+	return "*arr"
+}
+
+// arrMedia describes the media of an event: the series with its episodes,
+// the movie with its year, the artist with its albums or the author with
+// its books.
+func arrMedia(body map[string]interface{}) string {
+	if series, ok := body["series"].(map[string]interface{}); ok {
+		name := stringField(series, "title")
+		episodes := mapSlice(body["episodes"])
+		if len(episodes) == 1 {
+			name += " " + arrEpisode(episodes[0])
+		} else if len(episodes) > 1 {
+			name += fmt.Sprintf(" (%d episodes)", len(episodes))
+		}
+		return name
+	}
+	if movie, ok := body["movie"].(map[string]interface{}); ok {
+		name := stringField(movie, "title")
+		if year := stringField(movie, "year"); year != "" && year != "0" {
+			name += " (" + year + ")"
+		}
+		return name
+	}
+	if artist, ok := body["artist"].(map[string]interface{}); ok {
+		name := stringField(artist, "name")
+		if albums := mapSlice(body["albums"]); len(albums) == 1 {
+			name += " - " + stringField(albums[0], "title")
+		}
+		return name
+	}
+	if author, ok := body["author"].(map[string]interface{}); ok {
+		name := stringField(author, "name")
+		if books := mapSlice(body["books"]); len(books) == 1 {
+			name += " - " + stringField(books[0], "title")
+		}
+		return name
+	}
+	return ""
+}
+
+// arrEpisode formats an episode number as S01E02.
+func arrEpisode(episode map[string]interface{}) string {
+	return fmt.Sprintf("S%02dE%02d", intField(episode, "seasonNumber"), intField(episode, "episodeNumber"))
+}
+
+// arrDetails lists the episodes, release, quality and download client of
+// an event.
+func arrDetails(body map[string]interface{}) []string {
+	var lines []string
+	for _, episode := range mapSlice(body["episodes"]) {
+		line := arrEpisode(episode)
+		if title := stringField(episode, "title"); title != "" {
+			line += " - " + title
+		}
+		lines = append(lines, line)
+	}
+	release, _ := body["release"].(map[string]interface{})
+	quality := stringField(release, "quality")
+	for _, key := range []string{"episodeFile", "movieFile"} {
+		if file, ok := body[key].(map[string]interface{}); ok && quality == "" {
+			quality = stringField(file, "quality")
+		}
+	}
+	if quality != "" {
+		lines = append(lines, "Quality: "+quality)
+	}
+	if name := stringField(release, "releaseTitle"); name != "" {
+		lines = append(lines, "Release: "+name)
+	}
+	if indexer := stringField(release, "indexer"); indexer != "" {
+		lines = append(lines, "Indexer: "+indexer)
+	}
+	if client := stringField(body, "downloadClient"); client != "" {
+		lines = append(lines, "Download client: "+client)
+	}
+	return lines
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gotify/plugin-api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_ArrWebhook(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	w := postWebhook(p, map[string]interface{}{
+		"eventType":      "Download",
+		"instanceName":   "Sonarr",
+		"applicationUrl": "https://sonarr.example.com",
+		"series":         map[string]interface{}{"id": 1.0, "title": "The Office", "year": 2005.0},
+		"episodes": []interface{}{
+			map[string]interface{}{"seasonNumber": 2.0, "episodeNumber": 5.0, "title": "Halloween"},
+		},
+		"episodeFile":    map[string]interface{}{"quality": "HDTV-720p"},
+		"downloadClient": "qBittorrent",
+		"isUpgrade":      true,
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title:    "Upgraded: The Office S02E05",
+		Message:  "S02E05 - Halloween\nQuality: HDTV-720p\nDownload client: qBittorrent",
+		Priority: 5,
+		Extras: map[string]interface{}{
+			"source":    "arr",
+			"app":       "Sonarr",
+			"eventType": "Download",
+			"client::notification": map[string]interface{}{
+				"click": map[string]interface{}{"url": "https://sonarr.example.com"},
+			},
+		},
+	}, mockHandler.sentMessages[0])
+
+	msg := formatArrPayload(map[string]interface{}{
+		"eventType": "Grab",
+		"movie":     map[string]interface{}{"title": "Dune", "year": 2021.0},
+		"release": map[string]interface{}{
+			"quality":      "Bluray-1080p",
+			"releaseTitle": "Dune.2021.1080p.BluRay",
+			"indexer":      "NZBgeek",
+		},
+	}, defaultConfig())
+	assert.Equal(t, "Grabbed: Dune (2021)", msg.Title)
+	assert.Equal(t, "Quality: Bluray-1080p\nRelease: Dune.2021.1080p.BluRay\nIndexer: NZBgeek", msg.Message)
+	assert.Equal(t, 3, msg.Priority)
+	assert.Equal(t, "Radarr", msg.Extras["app"])
+
+	health := map[string]interface{}{
+		"eventType":    "Health",
+		"instanceName": "Radarr",
+		"level":        "error",
+		"message":      "All indexers are unavailable due to failures",
+		"type":         "IndexerStatusCheck",
+		"wikiUrl":      "https://wiki.servarr.com/radarr/system#indexers-are-unavailable-due-to-failures",
+	}
+	assert.True(t, isArrPayload(health))
+	msg = formatArrPayload(health, defaultConfig())
+	assert.Equal(t, "Radarr health issue: IndexerStatusCheck", msg.Title)
+	assert.Equal(t, "All indexers are unavailable due to failures", msg.Message)
+	assert.Equal(t, 8, msg.Priority)
+
+	health["level"] = "warning"
+	assert.Equal(t, 7, formatArrPayload(health, defaultConfig()).Priority)
+}
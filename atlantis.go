@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gotify/plugin-api"
+)
+
+// isAtlantisPayload detects the results Atlantis posts to webhooks of kind
+// "http", which describe the repository and pull request with Go field
+// names.
+func isAtlantisPayload(body map[string]interface{}) bool {
+	_, hasRepo := body["Repo"].(map[string]interface{})
+	_, hasPull := body["Pull"].(map[string]interface{})
+	_, hasSuccess := body["Success"].(bool)
+	return hasRepo && hasPull && hasSuccess
+}
+
+// formatAtlantisPayload renders an Atlantis plan or apply result titled by
+// the command, its outcome and the pull request, e.g. "Atlantis: apply
+// failed for octo/infra#42". Failures get priority 8. Clicking opens the pull
+// request.
+func formatAtlantisPayload(body map[string]interface{}, _ *Config) plugin.Message {
+	repo, _ := body["Repo"].(map[string]interface{})
+	pull, _ := body["Pull"].(map[string]interface{})
+	user, _ := body["User"].(map[string]interface{})
+	success, _ := body["Success"].(bool)
+
+	// Atlantis only sends apply results, plan results may be relayed with
+	// the command named
+	command := strings.ToLower(stringField(body, "Command", "Event", "command", "event"))
+	if command == "" {
+		command = "apply"
+	}
+	outcome, priority := "failed", 8
+	if success {
+		outcome, priority = "succeeded", 3
+	}
+
+	target := stringField(repo, "FullName")
+	if number := stringField(pull, "Num"); number != "" {
+		target += "#" + number
+	}
+	title := fmt.Sprintf("Atlantis: %s %s for %s", command, outcome, target)
+
+	var lines []string
+	if subject := stringField(pull, "Title"); subject != "" {
+		lines = append(lines, subject)
+	}
+	for _, field := range []struct{ label, value string }{
+		{"Project", stringField(body, "ProjectName")},
+		{"Directory", stringField(body, "Directory")},
+		{"Workspace", stringField(body, "Workspace")},
+		{"Commit", shortSHA(stringField(pull, "HeadCommit"))},
+		{"User", stringField(user, "Username")},
+	} {
+		if field.value != "" {
+			lines = append(lines, field.label+": "+field.value)
+		}
+	}
+	if head, base := stringField(pull, "HeadBranch"), stringField(pull, "BaseBranch"); head != "" && base != "" {
+		lines = append(lines, "Branch: "+head+" → "+base)
+	}
+	message := strings.Join(lines, "\n")
+	if message == "" {
+		message = title
+	}
+
+	extras := map[string]interface{}{
+		"source":  "atlantis",
+		"command": command,
+		"success": success,
+	}
+	if name := stringField(repo, "FullName"); name != "" {
+		extras["repository"] = name
+	}
+	if url := stringField(pull, "URL"); url != "" {
+		extras["client::notification"] = map[string]interface{}{
+			"click": map[string]interface{}{"url": url},
+		}
+	}
+
+	return plugin.Message{
+		Title:    title,
+		Message:  message,
+		Priority: priority,
+		Extras:   extras,
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gotify/plugin-api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_AtlantisApply(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	w := postWebhook(p, map[string]interface{}{
+		"Workspace": "default",
+		"Repo": map[string]interface{}{
+			"FullName": "octo/infra",
+			"Owner":    "octo",
+			"Name":     "infra",
+		},
+		"Pull": map[string]interface{}{
+			"Num":        42.0,
+			"HeadCommit": "4e1a8c0d2b7f",
+			"URL":        "https://github.com/octo/infra/pull/42",
+			"HeadBranch": "add-bucket",
+			"BaseBranch": "main",
+			"Author":     "alice",
+		},
+		"User":        map[string]interface{}{"Username": "bob"},
+		"Success":     false,
+		"Directory":   "envs/prod",
+		"ProjectName": "prod",
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title:    "Atlantis: apply failed for octo/infra#42",
+		Message:  "Project: prod\nDirectory: envs/prod\nWorkspace: default\nCommit: 4e1a8c0\nUser: bob\nBranch: add-bucket → main",
+		Priority: 8,
+		Extras: map[string]interface{}{
+			"source":     "atlantis",
+			"command":    "apply",
+			"success":    false,
+			"repository": "octo/infra",
+			"client::notification": map[string]interface{}{
+				"click": map[string]interface{}{"url": "https://github.com/octo/infra/pull/42"},
+			},
+		},
+	}, mockHandler.sentMessages[0])
+}
+
+func TestWebhookForwarderPlugin_AtlantisPlanSucceeded(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	postWebhook(p, map[string]interface{}{
+		"Command": "Plan",
+		"Repo":    map[string]interface{}{"FullName": "octo/infra"},
+		"Pull":    map[string]interface{}{"Num": 7.0},
+		"Success": true,
+	})
+
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, "Atlantis: plan succeeded for octo/infra#7", mockHandler.sentMessages[0].Title)
+	assert.Equal(t, 3, mockHandler.sentMessages[0].Priority)
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gotify/plugin-api"
+)
+
+// autheliaEvent describes how a known Authelia event is presented.
+type autheliaEvent struct {
+	title    string
+	priority int
+}
+
+// autheliaEvents maps Authelia notification events to their presentation.
+var autheliaEvents = map[string]autheliaEvent{
+	"identity_verification": {title: "Identity verification requested", priority: 5},
+	"password_reset":        {title: "Password reset requested", priority: 5},
+	"first_factor_failed":   {title: "Failed login attempt", priority: 6},
+	"second_factor_failed":  {title: "Failed 2FA attempt", priority: 7},
+	"user_banned":           {title: "User banned", priority: 8},
+	"ip_banned":             {title: "IP banned", priority: 8},
+	"second_factor_added":   {title: "2FA device added", priority: 5},
+	"second_factor_removed": {title: "2FA device removed", priority: 6},
+}
+
+// isAutheliaPayload detects Authelia notifications, either explicitly tagged
+// with source "authelia" or carrying a known event with the remote IP.
+func isAutheliaPayload(body map[string]interface{}) bool {
+	if sourceIs(body, "authelia") {
+		return true
+	}
+	_, known := autheliaEvents[strings.ToLower(stringField(body, "event"))]
+	return known && stringField(body, "remote_ip", "remoteIP", "RemoteIP") != ""
+}
+
+// formatAutheliaPayload renders an Authelia notification with user and
+// source IP context.
+func formatAutheliaPayload(body map[string]interface{}, _ *Config) plugin.Message {
+	event := strings.ToLower(stringField(body, "event"))
+	user := stringField(body, "username", "user", "display_name", "DisplayName")
+	remoteIP := stringField(body, "remote_ip", "remoteIP", "RemoteIP")
+	domain := stringField(body, "domain", "Domain")
+
+	info, known := autheliaEvents[event]
+	if !known {
+		info = autheliaEvent{title: "Notification", priority: 5}
+		if event != "" {
+			info.title = strings.ReplaceAll(event, "_", " ")
+		}
+	}
+
+	title := "Authelia: " + info.title
+	if user != "" {
+		title += " for " + user
+	}
+
+	var lines []string
+	if text := stringField(body, "message", "title", "Title"); text != "" {
+		lines = append(lines, text, "")
+	}
+	if user != "" {
+		lines = append(lines, fmt.Sprintf("User: %s", user))
+	}
+	if remoteIP != "" {
+		lines = append(lines, fmt.Sprintf("Source IP: %s", remoteIP))
+	}
+	if domain != "" {
+		lines = append(lines, fmt.Sprintf("Domain: %s", domain))
+	}
+	if method := stringField(body, "method"); method != "" {
+		lines = append(lines, fmt.Sprintf("Method: %s", method))
+	}
+
+	extras := map[string]interface{}{"source": "authelia"}
+	if event != "" {
+		extras["event"] = event
+	}
+	if user != "" {
+		extras["user"] = user
+	}
+	if remoteIP != "" {
+		extras["remoteIP"] = remoteIP
+	}
+
+	return plugin.Message{
+		Title:    title,
+		Message:  strings.TrimSpace(strings.Join(lines, "\n")),
+		Priority: info.priority,
+		Extras:   extras,
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gotify/plugin-api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_AutheliaWebhook(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	w := postWebhook(p, map[string]interface{}{
+		"event":     "second_factor_failed",
+		"username":  "john",
+		"remote_ip": "203.0.113.7",
+		"domain":    "auth.example.com",
+		"method":    "totp",
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title:    "Authelia: Failed 2FA attempt for john",
+		Message:  "User: john\nSource IP: 203.0.113.7\nDomain: auth.example.com\nMethod: totp",
+		Priority: 7,
+		Extras: map[string]interface{}{
+			"source":   "authelia",
+			"event":    "second_factor_failed",
+			"user":     "john",
+			"remoteIP": "203.0.113.7",
+		},
+	}, mockHandler.sentMessages[0])
+}
+
+func TestIsAutheliaPayload(t *testing.T) {
+	assert.True(t, isAutheliaPayload(map[string]interface{}{"source": "Authelia", "message": "x"}))
+	assert.True(t, isAutheliaPayload(map[string]interface{}{"event": "user_banned", "remote_ip": "10.0.0.1"}))
+	assert.False(t, isAutheliaPayload(map[string]interface{}{"event": "user_banned"}))
+	assert.False(t, isAutheliaPayload(map[string]interface{}{"message": "hello"}))
+}
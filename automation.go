@@ -0,0 +1,182 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gotify/plugin-api"
+)
+
+// isAWXPayload detects the default body of AWX and Ansible Automation
+// Platform webhook notifications.
+func isAWXPayload(body map[string]interface{}) bool {
+	return hasFields(body, "id", "name", "status", "url") &&
+		hasAnyField(body, "friendly_name", "playbook", "inventory", "hosts")
+}
+
+// formatAWXPayload renders an AWX job titled by the job template, job ID
+// and status, e.g. "AWX: Deploy web #38 failed", listing the failed hosts.
+// Failed jobs get priority 8. Clicking opens the job.
+func formatAWXPayload(body map[string]interface{}, _ *Config) plugin.Message {
+	build := ciBuild{
+		source:  "awx",
+		service: "AWX",
+		project: stringField(body, "name"),
+		number:  stringField(body, "id"),
+		status:  ciStatus(stringField(body, "status")),
+		url:     stringField(body, "url"),
+	}
+	build.duration = ciElapsed(stringField(body, "started"), stringField(body, "finished"))
+
+	hosts, _ := body["hosts"].(map[string]interface{})
+	var failed []string
+	for name, value := range hosts {
+		summary, _ := value.(map[string]interface{})
+		if summary["failed"] == true || intField(summary, "failures", "dark") > 0 {
+			failed = append(failed, name)
+		}
+	}
+	sort.Strings(failed)
+	for _, field := range []struct{ label, value string }{
+		{"Inventory", stringField(body, "inventory")},
+		{"Playbook", stringField(body, "playbook")},
+		{"Limit", stringField(body, "limit")},
+		{"Failed hosts", strings.Join(failed, ", ")},
+		{"Launched by", stringField(body, "created_by")},
+	} {
+		if field.value != "" {
+			build.details = append(build.details, field.label+": "+field.value)
+		}
+	}
+	if traceback := firstLine(stringField(body, "traceback")); traceback != "" {
+		build.details = append(build.details, "Error: "+traceback)
+	}
+	return ciMessage(build)
+}
+
+// isRundeckPayload detects JSON webhook notifications of Rundeck, which
+// describe the execution and its job.
+func isRundeckPayload(body map[string]interface{}) bool {
+	execution, ok := body["execution"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	_, hasJob := execution["job"].(map[string]interface{})
+	return hasJob && hasAnyField(body, "executionId", "trigger", "status")
+}
+
+// formatRundeckPayload renders a Rundeck execution titled by the job group
+// and name, e.g. "Rundeck: ops/backup #5 failed", listing the failed nodes.
+// Executions running longer than average are reported as overdue.
+func formatRundeckPayload(body map[string]interface{}, _ *Config) plugin.Message {
+	execution, _ := body["execution"].(map[string]interface{})
+	job, _ := execution["job"].(map[string]interface{})
+
+	name := stringField(job, "name")
+	if group := stringField(job, "group"); group != "" {
+		name = group + "/" + name
+	}
+	status := ciStatus(stringField(execution, "status", "state"))
+	if status == "" {
+		status = ciStatus(stringField(body, "status"))
+	}
+	if stringField(body, "trigger") == "avgduration" {
+		status = "overdue"
+	}
+
+	build := ciBuild{
+		source:  "rundeck",
+		service: "Rundeck",
+		project: name,
+		number:  stringField(execution, "id"),
+		status:  status,
+		url:     stringField(execution, "permalink", "href"),
+	}
+	started, _ := execution["date-started"].(map[string]interface{})
+	ended, _ := execution["date-ended"].(map[string]interface{})
+	if start, end := intField(started, "unixtime"), intField(ended, "unixtime"); start > 0 && end > start {
+		build.duration = time.Duration(end-start) * time.Millisecond
+	}
+	for _, field := range []struct{ label, value string }{
+		{"Project", stringField(execution, "project")},
+		{"Failed nodes", joinStrings(execution["failedNodes"])},
+		{"Started by", stringField(execution, "user")},
+	} {
+		if field.value != "" {
+			build.details = append(build.details, field.label+": "+field.value)
+		}
+	}
+	if build.project == "" {
+		build.project = "Job"
+	}
+	return ciMessage(build)
+}
+
+// isAirflowPayload detects the callbacks of Apache Airflow DAGs and tasks
+// posted with an HTTP hook or notifier, which carry the DAG ID and the state
+// of the run or task instance.
+func isAirflowPayload(body map[string]interface{}) bool {
+	return stringField(body, "dag_id") != "" && hasAnyField(body, "state", "status") &&
+		hasAnyField(body, "task_id", "run_id", "execution_date", "logical_date", "log_url")
+}
+
+// formatAirflowPayload renders a DAG run or task instance titled by DAG,
+// task and state, e.g. "Airflow: etl.load failed", with the exception and a
+// link to the task log. Failed runs get priority 8, retries 5. Clicking
+// opens the log.
+func formatAirflowPayload(body map[string]interface{}, config *Config) plugin.Message {
+	project := stringField(body, "dag_id")
+	if task := stringField(body, "task_id"); task != "" {
+		project += "." + task
+	}
+	status := stringField(body, "state", "status")
+	switch strings.ToLower(status) {
+	case "up_for_retry", "up_for_reschedule":
+		status = "retrying"
+	case "sla_miss":
+		status = "missed its SLA"
+	default:
+		status = ciStatus(status)
+	}
+	build := ciBuild{
+		source:  "airflow",
+		service: "Airflow",
+		project: project,
+		status:  status,
+		url:     stringField(body, "log_url", "url"),
+	}
+
+	if text := strings.TrimSpace(stringField(body, "exception", "error")); text != "" {
+		build.details = append(build.details, "Error: "+firstLine(text))
+	}
+	logical := config.formatTimestamp(stringField(body, "logical_date", "execution_date"))
+	for _, field := range []struct{ label, value string }{
+		{"Run", stringField(body, "run_id")},
+		{"Logical date", logical},
+		{"Owner", stringField(body, "owner")},
+	} {
+		if field.value != "" {
+			build.details = append(build.details, field.label+": "+field.value)
+		}
+	}
+	if try := intField(body, "try_number"); try > 0 {
+		attempt := strconv.Itoa(try)
+		if tries := intField(body, "max_tries"); tries > 0 {
+			// Airflow counts retries in max_tries, not attempts
+			attempt += "/" + strconv.Itoa(tries+1)
+		}
+		build.details = append(build.details, "Try: "+attempt)
+	}
+	if build.url != "" {
+		build.details = append(build.details, "Log: "+build.url)
+	}
+
+	if seconds, ok := body["duration"].(float64); ok && seconds > 0 {
+		build.duration = time.Duration(seconds * float64(time.Second))
+	} else {
+		build.duration = ciElapsed(stringField(body, "start_date"), stringField(body, "end_date"))
+	}
+	return ciMessage(build)
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gotify/plugin-api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_AWXJob(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	w := postWebhook(p, map[string]interface{}{
+		"id":            38.0,
+		"name":          "Deploy web",
+		"url":           "https://awx.example.com/#/jobs/playbook/38",
+		"created_by":    "admin",
+		"started":       "2024-03-01T10:00:00.000000Z",
+		"finished":      "2024-03-01T10:02:00.000000Z",
+		"status":        "failed",
+		"traceback":     "",
+		"inventory":     "Production",
+		"project":       "Web",
+		"playbook":      "deploy.yml",
+		"friendly_name": "Job",
+		"hosts": map[string]interface{}{
+			"web1": map[string]interface{}{"failed": false, "failures": 0.0, "ok": 5.0},
+			"web2": map[string]interface{}{"failed": true, "failures": 1.0, "ok": 3.0},
+		},
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title:    "AWX: Deploy web #38 failed",
+		Message:  "Inventory: Production\nPlaybook: deploy.yml\nFailed hosts: web2\nLaunched by: admin\nDuration: 2m",
+		Priority: 8,
+		Extras: map[string]interface{}{
+			"source":  "awx",
+			"project": "Deploy web",
+			"build":   "38",
+			"status":  "failed",
+			"client::notification": map[string]interface{}{
+				"click": map[string]interface{}{"url": "https://awx.example.com/#/jobs/playbook/38"},
+			},
+		},
+	}, mockHandler.sentMessages[0])
+}
+
+func TestWebhookForwarderPlugin_RundeckExecution(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	execution := func(trigger, status string) map[string]interface{} {
+		return map[string]interface{}{
+			"executionId": 5.0,
+			"trigger":     trigger,
+			"status":      status,
+			"execution": map[string]interface{}{
+				"id":           5.0,
+				"permalink":    "https://rundeck.example.com/project/ops/execution/show/5",
+				"status":       status,
+				"project":      "ops",
+				"user":         "alice",
+				"date-started": map[string]interface{}{"unixtime": 1700000000000.0},
+				"date-ended":   map[string]interface{}{"unixtime": 1700000300000.0},
+				"job":          map[string]interface{}{"name": "backup", "group": "nightly"},
+				"failedNodes":  []interface{}{"db1"},
+			},
+		}
+	}
+
+	postWebhook(p, execution("failure", "failed"))
+	postWebhook(p, execution("success", "succeeded"))
+
+	assert.Len(t, mockHandler.sentMessages, 2)
+	assert.Equal(t, "Rundeck: nightly/backup #5 failed", mockHandler.sentMessages[0].Title)
+	assert.Equal(t, "Project: ops\nFailed nodes: db1\nStarted by: alice\nDuration: 5m", mockHandler.sentMessages[0].Message)
+	assert.Equal(t, 8, mockHandler.sentMessages[0].Priority)
+	assert.Equal(t, "Rundeck: nightly/backup #5 succeeded", mockHandler.sentMessages[1].Title)
+	assert.Equal(t, 3, mockHandler.sentMessages[1].Priority)
+}
+
+func TestWebhookForwarderPlugin_AirflowTaskFailure(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+	p.SetStorageHandler(&MockStorageHandler{})
+	config := defaultConfig()
+	config.Timezone = "UTC"
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+
+	logURL := "http://airflow:8080/dags/etl_daily/grid?dag_run_id=scheduled__2024-03-01&task_id=load_orders&tab=logs"
+	w := postWebhook(p, map[string]interface{}{
+		"dag_id":         "etl_daily",
+		"task_id":        "load_orders",
+		"run_id":         "scheduled__2024-03-01T00:00:00+00:00",
+		"state":          "failed",
+		"execution_date": "2024-03-01T00:00:00+00:00",
+		"try_number":     3.0,
+		"max_tries":      2.0,
+		"exception":      "psycopg2.OperationalError: could not connect to server\nIs the server running?",
+		"log_url":        logURL,
+		"duration":       125.4,
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title: "Airflow: etl_daily.load_orders failed",
+		Message: "Error: psycopg2.OperationalError: could not connect to server\n" +
+			"Run: scheduled__2024-03-01T00:00:00+00:00\nLogical date: 2024-03-01 00:00:00 UTC\nTry: 3/3\n" +
+			"Log: " + logURL + "\nDuration: 2m",
+		Priority: 8,
+		Extras: map[string]interface{}{
+			"source":  "airflow",
+			"project": "etl_daily.load_orders",
+			"status":  "failed",
+			"client::notification": map[string]interface{}{
+				"click": map[string]interface{}{"url": logURL},
+			},
+		},
+	}, mockHandler.sentMessages[0])
+}
+
+func TestWebhookForwarderPlugin_AirflowRetry(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	postWebhook(p, map[string]interface{}{
+		"dag_id":     "etl_daily",
+		"task_id":    "extract",
+		"state":      "up_for_retry",
+		"run_id":     "manual__2024-03-01",
+		"start_date": "2024-03-01T10:00:00Z",
+		"end_date":   "2024-03-01T10:00:42Z",
+	})
+
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, "Airflow: etl_daily.extract retrying", mockHandler.sentMessages[0].Title)
+	assert.Equal(t, "Run: manual__2024-03-01\nDuration: 42s", mockHandler.sentMessages[0].Message)
+	assert.Equal(t, 5, mockHandler.sentMessages[0].Priority)
+}
@@ -0,0 +1,43 @@
+package main
+
+import "time"
+
+// backgroundInterval is how often the background tasks run.
+const backgroundInterval = time.Minute
+
+// startBackgroundTasks starts the periodic tasks of the plugin: escalating
+// alerts that keep firing and sending due dependency digests. They run
+// until stopBackgroundTasks is called.
+func (p *WebhookForwarderPlugin) startBackgroundTasks() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.backgroundDone != nil {
+		return
+	}
+	done := make(chan struct{})
+	p.backgroundDone = done
+
+	go func() {
+		ticker := time.NewTicker(backgroundInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				p.escalateAlerts(timeNow())
+				p.flushDependencyDigest(timeNow())
+			}
+		}
+	}()
+}
+
+// stopBackgroundTasks stops the tasks started by startBackgroundTasks.
+func (p *WebhookForwarderPlugin) stopBackgroundTasks() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.backgroundDone != nil {
+		close(p.backgroundDone)
+		p.backgroundDone = nil
+	}
+}
@@ -0,0 +1,17 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_BackgroundTasksLifecycle(t *testing.T) {
+	p := &WebhookForwarderPlugin{}
+	assert.NoError(t, p.Enable())
+	assert.NotNil(t, p.backgroundDone)
+	p.startBackgroundTasks()
+	assert.NoError(t, p.Disable())
+	assert.Nil(t, p.backgroundDone)
+	p.stopBackgroundTasks()
+}
@@ -0,0 +1,340 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gotify/plugin-api"
+)
+
+// backupPriorities maps normalised backup results onto priorities. Failed
+// backups get 9 as they are easily missed until the data is needed.
+var backupPriorities = map[string]int{
+	"failed":    9,
+	"warning":   6,
+	"started":   2,
+	"succeeded": 3,
+}
+
+// backupResults normalises the results reported by backup tools to
+// "succeeded", "warning", "failed" or "started".
+var backupResults = map[string]string{
+	"success":   "succeeded",
+	"succeeded": "succeeded",
+	"ok":        "succeeded",
+	"finish":    "succeeded",
+	"finished":  "succeeded",
+	"completed": "succeeded",
+	"warning":   "warning",
+	"warnings":  "warning",
+	"error":     "failed",
+	"fatal":     "failed",
+	"fail":      "failed",
+	"failed":    "failed",
+	"failure":   "failed",
+	"start":     "started",
+	"started":   "started",
+	"running":   "started",
+}
+
+// backupTools names the tools whose templated webhooks are recognised by
+// their "source" field.
+var backupTools = map[string]string{
+	"borgmatic":     "borgmatic",
+	"borg":          "Borg",
+	"restic":        "restic",
+	"resticprofile": "resticprofile",
+	"autorestic":    "autorestic",
+	"backup":        "Backup",
+}
+
+// backupReport is the result of a backup run.
+type backupReport struct {
+	source, tool  string
+	name, result  string
+	operation     string
+	host          string
+	files, errors int
+	size          int64
+	duration      time.Duration
+	problems      []string
+	details       []string
+}
+
+// isDuplicatiPayload detects the JSON report Duplicati sends with
+// --send-http-url and --send-http-result-output-format=Json.
+func isDuplicatiPayload(body map[string]interface{}) bool {
+	data, ok := body["Data"].(map[string]interface{})
+	return ok && hasAnyField(data, "ParsedResult", "MainOperation")
+}
+
+// formatDuplicatiPayload renders a Duplicati report, e.g. "Duplicati:
+// Documents backup failed" with the warnings and errors of the run.
+func formatDuplicatiPayload(body map[string]interface{}, _ *Config) plugin.Message {
+	data, _ := body["Data"].(map[string]interface{})
+	extra, _ := body["Extra"].(map[string]interface{})
+
+	report := backupReport{
+		source:    "duplicati",
+		tool:      "Duplicati",
+		name:      stringField(extra, "backup-name"),
+		result:    stringField(data, "ParsedResult"),
+		operation: stringField(extra, "OperationName"),
+		host:      stringField(extra, "machine-name"),
+		files:     intField(data, "ExaminedFiles"),
+		errors:    intField(data, "FilesWithError"),
+		size:      int64(intField(data, "SizeOfExaminedFiles")),
+		duration:  duplicatiDuration(stringField(data, "Duration")),
+	}
+	if report.operation == "" {
+		report.operation = stringField(data, "MainOperation")
+	}
+	if added := intField(data, "AddedFiles"); added > 0 || intField(data, "ModifiedFiles") > 0 {
+		report.details = append(report.details, fmt.Sprintf("Changes: %d added, %d modified, %d deleted",
+			added, intField(data, "ModifiedFiles"), intField(data, "DeletedFiles")))
+	}
+	if uploaded := intField(data, "SizeOfAddedFiles") + intField(data, "SizeOfModifiedFiles"); uploaded > 0 {
+		report.details = append(report.details, "Changed data: "+humanizeBytes(int64(uploaded)))
+	}
+	for _, key := range []string{"Errors", "Warnings"} {
+		if items, ok := data[key].([]interface{}); ok {
+			for _, item := range items {
+				if text := looseString(item); text != "" {
+					report.problems = append(report.problems, text)
+				}
+			}
+		}
+	}
+	return backupMessage(report)
+}
+
+// duplicatiDuration parses a .NET time span such as "00:05:12.3456789" or
+// "1.02:00:00".
+func duplicatiDuration(span string) time.Duration {
+	var days int
+	if dot := strings.Index(span, "."); dot >= 0 && dot < strings.Index(span, ":") {
+		days, _ = strconv.Atoi(span[:dot])
+		span = span[dot+1:]
+	}
+	parts := strings.Split(span, ":")
+	if len(parts) != 3 {
+		return 0
+	}
+	hours, err1 := strconv.Atoi(parts[0])
+	minutes, err2 := strconv.Atoi(parts[1])
+	seconds, err3 := strconv.ParseFloat(parts[2], 64)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return 0
+	}
+	return time.Duration(days)*24*time.Hour + time.Duration(hours)*time.Hour +
+		time.Duration(minutes)*time.Minute + time.Duration(seconds*float64(time.Second))
+}
+
+// isBackupToolPayload detects webhooks of borgmatic, restic and their
+// wrappers: templated payloads naming the tool in "source" with a status,
+// the JSON output of "borg create --json" and restic's backup summary.
+func isBackupToolPayload(body map[string]interface{}) bool {
+	if _, ok := backupTools[strings.ToLower(stringField(body, "source", "app"))]; ok {
+		return hasAnyField(body, "status", "result", "state", "success", "error")
+	}
+	if stringField(body, "message_type") == "summary" && hasFields(body, "snapshot_id") {
+		return true
+	}
+	archive, ok := body["archive"].(map[string]interface{})
+	_, hasRepository := body["repository"].(map[string]interface{})
+	return ok && hasRepository && hasFields(archive, "stats")
+}
+
+// formatBackupToolPayload renders the result of a borgmatic, Borg or restic
+// run, e.g. "borgmatic: nas backup failed".
+func formatBackupToolPayload(body map[string]interface{}, _ *Config) plugin.Message {
+	switch {
+	case stringField(body, "message_type") == "summary":
+		return backupMessage(resticSummaryReport(body))
+	case hasFields(body, "archive") && !hasFields(body, "source"):
+		return backupMessage(borgCreateReport(body))
+	}
+
+	key := strings.ToLower(stringField(body, "source", "app"))
+	report := backupReport{
+		source:    key,
+		tool:      backupTools[key],
+		name:      stringField(body, "name", "profile", "config", "repository", "repo"),
+		result:    stringField(body, "status", "result", "state"),
+		operation: stringField(body, "operation", "command", "action"),
+		host:      stringField(body, "host", "hostname"),
+		files:     intField(body, "files", "total_files_processed", "nfiles"),
+		size:      int64(intField(body, "size", "bytes", "total_bytes_processed", "original_size")),
+	}
+	if success, ok := body["success"].(bool); ok && report.result == "" {
+		report.result = "failed"
+		if success {
+			report.result = "succeeded"
+		}
+	}
+	if text := stringField(body, "error", "stderr"); text != "" {
+		report.problems = append(report.problems, text)
+		if report.result == "" {
+			report.result = "failed"
+		}
+	}
+	if text := stringField(body, "message", "output"); text != "" {
+		report.details = append(report.details, text)
+	}
+	report.duration = backupDuration(body["duration"])
+	return backupMessage(report)
+}
+
+// resticSummaryReport reads the summary line of "restic backup --json".
+func resticSummaryReport(body map[string]interface{}) backupReport {
+	report := backupReport{
+		source:   "restic",
+		tool:     "restic",
+		name:     stringField(body, "profile", "name"),
+		result:   "succeeded",
+		files:    intField(body, "total_files_processed"),
+		size:     int64(intField(body, "total_bytes_processed")),
+		duration: backupDuration(body["total_duration"]),
+	}
+	report.details = append(report.details,
+		fmt.Sprintf("Changes: %d new, %d changed, %d unmodified",
+			intField(body, "files_new"), intField(body, "files_changed"), intField(body, "files_unmodified")))
+	if added := intField(body, "data_added"); added > 0 {
+		report.details = append(report.details, "Added: "+humanizeBytes(int64(added)))
+	}
+	if snapshot := stringField(body, "snapshot_id"); snapshot != "" {
+		report.details = append(report.details, "Snapshot: "+shortSHA(snapshot))
+	}
+	return report
+}
+
+// borgCreateReport reads the JSON output of "borg create --json", which
+// borgmatic prints with --json.
+func borgCreateReport(body map[string]interface{}) backupReport {
+	archive, _ := body["archive"].(map[string]interface{})
+	repository, _ := body["repository"].(map[string]interface{})
+	stats, _ := archive["stats"].(map[string]interface{})
+
+	report := backupReport{
+		source:   "borg",
+		tool:     "Borg",
+		name:     stringField(archive, "name"),
+		result:   "succeeded",
+		files:    intField(stats, "nfiles"),
+		size:     int64(intField(stats, "original_size")),
+		duration: backupDuration(archive["duration"]),
+	}
+	if deduplicated := intField(stats, "deduplicated_size"); deduplicated > 0 {
+		report.details = append(report.details, "Deduplicated: "+humanizeBytes(int64(deduplicated)))
+	}
+	if location := stringField(repository, "location"); location != "" {
+		report.details = append(report.details, "Repository: "+location)
+	}
+	return report
+}
+
+// backupDuration reads a duration given in seconds or as a Go duration.
+func backupDuration(value interface{}) time.Duration {
+	switch v := value.(type) {
+	case float64:
+		return time.Duration(v * float64(time.Second))
+	case string:
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+		if seconds, err := strconv.ParseFloat(v, 64); err == nil {
+			return time.Duration(seconds * float64(time.Second))
+		}
+		return duplicatiDuration(v)
+	}
+	return 0
+}
+
+// backupMessage renders a backup report titled by tool, name and result,
+// e.g. "Duplicati: Documents backup succeeded".
+func backupMessage(report backupReport) plugin.Message {
+	result := strings.ToLower(strings.TrimSpace(report.result))
+	if normalised, ok := backupResults[result]; ok {
+		result = normalised
+	}
+	if report.errors > 0 && result == "succeeded" {
+		result = "warning"
+	}
+
+	operation := strings.ToLower(report.operation)
+	if operation == "" {
+		operation = "backup"
+	}
+	summary := operation + " " + result
+	switch result {
+	case "warning":
+		summary = operation + " finished with warnings"
+	case "":
+		summary = operation + " finished"
+	}
+	title := report.tool + ": " + strings.TrimSpace(report.name+" "+summary)
+
+	var paragraphs, lines []string
+	if len(report.problems) > 0 {
+		paragraphs = append(paragraphs, strings.Join(report.problems, "\n"))
+	}
+	if report.files > 0 {
+		lines = append(lines, fmt.Sprintf("Files: %d", report.files))
+	}
+	if report.size > 0 {
+		lines = append(lines, "Size: "+humanizeBytes(report.size))
+	}
+	lines = append(lines, report.details...)
+	if report.errors > 0 {
+		lines = append(lines, fmt.Sprintf("Files with errors: %d", report.errors))
+	}
+	if report.duration > 0 {
+		lines = append(lines, "Duration: "+humanizeDuration(report.duration))
+	}
+	if report.host != "" {
+		lines = append(lines, "Host: "+report.host)
+	}
+	if len(lines) > 0 {
+		paragraphs = append(paragraphs, strings.Join(lines, "\n"))
+	}
+	message := strings.Join(paragraphs, "\n\n")
+	if message == "" {
+		message = title
+	}
+
+	priority, ok := backupPriorities[result]
+	if !ok {
+		priority = 5
+	}
+	extras := map[string]interface{}{"source": report.source}
+	if report.name != "" {
+		extras["backup"] = report.name
+	}
+	if result != "" {
+		extras["result"] = result
+	}
+
+	return plugin.Message{
+		Title:    title,
+		Message:  message,
+		Priority: priority,
+		Extras:   extras,
+	}
+}
+
+// humanizeBytes formats a size in bytes with binary units, e.g. "1.5 GiB".
+func humanizeBytes(size int64) string {
+	if size < 1024 {
+		return fmt.Sprintf("%d B", size)
+	}
+	value := float64(size)
+	for _, unit := range []string{"KiB", "MiB", "GiB", "TiB"} {
+		value /= 1024
+		if value < 1024 || unit == "TiB" {
+			return fmt.Sprintf("%.1f %s", value, unit)
+		}
+	}
+	return ""
+}
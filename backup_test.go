@@ -0,0 +1,144 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gotify/plugin-api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_DuplicatiReport(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	w := postWebhook(p, map[string]interface{}{
+		"Data": map[string]interface{}{
+			"DeletedFiles":        0.0,
+			"ModifiedFiles":       2.0,
+			"ExaminedFiles":       1234.0,
+			"AddedFiles":          5.0,
+			"SizeOfModifiedFiles": 1024.0,
+			"SizeOfAddedFiles":    1047552.0,
+			"SizeOfExaminedFiles": 5368709120.0,
+			"FilesWithError":      0.0,
+			"ParsedResult":        "Error",
+			"Duration":            "00:05:12.3456789",
+			"Warnings":            []interface{}{},
+			"Errors":              []interface{}{"Failed to connect: The remote server returned an error: (503)"},
+		},
+		"Extra": map[string]interface{}{
+			"OperationName": "Backup",
+			"backup-name":   "Documents",
+			"machine-name":  "nas",
+		},
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title: "Duplicati: Documents backup failed",
+		Message: "Failed to connect: The remote server returned an error: (503)\n\n" +
+			"Files: 1234\nSize: 5.0 GiB\nChanges: 5 added, 2 modified, 0 deleted\nChanged data: 1.0 MiB\nDuration: 5m\nHost: nas",
+		Priority: 9,
+		Extras: map[string]interface{}{
+			"source": "duplicati",
+			"backup": "Documents",
+			"result": "failed",
+		},
+	}, mockHandler.sentMessages[0])
+}
+
+func TestWebhookForwarderPlugin_BackupToolPayloads(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     map[string]interface{}
+		title    string
+		message  string
+		priority int
+	}{
+		{
+			name: "borgmatic template",
+			body: map[string]interface{}{
+				"source":   "borgmatic",
+				"name":     "nas",
+				"status":   "fail",
+				"error":    "Repository /mnt/backup does not exist.",
+				"duration": "42s",
+			},
+			title:    "borgmatic: nas backup failed",
+			message:  "Repository /mnt/backup does not exist.\n\nDuration: 42s",
+			priority: 9,
+		},
+		{
+			name: "resticprofile template",
+			body: map[string]interface{}{
+				"source":  "resticprofile",
+				"profile": "home",
+				"success": true,
+				"files":   "310",
+				"size":    2048.0,
+			},
+			title:    "resticprofile: home backup succeeded",
+			message:  "Files: 310\nSize: 2.0 KiB",
+			priority: 3,
+		},
+		{
+			name: "restic summary",
+			body: map[string]interface{}{
+				"message_type":          "summary",
+				"files_new":             3.0,
+				"files_changed":         1.0,
+				"files_unmodified":      100.0,
+				"data_added":            4096.0,
+				"total_files_processed": 104.0,
+				"total_bytes_processed": 10485760.0,
+				"total_duration":        3.5,
+				"snapshot_id":           "8e2a9d7c4b1f0a3e",
+			},
+			title:    "restic: backup succeeded",
+			message:  "Files: 104\nSize: 10.0 MiB\nChanges: 3 new, 1 changed, 100 unmodified\nAdded: 4.0 KiB\nSnapshot: 8e2a9d7\nDuration: 4s",
+			priority: 3,
+		},
+		{
+			name: "borg create",
+			body: map[string]interface{}{
+				"archive": map[string]interface{}{
+					"name":     "nas-2024-03-01",
+					"duration": 95.2,
+					"stats": map[string]interface{}{
+						"nfiles":            52.0,
+						"original_size":     1536.0,
+						"deduplicated_size": 512.0,
+					},
+				},
+				"repository": map[string]interface{}{"location": "ssh://backup@host/./repo"},
+			},
+			title:    "Borg: nas-2024-03-01 backup succeeded",
+			message:  "Files: 52\nSize: 1.5 KiB\nDeduplicated: 512 B\nRepository: ssh://backup@host/./repo\nDuration: 1m",
+			priority: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockHandler := &MockMessageHandler{}
+			p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+			w := postWebhook(p, tt.body)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Len(t, mockHandler.sentMessages, 1)
+			assert.Equal(t, tt.title, mockHandler.sentMessages[0].Title)
+			assert.Equal(t, tt.message, mockHandler.sentMessages[0].Message)
+			assert.Equal(t, tt.priority, mockHandler.sentMessages[0].Priority)
+		})
+	}
+}
+
+func TestDuplicatiDuration(t *testing.T) {
+	assert.Equal(t, 5*time.Minute+12*time.Second+500*time.Millisecond, duplicatiDuration("00:05:12.5"))
+	assert.Equal(t, 26*time.Hour, duplicatiDuration("1.02:00:00"))
+	assert.Equal(t, time.Duration(0), duplicatiDuration("soon"))
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gotify/plugin-api"
+)
+
+// brokerLabel is a label identifying where a broker alert comes from.
+type brokerLabel struct {
+	label, name string
+}
+
+// brokerService describes the alerts of a message broker. An alert belongs
+// to the broker if its alertname has one of the prefixes or it carries one
+// of the marker labels. The context labels are listed from the cluster down
+// to the queue or topic.
+type brokerService struct {
+	source, name string
+	prefixes     []string
+	markers      []string
+	labels       []brokerLabel
+}
+
+// brokerServices lists the brokers whose Prometheus alerts are rendered
+// with their broker context: the rules of the rabbitmq-prometheus plugin
+// and awesome-prometheus-alerts, and those of kafka_exporter and Strimzi.
+var brokerServices = []brokerService{
+	{
+		source:   "rabbitmq",
+		name:     "RabbitMQ",
+		prefixes: []string{"rabbitmq"},
+		markers:  []string{"rabbitmq_cluster", "rabbitmq_node"},
+		labels: []brokerLabel{
+			{"rabbitmq_cluster", "Cluster"},
+			{"rabbitmq_node", "Node"},
+			{"vhost", "Vhost"},
+			{"exchange", "Exchange"},
+			{"queue", "Queue"},
+		},
+	},
+	{
+		source:   "kafka",
+		name:     "Kafka",
+		prefixes: []string{"kafka", "strimzi"},
+		markers:  []string{"kafka_cluster", "strimzi_io_cluster", "consumergroup"},
+		labels: []brokerLabel{
+			{"kafka_cluster", "Cluster"},
+			{"strimzi_io_cluster", "Cluster"},
+			{"broker", "Broker"},
+			{"topic", "Topic"},
+			{"partition", "Partition"},
+			{"consumergroup", "Consumer group"},
+		},
+	},
+}
+
+// matches reports whether an alert with the given labels belongs to the
+// broker.
+func (b brokerService) matches(labels map[string]interface{}) bool {
+	alertname := strings.ToLower(stringField(labels, "alertname"))
+	for _, prefix := range b.prefixes {
+		if strings.HasPrefix(alertname, prefix) {
+			return true
+		}
+	}
+	return hasAnyField(labels, b.markers...)
+}
+
+// alertBroker returns the broker all alerts of an Alertmanager notification
+// come from.
+func alertBroker(body map[string]interface{}) (brokerService, bool) {
+	alerts := mapSlice(body["alerts"])
+	if len(alerts) == 0 || !isAlertmanagerPayload(body) {
+		return brokerService{}, false
+	}
+	for _, broker := range brokerServices {
+		matched := true
+		for _, alert := range alerts {
+			labels, _ := alert["labels"].(map[string]interface{})
+			matched = matched && broker.matches(labels)
+		}
+		if matched {
+			return broker, true
+		}
+	}
+	return brokerService{}, false
+}
+
+// isRabbitMQPayload detects Alertmanager notifications whose alerts all
+// come from RabbitMQ rules.
+func isRabbitMQPayload(body map[string]interface{}) bool {
+	broker, ok := alertBroker(body)
+	return ok && broker.source == "rabbitmq"
+}
+
+// isKafkaPayload detects Alertmanager notifications whose alerts all come
+// from Kafka rules.
+func isKafkaPayload(body map[string]interface{}) bool {
+	broker, ok := alertBroker(body)
+	return ok && broker.source == "kafka"
+}
+
+// formatBrokerPayload renders broker alerts with the queue or consumer group
+// in the title, e.g. "RabbitMQ orders: Queue backlog", and the cluster,
+// vhost, queue or topic as the first lines of each alert. Priorities follow
+// alertmanager.severityPriorities.
+func formatBrokerPayload(body map[string]interface{}, config *Config) plugin.Message {
+	broker, _ := alertBroker(body)
+	alerts := mapSlice(body["alerts"])
+	status := stringField(body, "status")
+
+	priority := 0
+	var lines, subjects []string
+	for _, alert := range alerts {
+		labels, _ := alert["labels"].(map[string]interface{})
+		annotations, _ := alert["annotations"].(map[string]interface{})
+		alertStatus := stringField(alert, "status")
+		if alertStatus == "" {
+			alertStatus = status
+		}
+
+		context, subject := broker.context(labels, config)
+		if subject != "" {
+			subjects = append(subjects, subject)
+		}
+		line := broker.description(stringField(labels, "alertname"))
+		if alertStatus == "resolved" {
+			line += " (resolved)"
+		}
+		if len(alerts) > 1 {
+			line = "- " + line
+			if subject != "" {
+				line += ": " + subject
+			}
+		}
+		lines = append(lines, line)
+		for _, item := range context {
+			lines = append(lines, "  "+item)
+		}
+		if text := stringField(annotations, "description", "summary", "message"); text != "" {
+			lines = append(lines, "  "+strings.TrimSpace(text))
+		}
+
+		if p := config.Alertmanager.alertPriority(labelsAlert(labels), alertStatus); p > priority {
+			priority = p
+		}
+	}
+
+	var title string
+	if len(alerts) == 1 {
+		labels, _ := alerts[0]["labels"].(map[string]interface{})
+		title = broker.name + ": " + broker.description(stringField(labels, "alertname"))
+		if len(subjects) == 1 {
+			title = fmt.Sprintf("%s %s: %s", broker.name, subjects[0], broker.description(stringField(labels, "alertname")))
+		}
+		// The title already names the alert
+		lines = lines[1:]
+		for i := range lines {
+			lines[i] = strings.TrimPrefix(lines[i], "  ")
+		}
+	} else {
+		title = fmt.Sprintf("%s: %d alerts", broker.name, len(alerts))
+		if len(subjects) > 0 {
+			title += " (" + strings.Join(uniqueStrings(subjects), ", ") + ")"
+		}
+	}
+	if status == "resolved" {
+		title = "Resolved: " + title
+	}
+	message := strings.Join(lines, "\n")
+	if message == "" {
+		message = title
+	}
+
+	extras := map[string]interface{}{"source": broker.source}
+	if status != "" {
+		extras["status"] = status
+	}
+	if len(alerts) == 1 {
+		labels, _ := alerts[0]["labels"].(map[string]interface{})
+		if alertname := stringField(labels, "alertname"); alertname != "" {
+			extras["alertname"] = alertname
+		}
+		for _, label := range broker.labels {
+			if value := stringField(labels, label.label); value != "" {
+				extras[label.label] = value
+			}
+		}
+	}
+	if externalURL := stringField(body, "externalURL"); externalURL != "" {
+		extras["externalURL"] = externalURL
+	}
+
+	return plugin.Message{
+		Title:    title,
+		Message:  message,
+		Priority: priority,
+		Extras:   extras,
+	}
+}
+
+// context returns the context lines of an alert, e.g. "Vhost: /", and its
+// subject: the queue, exchange, topic or consumer group, falling back to
+// the node or cluster. Labels hidden by the label filter are left out.
+func (b brokerService) context(labels map[string]interface{}, config *Config) ([]string, string) {
+	var lines []string
+	subject := ""
+	for _, label := range b.labels {
+		value := stringField(labels, label.label)
+		if value == "" || !config.Labels.allows(label.label) {
+			continue
+		}
+		lines = append(lines, label.name+": "+value)
+		if label.label != "partition" {
+			subject = value
+		}
+	}
+	return lines, subject
+}
+
+// description returns a readable description for an alert name, e.g.
+// "Queue backlog" for "RabbitMQQueueBacklog".
+func (b brokerService) description(alertname string) string {
+	name := alertname
+	for _, prefix := range append([]string{b.name}, b.prefixes...) {
+		if len(name) > len(prefix) && strings.EqualFold(name[:len(prefix)], prefix) {
+			name = name[len(prefix):]
+			break
+		}
+	}
+	name = strings.TrimLeft(name, "_ ")
+	if name == "" {
+		return "Alert"
+	}
+	words := camelCaseBoundary.ReplaceAllString(name, "$1 $2")
+	words = strings.ReplaceAll(words, "_", " ")
+	return capitalize(strings.ToLower(words))
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gotify/plugin-api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_RabbitMQAlert(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	w := postWebhook(p, map[string]interface{}{
+		"version":     "4",
+		"status":      "firing",
+		"receiver":    "gotify",
+		"externalURL": "http://alertmanager:9093",
+		"alerts": []interface{}{
+			map[string]interface{}{
+				"status": "firing",
+				"labels": map[string]interface{}{
+					"alertname":        "RabbitMQQueueBacklog",
+					"rabbitmq_cluster": "prod",
+					"vhost":            "/",
+					"queue":            "orders",
+					"severity":         "warning",
+				},
+				"annotations": map[string]interface{}{"description": "12000 messages ready for 10 minutes"},
+				"startsAt":    "2024-05-01T12:00:00Z",
+			},
+		},
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title:    "RabbitMQ orders: Queue backlog",
+		Message:  "Cluster: prod\nVhost: /\nQueue: orders\n12000 messages ready for 10 minutes",
+		Priority: 6,
+		Extras: map[string]interface{}{
+			"source":           "rabbitmq",
+			"status":           "firing",
+			"alertname":        "RabbitMQQueueBacklog",
+			"rabbitmq_cluster": "prod",
+			"vhost":            "/",
+			"queue":            "orders",
+			"externalURL":      "http://alertmanager:9093",
+		},
+	}, mockHandler.sentMessages[0])
+}
+
+func TestWebhookForwarderPlugin_KafkaAlerts(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+	config := defaultConfig()
+	config.Alertmanager.SplitAlerts = false
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+
+	w := postWebhook(p, map[string]interface{}{
+		"version": "4",
+		"status":  "firing",
+		"alerts": []interface{}{
+			map[string]interface{}{
+				"status": "firing",
+				"labels": map[string]interface{}{
+					"alertname":          "KafkaConsumerGroupLag",
+					"strimzi_io_cluster": "events",
+					"topic":              "payments",
+					"consumergroup":      "billing",
+					"severity":           "critical",
+				},
+			},
+			map[string]interface{}{
+				"status": "resolved",
+				"labels": map[string]interface{}{
+					"alertname":          "KafkaUnderReplicatedPartitions",
+					"strimzi_io_cluster": "events",
+					"topic":              "orders",
+					"partition":          "3",
+				},
+			},
+		},
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	msg := mockHandler.sentMessages[0]
+	assert.Equal(t, "Kafka: 2 alerts (billing, orders)", msg.Title)
+	assert.Equal(t, "- Consumer group lag: billing\n  Cluster: events\n  Topic: payments\n  Consumer group: billing\n"+
+		"- Under replicated partitions (resolved): orders\n  Cluster: events\n  Topic: orders\n  Partition: 3", msg.Message)
+	assert.Equal(t, 10, msg.Priority)
+	assert.Equal(t, "kafka", msg.Extras["source"])
+}
+
+func TestWebhookForwarderPlugin_BrokerAlertmanagerSettings(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+	config := defaultConfig()
+	config.Alertmanager.NotifyOnResolved = false
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+
+	backlog := func(status string) map[string]interface{} {
+		return map[string]interface{}{
+			"version": "4",
+			"status":  status,
+			"alerts": []interface{}{
+				map[string]interface{}{
+					"status": status,
+					"labels": map[string]interface{}{"alertname": "RabbitMQQueueBacklog", "queue": "orders"},
+				},
+				map[string]interface{}{
+					"status": "resolved",
+					"labels": map[string]interface{}{"alertname": "RabbitMQQueueBacklog", "queue": "invoices"},
+				},
+			},
+		}
+	}
+
+	w := postWebhook(p, backlog("resolved"))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Resolved alerts are not forwarded")
+	assert.Empty(t, mockHandler.sentMessages)
+
+	// Only the firing alert of a mixed notification is forwarded
+	w = postWebhook(p, backlog("firing"))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, "RabbitMQ orders: Queue backlog", mockHandler.sentMessages[0].Title)
+
+	disabled := false
+	config.Sources = map[string]*SourceConfig{"alertmanager": {Enabled: &disabled}}
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+	w = postWebhook(p, backlog("firing"))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+}
+
+func TestIsBrokerPayload(t *testing.T) {
+	payload := func(labels ...map[string]interface{}) map[string]interface{} {
+		var alerts []interface{}
+		for _, l := range labels {
+			alerts = append(alerts, map[string]interface{}{"status": "firing", "labels": l})
+		}
+		return map[string]interface{}{"version": "4", "status": "firing", "alerts": alerts}
+	}
+
+	assert.True(t, isRabbitMQPayload(payload(map[string]interface{}{"alertname": "RabbitmqNodeDown"})))
+	assert.True(t, isKafkaPayload(payload(map[string]interface{}{"alertname": "ConsumerLag", "consumergroup": "billing"})))
+	assert.False(t, isKafkaPayload(payload(
+		map[string]interface{}{"alertname": "KafkaTopicOffline"},
+		map[string]interface{}{"alertname": "HostDown"},
+	)))
+	assert.False(t, isRabbitMQPayload(payload(map[string]interface{}{"alertname": "HostDown", "queue": "orders"})))
+}
@@ -0,0 +1,367 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gotify/plugin-api"
+)
+
+// ciStatuses normalises the build statuses of CI servers to "started",
+// "succeeded", "failed", "unstable", "cancelled" or "skipped".
+var ciStatuses = map[string]string{
+	"queued":              "started",
+	"pending":             "started",
+	"start":               "started",
+	"started":             "started",
+	"running":             "started",
+	"success":             "succeeded",
+	"successful":          "succeeded",
+	"succeeded":           "succeeded",
+	"passed":              "succeeded",
+	"fixed":               "succeeded",
+	"failure":             "failed",
+	"failed":              "failed",
+	"failed-with-retry":   "failed",
+	"upstream_failed":     "failed",
+	"error":               "failed",
+	"errored":             "failed",
+	"broken":              "failed",
+	"still failing":       "failed",
+	"timedout":            "failed",
+	"infrastructure_fail": "failed",
+	"unauthorized":        "failed",
+	"unstable":            "unstable",
+	"aborted":             "cancelled",
+	"cancelled":           "cancelled",
+	"canceled":            "cancelled",
+	"killed":              "cancelled",
+	"not_built":           "skipped",
+	"skipped":             "skipped",
+}
+
+// ciPriorities maps normalised build statuses to priorities. Failed builds
+// are high priority so they get through to phones.
+var ciPriorities = map[string]int{
+	"failed":    8,
+	"unstable":  6,
+	"cancelled": 4,
+	"succeeded": 3,
+	"skipped":   2,
+	"started":   2,
+}
+
+// ciBuild is a build reported by a CI server.
+type ciBuild struct {
+	source, service string
+	project, number string
+	status          string
+	branch, commit  string
+	subject, author string
+	workflow        string
+	details         []string
+	url             string
+	duration        time.Duration
+}
+
+// ciStatus normalises a build status, keeping unknown statuses lower case.
+func ciStatus(status string) string {
+	status = strings.ToLower(strings.TrimSpace(status))
+	if normalised, ok := ciStatuses[status]; ok {
+		return normalised
+	}
+	return status
+}
+
+// ciMessage renders a build with the project, build number and status in
+// the title, e.g. "Jenkins: deploy #18 failed". Clicking opens the build.
+func ciMessage(build ciBuild) plugin.Message {
+	title := build.service + ": " + build.project
+	if build.number != "" {
+		title += " #" + build.number
+	}
+	if build.status != "" {
+		title += " " + build.status
+	}
+
+	var lines []string
+	if build.workflow != "" {
+		lines = append(lines, "Workflow: "+build.workflow)
+	}
+	if build.branch != "" {
+		lines = append(lines, "Branch: "+build.branch)
+	}
+	if build.commit != "" || build.subject != "" {
+		commit := strings.TrimSpace(shortSHA(build.commit) + " " + firstLine(build.subject))
+		lines = append(lines, "Commit: "+commit)
+	}
+	if build.author != "" {
+		lines = append(lines, "Author: "+build.author)
+	}
+	lines = append(lines, build.details...)
+	if build.duration > 0 {
+		lines = append(lines, "Duration: "+humanizeDuration(build.duration))
+	}
+	message := strings.Join(lines, "\n")
+	if message == "" {
+		message = title
+	}
+
+	priority, ok := ciPriorities[build.status]
+	if !ok {
+		priority = 5
+	}
+
+	extras := map[string]interface{}{"source": build.source}
+	for key, value := range map[string]string{
+		"project": build.project,
+		"build":   build.number,
+		"status":  build.status,
+		"branch":  build.branch,
+	} {
+		if value != "" {
+			extras[key] = value
+		}
+	}
+	if build.url != "" {
+		extras["client::notification"] = map[string]interface{}{
+			"click": map[string]interface{}{"url": build.url},
+		}
+	}
+
+	return plugin.Message{
+		Title:    title,
+		Message:  message,
+		Priority: priority,
+		Extras:   extras,
+	}
+}
+
+// ciElapsed returns the time between two RFC 3339 timestamps, or 0 if
+// either is missing.
+func ciElapsed(started, finished string) time.Duration {
+	start, err := time.Parse(time.RFC3339, started)
+	if err != nil {
+		return 0
+	}
+	end, err := time.Parse(time.RFC3339, finished)
+	if err != nil || !end.After(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
+
+// firstLine returns the first line of a commit message.
+func firstLine(text string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	return strings.TrimSpace(line)
+}
+
+// isJenkinsPayload detects the payloads of Jenkins' Notification plugin,
+// which describe the build under "build" with a phase, and of the Outbound
+// WebHook plugin.
+func isJenkinsPayload(body map[string]interface{}) bool {
+	if build, ok := body["build"].(map[string]interface{}); ok {
+		return stringField(body, "name") != "" && stringField(build, "phase") != ""
+	}
+	return hasFields(body, "projectName", "buildName", "buildUrl")
+}
+
+// formatJenkinsPayload renders a Jenkins build. Builds of the Notification
+// plugin without a status yet are reported by their phase, e.g. "started".
+// Clicking opens the console output of the build.
+func formatJenkinsPayload(body map[string]interface{}, _ *Config) plugin.Message {
+	build := ciBuild{source: "jenkins", service: "Jenkins"}
+	var url string
+	if details, ok := body["build"].(map[string]interface{}); ok {
+		// Notification plugin
+		build.project = stringField(body, "display_name", "name")
+		build.number = stringField(details, "number")
+		status := stringField(details, "status")
+		if status == "" {
+			status = stringField(details, "phase")
+		}
+		build.status = ciStatus(status)
+		if scm, ok := details["scm"].(map[string]interface{}); ok {
+			build.branch = strings.TrimPrefix(stringField(scm, "branch"), "origin/")
+			build.commit = stringField(scm, "commit")
+			build.author = joinStrings(scm["culprits"])
+		}
+		url = stringField(details, "full_url")
+		if duration := intField(details, "duration"); duration > 0 {
+			build.duration = time.Duration(duration) * time.Millisecond
+		}
+	} else {
+		// Outbound WebHook plugin
+		build.project = stringField(body, "projectName")
+		build.number = strings.TrimPrefix(stringField(body, "buildName"), "#")
+		build.status = ciStatus(stringField(body, "event"))
+		url = stringField(body, "buildUrl")
+	}
+	if url != "" {
+		build.url = strings.TrimSuffix(url, "/") + "/console"
+	}
+	if build.project == "" {
+		build.project = "Build"
+	}
+	return ciMessage(build)
+}
+
+// isDronePayload detects build webhooks of Drone, which describe the build
+// under "build" and the repository under "repo".
+func isDronePayload(body map[string]interface{}) bool {
+	return isDroneStyleBuild(body, "build")
+}
+
+// isWoodpeckerPayload detects pipeline webhooks of Woodpecker, a fork of
+// Drone describing the build under "pipeline".
+func isWoodpeckerPayload(body map[string]interface{}) bool {
+	return isDroneStyleBuild(body, "pipeline")
+}
+
+// isDroneStyleBuild reports whether body has a repository and a numbered
+// build with a status under key.
+func isDroneStyleBuild(body map[string]interface{}, key string) bool {
+	_, hasRepo := body["repo"].(map[string]interface{})
+	build, ok := body[key].(map[string]interface{})
+	return hasRepo && ok && hasFields(build, "number", "status")
+}
+
+// formatDronePayload renders a Drone build.
+func formatDronePayload(body map[string]interface{}, _ *Config) plugin.Message {
+	return ciMessage(droneStyleBuild(body, "build", "drone", "Drone"))
+}
+
+// formatWoodpeckerPayload renders a Woodpecker pipeline.
+func formatWoodpeckerPayload(body map[string]interface{}, _ *Config) plugin.Message {
+	return ciMessage(droneStyleBuild(body, "pipeline", "woodpecker", "Woodpecker"))
+}
+
+// droneStyleBuild reads the build of a Drone or Woodpecker webhook. The
+// build link points to the CI server if it can be derived from the system
+// link, otherwise to the link of the build.
+func droneStyleBuild(body map[string]interface{}, key, source, service string) ciBuild {
+	repo, _ := body["repo"].(map[string]interface{})
+	details, _ := body[key].(map[string]interface{})
+
+	build := ciBuild{
+		source:  source,
+		service: service,
+		project: stringField(repo, "slug", "full_name", "name"),
+		number:  stringField(details, "number"),
+		status:  ciStatus(stringField(details, "status")),
+		branch:  stringField(details, "source", "branch"),
+		subject: stringField(details, "message"),
+		author:  stringField(details, "author_login", "author", "sender"),
+		url:     stringField(details, "link", "link_url", "forge_url"),
+	}
+	if build.branch == "" {
+		build.branch = strings.TrimPrefix(stringField(details, "ref"), "refs/heads/")
+	}
+	switch commit := details["commit"].(type) {
+	case string:
+		build.commit = commit
+	case map[string]interface{}:
+		build.commit = stringField(commit, "sha")
+	}
+	if build.commit == "" {
+		build.commit = stringField(details, "after")
+	}
+	started := intField(details, "started", "started_at")
+	if finished := intField(details, "finished", "finished_at"); started > 0 && finished > started {
+		build.duration = time.Duration(finished-started) * time.Second
+	}
+	if system, ok := body["system"].(map[string]interface{}); ok && build.project != "" && build.number != "" {
+		if link := stringField(system, "link"); link != "" {
+			build.url = strings.TrimSuffix(link, "/") + "/" + build.project + "/" + build.number
+		}
+	}
+	if build.project == "" {
+		build.project = "Build"
+	}
+	return build
+}
+
+// isCircleCIPayload detects workflow-completed and job-completed webhooks of
+// CircleCI.
+func isCircleCIPayload(body map[string]interface{}) bool {
+	switch stringField(body, "type") {
+	case "workflow-completed", "job-completed":
+		return hasFields(body, "pipeline", "project")
+	}
+	return false
+}
+
+// formatCircleCIPayload renders a CircleCI workflow or job with the pipeline
+// number. Clicking opens the workflow.
+func formatCircleCIPayload(body map[string]interface{}, _ *Config) plugin.Message {
+	project, _ := body["project"].(map[string]interface{})
+	pipeline, _ := body["pipeline"].(map[string]interface{})
+	vcs, _ := pipeline["vcs"].(map[string]interface{})
+	commit, _ := vcs["commit"].(map[string]interface{})
+	author, _ := commit["author"].(map[string]interface{})
+	workflow, _ := body["workflow"].(map[string]interface{})
+
+	build := ciBuild{
+		source:   "circleci",
+		service:  "CircleCI",
+		project:  stringField(project, "name"),
+		number:   stringField(pipeline, "number"),
+		status:   ciStatus(stringField(workflow, "status")),
+		branch:   stringField(vcs, "branch", "tag"),
+		commit:   stringField(vcs, "revision"),
+		subject:  stringField(commit, "subject"),
+		author:   stringField(author, "name", "login"),
+		workflow: stringField(workflow, "name"),
+		url:      stringField(workflow, "url"),
+		duration: ciElapsed(stringField(workflow, "created_at"), stringField(workflow, "stopped_at")),
+	}
+	// Slugs look like "gh/octo/app"
+	if _, slug, ok := strings.Cut(stringField(project, "slug"), "/"); ok {
+		build.project = slug
+	}
+	if job, ok := body["job"].(map[string]interface{}); ok {
+		build.status = ciStatus(stringField(job, "status"))
+		if name := stringField(job, "name"); name != "" {
+			build.workflow = strings.TrimPrefix(build.workflow+" / "+name, " / ")
+		}
+		build.duration = ciElapsed(stringField(job, "started_at"), stringField(job, "stopped_at"))
+	}
+	if build.project == "" {
+		build.project = "Build"
+	}
+	return ciMessage(build)
+}
+
+// isTravisPayload detects build notifications of Travis CI, sent as JSON or
+// as a form with a "payload" field.
+func isTravisPayload(body map[string]interface{}) bool {
+	return stringField(body, "build_url") != "" && hasAnyField(body, "status_message", "result_message", "state")
+}
+
+// formatTravisPayload renders a Travis CI build. Clicking opens the build.
+func formatTravisPayload(body map[string]interface{}, _ *Config) plugin.Message {
+	repository, _ := body["repository"].(map[string]interface{})
+	build := ciBuild{
+		source:  "travis",
+		service: "Travis CI",
+		project: stringField(repository, "name"),
+		number:  stringField(body, "number"),
+		status:  ciStatus(stringField(body, "status_message", "result_message", "state")),
+		branch:  stringField(body, "branch"),
+		commit:  stringField(body, "commit"),
+		subject: stringField(body, "message"),
+		author:  stringField(body, "author_name", "committer_name"),
+		url:     stringField(body, "build_url"),
+	}
+	if owner := stringField(repository, "owner_name"); owner != "" && build.project != "" {
+		build.project = owner + "/" + build.project
+	}
+	if seconds := intField(body, "duration"); seconds > 0 {
+		build.duration = time.Duration(seconds) * time.Second
+	}
+	if build.project == "" {
+		build.project = "Build"
+	}
+	return ciMessage(build)
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gotify/plugin-api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_JenkinsNotification(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	w := postWebhook(p, map[string]interface{}{
+		"name": "deploy",
+		"url":  "job/deploy/",
+		"build": map[string]interface{}{
+			"full_url": "https://jenkins.example.com/job/deploy/18/",
+			"number":   18.0,
+			"phase":    "COMPLETED",
+			"status":   "FAILURE",
+			"url":      "job/deploy/18/",
+			"duration": 185000.0,
+			"scm": map[string]interface{}{
+				"url":      "https://github.com/octo/app.git",
+				"branch":   "origin/main",
+				"commit":   "c6d86dc7ca0fe3e0cd23ad6d4ab5c2a1e0c5b8a7",
+				"culprits": []interface{}{"alice"},
+			},
+		},
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title:    "Jenkins: deploy #18 failed",
+		Message:  "Branch: main\nCommit: c6d86dc\nAuthor: alice\nDuration: 3m",
+		Priority: 8,
+		Extras: map[string]interface{}{
+			"source":  "jenkins",
+			"project": "deploy",
+			"build":   "18",
+			"status":  "failed",
+			"branch":  "main",
+			"client::notification": map[string]interface{}{
+				"click": map[string]interface{}{"url": "https://jenkins.example.com/job/deploy/18/console"},
+			},
+		},
+	}, mockHandler.sentMessages[0])
+}
+
+func TestWebhookForwarderPlugin_JenkinsStarted(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	postWebhook(p, map[string]interface{}{
+		"name":  "deploy",
+		"build": map[string]interface{}{"number": 19.0, "phase": "STARTED"},
+	})
+
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, "Jenkins: deploy #19 started", mockHandler.sentMessages[0].Title)
+	assert.Equal(t, 2, mockHandler.sentMessages[0].Priority)
+}
+
+func TestWebhookForwarderPlugin_JenkinsOutboundWebhook(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	postWebhook(p, map[string]interface{}{
+		"projectName": "nightly",
+		"buildName":   "#7",
+		"buildUrl":    "https://jenkins.example.com/job/nightly/7/",
+		"event":       "success",
+	})
+
+	assert.Len(t, mockHandler.sentMessages, 1)
+	msg := mockHandler.sentMessages[0]
+	assert.Equal(t, "Jenkins: nightly #7 succeeded", msg.Title)
+	assert.Equal(t, 3, msg.Priority)
+	assert.Equal(t, map[string]interface{}{
+		"click": map[string]interface{}{"url": "https://jenkins.example.com/job/nightly/7/console"},
+	}, msg.Extras["client::notification"])
+}
+
+func TestWebhookForwarderPlugin_DroneBuild(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	w := postWebhook(p, map[string]interface{}{
+		"event":  "build",
+		"action": "updated",
+		"repo":   map[string]interface{}{"namespace": "octo", "name": "app", "slug": "octo/app"},
+		"build": map[string]interface{}{
+			"number":       42.0,
+			"status":       "success",
+			"event":        "push",
+			"message":      "Fix login\n\nDetails",
+			"after":        "9f8e7d6c5b4a3f2e1d0c",
+			"ref":          "refs/heads/main",
+			"source":       "main",
+			"author_login": "alice",
+			"started":      1700000000.0,
+			"finished":     1700000150.0,
+		},
+		"system": map[string]interface{}{"link": "https://drone.example.com"},
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title:    "Drone: octo/app #42 succeeded",
+		Message:  "Branch: main\nCommit: 9f8e7d6 Fix login\nAuthor: alice\nDuration: 2m",
+		Priority: 3,
+		Extras: map[string]interface{}{
+			"source":  "drone",
+			"project": "octo/app",
+			"build":   "42",
+			"status":  "succeeded",
+			"branch":  "main",
+			"client::notification": map[string]interface{}{
+				"click": map[string]interface{}{"url": "https://drone.example.com/octo/app/42"},
+			},
+		},
+	}, mockHandler.sentMessages[0])
+}
+
+func TestWebhookForwarderPlugin_WoodpeckerPipeline(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	postWebhook(p, map[string]interface{}{
+		"repo": map[string]interface{}{"full_name": "octo/app"},
+		"pipeline": map[string]interface{}{
+			"number":  7.0,
+			"status":  "failure",
+			"branch":  "dev",
+			"commit":  "1a2b3c4d5e6f",
+			"message": "Bump deps",
+			"author":  "bob",
+			"link":    "https://ci.example.com/repos/1/pipeline/7",
+		},
+	})
+
+	assert.Len(t, mockHandler.sentMessages, 1)
+	msg := mockHandler.sentMessages[0]
+	assert.Equal(t, "Woodpecker: octo/app #7 failed", msg.Title)
+	assert.Equal(t, "Branch: dev\nCommit: 1a2b3c4 Bump deps\nAuthor: bob", msg.Message)
+	assert.Equal(t, 8, msg.Priority)
+	assert.Equal(t, "woodpecker", msg.Extras["source"])
+}
+
+func TestWebhookForwarderPlugin_CircleCIWorkflow(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	w := postWebhook(p, map[string]interface{}{
+		"type":        "workflow-completed",
+		"id":          "3888f21b-eaa7-38e3-8f3d-75a63bba8895",
+		"happened_at": "2024-03-01T10:05:00.000Z",
+		"workflow": map[string]interface{}{
+			"id":         "fda08377-fe7e-46b1-8992-3a7aaecac9c3",
+			"name":       "build-and-test",
+			"status":     "failed",
+			"url":        "https://app.circleci.com/pipelines/github/octo/app/130/workflows/fda08377",
+			"created_at": "2024-03-01T10:00:00Z",
+			"stopped_at": "2024-03-01T10:05:00Z",
+		},
+		"pipeline": map[string]interface{}{
+			"number": 130.0,
+			"vcs": map[string]interface{}{
+				"branch":   "main",
+				"revision": "1a2b3c4d5e6f",
+				"commit": map[string]interface{}{
+					"subject": "Fix login",
+					"author":  map[string]interface{}{"name": "Alice"},
+				},
+			},
+		},
+		"project": map[string]interface{}{"name": "app", "slug": "gh/octo/app"},
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title:    "CircleCI: octo/app #130 failed",
+		Message:  "Workflow: build-and-test\nBranch: main\nCommit: 1a2b3c4 Fix login\nAuthor: Alice\nDuration: 5m",
+		Priority: 8,
+		Extras: map[string]interface{}{
+			"source":  "circleci",
+			"project": "octo/app",
+			"build":   "130",
+			"status":  "failed",
+			"branch":  "main",
+			"client::notification": map[string]interface{}{
+				"click": map[string]interface{}{"url": "https://app.circleci.com/pipelines/github/octo/app/130/workflows/fda08377"},
+			},
+		},
+	}, mockHandler.sentMessages[0])
+}
+
+func TestWebhookForwarderPlugin_TravisForm(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+	router := gin.New()
+	router.POST("/message", p.handleWebhookMessage)
+
+	form := url.Values{"payload": {`{"id":1,"number":"42","status":1,"status_message":"Still Failing","build_url":"https://app.travis-ci.com/octo/app/builds/1","branch":"main","commit":"62aae5f70ceee39123ef","message":"Bump deps","author_name":"Bob","duration":95,"repository":{"name":"app","owner_name":"octo"}}`}}
+	req := httptest.NewRequest("POST", "/message", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	msg := mockHandler.sentMessages[0]
+	assert.Equal(t, "Travis CI: octo/app #42 failed", msg.Title)
+	assert.Equal(t, "Branch: main\nCommit: 62aae5f Bump deps\nAuthor: Bob\nDuration: 1m", msg.Message)
+	assert.Equal(t, 8, msg.Priority)
+	assert.Equal(t, map[string]interface{}{
+		"click": map[string]interface{}{"url": "https://app.travis-ci.com/octo/app/builds/1"},
+	}, msg.Extras["client::notification"])
+}
+
+func TestCIStatus(t *testing.T) {
+	assert.Equal(t, "failed", ciStatus("FAILURE"))
+	assert.Equal(t, "cancelled", ciStatus("Aborted"))
+	assert.Equal(t, "succeeded", ciStatus("passed"))
+	assert.Equal(t, "blocked", ciStatus("blocked"))
+}
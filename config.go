@@ -0,0 +1,429 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Config holds the per-user plugin configuration editable in the Gotify UI.
+type Config struct {
+	// GenericWebhooks enables forwarding of payloads that are not Grafana alerts.
+	GenericWebhooks bool `yaml:"genericWebhooks"`
+	// DefaultTitle replaces the built-in title used when a payload has none.
+	DefaultTitle string `yaml:"defaultTitle"`
+	// TitlePrefix and TitleSuffix are added around every forwarded title.
+	TitlePrefix string `yaml:"titlePrefix"`
+	TitleSuffix string `yaml:"titleSuffix"`
+	// TitleTemplate and MessageTemplate are Go text/template strings rendered
+	// with the decoded webhook payload. Empty templates keep the default output.
+	TitleTemplate   string `yaml:"titleTemplate"`
+	MessageTemplate string `yaml:"messageTemplate"`
+	// Grafana holds options specific to Grafana alerts.
+	Grafana GrafanaConfig `yaml:"grafana"`
+	// Alertmanager holds options for Prometheus Alertmanager webhooks.
+	Alertmanager AlertmanagerConfig `yaml:"alertmanager"`
+	// QuietHours drops or downgrades low priority messages at night.
+	QuietHours QuietHoursConfig `yaml:"quietHours"`
+	// MinPriority and MaxPriority clamp the final priority of every message,
+	// 0 disables the respective limit.
+	MinPriority int `yaml:"minPriority"`
+	MaxPriority int `yaml:"maxPriority"`
+	// MaxMessageLength limits the message body length, 0 disables the limit.
+	MaxMessageLength int `yaml:"maxMessageLength"`
+	// TruncateStrategy is "truncate", "truncate-with-ellipsis" or "summary-only".
+	TruncateStrategy string `yaml:"truncateStrategy"`
+	// Timezone (IANA name) and TimeFormat (Go layout) control how timestamps
+	// are rendered. Defaults to the server's local time.
+	Timezone   string `yaml:"timezone"`
+	TimeFormat string `yaml:"timeFormat"`
+	// Labels selects which alert labels and annotations are rendered.
+	Labels LabelFilterConfig `yaml:"labels"`
+	// LogAnnotations name the alert annotations holding the log line that
+	// triggered a Loki or Mimir ruler alert, shown as a code block below the
+	// summary.
+	LogAnnotations []string `yaml:"logAnnotations"`
+	// Sources holds per-source profiles keyed by source name, e.g.
+	// "grafana", "generic" or "authelia".
+	Sources map[string]*SourceConfig `yaml:"sources"`
+	// IFTTT maps the fields of IFTTT Webhooks / Zapier payloads.
+	IFTTT IFTTTConfig `yaml:"ifttt"`
+	// UniFi holds options for UniFi Network and Protect alarms.
+	UniFi UniFiConfig `yaml:"unifi"`
+	// Frigate holds options for Frigate NVR events.
+	Frigate FrigateConfig `yaml:"frigate"`
+	// Printer holds options for OctoPrint and Moonraker notifications.
+	Printer PrinterConfig `yaml:"printer"`
+	// MinIO holds options for MinIO bucket notifications.
+	MinIO MinIOConfig `yaml:"minio"`
+	// Tautulli sets the priorities of Tautulli notifications.
+	Tautulli TautulliConfig `yaml:"tautulli"`
+	// SNS holds options for Amazon SNS HTTPS subscriptions.
+	SNS SNSConfig `yaml:"sns"`
+	// GitHub holds options for GitHub webhooks.
+	GitHub GitHubConfig `yaml:"github"`
+	// Stripe holds options for Stripe webhooks.
+	Stripe StripeConfig `yaml:"stripe"`
+	// Dependencies condenses pull requests of dependency update bots into
+	// digests.
+	Dependencies DependencyDigestConfig `yaml:"dependencies"`
+	// DefaultExtras are merged into the extras of every forwarded message,
+	// e.g. {"client::display": {"contentType": "text/markdown"}}.
+	DefaultExtras map[string]interface{} `yaml:"defaultExtras"`
+	// Routes defines additional named webhook endpoints with their own defaults.
+	Routes []RouteConfig `yaml:"routes"`
+	// ConfigToken protects the GET/PUT /config endpoints, which are
+	// disabled while it is empty.
+	ConfigToken string `yaml:"configToken"`
+	// ResponseCodes selects the HTTP status returned for messages that are
+	// accepted but not forwarded.
+	ResponseCodes ResponseCodesConfig `yaml:"responseCodes"`
+
+	titleTmpl   *template.Template
+	messageTmpl *template.Template
+	location    *time.Location
+	// unexpanded is the config before environment variable expansion.
+	unexpanded *Config
+}
+
+// GrafanaConfig holds options specific to Grafana alert webhooks.
+type GrafanaConfig struct {
+	// URL is the base URL of Grafana, used to complete relative links such
+	// as those of Grafana Incident.
+	URL string `yaml:"url"`
+	// NotifyOnResolved forwards resolved alerts. When false they are
+	// acknowledged but not sent to the user.
+	NotifyOnResolved bool `yaml:"notifyOnResolved"`
+	// SplitAlerts sends one message per alert instead of a single message
+	// for the whole notification.
+	SplitAlerts bool `yaml:"splitAlerts"`
+	// RawMessage forwards the message text generated by Grafana instead of
+	// the markdown rendering of the alerts.
+	RawMessage bool `yaml:"rawMessage"`
+	// AnnotationsOnly builds the message from the summary and description
+	// annotations of the alerts, using Grafana's message text only when no
+	// alert has them.
+	AnnotationsOnly bool `yaml:"annotationsOnly"`
+	// Verbosity selects a formatting preset: "minimal" renders only the
+	// annotations like AnnotationsOnly, "standard" the default rendering,
+	// "detailed" adds all annotations and the alert rule link, and "raw"
+	// forwards Grafana's message text like RawMessage.
+	Verbosity string `yaml:"verbosity"`
+	// SummaryTitle replaces Grafana's title of grouped notifications with
+	// the number of firing and resolved alerts and the group name. Without
+	// a title from Grafana the summary is used anyway.
+	SummaryTitle bool `yaml:"summaryTitle"`
+	// ClickTarget selects the page opened when the notification is clicked:
+	// "dashboard", "panel", "silence" or "none".
+	ClickTarget string `yaml:"clickTarget"`
+	// ContextLabels name the labels identifying where an alert comes from,
+	// such as its folder and rule group. ContextPlacement renders their
+	// values as "Folder / Group" in the "message" body, the "title" or
+	// "none".
+	ContextLabels    []string `yaml:"contextLabels"`
+	ContextPlacement string   `yaml:"contextPlacement"`
+	// StatusEmoji maps alert statuses ("firing", "resolved", "nodata",
+	// "error", ...) to an emoji added in front of the title.
+	StatusEmoji map[string]string `yaml:"statusEmoji"`
+	// SeverityLabel names the alert label holding the severity, and
+	// SeverityPriorities maps its values to priorities for firing alerts.
+	// Alerts without a known severity use the status-based priority.
+	SeverityLabel      string         `yaml:"severityLabel"`
+	SeverityPriorities map[string]int `yaml:"severityPriorities"`
+	// NoDataPriority and ErrorPriority replace the priority of alerts raised
+	// because a query returned no data or failed, 0 treats them like other
+	// alerts.
+	NoDataPriority int `yaml:"noDataPriority"`
+	ErrorPriority  int `yaml:"errorPriority"`
+	// DedupWindow suppresses repeated notifications for alerts whose status
+	// has not changed within the window (Go duration, e.g. "4h"). Empty
+	// disables deduplication.
+	DedupWindow string `yaml:"dedupWindow"`
+	// StateChangesOnly notifies alerts only when their status changes,
+	// suppressing all repeated notifications regardless of DedupWindow.
+	StateChangesOnly bool `yaml:"stateChangesOnly"`
+
+	// DigestThreshold condenses notifications with more alerts than this
+	// into a digest listing only the first alerts and the number of the
+	// others, also instead of splitting them. 0 disables the digest.
+	DigestThreshold int `yaml:"digestThreshold"`
+
+	// AllClear replaces the notifications of resolved alerts with a single
+	// summary once all alerts of a group are resolved.
+	AllClear bool `yaml:"allClear"`
+	// Signature verifies the HMAC signature of Grafana webhook requests.
+	Signature SignatureConfig `yaml:"signature"`
+	// Receivers holds profiles keyed by the receiver (contact point) name
+	// of the webhook. They override the "grafana" source profile.
+	Receivers map[string]*SourceConfig `yaml:"receivers"`
+	// Orgs holds per-organization settings keyed by the orgId of the
+	// webhook, for Grafana instances with several organizations.
+	Orgs map[int]*GrafanaOrgConfig `yaml:"orgs"`
+	// Escalation re-sends alerts that keep firing at a higher priority.
+	Escalation EscalationConfig `yaml:"escalation"`
+	// Flapping collapses notifications of alerts that keep changing between
+	// firing and resolved.
+	Flapping FlappingConfig `yaml:"flapping"`
+
+	dedupWindow time.Duration
+}
+
+// SourceConfig overrides the defaults for messages from a single source.
+type SourceConfig struct {
+	// Enabled accepts or rejects payloads from the source, defaults to true.
+	Enabled *bool `yaml:"enabled"`
+	// Priority replaces the priority derived by the source. For generic
+	// webhooks it is used when the payload has no priority. 0 keeps the default.
+	Priority int `yaml:"priority"`
+	// Title is used when the payload has no title (generic and Grafana) or
+	// replaces the generated title (other services).
+	Title string `yaml:"title"`
+	// TitleTemplate and MessageTemplate replace the global templates.
+	TitleTemplate   string `yaml:"titleTemplate"`
+	MessageTemplate string `yaml:"messageTemplate"`
+
+	titleTmpl   *template.Template
+	messageTmpl *template.Template
+}
+
+// source returns the profile for the named source, or an empty profile if
+// none is configured.
+func (c *Config) source(name string) *SourceConfig {
+	if profile, ok := c.Sources[name]; ok && profile != nil {
+		return profile
+	}
+	return &SourceConfig{}
+}
+
+// sourceEnabled reports whether payloads from the named source are accepted.
+// With genericWebhooks disabled, only Grafana alerts are accepted.
+func (c *Config) sourceEnabled(name string) bool {
+	if name != "grafana" && !c.GenericWebhooks {
+		return false
+	}
+	enabled := c.source(name).Enabled
+	return enabled == nil || *enabled
+}
+
+// defaultTitle returns the title for payloads that have none, taken from
+// the source profile, the config or fallback in that order.
+func (c *Config) defaultTitle(profile *SourceConfig, fallback string) string {
+	if profile.Title != "" {
+		return profile.Title
+	}
+	if c.DefaultTitle != "" {
+		return c.DefaultTitle
+	}
+	return fallback
+}
+
+// defaultConfig returns the configuration used until the user changes it.
+func defaultConfig() *Config {
+	return &Config{
+		GenericWebhooks: true,
+		Grafana: GrafanaConfig{
+			NotifyOnResolved: true,
+			ClickTarget:      clickDashboard,
+			ContextLabels:    []string{"grafana_folder", "rule_group"},
+			ContextPlacement: contextMessage,
+			Verbosity:        verbosityStandard,
+			SeverityLabel:    "severity",
+			SeverityPriorities: map[string]int{
+				"critical": 10,
+				"warning":  6,
+				"info":     3,
+			},
+			Signature: SignatureConfig{
+				Header: "X-Grafana-Alerting-Signature",
+			},
+			NoDataPriority: 4,
+			ErrorPriority:  7,
+			Escalation: EscalationConfig{
+				Priority: 10,
+			},
+			Flapping: FlappingConfig{
+				Window:   "30m",
+				Priority: 2,
+			},
+		},
+		Alertmanager: AlertmanagerConfig{
+			NotifyOnResolved: true,
+			SplitAlerts:      true,
+			SeverityLabel:    "severity",
+			SeverityPriorities: map[string]int{
+				"critical": 10,
+				"warning":  6,
+				"info":     3,
+			},
+		},
+		QuietHours: QuietHoursConfig{
+			MinPriority:       8,
+			Action:            "downgrade",
+			DowngradePriority: 1,
+		},
+		TruncateStrategy: truncateEllipsis,
+		TimeFormat:       defaultTimeFormat,
+		LogAnnotations:   []string{"log_line", "logline", "log"},
+		IFTTT: IFTTTConfig{
+			TitleField:    "value1",
+			MessageField:  "value2",
+			PriorityField: "value3",
+		},
+		Tautulli: TautulliConfig{
+			ActionPriorities: defaultTautulliPriorities(),
+		},
+		SNS: SNSConfig{
+			VerifySignature: true,
+			AutoConfirm:     true,
+		},
+		Stripe: StripeConfig{
+			Events: defaultStripeEvents(),
+		},
+		Dependencies: DependencyDigestConfig{
+			GroupBy: "repository",
+		},
+		ResponseCodes: ResponseCodesConfig{
+			Filtered:  http.StatusOK,
+			Duplicate: http.StatusOK,
+			Muted:     http.StatusOK,
+		},
+	}
+}
+
+// DefaultConfig implements plugin.Configurer
+func (p *WebhookForwarderPlugin) DefaultConfig() interface{} {
+	return defaultConfig()
+}
+
+// ValidateAndSetConfig implements plugin.Configurer
+func (p *WebhookForwarderPlugin) ValidateAndSetConfig(c interface{}) error {
+	config, ok := c.(*Config)
+	if !ok || config == nil {
+		return errors.New("invalid configuration type")
+	}
+	prepared, err := prepareConfig(config)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	p.config = prepared
+	// A change made in the Gotify UI replaces a config imported via /config.
+	// Gotify also sets the stored config before Enable, so while disabled
+	// the override is only dropped by Enable once the config differs.
+	p.gotifyConfig = configFingerprint(config)
+	enabled := p.enabled
+	p.mu.Unlock()
+
+	if enabled {
+		return p.saveConfigOverride("")
+	}
+	return nil
+}
+
+// prepareConfig expands environment variable references in config and
+// validates the result, which is the config used to handle webhooks.
+func prepareConfig(config *Config) (*Config, error) {
+	expanded, err := config.expandEnv()
+	if err != nil {
+		return nil, err
+	}
+	if err := expanded.validate(); err != nil {
+		return nil, err
+	}
+	return expanded, nil
+}
+
+// validate checks the configuration and prepares derived values such as
+// compiled templates and timezones.
+func (c *Config) validate() error {
+	if err := c.validateTimeSettings(); err != nil {
+		return err
+	}
+	if err := c.compileTemplates(); err != nil {
+		return err
+	}
+	if err := c.Labels.validate(); err != nil {
+		return err
+	}
+	if err := c.dryRunTemplates(); err != nil {
+		return err
+	}
+	if err := c.Grafana.validate(); err != nil {
+		return err
+	}
+	if err := c.Alertmanager.validate(); err != nil {
+		return err
+	}
+	if err := c.QuietHours.validate(); err != nil {
+		return err
+	}
+	if err := c.Tautulli.validate(); err != nil {
+		return err
+	}
+	if err := c.Dependencies.validate(); err != nil {
+		return err
+	}
+	if err := validateTruncateStrategy(c.TruncateStrategy); err != nil {
+		return err
+	}
+	if err := c.validatePriorityLimits(); err != nil {
+		return err
+	}
+	if err := c.ResponseCodes.validate(); err != nil {
+		return err
+	}
+	if err := validateRoutes(c.Routes); err != nil {
+		return err
+	}
+	if c.DefaultExtras != nil {
+		c.DefaultExtras = normalizeExtras(c.DefaultExtras).(map[string]interface{})
+	}
+	return nil
+}
+
+// getConfig returns the active configuration, falling back to the defaults
+// when none has been set yet.
+func (p *WebhookForwarderPlugin) getConfig() *Config {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.config == nil {
+		return defaultConfig()
+	}
+	return p.config
+}
+
+// decorateTitle adds the configured prefix and suffix to a message title.
+func (c *Config) decorateTitle(title string) string {
+	parts := make([]string, 0, 3)
+	for _, part := range []string{c.TitlePrefix, title, c.TitleSuffix} {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// validatePriorityLimits checks minPriority and maxPriority.
+func (c *Config) validatePriorityLimits() error {
+	if c.MinPriority < 0 || c.MinPriority > 10 || c.MaxPriority < 0 || c.MaxPriority > 10 {
+		return errors.New("minPriority and maxPriority must be between 0 and 10")
+	}
+	if c.MinPriority > 0 && c.MaxPriority > 0 && c.MinPriority > c.MaxPriority {
+		return errors.New("minPriority must not be greater than maxPriority")
+	}
+	return nil
+}
+
+// clampPriority applies minPriority and maxPriority to a priority.
+func (c *Config) clampPriority(priority int) int {
+	if c.MinPriority > 0 && priority < c.MinPriority {
+		priority = c.MinPriority
+	}
+	if c.MaxPriority > 0 && priority > c.MaxPriority {
+		priority = c.MaxPriority
+	}
+	return priority
+}
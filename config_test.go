@@ -0,0 +1,140 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gotify/plugin-api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_DefaultConfig(t *testing.T) {
+	p := &WebhookForwarderPlugin{}
+	config, ok := p.DefaultConfig().(*Config)
+	assert.True(t, ok)
+	assert.True(t, config.GenericWebhooks)
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+	assert.Error(t, p.ValidateAndSetConfig("not a config"))
+}
+
+func TestWebhookForwarderPlugin_GenericWebhooksDisabled(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+	config := defaultConfig()
+	config.GenericWebhooks = false
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+
+	w := postWebhook(p, map[string]interface{}{"message": "generic"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	// Payloads of supported services are not Grafana alerts either
+	w = postWebhook(p, map[string]interface{}{"event": "user_banned", "username": "john", "remote_ip": "203.0.113.7"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "'authelia'")
+	w = postWebhook(p, map[string]interface{}{"text": "hi"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "'googlechat'")
+	assert.Empty(t, mockHandler.sentMessages)
+
+	w = postWebhook(p, map[string]interface{}{
+		"status": "firing",
+		"alerts": []interface{}{},
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title:    "Grafana Alert: firing",
+		Message:  "Alert notification from Grafana",
+		Priority: 8,
+		Extras:   map[string]interface{}{"source": "grafana", "status": "firing"},
+	}, mockHandler.sentMessages[0])
+}
+
+func TestWebhookForwarderPlugin_TitleDecoration(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+	config := defaultConfig()
+	config.DefaultTitle = "Notification"
+	config.TitlePrefix = "[prod]"
+	config.TitleSuffix = "(eu-west)"
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+
+	postWebhook(p, map[string]interface{}{"message": "no title"})
+	postWebhook(p, map[string]interface{}{"title": "Disk full", "message": "with title"})
+	postWebhook(p, map[string]interface{}{"alerts": []interface{}{}})
+
+	assert.Len(t, mockHandler.sentMessages, 3)
+	assert.Equal(t, "[prod] Notification (eu-west)", mockHandler.sentMessages[0].Title)
+	assert.Equal(t, "[prod] Disk full (eu-west)", mockHandler.sentMessages[1].Title)
+	assert.Equal(t, "[prod] Notification (eu-west)", mockHandler.sentMessages[2].Title)
+}
+
+func TestWebhookForwarderPlugin_NotifyOnResolvedDisabled(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+	config := defaultConfig()
+	config.Grafana.NotifyOnResolved = false
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+
+	w := postWebhook(p, map[string]interface{}{"status": "resolved", "alerts": []interface{}{}})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"forwarded":false`)
+	assert.Empty(t, mockHandler.sentMessages)
+
+	w = postWebhook(p, map[string]interface{}{"status": "firing", "alerts": []interface{}{}})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+}
+
+func TestWebhookForwarderPlugin_SourceProfiles(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+	disabled := false
+	config := defaultConfig()
+	config.Sources = map[string]*SourceConfig{
+		"generic":  {Priority: 2, Title: "Generic"},
+		"grafana":  {Priority: 9, MessageTemplate: "{{ .Status }} alert"},
+		"authelia": {Enabled: &disabled},
+	}
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+
+	postWebhook(p, map[string]interface{}{"message": "hello"})
+	postWebhook(p, map[string]interface{}{"message": "explicit", "priority": 7})
+	postWebhook(p, map[string]interface{}{"status": "firing", "alerts": []interface{}{}})
+	w := postWebhook(p, map[string]interface{}{"source": "authelia", "event": "user_banned"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	assert.Len(t, mockHandler.sentMessages, 3)
+	assert.Equal(t, "Generic", mockHandler.sentMessages[0].Title)
+	assert.Equal(t, 2, mockHandler.sentMessages[0].Priority)
+	assert.Equal(t, 7, mockHandler.sentMessages[1].Priority)
+	assert.Equal(t, 9, mockHandler.sentMessages[2].Priority)
+	assert.Equal(t, "firing alert", mockHandler.sentMessages[2].Message)
+
+	config.Sources["grafana"].TitleTemplate = "{{ .Status"
+	err := p.ValidateAndSetConfig(config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "sources.grafana.titleTemplate")
+}
+
+func TestWebhookForwarderPlugin_PriorityLimits(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+	config := defaultConfig()
+	config.MinPriority = 4
+	config.MaxPriority = 7
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+
+	postWebhook(p, map[string]interface{}{"message": "low", "priority": 1})
+	postWebhook(p, map[string]interface{}{"message": "high", "priority": 10})
+	postWebhook(p, map[string]interface{}{"status": "firing", "alerts": []interface{}{}})
+	assert.Len(t, mockHandler.sentMessages, 3)
+	assert.Equal(t, 4, mockHandler.sentMessages[0].Priority)
+	assert.Equal(t, 7, mockHandler.sentMessages[1].Priority)
+	assert.Equal(t, 7, mockHandler.sentMessages[2].Priority)
+
+	config.MinPriority = 8
+	assert.Error(t, p.ValidateAndSetConfig(config))
+	config.MinPriority = 11
+	config.MaxPriority = 0
+	assert.Error(t, p.ValidateAndSetConfig(config))
+}
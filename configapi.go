@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v3"
+)
+
+// maxConfigSize limits the size of an imported YAML config.
+const maxConfigSize = 1 << 20
+
+// parseConfigYAML decodes a YAML config on top of the defaults, like Gotify
+// does for the config stored in its database, and validates it.
+func parseConfigYAML(data []byte) (*Config, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, errors.New("empty configuration")
+	}
+	config := defaultConfig()
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(config); err != nil {
+		return nil, fmt.Errorf("invalid YAML: %w", err)
+	}
+	return prepareConfig(config)
+}
+
+// configFingerprint identifies a config set through the Gotify UI.
+func configFingerprint(config *Config) string {
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// applyConfigOverride restores a config previously imported via /config,
+// unless the Gotify UI config was changed since the import.
+func (p *WebhookForwarderPlugin) applyConfigOverride() error {
+	storage, err := p.loadStorage()
+	if err != nil || storage.ConfigOverride == "" {
+		return err
+	}
+	p.mu.RLock()
+	gotifyConfig := p.gotifyConfig
+	p.mu.RUnlock()
+	if storage.ConfigOverrideBase != gotifyConfig {
+		return p.saveConfigOverride("")
+	}
+	config, err := parseConfigYAML([]byte(storage.ConfigOverride))
+	if err != nil {
+		// The stored override is no longer valid, keep the Gotify config
+		return p.saveConfigOverride("")
+	}
+	p.mu.Lock()
+	p.config = config
+	p.mu.Unlock()
+	return nil
+}
+
+// saveConfigOverride persists an imported YAML config along with the Gotify
+// UI config it replaces, or removes it when data is empty.
+func (p *WebhookForwarderPlugin) saveConfigOverride(data string) error {
+	base := ""
+	if data != "" {
+		p.mu.RLock()
+		base = p.gotifyConfig
+		p.mu.RUnlock()
+	}
+	return p.updateStorage(func(storage *pluginStorage) {
+		storage.ConfigOverride = data
+		storage.ConfigOverrideBase = base
+	})
+}
+
+// authorizeConfigRequest checks the config token and writes an error
+// response if the request is not allowed.
+func (p *WebhookForwarderPlugin) authorizeConfigRequest(c *gin.Context) bool {
+	token := p.getConfig().ConfigToken
+	if token == "" {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Config endpoint is disabled, set configToken to enable it",
+		})
+		return false
+	}
+
+	provided := c.GetHeader("X-Config-Token")
+	if auth := c.GetHeader("Authorization"); provided == "" && strings.HasPrefix(auth, "Bearer ") {
+		provided = strings.TrimPrefix(auth, "Bearer ")
+	}
+	if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Invalid or missing config token",
+		})
+		return false
+	}
+	return true
+}
+
+// handleGetConfig exports the active configuration as YAML.
+func (p *WebhookForwarderPlugin) handleGetConfig(c *gin.Context) {
+	if !p.authorizeConfigRequest(c) {
+		return
+	}
+
+	data, err := yaml.Marshal(p.getConfig().exported())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to export configuration",
+			"details": err.Error(),
+		})
+		return
+	}
+	c.Data(http.StatusOK, "application/yaml; charset=utf-8", data)
+}
+
+// handlePutConfig imports a YAML configuration, applies it and persists it
+// so it survives restarts.
+func (p *WebhookForwarderPlugin) handlePutConfig(c *gin.Context) {
+	if !p.authorizeConfigRequest(c) {
+		return
+	}
+
+	data, err := io.ReadAll(io.LimitReader(c.Request.Body, maxConfigSize+1))
+	if err != nil || len(data) > maxConfigSize {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Configuration could not be read or is too large",
+		})
+		return
+	}
+
+	config, err := parseConfigYAML(data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid configuration",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := p.saveConfigOverride(string(data)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to persist configuration",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	p.mu.Lock()
+	p.config = config
+	p.mu.Unlock()
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Configuration updated",
+	})
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// MockStorageHandler implements plugin.StorageHandler for testing
+type MockStorageHandler struct {
+	data []byte
+}
+
+func (m *MockStorageHandler) Save(b []byte) error {
+	m.data = b
+	return nil
+}
+
+func (m *MockStorageHandler) Load() ([]byte, error) {
+	return m.data, nil
+}
+
+func configRequest(p *WebhookForwarderPlugin, method, token, body string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/config", p.handleGetConfig)
+	router.PUT("/config", p.handlePutConfig)
+
+	req := httptest.NewRequest(method, "/config", strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestWebhookForwarderPlugin_ConfigEndpoint(t *testing.T) {
+	storage := &MockStorageHandler{}
+	p := &WebhookForwarderPlugin{}
+	p.SetStorageHandler(storage)
+
+	// Disabled without a token
+	assert.Equal(t, http.StatusNotFound, configRequest(p, "GET", "", "").Code)
+
+	config := defaultConfig()
+	config.ConfigToken = "secret"
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+	assert.NoError(t, p.Enable())
+
+	assert.Equal(t, http.StatusUnauthorized, configRequest(p, "GET", "wrong", "").Code)
+
+	w := configRequest(p, "GET", "secret", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "genericWebhooks: true")
+
+	// Invalid YAML and unknown fields are rejected
+	assert.Equal(t, http.StatusBadRequest, configRequest(p, "PUT", "secret", "titlePrefix: [").Code)
+	assert.Equal(t, http.StatusBadRequest, configRequest(p, "PUT", "secret", "unknownField: 1").Code)
+	assert.Equal(t, http.StatusBadRequest, configRequest(p, "PUT", "secret", "truncateStrategy: cut").Code)
+
+	w = configRequest(p, "PUT", "secret", "configToken: secret\ntitlePrefix: \"[prod]\"\n")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "[prod]", p.getConfig().TitlePrefix)
+	assert.True(t, p.getConfig().GenericWebhooks)
+
+	// The imported config is restored by a new instance sharing the storage
+	restarted := &WebhookForwarderPlugin{}
+	restarted.SetStorageHandler(storage)
+	assert.NoError(t, restarted.ValidateAndSetConfig(config))
+	assert.NoError(t, restarted.Enable())
+	assert.Equal(t, "[prod]", restarted.getConfig().TitlePrefix)
+
+	// Editing the config in the Gotify UI replaces the imported config
+	assert.NoError(t, restarted.ValidateAndSetConfig(defaultConfig()))
+	stored, _ := restarted.loadStorage()
+	assert.Empty(t, stored.ConfigOverride)
+}
+
+func TestWebhookForwarderPlugin_ConfigSavedWhileDisabled(t *testing.T) {
+	storage := &MockStorageHandler{}
+	p := &WebhookForwarderPlugin{}
+	p.SetStorageHandler(storage)
+
+	config := defaultConfig()
+	config.ConfigToken = "secret"
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+	assert.NoError(t, p.Enable())
+	assert.Equal(t, http.StatusOK, configRequest(p, "PUT", "secret", "configToken: secret\ntitlePrefix: \"[prod]\"\n").Code)
+	assert.NoError(t, p.Disable())
+
+	// Saving the config in the Gotify UI while disabled replaces the import
+	edited := defaultConfig()
+	edited.ConfigToken = "secret"
+	edited.TitlePrefix = "[ui]"
+	assert.NoError(t, p.ValidateAndSetConfig(edited))
+	assert.NoError(t, p.Enable())
+	assert.Equal(t, "[ui]", p.getConfig().TitlePrefix)
+
+	stored, _ := p.loadStorage()
+	assert.Empty(t, stored.ConfigOverride)
+	assert.Empty(t, stored.ConfigOverrideBase)
+}
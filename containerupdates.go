@@ -0,0 +1,188 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gotify/plugin-api"
+)
+
+// isWatchtowerPayload detects Watchtower session reports sent with the
+// json.v1 notification template.
+func isWatchtowerPayload(body map[string]interface{}) bool {
+	report, ok := body["report"].(map[string]interface{})
+	return ok && hasAnyField(report, "updated", "failed", "scanned")
+}
+
+// formatWatchtowerPayload renders a Watchtower report listing the updated
+// and failed containers with their images. Failed updates get priority 7,
+// updates 4 and reports without changes 2.
+func formatWatchtowerPayload(body map[string]interface{}, _ *Config) plugin.Message {
+	report, _ := body["report"].(map[string]interface{})
+	updated := mapSlice(report["updated"])
+	failed := mapSlice(report["failed"])
+	host := stringField(body, "host")
+
+	title := "Watchtower: no updates"
+	switch {
+	case len(updated) == 1 && len(failed) == 0:
+		title = fmt.Sprintf("Watchtower: %s updated", stringField(updated[0], "name"))
+	case len(updated) > 0 && len(failed) == 0:
+		title = fmt.Sprintf("Watchtower: %d containers updated", len(updated))
+	case len(failed) > 0:
+		title = fmt.Sprintf("Watchtower: %d updates failed", len(failed))
+		if len(failed) == 1 {
+			title = fmt.Sprintf("Watchtower: update of %s failed", stringField(failed[0], "name"))
+		}
+	}
+	if host != "" {
+		title += " on " + host
+	}
+
+	var paragraphs []string
+	if len(updated) > 0 {
+		lines := []string{"Updated:"}
+		for _, container := range updated {
+			line := "- " + watchtowerContainer(container)
+			current, latest := shortImageID(stringField(container, "currentImageId")), shortImageID(stringField(container, "latestImageId"))
+			if current != "" && latest != "" {
+				line += fmt.Sprintf(": %s → %s", current, latest)
+			}
+			lines = append(lines, line)
+		}
+		paragraphs = append(paragraphs, strings.Join(lines, "\n"))
+	}
+	if len(failed) > 0 {
+		lines := []string{"Failed:"}
+		for _, container := range failed {
+			line := "- " + watchtowerContainer(container)
+			if reason := stringField(container, "error"); reason != "" {
+				line += ": " + reason
+			}
+			lines = append(lines, line)
+		}
+		paragraphs = append(paragraphs, strings.Join(lines, "\n"))
+	}
+	if scanned := mapSlice(report["scanned"]); len(scanned) > 0 {
+		paragraphs = append(paragraphs, fmt.Sprintf("Scanned %d containers", len(scanned)))
+	}
+	message := strings.Join(paragraphs, "\n\n")
+	if message == "" {
+		message = title
+	}
+
+	priority := 2
+	switch {
+	case len(failed) > 0:
+		priority = 7
+	case len(updated) > 0:
+		priority = 4
+	}
+
+	extras := map[string]interface{}{
+		"source":  "watchtower",
+		"updated": len(updated),
+		"failed":  len(failed),
+	}
+	if host != "" {
+		extras["host"] = host
+	}
+
+	return plugin.Message{
+		Title:    title,
+		Message:  message,
+		Priority: priority,
+		Extras:   extras,
+	}
+}
+
+// watchtowerContainer names a container of a Watchtower report with its image.
+func watchtowerContainer(container map[string]interface{}) string {
+	name := stringField(container, "name")
+	if image := stringField(container, "imageName"); image != "" {
+		name += " (" + image + ")"
+	}
+	return name
+}
+
+// shortImageID shortens an image ID or digest to 12 hex digits like docker.
+func shortImageID(id string) string {
+	if _, digest, ok := strings.Cut(id, ":"); ok {
+		id = digest
+	}
+	if len(id) > 12 {
+		id = id[:12]
+	}
+	return id
+}
+
+// isDiunPayload detects notifications of Diun's webhook notifier.
+func isDiunPayload(body map[string]interface{}) bool {
+	return hasFields(body, "diun_version", "image") || hasFields(body, "status", "image", "hub_link")
+}
+
+// formatDiunPayload renders a Diun image notification, titled by whether the
+// image is new or has an update.
+func formatDiunPayload(body map[string]interface{}, config *Config) plugin.Message {
+	image := stringField(body, "image")
+	status := stringField(body, "status")
+
+	title := "Diun: " + image
+	priority := 3
+	switch status {
+	case "new":
+		title = "New image: " + image
+	case "update":
+		title = "Image update available: " + image
+		priority = 4
+	}
+
+	var lines []string
+	metadata, _ := body["metadata"].(map[string]interface{})
+	if containers := stringField(metadata, "ctn_names"); containers != "" {
+		lines = append(lines, "Containers: "+containers)
+	}
+	host := stringField(body, "hostname")
+	if host != "" {
+		lines = append(lines, "Host: "+host)
+	}
+	if provider := stringField(body, "provider"); provider != "" {
+		lines = append(lines, "Provider: "+provider)
+	}
+	if platform := stringField(body, "platform"); platform != "" {
+		lines = append(lines, "Platform: "+platform)
+	}
+	if digest := shortImageID(stringField(body, "digest")); digest != "" {
+		lines = append(lines, "Digest: "+digest)
+	}
+	if created := config.formatTimestamp(stringField(body, "created")); created != "" {
+		lines = append(lines, "Created: "+created)
+	}
+	message := strings.Join(lines, "\n")
+	if message == "" {
+		message = title
+	}
+
+	extras := map[string]interface{}{
+		"source": "diun",
+		"image":  image,
+	}
+	if status != "" {
+		extras["status"] = status
+	}
+	if host != "" {
+		extras["host"] = host
+	}
+	if link := stringField(body, "hub_link"); link != "" {
+		extras["client::notification"] = map[string]interface{}{
+			"click": map[string]interface{}{"url": link},
+		}
+	}
+
+	return plugin.Message{
+		Title:    title,
+		Message:  message,
+		Priority: priority,
+		Extras:   extras,
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gotify/plugin-api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_WatchtowerWebhook(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	w := postWebhook(p, map[string]interface{}{
+		"title": "Watchtower updates on docker01",
+		"host":  "docker01",
+		"report": map[string]interface{}{
+			"scanned": []interface{}{map[string]interface{}{}, map[string]interface{}{}, map[string]interface{}{}},
+			"updated": []interface{}{
+				map[string]interface{}{
+					"name":           "nginx",
+					"imageName":      "nginx:latest",
+					"currentImageId": "sha256:3f8a00f137a0d2c8a2163a09901e28e2471999fde4efc2f9570b91f1c30acf94",
+					"latestImageId":  "sha256:a6bd71f48f6839d9faae1f29d3babef831e76bc213107682c5cc80f0cbb30866",
+					"state":          "Updated",
+				},
+			},
+			"failed": []interface{}{},
+		},
+		"entries": []interface{}{},
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title:    "Watchtower: nginx updated on docker01",
+		Message:  "Updated:\n- nginx (nginx:latest): 3f8a00f137a0 → a6bd71f48f68\n\nScanned 3 containers",
+		Priority: 4,
+		Extras: map[string]interface{}{
+			"source":  "watchtower",
+			"updated": 1,
+			"failed":  0,
+			"host":    "docker01",
+		},
+	}, mockHandler.sentMessages[0])
+
+	msg := formatWatchtowerPayload(map[string]interface{}{
+		"report": map[string]interface{}{
+			"failed": []interface{}{
+				map[string]interface{}{"name": "db", "imageName": "postgres:16", "error": "pull access denied"},
+			},
+		},
+	}, defaultConfig())
+	assert.Equal(t, "Watchtower: update of db failed", msg.Title)
+	assert.Equal(t, "Failed:\n- db (postgres:16): pull access denied", msg.Message)
+	assert.Equal(t, 7, msg.Priority)
+}
+
+func TestWebhookForwarderPlugin_DiunWebhook(t *testing.T) {
+	config := defaultConfig()
+	config.Timezone = "UTC"
+	assert.NoError(t, config.validate())
+
+	msg := formatDiunPayload(map[string]interface{}{
+		"diun_version": "4.28.0",
+		"hostname":     "docker01",
+		"status":       "update",
+		"provider":     "docker",
+		"image":        "docker.io/crazymax/diun:latest",
+		"hub_link":     "https://hub.docker.com/r/crazymax/diun",
+		"digest":       "sha256:216e3ae7de4ca8b553eb11ef7abda00651e79e537e85c46108284e5e91673e01",
+		"created":      "2024-03-26T12:23:56Z",
+		"platform":     "linux/amd64",
+		"metadata":     map[string]interface{}{"ctn_names": "diun"},
+	}, config)
+	assert.Equal(t, plugin.Message{
+		Title:    "Image update available: docker.io/crazymax/diun:latest",
+		Message:  "Containers: diun\nHost: docker01\nProvider: docker\nPlatform: linux/amd64\nDigest: 216e3ae7de4c\nCreated: 2024-03-26 12:23:56 UTC",
+		Priority: 4,
+		Extras: map[string]interface{}{
+			"source": "diun",
+			"image":  "docker.io/crazymax/diun:latest",
+			"status": "update",
+			"host":   "docker01",
+			"client::notification": map[string]interface{}{
+				"click": map[string]interface{}{"url": "https://hub.docker.com/r/crazymax/diun"},
+			},
+		},
+	}, msg)
+}
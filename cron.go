@@ -0,0 +1,230 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gotify/plugin-api"
+)
+
+// cronEvents normalises the event types of cron monitoring services to
+// "ran", "failed", "missed" or "recovered".
+var cronEvents = map[string]string{
+	"ran":       "ran",
+	"run":       "ran",
+	"complete":  "ran",
+	"completed": "ran",
+	"success":   "ran",
+	"succeeded": "ran",
+	"reporting": "ran",
+	"failed":    "failed",
+	"fail":      "failed",
+	"failure":   "failed",
+	"alert":     "failed",
+	"errored":   "failed",
+	"error":     "failed",
+	"missed":    "missed",
+	"missing":   "missed",
+	"late":      "missed",
+	"recovered": "recovered",
+	"recovery":  "recovered",
+	"resolved":  "recovered",
+}
+
+// cronPriorities maps normalised cron events to priorities. Failed and
+// missed jobs are high priority so they get through to phones.
+var cronPriorities = map[string]int{
+	"failed":    8,
+	"missed":    8,
+	"recovered": 3,
+	"ran":       1,
+}
+
+// isSnitchPayload detects Dead Man's Snitch webhooks, which describe the
+// snitch under data.snitch with a "snitch.<status>" event type.
+func isSnitchPayload(body map[string]interface{}) bool {
+	data, _ := body["data"].(map[string]interface{})
+	_, ok := data["snitch"].(map[string]interface{})
+	return ok && strings.HasPrefix(stringField(body, "type"), "snitch.")
+}
+
+// formatSnitchPayload renders a Dead Man's Snitch event. A snitch reporting
+// again after it was missing or errored is a recovery.
+func formatSnitchPayload(body map[string]interface{}, _ *Config) plugin.Message {
+	data, _ := body["data"].(map[string]interface{})
+	snitch, _ := data["snitch"].(map[string]interface{})
+	event := snitchEvent(body)
+
+	var lines []string
+	if status := stringField(snitch, "status"); status != "" {
+		if previous := stringField(snitch, "previous_status"); previous != "" && previous != status {
+			status = previous + " → " + status
+		}
+		lines = append(lines, "Status: "+status)
+	}
+	if kind, ok := snitch["type"].(map[string]interface{}); ok {
+		if interval := stringField(kind, "interval"); interval != "" {
+			lines = append(lines, "Interval: "+interval)
+		}
+	}
+	if tags := joinStrings(snitch["tags"]); tags != "" {
+		lines = append(lines, "Tags: "+tags)
+	}
+
+	return cronMessage("deadmanssnitch", "Dead Man's Snitch", stringField(snitch, "name", "token"), event,
+		stringField(snitch, "notes"), lines, map[string]string{
+			"token":  stringField(snitch, "token"),
+			"status": stringField(snitch, "status"),
+		}, stringField(snitch, "url", "check_in_url"))
+}
+
+// snitchEvent returns the normalised event of a Dead Man's Snitch webhook.
+func snitchEvent(body map[string]interface{}) string {
+	data, _ := body["data"].(map[string]interface{})
+	snitch, _ := data["snitch"].(map[string]interface{})
+	event := cronEvents[strings.TrimPrefix(stringField(body, "type"), "snitch.")]
+	if event == "ran" {
+		switch cronEvents[stringField(snitch, "previous_status")] {
+		case "failed", "missed":
+			event = "recovered"
+		}
+	}
+	return event
+}
+
+// isCronitorPayload detects Cronitor webhooks and similar cron monitoring
+// payloads naming a monitor and an event such as ran, failed or recovered.
+func isCronitorPayload(body map[string]interface{}) bool {
+	if !hasAnyField(body, "monitor", "monitor_name", "monitorName", "job") {
+		return false
+	}
+	return sourceIs(body, "cronitor") || cronitorEvent(body) != ""
+}
+
+// cronitorEvent returns the normalised event of a Cronitor style payload,
+// or "" if it is unknown.
+func cronitorEvent(body map[string]interface{}) string {
+	return cronEvents[strings.ToLower(stringField(body, "event", "type", "state"))]
+}
+
+// formatCronitorPayload renders a Cronitor style cron job event with the
+// monitor in the title and the failure description as message.
+func formatCronitorPayload(body map[string]interface{}, _ *Config) plugin.Message {
+	monitor, _ := body["monitor"].(map[string]interface{})
+	name := stringField(body, "name", "monitor_name", "monitorName", "job")
+	if name == "" {
+		name = stringField(monitor, "name", "key")
+	}
+	if name == "" {
+		name = stringField(body, "monitor")
+	}
+	key := stringField(body, "id", "monitor")
+	if key == "" {
+		key = stringField(monitor, "key", "id")
+	}
+
+	var lines []string
+	if rule := stringField(body, "rule"); rule != "" {
+		lines = append(lines, "Rule: "+rule)
+	}
+	if environment := stringField(body, "environment", "env"); environment != "" {
+		lines = append(lines, "Environment: "+environment)
+	}
+	if host := stringField(body, "host", "hostname"); host != "" {
+		lines = append(lines, "Host: "+host)
+	}
+	if code := stringField(body, "exit_code", "exitCode", "status_code"); code != "" {
+		lines = append(lines, "Exit code: "+code)
+	}
+
+	return cronMessage("cronitor", "Cronitor", name, cronitorEvent(body),
+		stringField(body, "message", "description", "text"), lines, map[string]string{
+			"monitor": key,
+		}, stringField(body, "url", "monitor_url", "monitorUrl"))
+}
+
+// cronMessage builds the message of a cron job event. The title names the
+// job and what happened to it; the description leads the message.
+func cronMessage(source, service, name, event, description string, lines []string, fields map[string]string, url string) plugin.Message {
+	if name == "" {
+		name = "Cron job"
+	}
+	title := fmt.Sprintf("%s: %s", service, name)
+	if event != "" {
+		title = fmt.Sprintf("%s: %s %s", service, name, event)
+	}
+
+	var paragraphs []string
+	if description = strings.TrimSpace(description); description != "" {
+		paragraphs = append(paragraphs, description)
+	}
+	if len(lines) > 0 {
+		paragraphs = append(paragraphs, strings.Join(lines, "\n"))
+	}
+	message := strings.Join(paragraphs, "\n\n")
+	if message == "" {
+		message = title
+	}
+
+	priority, ok := cronPriorities[event]
+	if !ok {
+		priority = 5
+	}
+
+	extras := map[string]interface{}{"source": source}
+	if event != "" {
+		extras["event"] = event
+	}
+	for key, value := range fields {
+		if value != "" {
+			extras[key] = value
+		}
+	}
+	if url != "" {
+		extras["client::notification"] = map[string]interface{}{
+			"click": map[string]interface{}{"url": url},
+		}
+	}
+
+	return plugin.Message{
+		Title:    title,
+		Message:  message,
+		Priority: priority,
+		Extras:   extras,
+	}
+}
+
+// snitchCheck identifies the snitch of a Dead Man's Snitch webhook, for
+// reporting how long it was failing when it recovers.
+func snitchCheck(body map[string]interface{}) (string, bool, time.Time) {
+	data, _ := body["data"].(map[string]interface{})
+	snitch, _ := data["snitch"].(map[string]interface{})
+	return cronCheck(stringField(snitch, "token", "name"), snitchEvent(body), stringField(body, "timestamp"))
+}
+
+// cronitorCheck identifies the monitor of a Cronitor style webhook, for
+// reporting how long it was failing when it recovers.
+func cronitorCheck(body map[string]interface{}) (string, bool, time.Time) {
+	id := stringField(body, "id", "monitor", "name", "monitor_name", "monitorName", "job")
+	if monitor, ok := body["monitor"].(map[string]interface{}); ok && id == "" {
+		id = stringField(monitor, "key", "id", "name")
+	}
+	return cronCheck(id, cronitorEvent(body), stringField(body, "timestamp", "time"))
+}
+
+// cronCheck maps a cron event to a down state. Failed and missed jobs are
+// down, successful runs and recoveries up; other events are not tracked.
+func cronCheck(id, event, timestamp string) (string, bool, time.Time) {
+	var changed time.Time
+	if t, err := time.Parse(time.RFC3339Nano, timestamp); err == nil {
+		changed = t
+	}
+	switch event {
+	case "failed", "missed":
+		return id, true, changed
+	case "ran", "recovered":
+		return id, false, changed
+	}
+	return "", false, changed
+}
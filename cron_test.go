@@ -0,0 +1,99 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gotify/plugin-api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_CronitorWebhook(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+	p.SetStorageHandler(&MockStorageHandler{})
+
+	w := postWebhook(p, map[string]interface{}{
+		"monitor":     "nightly-backup",
+		"name":        "Nightly Backup",
+		"event":       "failed",
+		"message":     "Job exited with status 1",
+		"environment": "production",
+		"exit_code":   1.0,
+		"timestamp":   "2024-05-01T02:00:00Z",
+		"url":         "https://cronitor.io/app/monitors/nightly-backup",
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title:    "Cronitor: Nightly Backup failed",
+		Message:  "Job exited with status 1\n\nEnvironment: production\nExit code: 1",
+		Priority: 8,
+		Extras: map[string]interface{}{
+			"source":  "cronitor",
+			"event":   "failed",
+			"monitor": "nightly-backup",
+			"client::notification": map[string]interface{}{
+				"click": map[string]interface{}{"url": "https://cronitor.io/app/monitors/nightly-backup"},
+			},
+		},
+	}, mockHandler.sentMessages[0])
+
+	// The recovery reports how long the job was failing
+	postWebhook(p, map[string]interface{}{
+		"monitor":   "nightly-backup",
+		"name":      "Nightly Backup",
+		"event":     "recovered",
+		"timestamp": "2024-05-02T02:05:00Z",
+	})
+	assert.Len(t, mockHandler.sentMessages, 2)
+	msg := mockHandler.sentMessages[1]
+	assert.Equal(t, "Cronitor: Nightly Backup recovered", msg.Title)
+	assert.Equal(t, 3, msg.Priority)
+	assert.Equal(t, "Cronitor: Nightly Backup recovered\n\nDown for 1d", msg.Message)
+}
+
+func TestWebhookForwarderPlugin_SnitchWebhook(t *testing.T) {
+	snitch := func(event, status, previous string) map[string]interface{} {
+		return map[string]interface{}{
+			"type":      "snitch." + event,
+			"timestamp": "2024-05-01T02:00:00Z",
+			"data": map[string]interface{}{
+				"snitch": map[string]interface{}{
+					"token":           "c2354d53d2",
+					"name":            "Nightly Backup",
+					"notes":           "Runs on db01",
+					"tags":            []interface{}{"backups"},
+					"status":          status,
+					"previous_status": previous,
+					"type":            map[string]interface{}{"interval": "daily"},
+				},
+			},
+		}
+	}
+
+	msg := formatSnitchPayload(snitch("missing", "missing", "healthy"), defaultConfig())
+	assert.Equal(t, plugin.Message{
+		Title:    "Dead Man's Snitch: Nightly Backup missed",
+		Message:  "Runs on db01\n\nStatus: healthy → missing\nInterval: daily\nTags: backups",
+		Priority: 8,
+		Extras: map[string]interface{}{
+			"source": "deadmanssnitch",
+			"event":  "missed",
+			"token":  "c2354d53d2",
+			"status": "missing",
+		},
+	}, msg)
+
+	msg = formatSnitchPayload(snitch("reporting", "healthy", "missing"), defaultConfig())
+	assert.Equal(t, "Dead Man's Snitch: Nightly Backup recovered", msg.Title)
+	assert.Equal(t, 3, msg.Priority)
+
+	msg = formatSnitchPayload(snitch("reporting", "healthy", "healthy"), defaultConfig())
+	assert.Equal(t, "Dead Man's Snitch: Nightly Backup ran", msg.Title)
+	assert.Equal(t, 1, msg.Priority)
+
+	assert.True(t, isSnitchPayload(snitch("errored", "errored", "healthy")))
+	assert.False(t, isCronitorPayload(map[string]interface{}{"monitor": "backup", "event": "unknown"}))
+}
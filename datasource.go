@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Datasource states of Grafana alerts whose query could not be evaluated.
+const (
+	datasourceNoData = "NoData"
+	datasourceError  = "Error"
+)
+
+// datasourceState returns datasourceNoData or datasourceError for alerts
+// Grafana raises because a query returned no data or failed, otherwise "".
+func datasourceState(alert GrafanaAlert) string {
+	switch strings.ToLower(alert.Labels["alertname"]) {
+	case "datasourcenodata":
+		return datasourceNoData
+	case "datasourceerror":
+		return datasourceError
+	}
+	// Legacy alerting reports these as the state of the rule
+	switch alert.Status {
+	case "no_data":
+		return datasourceNoData
+	case "error":
+		return datasourceError
+	}
+	return ""
+}
+
+// datasourceTitle returns the title for an alert in a datasource state,
+// naming the rule that could not be evaluated if known.
+func datasourceTitle(state string, alert GrafanaAlert) string {
+	title := "No data"
+	if state == datasourceError {
+		title = "Datasource error"
+	}
+	rule := alert.Labels["rulename"]
+	if alertname := alert.Labels["alertname"]; rule == "" && !strings.HasPrefix(strings.ToLower(alertname), "datasource") {
+		// Legacy alerts are named after their rule
+		rule = alertname
+	}
+	if rule != "" {
+		title += ": " + rule
+	}
+	return title
+}
+
+// alertName returns the name of an alert used in titles and headings, or
+// fallback if the alert has none.
+func alertName(alert GrafanaAlert, fallback string) string {
+	if state := datasourceState(alert); state != "" {
+		return datasourceTitle(state, alert)
+	}
+	if name := alert.Labels["alertname"]; name != "" {
+		return name
+	}
+	return fallback
+}
+
+// datasourcePriority returns the priority configured for a datasource state,
+// or 0 for other states.
+func (g *GrafanaConfig) datasourcePriority(state string) int {
+	switch state {
+	case datasourceNoData:
+		return g.NoDataPriority
+	case datasourceError:
+		return g.ErrorPriority
+	}
+	return 0
+}
+
+// validateDatasourcePriorities checks noDataPriority and errorPriority.
+func (g *GrafanaConfig) validateDatasourcePriorities() error {
+	for name, priority := range map[string]int{"noDataPriority": g.NoDataPriority, "errorPriority": g.ErrorPriority} {
+		if priority < 0 || priority > 10 {
+			return fmt.Errorf("invalid grafana.%s: priority must be between 0 and 10", name)
+		}
+	}
+	return nil
+}
+
+// datasourceWebhookTitle returns the title for a grouped notification whose
+// alerts are all in the same datasource state, otherwise "".
+func datasourceWebhookTitle(webhook GrafanaWebhook) string {
+	if len(webhook.Alerts) == 0 {
+		return ""
+	}
+	state := datasourceState(webhook.Alerts[0])
+	for _, alert := range webhook.Alerts[1:] {
+		if datasourceState(alert) != state {
+			return ""
+		}
+	}
+	switch {
+	case state == "":
+		return ""
+	case len(webhook.Alerts) == 1:
+		return datasourceTitle(state, webhook.Alerts[0])
+	}
+	return datasourceTitle(state, GrafanaAlert{})
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func datasourceGrafanaPayload(status, alertname string) map[string]interface{} {
+	payload := grafanaNotification(grafanaAlert(status, "alertname", alertname, "rulename", "CPU usage"))
+	payload["title"] = "[FIRING:1] " + alertname
+	return payload
+}
+
+func TestWebhookForwarderPlugin_GrafanaDatasourceStates(t *testing.T) {
+	p, mockHandler := newTestPlugin(t, defaultConfig())
+
+	postWebhook(p, datasourceGrafanaPayload("firing", "DatasourceError"))
+	msg := mockHandler.sentMessages[0]
+	assert.Equal(t, "Datasource error: CPU usage", msg.Title)
+	assert.Equal(t, 7, msg.Priority)
+	assert.Equal(t, datasourceError, msg.Extras["datasourceState"])
+
+	postWebhook(p, datasourceGrafanaPayload("firing", "DatasourceNoData"))
+	msg = mockHandler.sentMessages[1]
+	assert.Equal(t, "No data: CPU usage", msg.Title)
+	assert.Equal(t, 4, msg.Priority)
+
+	postWebhook(p, datasourceGrafanaPayload("resolved", "DatasourceNoData"))
+	msg = mockHandler.sentMessages[2]
+	assert.Equal(t, "[RESOLVED] No data: CPU usage", msg.Title)
+	assert.Equal(t, 3, msg.Priority)
+
+	// Ordinary alerts keep Grafana's title
+	postWebhook(p, datasourceGrafanaPayload("firing", "HighCPU"))
+	msg = mockHandler.sentMessages[3]
+	assert.Equal(t, "[FIRING:1] HighCPU", msg.Title)
+	assert.Equal(t, 8, msg.Priority)
+	assert.NotContains(t, msg.Extras, "datasourceState")
+}
+
+func TestWebhookForwarderPlugin_GrafanaDatasourceStatesSplitAlerts(t *testing.T) {
+	config := defaultConfig()
+	config.Grafana.SplitAlerts = true
+	config.Grafana.ErrorPriority = 9
+	p, mockHandler := newTestPlugin(t, config)
+
+	postWebhook(p, datasourceGrafanaPayload("firing", "DatasourceError"))
+	msg := mockHandler.sentMessages[0]
+	assert.Equal(t, "[FIRING] Datasource error: CPU usage", msg.Title)
+	assert.Equal(t, 9, msg.Priority)
+}
+
+func TestWebhookForwarderPlugin_GrafanaLegacyNoData(t *testing.T) {
+	p, mockHandler := newTestPlugin(t, defaultConfig())
+
+	postWebhook(p, map[string]interface{}{"ruleName": "CPU alert", "ruleId": 1, "state": "no_data", "title": "[No Data] CPU alert"})
+	msg := mockHandler.sentMessages[0]
+	assert.Equal(t, "No data: CPU alert", msg.Title)
+	assert.Equal(t, 4, msg.Priority)
+}
+
+func TestGrafanaConfig_DatasourcePriority(t *testing.T) {
+	config := defaultConfig()
+	assert.Equal(t, 4, config.Grafana.alertPriority(GrafanaAlert{Labels: map[string]string{"alertname": "DatasourceNoData"}}, "firing"))
+
+	config.Grafana.NoDataPriority = 0
+	assert.Equal(t, 8, config.Grafana.alertPriority(GrafanaAlert{Labels: map[string]string{"alertname": "DatasourceNoData"}}, "firing"))
+
+	config.Grafana.ErrorPriority = 11
+	assert.Error(t, config.validate())
+}
@@ -0,0 +1,430 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gotify/plugin-api"
+)
+
+// dependencyDigestMaxListed limits the pull requests listed in a digest.
+const dependencyDigestMaxListed = 20
+
+// DependencyDigestConfig condenses the pull requests of dependency update
+// bots (Renovate, Dependabot) into digests.
+type DependencyDigestConfig struct {
+	// Interval collects dependency pull requests opened by the bots and
+	// sends them as a digest once per interval (Go duration, e.g. "168h"
+	// for a weekly digest). Empty sends Renovate reports right away and
+	// forwards bot pull requests like other GitHub events.
+	Interval string `yaml:"interval"`
+	// GroupBy is "repository" for a digest per repository or "all" for a
+	// single digest of all repositories.
+	GroupBy string `yaml:"groupBy"`
+
+	interval time.Duration
+}
+
+// validate checks the digest settings and parses the interval.
+func (d *DependencyDigestConfig) validate() error {
+	d.interval = 0
+	if d.Interval != "" {
+		interval, err := time.ParseDuration(d.Interval)
+		if err != nil || interval <= 0 {
+			return fmt.Errorf("invalid dependencies.interval %q, expected a duration such as 168h", d.Interval)
+		}
+		d.interval = interval
+	}
+	switch d.GroupBy {
+	case "", "repository", "all":
+	default:
+		return fmt.Errorf("invalid dependencies.groupBy %q, must be repository or all", d.GroupBy)
+	}
+	return nil
+}
+
+// dependencyUpdate is a pull request of a dependency update bot waiting
+// for the next digest.
+type dependencyUpdate struct {
+	Repository    string    `json:"repository"`
+	RepositoryURL string    `json:"repositoryUrl,omitempty"`
+	Number        int       `json:"number,omitempty"`
+	Title         string    `json:"title"`
+	Bot           string    `json:"bot,omitempty"`
+	Received      time.Time `json:"received"`
+}
+
+// key identifies the pull request of an update.
+func (u dependencyUpdate) key() string {
+	if u.Number > 0 {
+		return fmt.Sprintf("%s#%d", u.Repository, u.Number)
+	}
+	return u.Repository + ":" + u.Title
+}
+
+// githubDependencyBots names the accounts of dependency update bots.
+var githubDependencyBots = map[string]string{
+	"renovate[bot]":   "renovate",
+	"renovate-bot":    "renovate",
+	"dependabot[bot]": "dependabot",
+}
+
+// githubDependencyUpdate returns the dependency update of a pull request
+// opened by Renovate or Dependabot.
+func githubDependencyUpdate(event string, body map[string]interface{}) (dependencyUpdate, bool) {
+	if event != "pull_request" || stringField(body, "action") != "opened" {
+		return dependencyUpdate{}, false
+	}
+	pull, _ := body["pull_request"].(map[string]interface{})
+	author, _ := pull["user"].(map[string]interface{})
+	sender, _ := body["sender"].(map[string]interface{})
+	bot, ok := githubDependencyBots[stringField(author, "login")]
+	if !ok {
+		if bot, ok = githubDependencyBots[stringField(sender, "login")]; !ok {
+			return dependencyUpdate{}, false
+		}
+	}
+	repository, _ := body["repository"].(map[string]interface{})
+	return dependencyUpdate{
+		Repository:    stringField(repository, "full_name"),
+		RepositoryURL: stringField(repository, "html_url"),
+		Number:        intField(pull, "number"),
+		Title:         stringField(pull, "title"),
+		Bot:           bot,
+	}, true
+}
+
+// isRenovateReport detects the JSON report of Renovate (reportType "file"
+// or "s3"), which lists the branches and pull requests per repository.
+func isRenovateReport(body map[string]interface{}) bool {
+	repositories, ok := body["repositories"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	for _, value := range repositories {
+		if repository, ok := value.(map[string]interface{}); ok && hasFields(repository, "branches") {
+			return true
+		}
+	}
+	return false
+}
+
+// renovateRepositories returns the repositories covered by a Renovate
+// report.
+func renovateRepositories(body map[string]interface{}) []string {
+	repositories, _ := body["repositories"].(map[string]interface{})
+	names := make([]string, 0, len(repositories))
+	for name := range repositories {
+		names = append(names, name)
+	}
+	return names
+}
+
+// renovateUpdates returns the branches of a Renovate report that have a
+// pull request.
+func renovateUpdates(body map[string]interface{}) []dependencyUpdate {
+	repositories, _ := body["repositories"].(map[string]interface{})
+	var updates []dependencyUpdate
+	for name, value := range repositories {
+		repository, _ := value.(map[string]interface{})
+		for _, branch := range mapSlice(repository["branches"]) {
+			number := intField(branch, "prNo")
+			if number <= 0 {
+				continue
+			}
+			updates = append(updates, dependencyUpdate{
+				Repository: name,
+				Number:     number,
+				Title:      stringField(branch, "prTitle", "branchName"),
+				Bot:        "renovate",
+			})
+		}
+	}
+	return updates
+}
+
+// handleRenovateReport sends or queues the pull requests of a Renovate
+// report that were not listed in its previous report. Reports are recorded
+// per repository once its new pull requests are queued or sent, so failed
+// deliveries are retried with the next report.
+func (p *WebhookForwarderPlugin) handleRenovateReport(c *gin.Context, body map[string]interface{}) {
+	repositories := renovateRepositories(body)
+	updates := renovateUpdates(body)
+	config := p.getConfig()
+	queue := config.Dependencies.interval > 0
+
+	fresh := updates
+	if err := p.updateStorage(func(storage *pluginStorage) {
+		fresh = storage.unreportedPullRequests(updates)
+		if queue || len(fresh) == 0 {
+			storage.queueDependencyUpdates(fresh, timeNow())
+			storage.recordRenovateReport(repositories, updates)
+		}
+	}); err != nil {
+		respondRenovateStorageError(c, err)
+		return
+	}
+
+	switch {
+	case len(fresh) == 0:
+		p.skipMessage(c, "renovate", skipFiltered, "No new dependency pull requests in the Renovate report")
+		return
+	case queue:
+		p.respondQueued(c, "renovate")
+		return
+	}
+	for _, group := range groupDependencyUpdates(fresh, config.Dependencies.GroupBy) {
+		if !p.sendMessage(c, "renovate", dependencyDigest(group)) {
+			return
+		}
+		// A later failed digest must not resend the delivered ones
+		if err := p.updateStorage(func(storage *pluginStorage) {
+			storage.recordRenovateReport(dependencyRepositories(group), updates)
+		}); err != nil {
+			respondRenovateStorageError(c, err)
+			return
+		}
+	}
+	// Record the repositories without new pull requests
+	if err := p.updateStorage(func(storage *pluginStorage) {
+		storage.recordRenovateReport(repositories, updates)
+	}); err != nil {
+		respondRenovateStorageError(c, err)
+		return
+	}
+	p.respondForwarded(c, "renovate")
+}
+
+// respondRenovateStorageError responds that a Renovate report could not be
+// stored.
+func respondRenovateStorageError(c *gin.Context, err error) {
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error":   "Could not store the Renovate report",
+		"details": err.Error(),
+	})
+}
+
+// unreportedPullRequests returns the updates not listed in the previous
+// Renovate report of their repository.
+func (s *pluginStorage) unreportedPullRequests(updates []dependencyUpdate) []dependencyUpdate {
+	reported := make(map[string]bool)
+	for _, keys := range s.ReportedPullRequests {
+		for _, key := range keys {
+			reported[key] = true
+		}
+	}
+	var fresh []dependencyUpdate
+	for _, update := range updates {
+		if !reported[update.key()] {
+			fresh = append(fresh, update)
+		}
+	}
+	return fresh
+}
+
+// recordRenovateReport replaces the reported pull requests of the given
+// repositories of a report, forgetting merged and closed ones.
+func (s *pluginStorage) recordRenovateReport(repositories []string, updates []dependencyUpdate) {
+	if s.ReportedPullRequests == nil {
+		s.ReportedPullRequests = make(map[string][]string)
+	}
+	recorded := make(map[string]bool, len(repositories))
+	for _, repository := range repositories {
+		delete(s.ReportedPullRequests, repository)
+		recorded[repository] = true
+	}
+	for _, update := range updates {
+		if recorded[update.Repository] {
+			s.ReportedPullRequests[update.Repository] = append(s.ReportedPullRequests[update.Repository], update.key())
+		}
+	}
+}
+
+// queueDependencyUpdates stores updates for the next digest and responds
+// that they were queued.
+func (p *WebhookForwarderPlugin) queueDependencyUpdates(c *gin.Context, source string, updates []dependencyUpdate) {
+	if err := p.updateStorage(func(storage *pluginStorage) {
+		storage.queueDependencyUpdates(updates, timeNow())
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Could not queue dependency updates",
+			"details": err.Error(),
+		})
+		return
+	}
+	p.respondQueued(c, source)
+}
+
+// queueDependencyUpdates adds updates to the next digest, ignoring pull
+// requests already queued.
+func (s *pluginStorage) queueDependencyUpdates(updates []dependencyUpdate, now time.Time) {
+	queued := make(map[string]bool, len(s.DependencyUpdates))
+	for _, update := range s.DependencyUpdates {
+		queued[update.key()] = true
+	}
+	for _, update := range updates {
+		if queued[update.key()] {
+			continue
+		}
+		queued[update.key()] = true
+		update.Received = now
+		s.DependencyUpdates = append(s.DependencyUpdates, update)
+	}
+}
+
+// respondQueued responds that a message was queued for the dependency
+// digest.
+func (p *WebhookForwarderPlugin) respondQueued(c *gin.Context, source string) {
+	c.JSON(http.StatusOK, gin.H{
+		"success":   true,
+		"forwarded": false,
+		"message":   "Queued for the dependency digest",
+		"type":      source,
+	})
+}
+
+// flushDependencyDigest sends the queued dependency updates once the oldest
+// has waited for dependencies.interval. Updates stay queued until their
+// digest is delivered, so digests failing or dropped during quiet hours are
+// retried.
+func (p *WebhookForwarderPlugin) flushDependencyDigest(now time.Time) {
+	config := p.getConfig()
+	interval := config.Dependencies.interval
+	if interval <= 0 {
+		return
+	}
+
+	var updates []dependencyUpdate
+	_ = p.updateStorage(func(storage *pluginStorage) {
+		if len(storage.DependencyUpdates) == 0 {
+			return
+		}
+		oldest := storage.DependencyUpdates[0].Received
+		for _, update := range storage.DependencyUpdates {
+			if update.Received.Before(oldest) {
+				oldest = update.Received
+			}
+		}
+		if now.Sub(oldest) < interval {
+			return
+		}
+		updates = storage.DependencyUpdates
+	})
+
+	delivered := make(map[string]bool)
+	for _, group := range groupDependencyUpdates(updates, config.Dependencies.GroupBy) {
+		if err := p.deliverMessage(nil, dependencyDigest(group)); err != nil {
+			continue
+		}
+		for _, update := range group {
+			delivered[update.key()] = true
+		}
+	}
+	if len(delivered) == 0 {
+		return
+	}
+	_ = p.updateStorage(func(storage *pluginStorage) {
+		var queued []dependencyUpdate
+		for _, update := range storage.DependencyUpdates {
+			if !delivered[update.key()] {
+				queued = append(queued, update)
+			}
+		}
+		storage.DependencyUpdates = queued
+	})
+}
+
+// groupDependencyUpdates sorts updates and splits them into the updates of
+// each digest, per repository or a single one when grouped by "all".
+func groupDependencyUpdates(updates []dependencyUpdate, groupBy string) [][]dependencyUpdate {
+	if len(updates) == 0 {
+		return nil
+	}
+	sorted := make([]dependencyUpdate, len(updates))
+	copy(sorted, updates)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Repository != sorted[j].Repository {
+			return sorted[i].Repository < sorted[j].Repository
+		}
+		return sorted[i].Number < sorted[j].Number
+	})
+
+	if groupBy == "all" {
+		return [][]dependencyUpdate{sorted}
+	}
+	var groups [][]dependencyUpdate
+	start := 0
+	for i := 1; i <= len(sorted); i++ {
+		if i == len(sorted) || sorted[i].Repository != sorted[start].Repository {
+			groups = append(groups, sorted[start:i])
+			start = i
+		}
+	}
+	return groups
+}
+
+// dependencyRepositories returns the repositories of sorted updates.
+func dependencyRepositories(updates []dependencyUpdate) []string {
+	var repositories []string
+	for i, update := range updates {
+		if i == 0 || update.Repository != updates[i-1].Repository {
+			repositories = append(repositories, update.Repository)
+		}
+	}
+	return repositories
+}
+
+// dependencyDigest renders sorted updates as a single digest listing the
+// pull requests, e.g. "7 dependency PRs opened in octo/app".
+func dependencyDigest(updates []dependencyUpdate) plugin.Message {
+	repositories := dependencyRepositories(updates)
+
+	count := fmt.Sprintf("%d dependency PRs", len(updates))
+	if len(updates) == 1 {
+		count = "1 dependency PR"
+	}
+	title := fmt.Sprintf("%s opened in %s", count, repositories[0])
+	if len(repositories) > 1 {
+		title = fmt.Sprintf("%s opened in %d repositories", count, len(repositories))
+	}
+
+	var lines []string
+	for i, update := range updates {
+		if i == dependencyDigestMaxListed {
+			lines = append(lines, fmt.Sprintf("… and %d more", len(updates)-dependencyDigestMaxListed))
+			break
+		}
+		ref := ""
+		if len(repositories) > 1 {
+			ref = update.Repository
+		}
+		if update.Number > 0 {
+			ref += fmt.Sprintf("#%d", update.Number)
+		}
+		lines = append(lines, strings.TrimSpace("- "+ref+" "+update.Title))
+	}
+
+	extras := map[string]interface{}{
+		"source": "dependencies",
+		"count":  len(updates),
+	}
+	if len(repositories) == 1 {
+		extras["repository"] = repositories[0]
+		if url := updates[0].RepositoryURL; url != "" {
+			extras["client::notification"] = map[string]interface{}{
+				"click": map[string]interface{}{"url": url + "/pulls"},
+			}
+		}
+	}
+
+	return plugin.Message{
+		Title:    title,
+		Message:  strings.Join(lines, "\n"),
+		Priority: 3,
+		Extras:   extras,
+	}
+}
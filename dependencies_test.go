@@ -0,0 +1,227 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gotify/plugin-api"
+	"github.com/stretchr/testify/assert"
+)
+
+func dependencyPullRequest(number float64, title string) map[string]interface{} {
+	return map[string]interface{}{
+		"action": "opened",
+		"pull_request": map[string]interface{}{
+			"number": number,
+			"title":  title,
+			"user":   map[string]interface{}{"login": "renovate[bot]"},
+		},
+		"repository": map[string]interface{}{"full_name": "octo/app", "html_url": "https://github.com/octo/app"},
+		"sender":     map[string]interface{}{"login": "renovate[bot]"},
+	}
+}
+
+func TestWebhookForwarderPlugin_DependencyDigest(t *testing.T) {
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	defer func() { timeNow = time.Now }()
+	timeNow = func() time.Time { return now }
+
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+	p.SetStorageHandler(&MockStorageHandler{})
+	config := defaultConfig()
+	config.Dependencies.Interval = "168h"
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+
+	headers := map[string]string{"X-GitHub-Event": "pull_request"}
+	w := postSignedWebhook(p, dependencyPullRequest(12, "Update dependency lodash to v4.17.21"), headers)
+	assert.Equal(t, http.StatusOK, w.Code)
+	postSignedWebhook(p, dependencyPullRequest(11, "Update actions/checkout action to v4"), headers)
+	postSignedWebhook(p, dependencyPullRequest(12, "Update dependency lodash to v4.17.21"), headers)
+	assert.Empty(t, mockHandler.sentMessages)
+
+	p.flushDependencyDigest(now.Add(24 * time.Hour))
+	assert.Empty(t, mockHandler.sentMessages)
+
+	// Undelivered digests stay queued
+	mockHandler.shouldFail = true
+	p.flushDependencyDigest(now.Add(168 * time.Hour))
+	mockHandler.shouldFail = false
+	assert.Empty(t, mockHandler.sentMessages)
+
+	p.flushDependencyDigest(now.Add(168 * time.Hour))
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title:    "2 dependency PRs opened in octo/app",
+		Message:  "- #11 Update actions/checkout action to v4\n- #12 Update dependency lodash to v4.17.21",
+		Priority: 3,
+		Extras: map[string]interface{}{
+			"source":     "dependencies",
+			"count":      2,
+			"repository": "octo/app",
+			"client::notification": map[string]interface{}{
+				"click": map[string]interface{}{"url": "https://github.com/octo/app/pulls"},
+			},
+		},
+	}, mockHandler.sentMessages[0])
+
+	// The queue is empty after the digest
+	p.flushDependencyDigest(now.Add(400 * time.Hour))
+	assert.Len(t, mockHandler.sentMessages, 1)
+}
+
+func TestWebhookForwarderPlugin_DependencyPullRequestWithoutDigest(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	postSignedWebhook(p, dependencyPullRequest(12, "Update dependency lodash to v4.17.21"), map[string]string{"X-GitHub-Event": "pull_request"})
+
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, "octo/app: PR #12 opened by renovate[bot]", mockHandler.sentMessages[0].Title)
+}
+
+func renovateReport(branches ...map[string]interface{}) map[string]interface{} {
+	list := make([]interface{}, len(branches))
+	for i, branch := range branches {
+		list[i] = branch
+	}
+	return map[string]interface{}{
+		"repositories": map[string]interface{}{
+			"octo/app": map[string]interface{}{"branches": list},
+		},
+	}
+}
+
+func TestWebhookForwarderPlugin_RenovateReportOnlyNewPullRequests(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+	p.SetStorageHandler(&MockStorageHandler{})
+
+	lodash := map[string]interface{}{"branchName": "renovate/lodash-4.x", "prNo": 12.0, "prTitle": "Update dependency lodash to v4.17.21"}
+	react := map[string]interface{}{"branchName": "renovate/react-18.x", "prNo": 13.0, "prTitle": "Update react to v18.3.1"}
+
+	postWebhook(p, renovateReport(lodash))
+	assert.Len(t, mockHandler.sentMessages, 1)
+
+	// Pull requests of the previous report are not notified again
+	w := postWebhook(p, renovateReport(lodash))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "No new dependency pull requests")
+	postWebhook(p, renovateReport(lodash, react))
+	assert.Len(t, mockHandler.sentMessages, 2)
+	assert.Equal(t, "1 dependency PR opened in octo/app", mockHandler.sentMessages[1].Title)
+	assert.Equal(t, "- #13 Update react to v18.3.1", mockHandler.sentMessages[1].Message)
+
+	// Failed deliveries are retried with the next report
+	lodash["prNo"] = 14.0
+	mockHandler.shouldFail = true
+	postWebhook(p, renovateReport(lodash, react))
+	mockHandler.shouldFail = false
+	postWebhook(p, renovateReport(lodash, react))
+	assert.Len(t, mockHandler.sentMessages, 3)
+	assert.Equal(t, "- #14 Update dependency lodash to v4.17.21", mockHandler.sentMessages[2].Message)
+
+	// Pull requests missing from a report are forgotten
+	postWebhook(p, renovateReport())
+	postWebhook(p, renovateReport(react))
+	assert.Len(t, mockHandler.sentMessages, 4)
+}
+
+func TestWebhookForwarderPlugin_RenovateReportFailedDigest(t *testing.T) {
+	mockHandler := &MockMessageHandler{failAfter: 1}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+	p.SetStorageHandler(&MockStorageHandler{})
+
+	report := map[string]interface{}{
+		"repositories": map[string]interface{}{
+			"octo/app": map[string]interface{}{
+				"branches": []interface{}{
+					map[string]interface{}{"branchName": "renovate/lodash-4.x", "prNo": 12.0, "prTitle": "Update dependency lodash to v4.17.21"},
+				},
+			},
+			"octo/api": map[string]interface{}{
+				"branches": []interface{}{
+					map[string]interface{}{"branchName": "renovate/go-1.x", "prNo": 3.0, "prTitle": "Update go to 1.22"},
+				},
+			},
+		},
+	}
+
+	// The second digest fails, the delivered first one is recorded
+	w := postWebhook(p, report)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, "1 dependency PR opened in octo/api", mockHandler.sentMessages[0].Title)
+
+	// Only the failed digest is retried with the next report
+	mockHandler.failAfter = 0
+	w = postWebhook(p, report)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 2)
+	assert.Equal(t, "1 dependency PR opened in octo/app", mockHandler.sentMessages[1].Title)
+}
+
+func TestWebhookForwarderPlugin_RenovateReportDigest(t *testing.T) {
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	defer func() { timeNow = time.Now }()
+	timeNow = func() time.Time { return now }
+
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+	p.SetStorageHandler(&MockStorageHandler{})
+	config := defaultConfig()
+	config.Dependencies.Interval = "168h"
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+
+	lodash := map[string]interface{}{"branchName": "renovate/lodash-4.x", "prNo": 12.0, "prTitle": "Update dependency lodash to v4.17.21"}
+	react := map[string]interface{}{"branchName": "renovate/react-18.x", "prNo": 13.0, "prTitle": "Update react to v18.3.1"}
+	postWebhook(p, renovateReport(lodash))
+	p.flushDependencyDigest(now.Add(168 * time.Hour))
+	assert.Len(t, mockHandler.sentMessages, 1)
+
+	// Still open pull requests are not part of the next digest
+	timeNow = func() time.Time { return now.Add(200 * time.Hour) }
+	postWebhook(p, renovateReport(lodash, react))
+	p.flushDependencyDigest(now.Add(400 * time.Hour))
+	assert.Len(t, mockHandler.sentMessages, 2)
+	assert.Equal(t, "1 dependency PR opened in octo/app", mockHandler.sentMessages[1].Title)
+}
+
+func TestWebhookForwarderPlugin_RenovateReport(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+	p.SetStorageHandler(&MockStorageHandler{})
+	config := defaultConfig()
+	config.Dependencies.GroupBy = "all"
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+
+	w := postWebhook(p, map[string]interface{}{
+		"problems": []interface{}{},
+		"repositories": map[string]interface{}{
+			"octo/app": map[string]interface{}{
+				"problems": []interface{}{},
+				"branches": []interface{}{
+					map[string]interface{}{"branchName": "renovate/lodash-4.x", "prNo": 12.0, "prTitle": "Update dependency lodash to v4.17.21"},
+					map[string]interface{}{"branchName": "renovate/major-react", "prNo": nil, "prTitle": "Update react to v19"},
+				},
+			},
+			"octo/api": map[string]interface{}{
+				"branches": []interface{}{
+					map[string]interface{}{"branchName": "renovate/go-1.x", "prNo": 3.0, "prTitle": "Update go to 1.22"},
+				},
+			},
+		},
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, "2 dependency PRs opened in 2 repositories", mockHandler.sentMessages[0].Title)
+	assert.Equal(t, "- octo/api#3 Update go to 1.22\n- octo/app#12 Update dependency lodash to v4.17.21", mockHandler.sentMessages[0].Message)
+}
+
+func TestDependencyDigestConfig_Validate(t *testing.T) {
+	assert.NoError(t, (&DependencyDigestConfig{Interval: "24h", GroupBy: "all"}).validate())
+	assert.Error(t, (&DependencyDigestConfig{Interval: "weekly"}).validate())
+	assert.Error(t, (&DependencyDigestConfig{GroupBy: "owner"}).validate())
+}
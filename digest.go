@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// digests reports whether a notification with the given number of alerts
+// is condensed into a digest instead of rendering every alert.
+func (g *GrafanaConfig) digests(alerts int) bool {
+	return g.DigestThreshold > 0 && alerts > g.DigestThreshold
+}
+
+// grafanaDigestBody renders the alerts of a notification as a compact list,
+// firing alerts first, limited to DigestThreshold entries followed by the
+// number of alerts left out, e.g. "+12 more".
+func (c *Config) grafanaDigestBody(webhook GrafanaWebhook) string {
+	alerts := make([]GrafanaAlert, len(webhook.Alerts))
+	copy(alerts, webhook.Alerts)
+	sort.SliceStable(alerts, func(i, j int) bool {
+		return webhook.alertStatus(alerts[i]) != "resolved" && webhook.alertStatus(alerts[j]) == "resolved"
+	})
+
+	shown := len(alerts)
+	if c.Grafana.DigestThreshold > 0 && shown > c.Grafana.DigestThreshold {
+		shown = c.Grafana.DigestThreshold
+	}
+	lines := make([]string, 0, shown+1)
+	for _, alert := range alerts[:shown] {
+		lines = append(lines, c.digestLine(webhook, alert))
+	}
+	if more := len(alerts) - shown; more > 0 {
+		lines = append(lines, fmt.Sprintf("+%d more", more))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// digestLine renders an alert as a single list item: its status and name,
+// followed by its summary annotation or, without one, the labels telling it
+// apart from the other alerts of the group.
+func (c *Config) digestLine(webhook GrafanaWebhook, alert GrafanaAlert) string {
+	line := "- "
+	if status := webhook.alertStatus(alert); status != "" {
+		line += fmt.Sprintf("**[%s]** ", strings.ToUpper(status))
+	}
+	line += alertName(alert, "Alert")
+
+	detail := ""
+	if summary := strings.TrimSpace(alert.Annotations["summary"]); summary != "" && c.Labels.allows("summary") {
+		detail = summary
+	} else {
+		labels := c.Labels.filter(alert.Labels)
+		pairs := make([]string, 0, len(labels))
+		for name, value := range labels {
+			if name == "alertname" || webhook.CommonLabels[name] == value {
+				continue
+			}
+			pairs = append(pairs, name+"="+value)
+		}
+		sort.Strings(pairs)
+		detail = strings.Join(pairs, ", ")
+	}
+	if detail != "" {
+		line += " — " + detail
+	}
+	return line
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_GrafanaDigest(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+	config := defaultConfig()
+	config.Grafana.SplitAlerts = true
+	config.Grafana.DigestThreshold = 2
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+
+	// Up to the threshold the alerts are still split
+	postWebhook(p, allClearPayload("firing", "firing"))
+	assert.Len(t, mockHandler.sentMessages, 2)
+
+	payload := allClearPayload("resolved", "firing", "firing", "firing")
+	payload["commonLabels"] = map[string]interface{}{"alertname": "NodeDown"}
+	alerts := payload["alerts"].([]interface{})
+	alerts[1].(map[string]interface{})["annotations"] = map[string]interface{}{"summary": "node2 is unreachable"}
+	postWebhook(p, payload)
+	assert.Len(t, mockHandler.sentMessages, 3)
+	msg := mockHandler.sentMessages[2]
+	assert.Equal(t, "3 firing, 1 resolved — node-alerts", msg.Title)
+	assert.Equal(t, "- **[FIRING]** NodeDown — node2 is unreachable\n- **[FIRING]** NodeDown — instance=node3\n+2 more", msg.Message)
+	assert.Equal(t, map[string]interface{}{"contentType": "text/markdown"}, msg.Extras["client::display"])
+
+	config.Grafana.DigestThreshold = -1
+	assert.Error(t, config.validate())
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"time"
+
+	"github.com/gotify/plugin-api"
+)
+
+// downtimeMaxAge limits how long the start of a downtime is remembered.
+const downtimeMaxAge = 30 * 24 * time.Hour
+
+// trackDowntime remembers when the check identified by key went down and,
+// once it recovers, returns how long it was down. changed is when the state
+// changed, the current time if the payload does not tell.
+func (p *WebhookForwarderPlugin) trackDowntime(key string, down bool, changed time.Time) (time.Duration, bool) {
+	var downtime time.Duration
+	recovered := false
+	_ = p.updateStorage(func(storage *pluginStorage) {
+		for check, since := range storage.Downtimes {
+			if changed.Sub(since) > downtimeMaxAge {
+				delete(storage.Downtimes, check)
+			}
+		}
+		since, ok := storage.Downtimes[key]
+		switch {
+		case down && !ok:
+			if storage.Downtimes == nil {
+				storage.Downtimes = make(map[string]time.Time)
+			}
+			storage.Downtimes[key] = changed
+		case !down && ok:
+			delete(storage.Downtimes, key)
+			if changed.After(since) {
+				downtime, recovered = changed.Sub(since), true
+			}
+		}
+	})
+	return downtime, recovered
+}
+
+// withDowntime adds how long a recovered check was down to the message and
+// its extras.
+func withDowntime(msg *plugin.Message, downtime time.Duration) {
+	msg.Message += "\n\nDown for " + humanizeDuration(downtime)
+	msg.Extras = withExtra(msg.Extras, "downtimeSeconds", int(downtime.Seconds()))
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_TrackDowntime(t *testing.T) {
+	p := &WebhookForwarderPlugin{}
+	p.SetStorageHandler(&MockStorageHandler{})
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	// Recoveries without a known downtime report nothing
+	_, ok := p.trackDowntime("pingdom:1", false, start)
+	assert.False(t, ok)
+
+	// Repeated down notifications keep the first one
+	_, ok = p.trackDowntime("pingdom:1", true, start)
+	assert.False(t, ok)
+	p.trackDowntime("pingdom:1", true, start.Add(5*time.Minute))
+	downtime, ok := p.trackDowntime("pingdom:1", false, start.Add(time.Hour))
+	assert.True(t, ok)
+	assert.Equal(t, time.Hour, downtime)
+
+	_, ok = p.trackDowntime("pingdom:1", false, start.Add(2*time.Hour))
+	assert.False(t, ok)
+
+	// Old downtimes are forgotten
+	p.trackDowntime("pingdom:2", true, start)
+	_, ok = p.trackDowntime("pingdom:2", false, start.Add(downtimeMaxAge+time.Hour))
+	assert.False(t, ok)
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"text/template"
+)
+
+// sampleGrafanaWebhook is a representative Grafana alert payload used to
+// validate templates when the config is saved.
+var sampleGrafanaWebhook = GrafanaWebhook{
+	Receiver: "gotify",
+	Status:   "firing",
+	Alerts: []GrafanaAlert{
+		{
+			Status:       "firing",
+			Labels:       map[string]string{"alertname": "HighCPU", "instance": "web1", "severity": "critical", "grafana_folder": "Infrastructure"},
+			Annotations:  map[string]string{"summary": "CPU usage above 90%", "description": "CPU usage on web1 has been above 90% for 5 minutes"},
+			StartsAt:     "2024-05-01T13:45:00Z",
+			EndsAt:       "0001-01-01T00:00:00Z",
+			SilenceURL:   "https://grafana.example.com/alerting/silence/new",
+			DashboardURL: "https://grafana.example.com/d/abc",
+			PanelURL:     "https://grafana.example.com/d/abc?viewPanel=1",
+			ValueString:  "[ var='A' labels={instance=web1} value=93.5 ]",
+		},
+	},
+	GroupLabels:       map[string]string{"alertname": "HighCPU"},
+	CommonLabels:      map[string]string{"alertname": "HighCPU", "instance": "web1", "severity": "critical"},
+	CommonAnnotations: map[string]string{"summary": "CPU usage above 90%"},
+	ExternalURL:       "https://grafana.example.com/",
+	Version:           "1",
+	GroupKey:          "{}:{alertname=\"HighCPU\"}",
+	OrgId:             1,
+	Title:             "[FIRING:1] HighCPU",
+	State:             "alerting",
+	Message:           "**Firing**\n\nValue: A=93.5\nLabels:\n - alertname = HighCPU",
+}
+
+// sampleWebhookMessage is a representative generic payload used to validate
+// templates when the config is saved.
+var sampleWebhookMessage = WebhookMessage{
+	Title:    "System Alert",
+	Message:  "Disk usage exceeded 90%",
+	Priority: 8,
+	Extras:   map[string]interface{}{"host": "web1"},
+}
+
+// dryRunTemplates renders the configured templates against the sample
+// payloads so errors are reported when the config is saved instead of
+// silently falling back at notification time. Global templates apply to
+// both generic and Grafana payloads and only need to render for one of them.
+func (c *Config) dryRunTemplates() error {
+	grafana := c.Labels.filterGrafanaLabels(sampleGrafanaWebhook)
+
+	for name, tmpl := range map[string]*template.Template{"titleTemplate": c.titleTmpl, "messageTemplate": c.messageTmpl} {
+		if tmpl == nil {
+			continue
+		}
+		_, grafanaErr := executeTemplate(tmpl, grafana)
+		_, genericErr := executeTemplate(tmpl, sampleWebhookMessage)
+		if grafanaErr != nil && genericErr != nil {
+			return fmt.Errorf("%s fails to render the sample payloads: %w", name, grafanaErr)
+		}
+	}
+
+	for source, profile := range c.Sources {
+		if profile == nil {
+			continue
+		}
+		var sample interface{} = map[string]interface{}{}
+		switch source {
+		case "grafana", "alertmanager":
+			sample = grafana
+		case "generic":
+			sample = sampleWebhookMessage
+		}
+		for name, tmpl := range map[string]*template.Template{"titleTemplate": profile.titleTmpl, "messageTemplate": profile.messageTmpl} {
+			if _, err := executeTemplate(tmpl, sample); err != nil {
+				return fmt.Errorf("sources.%s.%s fails to render a sample payload: %w", source, name, err)
+			}
+		}
+	}
+	for receiver, profile := range c.Grafana.Receivers {
+		if profile == nil {
+			continue
+		}
+		for name, tmpl := range map[string]*template.Template{"titleTemplate": profile.titleTmpl, "messageTemplate": profile.messageTmpl} {
+			if _, err := executeTemplate(tmpl, grafana); err != nil {
+				return fmt.Errorf("grafana.receivers.%s.%s fails to render a sample payload: %w", receiver, name, err)
+			}
+		}
+	}
+	return nil
+}
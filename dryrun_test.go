@@ -0,0 +1,41 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig_DryRunTemplates(t *testing.T) {
+	valid := []func(c *Config){
+		func(c *Config) { c.MessageTemplate = `{{ range .Alerts }}{{ .Labels.alertname }}{{ end }}` },
+		func(c *Config) { c.TitleTemplate = `{{ .Title }}` },
+		func(c *Config) { c.MessageTemplate = `{{ .Message }} ({{ .Priority }})` },
+		func(c *Config) {
+			c.Sources = map[string]*SourceConfig{"authelia": {MessageTemplate: `{{ .username }} from {{ .remote_ip }}`}}
+		},
+	}
+	for _, apply := range valid {
+		config := defaultConfig()
+		apply(config)
+		assert.NoError(t, config.validate())
+	}
+
+	invalid := []func(c *Config){
+		// Field exists in neither sample payload
+		func(c *Config) { c.MessageTemplate = `{{ .Alert.Name }}` },
+		// Wrong argument type for a helper
+		func(c *Config) { c.TitleTemplate = `{{ upper .Priority }}` },
+		func(c *Config) {
+			c.Sources = map[string]*SourceConfig{"grafana": {MessageTemplate: `{{ .Priority }}`}}
+		},
+		func(c *Config) {
+			c.Sources = map[string]*SourceConfig{"generic": {TitleTemplate: `{{ .Alerts }}`}}
+		},
+	}
+	for _, apply := range invalid {
+		config := defaultConfig()
+		apply(config)
+		assert.Error(t, config.validate())
+	}
+}
@@ -0,0 +1,29 @@
+package main
+
+import "strings"
+
+// emojiKey returns the key of grafana.statusEmoji for an alert status,
+// using "nodata" or "error" for unresolved datasource problems.
+func emojiKey(status, datasource string) string {
+	if datasource != "" && status != "resolved" {
+		return strings.ToLower(datasource)
+	}
+	return strings.ToLower(status)
+}
+
+// withStatusEmoji prefixes a title with the emoji configured for the status
+// of the alerts, see emojiKey.
+func (g *GrafanaConfig) withStatusEmoji(status, datasource, title string) string {
+	emoji := ""
+	key := emojiKey(status, datasource)
+	for name, value := range g.StatusEmoji {
+		if strings.ToLower(name) == key {
+			emoji = strings.TrimSpace(value)
+			break
+		}
+	}
+	if emoji == "" {
+		return title
+	}
+	return emoji + " " + title
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_GrafanaStatusEmoji(t *testing.T) {
+	config := defaultConfig()
+	config.Grafana.StatusEmoji = map[string]string{"firing": "🔥", "Resolved": "✅", "nodata": "⚠️"}
+	p, mockHandler := newTestPlugin(t, config)
+
+	postWebhook(p, datasourceGrafanaPayload("firing", "HighCPU"))
+	postWebhook(p, datasourceGrafanaPayload("resolved", "HighCPU"))
+	postWebhook(p, datasourceGrafanaPayload("firing", "DatasourceNoData"))
+	postWebhook(p, datasourceGrafanaPayload("firing", "DatasourceError"))
+
+	assert.Equal(t, "🔥 [FIRING:1] HighCPU", mockHandler.sentMessages[0].Title)
+	assert.Equal(t, "✅ [FIRING:1] HighCPU", mockHandler.sentMessages[1].Title)
+	assert.Equal(t, "⚠️ No data: CPU usage", mockHandler.sentMessages[2].Title)
+	// Statuses without an emoji keep the title
+	assert.Equal(t, "Datasource error: CPU usage", mockHandler.sentMessages[3].Title)
+
+	config.Grafana.SplitAlerts = true
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+	postWebhook(p, splitGrafanaPayload())
+	assert.Equal(t, "🔥 [FIRING] HighCPU", mockHandler.sentMessages[4].Title)
+	assert.Equal(t, "✅ [RESOLVED] DiskFull", mockHandler.sentMessages[5].Title)
+}
+
+func TestEmojiKey(t *testing.T) {
+	assert.Equal(t, "firing", emojiKey("firing", ""))
+	assert.Equal(t, "nodata", emojiKey("firing", datasourceNoData))
+	assert.Equal(t, "error", emojiKey("no_data", datasourceError))
+	assert.Equal(t, "resolved", emojiKey("resolved", datasourceNoData))
+}
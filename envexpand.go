@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// envPrefix is the prefix of the environment variables that may be
+// referenced. Plugin configs are edited by every Gotify user, so other
+// variables of the server, e.g. GOTIFY_DATABASE_CONNECTION, must not be
+// readable through them.
+const envPrefix = "GOTIFY_PLUGIN_"
+
+// envPattern matches ${NAME} and ${NAME:-default} references as well as the
+// escape sequence $${ which produces a literal ${.
+var envPattern = regexp.MustCompile(`\$\$\{|\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// expandEnvString replaces references to GOTIFY_PLUGIN_* environment
+// variables in s. References to other variables are kept as they are.
+// Variables that are unset and have no default are reported as an error.
+func expandEnvString(s string) (string, error) {
+	var missing string
+	result := envPattern.ReplaceAllStringFunc(s, func(ref string) string {
+		if ref == "$${" {
+			return "${"
+		}
+		match := envPattern.FindStringSubmatch(ref)
+		name, fallback := match[1], match[2]
+		if !strings.HasPrefix(name, envPrefix) {
+			return ref
+		}
+		value, ok := os.LookupEnv(name)
+		if strings.Contains(ref, ":-") {
+			if value == "" {
+				return fallback
+			}
+			return value
+		}
+		if !ok && missing == "" {
+			missing = name
+		}
+		return value
+	})
+	if missing != "" {
+		return "", fmt.Errorf("environment variable %s is not set", missing)
+	}
+	return result, nil
+}
+
+// expandEnv returns a copy of the config with environment variable
+// references expanded in all string values. The config itself keeps the
+// references so secrets are not stored verbatim by Gotify.
+func (c *Config) expandEnv() (*Config, error) {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	if err := expandEnvNode(&node); err != nil {
+		return nil, err
+	}
+	expanded := &Config{}
+	if err := node.Decode(expanded); err != nil {
+		return nil, err
+	}
+	expanded.unexpanded = c
+	return expanded, nil
+}
+
+// expandEnvNode expands string scalars below node. Mapping keys are left
+// untouched.
+func expandEnvNode(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if node.ShortTag() != "!!str" {
+			return nil
+		}
+		value, err := expandEnvString(node.Value)
+		if err != nil {
+			return err
+		}
+		node.Value = value
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			if err := expandEnvNode(node.Content[i]); err != nil {
+				return err
+			}
+		}
+	default:
+		for _, child := range node.Content {
+			if err := expandEnvNode(child); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// exported returns the config as entered by the user, with environment
+// variable references not expanded.
+func (c *Config) exported() *Config {
+	if c.unexpanded != nil {
+		return c.unexpanded
+	}
+	return c
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpandEnvString(t *testing.T) {
+	t.Setenv("GOTIFY_PLUGIN_TEST_HOST", "gotify.example.com")
+	t.Setenv("GOTIFY_PLUGIN_TEST_EMPTY", "")
+
+	value, err := expandEnvString("https://${GOTIFY_PLUGIN_TEST_HOST}/api")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://gotify.example.com/api", value)
+
+	value, err = expandEnvString("${GOTIFY_PLUGIN_TEST_UNSET:-fallback} ${GOTIFY_PLUGIN_TEST_EMPTY:-empty} ${GOTIFY_PLUGIN_TEST_EMPTY}")
+	assert.NoError(t, err)
+	assert.Equal(t, "fallback empty ", value)
+
+	value, err = expandEnvString("$${GOTIFY_PLUGIN_TEST_HOST} costs $5")
+	assert.NoError(t, err)
+	assert.Equal(t, "${GOTIFY_PLUGIN_TEST_HOST} costs $5", value)
+
+	_, err = expandEnvString("${GOTIFY_PLUGIN_TEST_UNSET}")
+	assert.EqualError(t, err, "environment variable GOTIFY_PLUGIN_TEST_UNSET is not set")
+
+	// Other variables of the server are not readable
+	t.Setenv("GOTIFY_DEFAULTUSER_PASS", "admin")
+	value, err = expandEnvString("${HOME} ${GOTIFY_DEFAULTUSER_PASS} ${GOTIFY_DEFAULTUSER_PASS:-none} ${UNSET_VARIABLE}")
+	assert.NoError(t, err)
+	assert.Equal(t, "${HOME} ${GOTIFY_DEFAULTUSER_PASS} ${GOTIFY_DEFAULTUSER_PASS:-none} ${UNSET_VARIABLE}", value)
+}
+
+func TestWebhookForwarderPlugin_ConfigEnvExpansion(t *testing.T) {
+	t.Setenv("GOTIFY_PLUGIN_TEST_TOKEN", "s3cret")
+	t.Setenv("GOTIFY_PLUGIN_TEST_ENV", "prod")
+
+	p := &WebhookForwarderPlugin{}
+	p.SetStorageHandler(&MockStorageHandler{})
+	config := defaultConfig()
+	config.ConfigToken = "${GOTIFY_PLUGIN_TEST_TOKEN}"
+	config.TitlePrefix = "[${GOTIFY_PLUGIN_TEST_ENV}]"
+	config.DefaultTitle = "${HOME}"
+	config.Sources = map[string]*SourceConfig{"grafana": {Title: "${GOTIFY_PLUGIN_TEST_ENV} alert"}}
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+
+	// The config stored by Gotify keeps the references
+	assert.Equal(t, "${GOTIFY_PLUGIN_TEST_TOKEN}", config.ConfigToken)
+	assert.Equal(t, "s3cret", p.getConfig().ConfigToken)
+	assert.Equal(t, "[prod]", p.getConfig().TitlePrefix)
+	assert.Equal(t, "${HOME}", p.getConfig().DefaultTitle)
+	assert.Equal(t, "prod alert", p.getConfig().source("grafana").Title)
+	assert.True(t, p.getConfig().GenericWebhooks)
+
+	w := configRequest(p, "GET", "s3cret", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "${GOTIFY_PLUGIN_TEST_TOKEN}")
+	assert.NotContains(t, w.Body.String(), "s3cret")
+
+	config = defaultConfig()
+	config.TitlePrefix = "${GOTIFY_PLUGIN_TEST_UNSET}"
+	assert.Error(t, p.ValidateAndSetConfig(config))
+}
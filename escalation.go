@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gotify/plugin-api"
+)
+
+// EscalationConfig re-sends alerts that keep firing at a higher priority.
+type EscalationConfig struct {
+	// After is how long an alert has to be firing before it is escalated
+	// (Go duration, e.g. "30m"). Empty disables escalation.
+	After string `yaml:"after"`
+	// Priority is the priority of the escalated notification.
+	Priority int `yaml:"priority"`
+
+	after time.Duration
+}
+
+// validate checks the escalation settings and parses the duration.
+func (e *EscalationConfig) validate() error {
+	e.after = 0
+	if e.After != "" {
+		after, err := time.ParseDuration(e.After)
+		if err != nil || after <= 0 {
+			return fmt.Errorf("invalid grafana.escalation.after %q, expected a duration such as 30m", e.After)
+		}
+		e.after = after
+	}
+	if e.Priority < 1 || e.Priority > 10 {
+		return errors.New("grafana.escalation.priority must be between 1 and 10")
+	}
+	return nil
+}
+
+// alertStart returns when an alert started firing according to Grafana,
+// or now if the payload has no valid start time.
+func alertStart(alert GrafanaAlert, now time.Time) time.Time {
+	started, err := time.Parse(time.RFC3339Nano, alert.StartsAt)
+	if err != nil || started.Year() <= 1 || started.After(now) {
+		return now
+	}
+	return started
+}
+
+// escalateAlerts re-sends every alert that has been firing for longer than
+// grafana.escalation.after. Each alert is escalated once per incident.
+func (p *WebhookForwarderPlugin) escalateAlerts(now time.Time) {
+	config := p.getConfig()
+	after := config.Grafana.Escalation.after
+	if after <= 0 {
+		return
+	}
+
+	var messages []plugin.Message
+	_ = p.updateStorage(func(storage *pluginStorage) {
+		for _, group := range storage.AlertGroups {
+			for _, state := range group.Alerts {
+				if state.Observed != "firing" || state.Alert == nil || state.Escalated || now.Sub(state.FiringSince) < after {
+					continue
+				}
+				state.Escalated = true
+				messages = append(messages, config.escalationMessage(*state.Alert, now.Sub(state.FiringSince)))
+			}
+		}
+	})
+	for _, msg := range messages {
+		_ = p.deliverMessage(nil, msg)
+	}
+}
+
+// escalationMessage repeats the notification of an alert that has been
+// firing for the given duration at the escalation priority.
+func (c *Config) escalationMessage(alert GrafanaAlert, firing time.Duration) plugin.Message {
+	msg := c.grafanaAlertMessage(c.source("grafana"), GrafanaWebhook{Status: "firing"}, alert)
+	msg.Message = fmt.Sprintf("⏰ Still firing for %s\n\n%s", humanizeDuration(firing.Truncate(time.Minute)), msg.Message)
+	msg.Priority = c.Grafana.Escalation.Priority
+	msg.Extras = withExtra(msg.Extras, "escalated", true)
+	return msg
+}
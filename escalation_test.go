@@ -0,0 +1,57 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_EscalateAlerts(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	defer func() { timeNow = time.Now }()
+	timeNow = func() time.Time { return now }
+
+	config := defaultConfig()
+	config.Grafana.Escalation.After = "30m"
+	p, mockHandler := newTestPlugin(t, config)
+
+	payload := dedupGrafanaPayload("firing")
+	notificationAlert(payload, 0)["startsAt"] = "2024-05-01T11:50:00Z"
+	postWebhook(p, payload)
+	assert.Len(t, mockHandler.sentMessages, 1)
+
+	p.escalateAlerts(now.Add(10 * time.Minute))
+	assert.Len(t, mockHandler.sentMessages, 1)
+
+	// The alert started firing 10 minutes before the webhook
+	p.escalateAlerts(now.Add(25 * time.Minute))
+	assert.Len(t, mockHandler.sentMessages, 2)
+	msg := mockHandler.sentMessages[1]
+	assert.Equal(t, "[FIRING] HighCPU", msg.Title)
+	assert.Equal(t, 10, msg.Priority)
+	assert.Equal(t, true, msg.Extras["escalated"])
+	assert.Contains(t, msg.Message, "⏰ Still firing for 35m\n\n")
+
+	// Alerts are escalated once per incident
+	p.escalateAlerts(now.Add(time.Hour))
+	assert.Len(t, mockHandler.sentMessages, 2)
+
+	now = now.Add(2 * time.Hour)
+	postWebhook(p, dedupGrafanaPayload("resolved"))
+	p.escalateAlerts(now.Add(time.Hour))
+	assert.Len(t, mockHandler.sentMessages, 3)
+
+	postWebhook(p, dedupGrafanaPayload("firing"))
+	p.escalateAlerts(now.Add(31 * time.Minute))
+	assert.Len(t, mockHandler.sentMessages, 5)
+	assert.Contains(t, mockHandler.sentMessages[4].Message, "Still firing for 31m")
+}
+
+func TestEscalationConfig_Validate(t *testing.T) {
+	assert.NoError(t, (&EscalationConfig{Priority: 10}).validate())
+	assert.NoError(t, (&EscalationConfig{After: "1h", Priority: 9}).validate())
+	assert.Error(t, (&EscalationConfig{After: "soon", Priority: 9}).validate())
+	assert.Error(t, (&EscalationConfig{After: "-5m", Priority: 9}).validate())
+	assert.Error(t, (&EscalationConfig{After: "30m", Priority: 11}).validate())
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+)
+
+// mergeExtras returns extras with defaults merged in. Values from extras win;
+// nested objects (e.g. "client::display") are merged key by key.
+func mergeExtras(defaults, extras map[string]interface{}) map[string]interface{} {
+	if len(defaults) == 0 {
+		return extras
+	}
+	result := make(map[string]interface{}, len(defaults)+len(extras))
+	for key, value := range defaults {
+		result[key] = value
+	}
+	for key, value := range extras {
+		defaultMap, defaultIsMap := result[key].(map[string]interface{})
+		valueMap, valueIsMap := value.(map[string]interface{})
+		if defaultIsMap && valueIsMap {
+			result[key] = mergeExtras(defaultMap, valueMap)
+		} else {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+// normalizeExtras converts YAML decoded maps with non-string keys into the
+// map[string]interface{} form used in Gotify message extras.
+func normalizeExtras(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[fmt.Sprint(key)] = normalizeExtras(item)
+		}
+		return result
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[key] = normalizeExtras(item)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = normalizeExtras(item)
+		}
+		return result
+	}
+	return value
+}
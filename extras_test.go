@@ -0,0 +1,55 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeExtras(t *testing.T) {
+	defaults := map[string]interface{}{
+		"client::display": map[string]interface{}{"contentType": "text/markdown"},
+		"team":            "ops",
+	}
+	extras := map[string]interface{}{
+		"client::display":      map[string]interface{}{"contentType": "text/plain"},
+		"client::notification": map[string]interface{}{"click": map[string]interface{}{"url": "https://example.com"}},
+		"team":                 "db",
+	}
+
+	assert.Equal(t, map[string]interface{}{
+		"client::display":      map[string]interface{}{"contentType": "text/plain"},
+		"client::notification": map[string]interface{}{"click": map[string]interface{}{"url": "https://example.com"}},
+		"team":                 "db",
+	}, mergeExtras(defaults, extras))
+	assert.Equal(t, defaults, mergeExtras(defaults, nil))
+	assert.Equal(t, extras, mergeExtras(nil, extras))
+}
+
+func TestNormalizeExtras(t *testing.T) {
+	normalized := normalizeExtras(map[string]interface{}{
+		"client::display": map[interface{}]interface{}{"contentType": "text/markdown"},
+		"tags":            []interface{}{map[interface{}]interface{}{1: "one"}},
+	})
+	assert.Equal(t, map[string]interface{}{
+		"client::display": map[string]interface{}{"contentType": "text/markdown"},
+		"tags":            []interface{}{map[string]interface{}{"1": "one"}},
+	}, normalized)
+}
+
+func TestWebhookForwarderPlugin_DefaultExtras(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+	config := defaultConfig()
+	config.DefaultExtras = map[string]interface{}{
+		"client::display": map[interface{}]interface{}{"contentType": "text/markdown"},
+	}
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+
+	postWebhook(p, map[string]interface{}{"message": "hello", "extras": map[string]interface{}{"key": "value"}})
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, map[string]interface{}{
+		"client::display": map[string]interface{}{"contentType": "text/markdown"},
+		"key":             "value",
+	}, mockHandler.sentMessages[0].Extras)
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gotify/plugin-api"
+)
+
+// falcoPriorities maps Falco rule priorities onto Gotify priorities.
+var falcoPriorities = map[string]int{
+	"emergency":     10,
+	"alert":         10,
+	"critical":      9,
+	"error":         8,
+	"warning":       6,
+	"notice":        4,
+	"informational": 3,
+	"info":          3,
+	"debug":         1,
+}
+
+// falcoOutputPrefix matches the time and priority Falco puts in front of
+// the output text, e.g. "16:31:56.746609046: Error ".
+var falcoOutputPrefix = regexp.MustCompile(`^\d{2}:\d{2}:\d{2}\.\d+: [A-Za-z]+ `)
+
+// isFalcoPayload detects alerts of Falco's HTTP output and Falcosidekick.
+func isFalcoPayload(body map[string]interface{}) bool {
+	if !hasFields(body, "rule", "priority", "output") {
+		return false
+	}
+	_, known := falcoPriorities[strings.ToLower(stringField(body, "priority"))]
+	return known
+}
+
+// formatFalcoPayload renders a Falco alert with the rule and priority in the
+// title, the output text as message and the output fields as a label list.
+func formatFalcoPayload(body map[string]interface{}, config *Config) plugin.Message {
+	rule := stringField(body, "rule")
+	level := stringField(body, "priority")
+	title := fmt.Sprintf("[%s] Falco: %s", strings.ToUpper(level), rule)
+
+	var paragraphs []string
+	if output := falcoOutputPrefix.ReplaceAllString(strings.TrimSpace(stringField(body, "output")), ""); output != "" {
+		paragraphs = append(paragraphs, output)
+	}
+	fields, _ := body["output_fields"].(map[string]interface{})
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		if looseString(fields[name]) != "" && config.Labels.allows(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	var items []string
+	for _, name := range names {
+		items = append(items, fmt.Sprintf("- **%s**: %s", name, looseString(fields[name])))
+	}
+	if host := stringField(body, "hostname"); host != "" {
+		items = append(items, "- **Host**: "+host)
+	}
+	if tags := joinStrings(body["tags"]); tags != "" {
+		items = append(items, "- **Tags**: "+tags)
+	}
+	if when := config.formatTimestamp(stringField(body, "time")); when != "" {
+		items = append(items, "- **Time**: "+when)
+	}
+	if len(items) > 0 {
+		paragraphs = append(paragraphs, strings.Join(items, "\n"))
+	}
+	message := strings.Join(paragraphs, "\n\n")
+	if message == "" {
+		message = title
+	}
+
+	priority, ok := falcoPriorities[strings.ToLower(level)]
+	if !ok {
+		priority = 5
+	}
+
+	extras := map[string]interface{}{
+		"source":          "falco",
+		"rule":            rule,
+		"priority":        level,
+		"client::display": map[string]interface{}{"contentType": "text/markdown"},
+	}
+	if host := stringField(body, "hostname"); host != "" {
+		extras["hostname"] = host
+	}
+
+	return plugin.Message{
+		Title:    title,
+		Message:  message,
+		Priority: priority,
+		Extras:   extras,
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gotify/plugin-api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_FalcoWebhook(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	w := postWebhook(p, map[string]interface{}{
+		"output":   "16:31:56.746609046: Error File below a known binary directory opened for writing (user=root command=touch /bin/hack file=/bin/hack)",
+		"priority": "Error",
+		"rule":     "Write below binary dir",
+		"source":   "syscall",
+		"hostname": "node-2",
+		"tags":     []interface{}{"filesystem", "mitre_persistence"},
+		"output_fields": map[string]interface{}{
+			"fd.name":      "/bin/hack",
+			"proc.cmdline": "touch /bin/hack",
+			"user.name":    "root",
+			"user.uid":     0.0,
+			"container.id": nil,
+		},
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title:    "[ERROR] Falco: Write below binary dir",
+		Message:  "File below a known binary directory opened for writing (user=root command=touch /bin/hack file=/bin/hack)\n\n- **fd.name**: /bin/hack\n- **proc.cmdline**: touch /bin/hack\n- **user.name**: root\n- **user.uid**: 0\n- **Host**: node-2\n- **Tags**: filesystem, mitre_persistence",
+		Priority: 8,
+		Extras: map[string]interface{}{
+			"source":          "falco",
+			"rule":            "Write below binary dir",
+			"priority":        "Error",
+			"hostname":        "node-2",
+			"client::display": map[string]interface{}{"contentType": "text/markdown"},
+		},
+	}, mockHandler.sentMessages[0])
+
+	for level, priority := range map[string]int{"Emergency": 10, "Critical": 9, "Warning": 6, "Notice": 4, "Informational": 3, "Debug": 1} {
+		msg := formatFalcoPayload(map[string]interface{}{"rule": "r", "priority": level, "output": "o"}, defaultConfig())
+		assert.Equal(t, priority, msg.Priority, level)
+	}
+	assert.False(t, isFalcoPayload(map[string]interface{}{"rule": "r", "priority": "high", "output": "o"}))
+}
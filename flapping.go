@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gotify/plugin-api"
+)
+
+// FlappingConfig collapses the notifications of alerts that change between
+// firing and resolved too often.
+type FlappingConfig struct {
+	// Threshold is the number of status changes within Window after which
+	// an alert is considered flapping. 0 disables flapping detection.
+	Threshold int `yaml:"threshold"`
+	// Window is the Go duration over which status changes are counted.
+	Window string `yaml:"window"`
+	// Priority is used for the message announcing that an alert is flapping.
+	Priority int `yaml:"priority"`
+
+	window time.Duration
+}
+
+// flapState classifies an alert after its latest status was observed.
+type flapState int
+
+const (
+	// flapStable alerts are notified as usual.
+	flapStable flapState = iota
+	// flapStarted alerts have just started flapping and are announced once.
+	flapStarted
+	// flapOngoing alerts are still flapping and are not notified.
+	flapOngoing
+)
+
+// validate checks the flapping options.
+func (f *FlappingConfig) validate() error {
+	if f.Threshold < 0 {
+		return fmt.Errorf("grafana.flapping.threshold must not be negative")
+	}
+	if f.Priority < 0 || f.Priority > 10 {
+		return fmt.Errorf("grafana.flapping.priority must be between 0 and 10")
+	}
+	f.window = 0
+	if f.Threshold == 0 {
+		return nil
+	}
+	window, err := time.ParseDuration(f.Window)
+	if err != nil || window <= 0 {
+		return fmt.Errorf("invalid grafana.flapping.window %q, expected a duration such as 30m", f.Window)
+	}
+	f.window = window
+	return nil
+}
+
+// evaluate updates the flapping state of an alert after its status was
+// observed at now.
+func (f *FlappingConfig) evaluate(state *alertState, now time.Time) flapState {
+	recent := state.Changes[:0]
+	for _, change := range state.Changes {
+		if now.Sub(change) < f.window {
+			recent = append(recent, change)
+		}
+	}
+	state.Changes = recent
+
+	switch {
+	case len(recent) <= f.Threshold:
+		state.Flapping = false
+		return flapStable
+	case state.Flapping:
+		return flapOngoing
+	}
+	state.Flapping = true
+	return flapStarted
+}
+
+// partitionFlapping splits alerts into those that just started flapping and
+// those that are stable. Alerts that are still flapping are left out.
+func partitionFlapping(alerts []GrafanaAlert, states map[string]flapState) (started, stable []GrafanaAlert) {
+	for _, alert := range alerts {
+		switch states[alertFingerprint(alert)] {
+		case flapStarted:
+			started = append(started, alert)
+		case flapStable:
+			stable = append(stable, alert)
+		}
+	}
+	return started, stable
+}
+
+// flappingMessage announces alerts that started flapping. Further
+// notifications for them are suppressed until they stabilize.
+func (c *Config) flappingMessage(webhook GrafanaWebhook, alerts []GrafanaAlert) plugin.Message {
+	names := make([]string, 0, len(alerts))
+	for _, alert := range alerts {
+		name := alertName(alert, "Alert")
+		if instance := alert.Labels["instance"]; instance != "" {
+			name += " (" + instance + ")"
+		}
+		names = append(names, name)
+	}
+
+	title := fmt.Sprintf("%d alerts are flapping", len(alerts))
+	if len(alerts) == 1 {
+		title = names[0] + " is flapping"
+	}
+	lines := []string{
+		fmt.Sprintf("Changed between firing and resolved more than %d times within %s. Further notifications are suppressed until the status is stable.", c.Grafana.Flapping.Threshold, humanizeDuration(c.Grafana.Flapping.window)),
+	}
+	if len(alerts) > 1 {
+		lines = append(lines, "")
+		for _, name := range names {
+			lines = append(lines, "- "+name)
+		}
+	}
+
+	extras := map[string]interface{}{
+		"source":   "grafana",
+		"status":   webhook.alertStatus(alerts[0]),
+		"flapping": true,
+	}
+	if notification := c.Grafana.notificationExtras(webhook, alerts...); notification != nil {
+		extras["client::notification"] = notification
+	}
+
+	return plugin.Message{
+		Title:    title,
+		Message:  strings.Join(lines, "\n"),
+		Priority: c.Grafana.Flapping.Priority,
+		Extras:   extras,
+	}
+}
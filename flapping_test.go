@@ -0,0 +1,70 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_GrafanaFlapping(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	defer func() { timeNow = time.Now }()
+	timeNow = func() time.Time { return now }
+
+	config := defaultConfig()
+	config.Grafana.Flapping.Threshold = 2
+	p, mockHandler := newTestPlugin(t, config)
+
+	// Two status changes are notified as usual
+	for _, status := range []string{"firing", "resolved", "firing"} {
+		postWebhook(p, dedupGrafanaPayload(status))
+		now = now.Add(time.Minute)
+	}
+	assert.Len(t, mockHandler.sentMessages, 3)
+
+	// The third change announces the flapping alert once
+	postWebhook(p, dedupGrafanaPayload("resolved"))
+	assert.Len(t, mockHandler.sentMessages, 4)
+	msg := mockHandler.sentMessages[3]
+	assert.Equal(t, "HighCPU (web1) is flapping", msg.Title)
+	assert.Equal(t, "Changed between firing and resolved more than 2 times within 30m. Further notifications are suppressed until the status is stable.", msg.Message)
+	assert.Equal(t, 2, msg.Priority)
+	assert.Equal(t, true, msg.Extras["flapping"])
+
+	now = now.Add(time.Minute)
+	w := postWebhook(p, dedupGrafanaPayload("firing"))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "flapping alerts are suppressed")
+	assert.Len(t, mockHandler.sentMessages, 4)
+
+	// Notifications resume once the alert is stable
+	now = now.Add(time.Hour)
+	postWebhook(p, dedupGrafanaPayload("resolved"))
+	assert.Len(t, mockHandler.sentMessages, 5)
+	assert.Equal(t, "1 resolved", mockHandler.sentMessages[4].Title)
+}
+
+func TestWebhookForwarderPlugin_GrafanaFlappingSplitAlerts(t *testing.T) {
+	config := defaultConfig()
+	config.Grafana.SplitAlerts = true
+	config.Grafana.Flapping.Threshold = 1
+	p, mockHandler := newTestPlugin(t, config)
+
+	postWebhook(p, dedupGrafanaPayload("firing"))
+	postWebhook(p, dedupGrafanaPayload("resolved"))
+	postWebhook(p, dedupGrafanaPayload("firing"))
+	postWebhook(p, dedupGrafanaPayload("resolved"))
+
+	assert.Len(t, mockHandler.sentMessages, 3)
+	assert.Equal(t, "HighCPU (web1) is flapping", mockHandler.sentMessages[2].Title)
+}
+
+func TestFlappingConfig_Validate(t *testing.T) {
+	assert.NoError(t, (&FlappingConfig{}).validate())
+	assert.NoError(t, (&FlappingConfig{Threshold: 3, Window: "1h"}).validate())
+	assert.Error(t, (&FlappingConfig{Threshold: 3, Window: ""}).validate())
+	assert.Error(t, (&FlappingConfig{Threshold: -1}).validate())
+	assert.Error(t, (&FlappingConfig{Priority: 11}).validate())
+}
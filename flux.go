@@ -0,0 +1,139 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/gotify/plugin-api"
+)
+
+// fluxSeverityPriorities maps the severity of Flux events onto priorities.
+var fluxSeverityPriorities = map[string]int{
+	"error": 8,
+	"info":  3,
+	"trace": 1,
+}
+
+// fluxReasons describes reasons that do not read well when split into words.
+var fluxReasons = map[string]string{
+	"ReconciliationSucceeded": "reconciled",
+	"ReconciliationFailed":    "reconcile failed",
+	"Succeeded":               "reconciled",
+	"Failed":                  "failed",
+}
+
+// isFluxPayload detects events of Flux's notification-controller sent by
+// the generic webhook provider. Unlike Kubernetes events they carry a
+// severity and the reporting controller.
+func isFluxPayload(body map[string]interface{}) bool {
+	object, ok := body["involvedObject"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	if _, known := fluxSeverityPriorities[stringField(body, "severity")]; !known {
+		return false
+	}
+	return stringField(body, "reportingController") != "" ||
+		strings.Contains(stringField(object, "apiVersion"), "toolkit.fluxcd.io")
+}
+
+// formatFluxPayload renders a Flux event titled by the object and reason,
+// e.g. "Kustomization apps reconcile failed". The severity sets the
+// priority.
+func formatFluxPayload(body map[string]interface{}, config *Config) plugin.Message {
+	object, _ := body["involvedObject"].(map[string]interface{})
+	kind := stringField(object, "kind")
+	name := stringField(object, "name")
+	namespace := stringField(object, "namespace")
+	reason := stringField(body, "reason")
+	severity := stringField(body, "severity")
+
+	title := strings.TrimSpace(kind + " " + name + " " + fluxReason(reason))
+
+	var paragraphs, lines []string
+	metadata, _ := body["metadata"].(map[string]interface{})
+	if summary := strings.TrimSpace(stringField(metadata, "summary")); summary != "" {
+		paragraphs = append(paragraphs, summary)
+	}
+	if text := strings.TrimSpace(stringField(body, "message")); text != "" {
+		paragraphs = append(paragraphs, text)
+	}
+	if revision := stringField(metadata, "revision"); revision != "" {
+		lines = append(lines, "Revision: "+revision)
+	}
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		if key != "revision" && key != "summary" && config.Labels.allows(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if value := looseString(metadata[key]); value != "" {
+			lines = append(lines, key+": "+value)
+		}
+	}
+	if namespace != "" {
+		lines = append(lines, "Namespace: "+namespace)
+	}
+	if controller := stringField(body, "reportingController"); controller != "" {
+		lines = append(lines, "Controller: "+controller)
+	}
+	if when := config.formatTimestamp(stringField(body, "timestamp")); when != "" {
+		lines = append(lines, "Time: "+when)
+	}
+	if len(lines) > 0 {
+		paragraphs = append(paragraphs, strings.Join(lines, "\n"))
+	}
+	message := strings.Join(paragraphs, "\n\n")
+	if message == "" {
+		message = title
+	}
+
+	priority, ok := fluxSeverityPriorities[severity]
+	if !ok {
+		priority = 5
+	}
+
+	extras := map[string]interface{}{"source": "flux"}
+	for key, value := range map[string]string{
+		"kind":      kind,
+		"name":      name,
+		"namespace": namespace,
+		"reason":    reason,
+		"severity":  severity,
+	} {
+		if value != "" {
+			extras[key] = value
+		}
+	}
+
+	return plugin.Message{
+		Title:    title,
+		Message:  message,
+		Priority: priority,
+		Extras:   extras,
+	}
+}
+
+// fluxReason turns a reason like "HealthCheckFailed" into "health check
+// failed".
+func fluxReason(reason string) string {
+	if described, ok := fluxReasons[reason]; ok {
+		return described
+	}
+	var words []string
+	start := 0
+	runes := []rune(reason)
+	for i := 1; i < len(runes); i++ {
+		if unicode.IsUpper(runes[i]) && !unicode.IsUpper(runes[i-1]) {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	if start < len(runes) {
+		words = append(words, string(runes[start:]))
+	}
+	return strings.ToLower(strings.Join(words, " "))
+}
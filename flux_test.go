@@ -0,0 +1,61 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gotify/plugin-api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_FluxEvent(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+	p.SetStorageHandler(&MockStorageHandler{})
+	config := defaultConfig()
+	config.Timezone = "UTC"
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+
+	w := postWebhook(p, map[string]interface{}{
+		"involvedObject": map[string]interface{}{
+			"kind":       "Kustomization",
+			"namespace":  "flux-system",
+			"name":       "apps",
+			"apiVersion": "kustomize.toolkit.fluxcd.io/v1",
+		},
+		"severity":  "error",
+		"timestamp": "2024-03-01T10:15:00Z",
+		"message":   "Deployment/shop/web dry-run failed: field is immutable",
+		"reason":    "ReconciliationFailed",
+		"metadata": map[string]interface{}{
+			"revision": "main@sha1:4e1a8c0d",
+			"summary":  "Production cluster",
+			"env":      "prod",
+		},
+		"reportingController": "kustomize-controller",
+		"reportingInstance":   "kustomize-controller-7f5d6c9b8-x2x4z",
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title:    "Kustomization apps reconcile failed",
+		Message:  "Production cluster\n\nDeployment/shop/web dry-run failed: field is immutable\n\nRevision: main@sha1:4e1a8c0d\nenv: prod\nNamespace: flux-system\nController: kustomize-controller\nTime: 2024-03-01 10:15:00 UTC",
+		Priority: 8,
+		Extras: map[string]interface{}{
+			"source":    "flux",
+			"kind":      "Kustomization",
+			"name":      "apps",
+			"namespace": "flux-system",
+			"reason":    "ReconciliationFailed",
+			"severity":  "error",
+		},
+	}, mockHandler.sentMessages[0])
+}
+
+func TestFluxReason(t *testing.T) {
+	assert.Equal(t, "reconciled", fluxReason("ReconciliationSucceeded"))
+	assert.Equal(t, "health check failed", fluxReason("HealthCheckFailed"))
+	assert.Equal(t, "upgrade succeeded", fluxReason("UpgradeSucceeded"))
+	assert.Equal(t, "dependency not ready", fluxReason("DependencyNotReady"))
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gotify/plugin-api"
+)
+
+// FrigateConfig holds options for Frigate NVR events.
+type FrigateConfig struct {
+	// URL is the base URL of Frigate, used to link the snapshot of events
+	// that do not include a snapshot URL.
+	URL string `yaml:"url"`
+}
+
+// isFrigatePayload detects Frigate events, either the MQTT event message
+// with the event state in "before" and "after" or a single event object.
+func isFrigatePayload(body map[string]interface{}) bool {
+	if after, ok := body["after"].(map[string]interface{}); ok {
+		return hasFields(after, "camera", "label")
+	}
+	return hasFields(body, "camera", "label") && hasAnyField(body, "score", "top_score", "id", "start_time")
+}
+
+// formatFrigatePayload renders a Frigate detection, titled like "Person
+// detected on driveway", with the snapshot shown as big image.
+func formatFrigatePayload(body map[string]interface{}, config *Config) plugin.Message {
+	event := body
+	if after, ok := body["after"].(map[string]interface{}); ok {
+		event = after
+	}
+	camera := strings.ReplaceAll(stringField(event, "camera"), "_", " ")
+	label := strings.ReplaceAll(stringField(event, "label"), "_", " ")
+	if label == "" {
+		label = "object"
+	}
+	title := fmt.Sprintf("%s detected on %s", capitalize(label), camera)
+
+	var lines []string
+	if subLabel := frigateSubLabel(event["sub_label"]); subLabel != "" {
+		lines = append(lines, "Identified as: "+subLabel)
+	}
+	if score, ok := event["top_score"].(float64); ok && score > 0 {
+		lines = append(lines, fmt.Sprintf("Score: %d%%", int(score*100+0.5)))
+	} else if score, ok := event["score"].(float64); ok && score > 0 {
+		lines = append(lines, fmt.Sprintf("Score: %d%%", int(score*100+0.5)))
+	}
+	if zones := joinStrings(event["entered_zones"]); zones != "" {
+		lines = append(lines, "Zones: "+zones)
+	} else if zones := joinStrings(event["current_zones"]); zones != "" {
+		lines = append(lines, "Zones: "+zones)
+	}
+	message := strings.Join(lines, "\n")
+	if message == "" {
+		message = title
+	}
+
+	id := stringField(event, "id")
+	extras := map[string]interface{}{"source": "frigate"}
+	for key, value := range map[string]string{
+		"camera":  stringField(event, "camera"),
+		"label":   stringField(event, "label"),
+		"eventId": id,
+		"type":    stringField(body, "type"),
+	} {
+		if value != "" {
+			extras[key] = value
+		}
+	}
+	if snapshot := frigateSnapshotURL(body, event, config); snapshot != "" {
+		extras["client::notification"] = map[string]interface{}{
+			"bigImageUrl": snapshot,
+			"click":       map[string]interface{}{"url": snapshot},
+		}
+	}
+
+	// Ended events are only a follow-up to the detection
+	priority := 5
+	if stringField(body, "type") == "end" {
+		priority = 3
+	}
+
+	return plugin.Message{
+		Title:    title,
+		Message:  message,
+		Priority: priority,
+		Extras:   extras,
+	}
+}
+
+// frigateSnapshotURL returns the snapshot URL of the payload or, for events
+// with a snapshot, the snapshot API URL of the configured Frigate instance.
+func frigateSnapshotURL(body, event map[string]interface{}, config *Config) string {
+	if url := stringField(body, "snapshot_url", "snapshotUrl", "snapshot"); url != "" {
+		return url
+	}
+	if url := stringField(event, "snapshot_url", "snapshotUrl"); url != "" {
+		return url
+	}
+	id := stringField(event, "id")
+	hasSnapshot, ok := event["has_snapshot"].(bool)
+	if config == nil || config.Frigate.URL == "" || id == "" || (ok && !hasSnapshot) {
+		return ""
+	}
+	return strings.TrimSuffix(config.Frigate.URL, "/") + "/api/events/" + id + "/snapshot.jpg"
+}
+
+// frigateSubLabel returns the sub label of an event, which is a string or a
+// [name, score] pair depending on the Frigate version.
+func frigateSubLabel(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []interface{}:
+		if len(v) > 0 {
+			if name, ok := v[0].(string); ok {
+				if len(v) > 1 {
+					if score, ok := v[1].(float64); ok {
+						return name + " (" + strconv.Itoa(int(score*100+0.5)) + "%)"
+					}
+				}
+				return name
+			}
+		}
+	}
+	return ""
+}
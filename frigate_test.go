@@ -0,0 +1,62 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gotify/plugin-api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_FrigateWebhook(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+	config := defaultConfig()
+	config.Frigate.URL = "https://frigate.example.com/"
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+
+	w := postWebhook(p, map[string]interface{}{
+		"type":   "new",
+		"before": map[string]interface{}{},
+		"after": map[string]interface{}{
+			"id":            "1607123955.475377-mxklsc",
+			"camera":        "driveway",
+			"label":         "person",
+			"sub_label":     []interface{}{"Alice", 0.87},
+			"top_score":     0.958984375,
+			"entered_zones": []interface{}{"porch"},
+			"has_snapshot":  true,
+		},
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	snapshot := "https://frigate.example.com/api/events/1607123955.475377-mxklsc/snapshot.jpg"
+	assert.Equal(t, plugin.Message{
+		Title:    "Person detected on driveway",
+		Message:  "Identified as: Alice (87%)\nScore: 96%\nZones: porch",
+		Priority: 5,
+		Extras: map[string]interface{}{
+			"source":  "frigate",
+			"camera":  "driveway",
+			"label":   "person",
+			"eventId": "1607123955.475377-mxklsc",
+			"type":    "new",
+			"client::notification": map[string]interface{}{
+				"bigImageUrl": snapshot,
+				"click":       map[string]interface{}{"url": snapshot},
+			},
+		},
+	}, mockHandler.sentMessages[0])
+
+	// A single event object with its own snapshot URL
+	msg := formatFrigatePayload(map[string]interface{}{
+		"camera":       "back_yard",
+		"label":        "dog",
+		"score":        0.71,
+		"snapshot_url": "https://example.com/snap.jpg",
+	}, defaultConfig())
+	assert.Equal(t, "Dog detected on back yard", msg.Title)
+	assert.Equal(t, "Score: 71%", msg.Message)
+	assert.Equal(t, "https://example.com/snap.jpg", msg.Extras["client::notification"].(map[string]interface{})["bigImageUrl"])
+}
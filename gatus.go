@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gotify/plugin-api"
+)
+
+// gatusTextPattern matches the text of Gatus' default custom alert body,
+// "[ALERT_TRIGGERED_OR_RESOLVED]: [ENDPOINT_NAME] - [ALERT_DESCRIPTION]".
+var gatusTextPattern = regexp.MustCompile(`^(TRIGGERED|RESOLVED): (.+)$`)
+
+// isGatusPayload detects alerts of Gatus' custom alerting provider: a JSON
+// body built from the endpoint and alert placeholders, or the default body
+// with a single text field.
+func isGatusPayload(body map[string]interface{}) bool {
+	if sourceIs(body, "gatus") {
+		return true
+	}
+	if hasAnyField(body, "endpoint_name", "endpointName") {
+		return gatusStatus(body) != ""
+	}
+	return len(body) == 1 && gatusTextPattern.MatchString(stringField(body, "text"))
+}
+
+// gatusStatus returns the alert state ("triggered" or "resolved") of a
+// Gatus payload, or "" if it is unknown.
+func gatusStatus(body map[string]interface{}) string {
+	status := strings.ToLower(stringField(body, "status", "alert", "state"))
+	if status == "" {
+		if match := gatusTextPattern.FindStringSubmatch(stringField(body, "text")); match != nil {
+			status = strings.ToLower(match[1])
+		}
+	}
+	switch status {
+	case "triggered", "resolved":
+		return status
+	}
+	return ""
+}
+
+// formatGatusPayload renders a Gatus endpoint health alert with the endpoint
+// in the title and the alert description and condition results as message.
+// Triggered alerts get priority 8, resolved alerts 3.
+func formatGatusPayload(body map[string]interface{}, _ *Config) plugin.Message {
+	status := gatusStatus(body)
+	name := stringField(body, "endpoint_name", "endpointName", "name")
+	if group := stringField(body, "endpoint_group", "endpointGroup", "group"); group != "" && name != "" {
+		name = group + "/" + name
+	}
+	description := strings.TrimSpace(stringField(body, "alert_description", "alertDescription", "description"))
+	if match := gatusTextPattern.FindStringSubmatch(stringField(body, "text")); match != nil && name == "" {
+		name, description, _ = strings.Cut(match[2], " - ")
+	}
+	if name == "" {
+		name = "Endpoint"
+	}
+
+	title := "Gatus: " + name
+	priority := 5
+	switch status {
+	case "triggered":
+		title = fmt.Sprintf("Gatus: %s is unhealthy", name)
+		priority = 8
+	case "resolved":
+		title = fmt.Sprintf("Gatus: %s is healthy again", name)
+		priority = 3
+	}
+
+	var paragraphs []string
+	if description != "" {
+		paragraphs = append(paragraphs, description)
+	}
+	if conditions := strings.TrimSpace(stringField(body, "conditions", "result_conditions")); conditions != "" {
+		paragraphs = append(paragraphs, "Conditions:\n"+conditions)
+	}
+	if errors := strings.TrimSpace(stringField(body, "errors", "result_errors")); errors != "" {
+		paragraphs = append(paragraphs, "Errors:\n"+errors)
+	}
+	url := stringField(body, "endpoint_url", "endpointUrl", "url")
+	if url != "" {
+		paragraphs = append(paragraphs, "URL: "+url)
+	}
+	message := strings.Join(paragraphs, "\n\n")
+	if message == "" {
+		message = title
+	}
+
+	extras := map[string]interface{}{"source": "gatus"}
+	if status != "" {
+		extras["status"] = status
+	}
+	if endpoint := stringField(body, "endpoint_name", "endpointName", "name"); endpoint != "" {
+		extras["endpoint"] = endpoint
+	}
+	if url != "" {
+		extras["client::notification"] = map[string]interface{}{
+			"click": map[string]interface{}{"url": url},
+		}
+	}
+
+	return plugin.Message{
+		Title:    title,
+		Message:  message,
+		Priority: priority,
+		Extras:   extras,
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gotify/plugin-api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_GatusWebhook(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	w := postWebhook(p, map[string]interface{}{
+		"endpoint_name":     "api",
+		"endpoint_group":    "core",
+		"endpoint_url":      "https://api.example.com/health",
+		"alert_description": "healthcheck failed 3 times in a row",
+		"status":            "TRIGGERED",
+		"conditions":        "✅ - `[CONNECTED] == true`\n❌ - `[STATUS] == 200`",
+		"errors":            "",
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title:    "Gatus: core/api is unhealthy",
+		Message:  "healthcheck failed 3 times in a row\n\nConditions:\n✅ - `[CONNECTED] == true`\n❌ - `[STATUS] == 200`\n\nURL: https://api.example.com/health",
+		Priority: 8,
+		Extras: map[string]interface{}{
+			"source":   "gatus",
+			"status":   "triggered",
+			"endpoint": "api",
+			"client::notification": map[string]interface{}{
+				"click": map[string]interface{}{"url": "https://api.example.com/health"},
+			},
+		},
+	}, mockHandler.sentMessages[0])
+}
+
+func TestFormatGatusPayload_DefaultBody(t *testing.T) {
+	body := map[string]interface{}{"text": "RESOLVED: api - healthcheck failed 3 times in a row"}
+	assert.True(t, isGatusPayload(body))
+	msg := formatGatusPayload(body, defaultConfig())
+	assert.Equal(t, "Gatus: api is healthy again", msg.Title)
+	assert.Equal(t, "healthcheck failed 3 times in a row", msg.Message)
+	assert.Equal(t, 3, msg.Priority)
+
+	assert.False(t, isGatusPayload(map[string]interface{}{"text": "Hello"}))
+	assert.False(t, isGatusPayload(map[string]interface{}{"endpoint_name": "api", "status": "ok"}))
+}
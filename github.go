@@ -0,0 +1,417 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gotify/plugin-api"
+)
+
+// githubMaxCommits limits the commits listed for a push.
+const githubMaxCommits = 5
+
+// githubMaxBody limits the issue, pull request and release text included in
+// a message.
+const githubMaxBody = 300
+
+// GitHubConfig holds options for GitHub repository and organization webhooks.
+type GitHubConfig struct {
+	// Secret is the secret of the GitHub webhook. If set, requests must carry
+	// a valid X-Hub-Signature-256 header.
+	Secret string `yaml:"secret"`
+	// FailuresOnly drops workflow runs and jobs that did not fail, including
+	// those not completed yet.
+	FailuresOnly bool `yaml:"failuresOnly"`
+}
+
+// verify reports whether the body is signed with the secret, as given by
+// the "sha256=<hex>" X-Hub-Signature-256 header.
+func (g *GitHubConfig) verify(header http.Header, body []byte) bool {
+	if g.Secret == "" {
+		return true
+	}
+	hexSignature, ok := strings.CutPrefix(header.Get("X-Hub-Signature-256"), "sha256=")
+	if !ok {
+		return false
+	}
+	signature, err := hex.DecodeString(hexSignature)
+	if err != nil || len(signature) == 0 {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(g.Secret))
+	mac.Write(body)
+	return hmac.Equal(signature, mac.Sum(nil))
+}
+
+// githubConclusionPriorities maps the conclusion of workflow runs and check
+// runs onto priorities, failures being the most important.
+var githubConclusionPriorities = map[string]int{
+	"failure":         8,
+	"timed_out":       8,
+	"startup_failure": 8,
+	"action_required": 6,
+	"cancelled":       4,
+	"stale":           3,
+	"neutral":         3,
+	"skipped":         2,
+	"success":         3,
+}
+
+// githubConclusionStates names conclusions that do not read well as is.
+var githubConclusionStates = map[string]string{
+	"failure":         "failed",
+	"success":         "succeeded",
+	"startup_failure": "failed to start",
+}
+
+// isGitHubRequest detects GitHub webhook deliveries by their event header.
+func isGitHubRequest(c *gin.Context) bool {
+	return c.GetHeader("X-GitHub-Event") != ""
+}
+
+// handleGitHubWebhook formats a GitHub webhook delivery by the event named
+// in the X-GitHub-Event header. Pings sent when the webhook is created are
+// answered without forwarding a message.
+func (p *WebhookForwarderPlugin) handleGitHubWebhook(c *gin.Context, body map[string]interface{}) {
+	event := c.GetHeader("X-GitHub-Event")
+	if event == "ping" {
+		c.JSON(http.StatusOK, gin.H{
+			"message": "pong",
+		})
+		return
+	}
+	if update, ok := githubDependencyUpdate(event, body); ok && p.getConfig().Dependencies.interval > 0 {
+		p.queueDependencyUpdates(c, "github", []dependencyUpdate{update})
+		return
+	}
+	if p.getConfig().GitHub.FailuresOnly && githubWorkflowEvent(event) && !githubWorkflowFailed(event, body) {
+		p.skipMessage(c, "github", skipFiltered, "Only failed GitHub workflows are forwarded")
+		return
+	}
+	formatter := payloadFormatter{
+		source: "github",
+		format: func(body map[string]interface{}, config *Config) plugin.Message {
+			return formatGitHubEvent(event, body, config)
+		},
+	}
+	p.handleDetectedPayload(c, &formatter, body)
+}
+
+// formatGitHubEvent renders a GitHub event as a human readable summary with
+// the repository in the title, e.g. "octo/app: PR #42 opened by alice".
+// Clicking the message opens the event on GitHub.
+func formatGitHubEvent(event string, body map[string]interface{}, config *Config) plugin.Message {
+	if githubWorkflowEvent(event) {
+		return formatGitHubWorkflow(event, body, config)
+	}
+	repository, _ := body["repository"].(map[string]interface{})
+	repo := stringField(repository, "full_name")
+	sender, _ := body["sender"].(map[string]interface{})
+	user := stringField(sender, "login")
+	action := stringField(body, "action")
+
+	var summary, message, link string
+	priority := 3
+	switch event {
+	case "push":
+		summary, message, link = githubPush(body, user)
+	case "issues", "pull_request":
+		key, kind := "issue", "Issue"
+		if event == "pull_request" {
+			key, kind = "pull_request", "PR"
+		}
+		item, _ := body[key].(map[string]interface{})
+		if action == "closed" && item["merged"] == true {
+			action = "merged"
+		}
+		summary = fmt.Sprintf("%s #%s %s by %s", kind, stringField(item, "number"), strings.ReplaceAll(action, "_", " "), user)
+		lines := []string{stringField(item, "title")}
+		if event == "pull_request" {
+			head, _ := item["head"].(map[string]interface{})
+			base, _ := item["base"].(map[string]interface{})
+			if from, to := stringField(head, "ref"), stringField(base, "ref"); from != "" && to != "" {
+				lines = append(lines, from+" → "+to)
+			}
+		}
+		if text := strings.TrimSpace(stringField(item, "body")); text != "" && action == "opened" {
+			lines = append(lines, "", githubExcerpt(text))
+		}
+		message = strings.Join(lines, "\n")
+		link = stringField(item, "html_url")
+		if action == "opened" || action == "merged" {
+			priority = 4
+		}
+	case "release":
+		release, _ := body["release"].(map[string]interface{})
+		tag := stringField(release, "tag_name")
+		summary = fmt.Sprintf("Release %s %s by %s", tag, action, user)
+		lines := []string{stringField(release, "name")}
+		if text := strings.TrimSpace(stringField(release, "body")); text != "" {
+			lines = append(lines, "", githubExcerpt(text))
+		}
+		message = strings.Join(lines, "\n")
+		link = stringField(release, "html_url")
+		if action == "published" {
+			priority = 4
+		}
+	case "package", "registry_package":
+		pkg, _ := body[event].(map[string]interface{})
+		version, _ := pkg["package_version"].(map[string]interface{})
+		name := stringField(version, "package_url")
+		if name == "" {
+			name = strings.TrimSpace(stringField(pkg, "name") + " " + stringField(version, "version", "name"))
+		}
+		kind := "Package"
+		if strings.EqualFold(stringField(pkg, "package_type", "ecosystem"), "container") {
+			kind = "Image"
+		}
+		summary = fmt.Sprintf("%s %s %s by %s", kind, name, action, user)
+		message = stringField(version, "summary", "description")
+		link = stringField(version, "html_url")
+		if link == "" {
+			link = stringField(pkg, "html_url")
+		}
+	case "check_run":
+		run, _ := body[event].(map[string]interface{})
+		name := stringField(run, "name")
+		conclusion := stringField(run, "conclusion")
+		branch := stringField(run, "head_branch")
+		if suite, ok := run["check_suite"].(map[string]interface{}); ok && branch == "" {
+			branch = stringField(suite, "head_branch")
+		}
+		state := conclusion
+		if action != "completed" || conclusion == "" {
+			state, priority = strings.ReplaceAll(action, "_", " "), 2
+		} else if value, ok := githubConclusionPriorities[conclusion]; ok {
+			priority = value
+		}
+		if value, ok := githubConclusionStates[state]; ok {
+			state = value
+		}
+		summary = fmt.Sprintf("Check %s %s", name, strings.ReplaceAll(state, "_", " "))
+		if branch != "" {
+			summary += " on " + branch
+		}
+		var lines []string
+		if title := stringField(run, "display_title"); title != "" {
+			lines = append(lines, title)
+		}
+		if sha := stringField(run, "head_sha"); sha != "" {
+			lines = append(lines, "Commit: "+shortSHA(sha))
+		}
+		if user != "" {
+			lines = append(lines, "Triggered by: "+user)
+		}
+		message = strings.Join(lines, "\n")
+		link = stringField(run, "html_url", "details_url")
+	default:
+		summary = strings.ReplaceAll(event, "_", " ")
+		if action != "" {
+			summary += " " + strings.ReplaceAll(action, "_", " ")
+		}
+		if user != "" {
+			summary += " by " + user
+		}
+	}
+
+	title := summary
+	if repo != "" {
+		title = repo + ": " + summary
+	}
+	if strings.TrimSpace(message) == "" {
+		message = title
+	}
+
+	extras := map[string]interface{}{
+		"source": "github",
+		"event":  event,
+	}
+	if repo != "" {
+		extras["repository"] = repo
+	}
+	if action != "" {
+		extras["action"] = action
+	}
+	if link == "" {
+		link = stringField(repository, "html_url")
+	}
+	if link != "" {
+		extras["client::notification"] = map[string]interface{}{
+			"click": map[string]interface{}{"url": link},
+		}
+	}
+
+	return plugin.Message{
+		Title:    title,
+		Message:  message,
+		Priority: priority,
+		Extras:   extras,
+	}
+}
+
+// githubPush summarizes a push event, listing its first commits.
+func githubPush(body map[string]interface{}, user string) (summary, message, link string) {
+	branch := strings.TrimPrefix(strings.TrimPrefix(stringField(body, "ref"), "refs/heads/"), "refs/tags/")
+	commits := mapSlice(body["commits"])
+	switch {
+	case body["deleted"] == true:
+		summary = fmt.Sprintf("%s deleted %s", user, branch)
+	case body["created"] == true && len(commits) == 0:
+		summary = fmt.Sprintf("%s created %s", user, branch)
+	case len(commits) == 1:
+		summary = fmt.Sprintf("%s pushed 1 commit to %s", user, branch)
+	default:
+		summary = fmt.Sprintf("%s pushed %d commits to %s", user, len(commits), branch)
+	}
+
+	var lines []string
+	for i, commit := range commits {
+		if i == githubMaxCommits {
+			lines = append(lines, fmt.Sprintf("… and %d more", len(commits)-githubMaxCommits))
+			break
+		}
+		text, _, _ := strings.Cut(stringField(commit, "message"), "\n")
+		lines = append(lines, fmt.Sprintf("- %s %s", shortSHA(stringField(commit, "id")), text))
+	}
+	return summary, strings.Join(lines, "\n"), stringField(body, "compare")
+}
+
+// githubExcerpt shortens issue and release text to githubMaxBody characters.
+func githubExcerpt(text string) string {
+	if excerpt := truncateRunes(text, githubMaxBody); excerpt != text {
+		return excerpt + "…"
+	}
+	return text
+}
+
+// shortSHA shortens a commit hash to 7 characters like git.
+func shortSHA(sha string) string {
+	return truncateRunes(sha, 7)
+}
+
+// githubWorkflowEvent reports whether event is about a GitHub Actions
+// workflow run or job.
+func githubWorkflowEvent(event string) bool {
+	return event == "workflow_run" || event == "workflow_job"
+}
+
+// githubWorkflowFailed reports whether a workflow run or job completed with
+// a failing conclusion.
+func githubWorkflowFailed(event string, body map[string]interface{}) bool {
+	run, _ := body[event].(map[string]interface{})
+	return stringField(body, "action") == "completed" && githubConclusionPriorities[stringField(run, "conclusion")] >= 8
+}
+
+// formatGitHubWorkflow renders a GitHub Actions workflow run or job as a CI
+// digest like "CI ❌ octo/app@main — test", listing the failed steps of
+// jobs. Runs and jobs not completed yet are low priority.
+func formatGitHubWorkflow(event string, body map[string]interface{}, config *Config) plugin.Message {
+	repository, _ := body["repository"].(map[string]interface{})
+	repo := stringField(repository, "full_name")
+	sender, _ := body["sender"].(map[string]interface{})
+	run, _ := body[event].(map[string]interface{})
+	action := stringField(body, "action")
+	conclusion := stringField(run, "conclusion")
+
+	name := stringField(run, "name")
+	if workflow := stringField(run, "workflow_name"); workflow != "" && workflow != name {
+		name = workflow + " / " + name
+	}
+	target := repo
+	if branch := stringField(run, "head_branch"); branch != "" {
+		target += "@" + branch
+	}
+
+	icon, priority := "⏳", 2
+	if action == "completed" && conclusion != "" {
+		switch value := githubConclusionPriorities[conclusion]; {
+		case conclusion == "success":
+			icon, priority = "✅", value
+		case value >= 8:
+			icon, priority = "❌", value
+		case value > 0:
+			icon, priority = "⚠️", value
+		default:
+			icon, priority = "⚠️", 5
+		}
+	}
+	title := fmt.Sprintf("CI %s %s — %s", icon, target, name)
+	if target == "" {
+		title = fmt.Sprintf("CI %s %s", icon, name)
+	}
+
+	var lines []string
+	if text := stringField(run, "display_title"); text != "" {
+		lines = append(lines, text)
+	}
+	state := conclusion
+	if action != "completed" || state == "" {
+		state = stringField(run, "status", "conclusion")
+		if state == "" {
+			state = action
+		}
+	}
+	if value, ok := githubConclusionStates[state]; ok {
+		state = value
+	}
+	lines = append(lines, "Status: "+strings.ReplaceAll(state, "_", " "))
+	var failed []string
+	for _, step := range mapSlice(run["steps"]) {
+		if githubConclusionPriorities[stringField(step, "conclusion")] >= 8 {
+			failed = append(failed, stringField(step, "name"))
+		}
+	}
+	if len(failed) > 0 {
+		lines = append(lines, "Failed steps: "+strings.Join(failed, ", "))
+	}
+	if sha := stringField(run, "head_sha"); sha != "" {
+		lines = append(lines, "Commit: "+shortSHA(sha))
+	}
+	actor, _ := run["actor"].(map[string]interface{})
+	if user := stringField(actor, "login"); user != "" {
+		lines = append(lines, "Triggered by: "+user)
+	} else if user := stringField(sender, "login"); user != "" {
+		lines = append(lines, "Triggered by: "+user)
+	}
+	if duration := githubDuration(run); duration > 0 && action == "completed" {
+		lines = append(lines, "Duration: "+humanizeDuration(duration))
+	}
+
+	extras := map[string]interface{}{
+		"source": "github",
+		"event":  event,
+	}
+	for key, value := range map[string]string{
+		"repository": repo,
+		"action":     action,
+		"conclusion": conclusion,
+	} {
+		if value != "" {
+			extras[key] = value
+		}
+	}
+	if link := stringField(run, "html_url"); link != "" {
+		extras["client::notification"] = map[string]interface{}{
+			"click": map[string]interface{}{"url": link},
+		}
+	}
+
+	return plugin.Message{
+		Title:    title,
+		Message:  strings.Join(lines, "\n"),
+		Priority: priority,
+		Extras:   extras,
+	}
+}
+
+// githubDuration returns how long a workflow run or job took, from its
+// start to its completion or last update.
+func githubDuration(run map[string]interface{}) time.Duration {
+	return ciElapsed(stringField(run, "started_at", "run_started_at"), stringField(run, "completed_at", "updated_at"))
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gotify/plugin-api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_GitHubPullRequest(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	w := postSignedWebhook(p, map[string]interface{}{
+		"action": "opened",
+		"number": 42.0,
+		"pull_request": map[string]interface{}{
+			"number":   42.0,
+			"title":    "Add dark mode",
+			"body":     "Closes #7",
+			"html_url": "https://github.com/octo/app/pull/42",
+			"head":     map[string]interface{}{"ref": "dark-mode"},
+			"base":     map[string]interface{}{"ref": "main"},
+		},
+		"repository": map[string]interface{}{"full_name": "octo/app", "html_url": "https://github.com/octo/app"},
+		"sender":     map[string]interface{}{"login": "alice"},
+	}, map[string]string{"X-GitHub-Event": "pull_request"})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title:    "octo/app: PR #42 opened by alice",
+		Message:  "Add dark mode\ndark-mode → main\n\nCloses #7",
+		Priority: 4,
+		Extras: map[string]interface{}{
+			"source":     "github",
+			"event":      "pull_request",
+			"repository": "octo/app",
+			"action":     "opened",
+			"client::notification": map[string]interface{}{
+				"click": map[string]interface{}{"url": "https://github.com/octo/app/pull/42"},
+			},
+		},
+	}, mockHandler.sentMessages[0])
+}
+
+func TestWebhookForwarderPlugin_GitHubPush(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	w := postSignedWebhook(p, map[string]interface{}{
+		"ref":     "refs/heads/main",
+		"compare": "https://github.com/octo/app/compare/abc...def",
+		"commits": []interface{}{
+			map[string]interface{}{"id": "1a2b3c4d5e6f", "message": "Fix login\n\nDetails"},
+			map[string]interface{}{"id": "7f8e9d0c1b2a", "message": "Update docs"},
+		},
+		"repository": map[string]interface{}{"full_name": "octo/app"},
+		"sender":     map[string]interface{}{"login": "bob"},
+	}, map[string]string{"X-GitHub-Event": "push"})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	msg := mockHandler.sentMessages[0]
+	assert.Equal(t, "octo/app: bob pushed 2 commits to main", msg.Title)
+	assert.Equal(t, "- 1a2b3c4 Fix login\n- 7f8e9d0 Update docs", msg.Message)
+	assert.Equal(t, 3, msg.Priority)
+}
+
+func TestWebhookForwarderPlugin_GitHubWorkflowRun(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	run := func(conclusion string) map[string]interface{} {
+		return map[string]interface{}{
+			"action": "completed",
+			"workflow_run": map[string]interface{}{
+				"name":          "CI",
+				"conclusion":    conclusion,
+				"head_branch":   "main",
+				"head_sha":      "9f8e7d6c5b4a",
+				"display_title": "Fix login",
+				"html_url":      "https://github.com/octo/app/actions/runs/1",
+			},
+			"repository": map[string]interface{}{"full_name": "octo/app"},
+			"sender":     map[string]interface{}{"login": "alice"},
+		}
+	}
+	headers := map[string]string{"X-GitHub-Event": "workflow_run"}
+
+	postSignedWebhook(p, run("failure"), headers)
+	postSignedWebhook(p, run("success"), headers)
+
+	assert.Len(t, mockHandler.sentMessages, 2)
+	assert.Equal(t, "CI ❌ octo/app@main — CI", mockHandler.sentMessages[0].Title)
+	assert.Equal(t, "Fix login\nStatus: failed\nCommit: 9f8e7d6\nTriggered by: alice", mockHandler.sentMessages[0].Message)
+	assert.Equal(t, 8, mockHandler.sentMessages[0].Priority)
+	assert.Equal(t, "CI ✅ octo/app@main — CI", mockHandler.sentMessages[1].Title)
+	assert.Equal(t, 3, mockHandler.sentMessages[1].Priority)
+}
+
+func TestWebhookForwarderPlugin_GitHubWorkflowJob(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+	p.SetStorageHandler(&MockStorageHandler{})
+	config := defaultConfig()
+	config.GitHub.FailuresOnly = true
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+
+	job := func(action, conclusion string) map[string]interface{} {
+		return map[string]interface{}{
+			"action": action,
+			"workflow_job": map[string]interface{}{
+				"name":          "test",
+				"workflow_name": "CI",
+				"status":        "completed",
+				"conclusion":    conclusion,
+				"head_branch":   "main",
+				"head_sha":      "9f8e7d6c5b4a",
+				"html_url":      "https://github.com/octo/app/actions/runs/1/job/2",
+				"started_at":    "2024-03-01T10:00:00Z",
+				"completed_at":  "2024-03-01T10:04:00Z",
+				"steps": []interface{}{
+					map[string]interface{}{"name": "Checkout", "conclusion": "success"},
+					map[string]interface{}{"name": "Run tests", "conclusion": "failure"},
+				},
+			},
+			"repository": map[string]interface{}{"full_name": "octo/app"},
+			"sender":     map[string]interface{}{"login": "alice"},
+		}
+	}
+	headers := map[string]string{"X-GitHub-Event": "workflow_job"}
+
+	w := postSignedWebhook(p, job("in_progress", ""), headers)
+	assert.Equal(t, http.StatusOK, w.Code)
+	postSignedWebhook(p, job("completed", "success"), headers)
+	assert.Empty(t, mockHandler.sentMessages)
+
+	w = postSignedWebhook(p, job("completed", "failure"), headers)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title:    "CI ❌ octo/app@main — CI / test",
+		Message:  "Status: failed\nFailed steps: Run tests\nCommit: 9f8e7d6\nTriggered by: alice\nDuration: 4m",
+		Priority: 8,
+		Extras: map[string]interface{}{
+			"source":     "github",
+			"event":      "workflow_job",
+			"repository": "octo/app",
+			"action":     "completed",
+			"conclusion": "failure",
+			"client::notification": map[string]interface{}{
+				"click": map[string]interface{}{"url": "https://github.com/octo/app/actions/runs/1/job/2"},
+			},
+		},
+	}, mockHandler.sentMessages[0])
+}
+
+func TestWebhookForwarderPlugin_GitHubPing(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	w := postSignedWebhook(p, map[string]interface{}{"zen": "Keep it logically awesome."}, map[string]string{"X-GitHub-Event": "ping"})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, mockHandler.sentMessages)
+}
+
+func TestWebhookForwarderPlugin_GitHubSignature(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+	config := defaultConfig()
+	config.GitHub.Secret = "s3cret"
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+
+	payload := map[string]interface{}{
+		"action":     "published",
+		"release":    map[string]interface{}{"tag_name": "v1.2.0", "name": "v1.2.0"},
+		"repository": map[string]interface{}{"full_name": "octo/app"},
+		"sender":     map[string]interface{}{"login": "alice"},
+	}
+	body, _ := json.Marshal(payload)
+
+	w := postSignedWebhook(p, payload, map[string]string{
+		"X-GitHub-Event":      "release",
+		"X-Hub-Signature-256": "sha256=" + sign("s3cret", string(body)),
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, "octo/app: Release v1.2.0 published by alice", mockHandler.sentMessages[0].Title)
+
+	w = postSignedWebhook(p, payload, map[string]string{
+		"X-GitHub-Event":      "release",
+		"X-Hub-Signature-256": "sha256=" + sign("wrong", string(body)),
+	})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	w = postSignedWebhook(p, payload, map[string]string{"X-GitHub-Event": "release"})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+}
+
+func TestWebhookForwarderPlugin_GitHubPackagePublished(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	postSignedWebhook(p, map[string]interface{}{
+		"action": "published",
+		"package": map[string]interface{}{
+			"name":         "app",
+			"package_type": "CONTAINER",
+			"html_url":     "https://github.com/octo/app/pkgs/container/app",
+			"package_version": map[string]interface{}{
+				"version":     "sha256:fea8895f450959fa",
+				"package_url": "ghcr.io/octo/app:1.2.0",
+				"html_url":    "https://github.com/octo/app/pkgs/container/app/12345",
+			},
+		},
+		"repository": map[string]interface{}{"full_name": "octo/app"},
+		"sender":     map[string]interface{}{"login": "github-actions[bot]"},
+	}, map[string]string{"X-GitHub-Event": "package"})
+
+	assert.Len(t, mockHandler.sentMessages, 1)
+	msg := mockHandler.sentMessages[0]
+	assert.Equal(t, "octo/app: Image ghcr.io/octo/app:1.2.0 published by github-actions[bot]", msg.Title)
+	assert.Equal(t, map[string]interface{}{
+		"click": map[string]interface{}{"url": "https://github.com/octo/app/pkgs/container/app/12345"},
+	}, msg.Extras["client::notification"])
+}
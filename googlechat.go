@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gotify/plugin-api"
+)
+
+// googleChatTextKeys are the fields a simple Google Chat text message may have.
+var googleChatTextKeys = map[string]bool{"text": true, "thread": true, "threadKey": true, "fallbackText": true}
+
+// isGoogleChatPayload detects Google Chat app messages: card messages
+// (cardsV2 or legacy cards) and simple text-only messages.
+func isGoogleChatPayload(body map[string]interface{}) bool {
+	if _, ok := body["cardsV2"].([]interface{}); ok {
+		return true
+	}
+	if _, ok := body["cards"].([]interface{}); ok {
+		return true
+	}
+	if stringField(body, "text") == "" {
+		return false
+	}
+	for key := range body {
+		if !googleChatTextKeys[key] {
+			return false
+		}
+	}
+	return true
+}
+
+// formatGoogleChatPayload converts the text and card widgets of a Google
+// Chat message into markdown.
+func formatGoogleChatPayload(body map[string]interface{}, _ *Config) plugin.Message {
+	var title string
+	var lines []string
+	if text := stringField(body, "text"); text != "" {
+		lines = append(lines, googleChatMarkdown(text), "")
+	}
+
+	cards := mapSlice(body["cards"])
+	for _, wrapper := range mapSlice(body["cardsV2"]) {
+		if card, ok := wrapper["card"].(map[string]interface{}); ok {
+			cards = append(cards, card)
+		}
+	}
+
+	for _, card := range cards {
+		if header, ok := card["header"].(map[string]interface{}); ok {
+			if headerTitle := stringField(header, "title"); title == "" {
+				title = headerTitle
+			} else if headerTitle != "" {
+				lines = append(lines, "**"+headerTitle+"**")
+			}
+			if subtitle := stringField(header, "subtitle"); subtitle != "" {
+				lines = append(lines, subtitle)
+			}
+		}
+		for _, section := range mapSlice(card["sections"]) {
+			if header := stringField(section, "header"); header != "" {
+				lines = append(lines, "**"+googleChatMarkdown(header)+"**")
+			}
+			for _, widget := range mapSlice(section["widgets"]) {
+				lines = append(lines, googleChatWidget(widget)...)
+			}
+			lines = append(lines, "")
+		}
+	}
+
+	if title == "" {
+		title = "Google Chat Message"
+	}
+
+	return plugin.Message{
+		Title:    title,
+		Message:  strings.TrimSpace(strings.Join(lines, "\n")),
+		Priority: 5,
+		Extras: map[string]interface{}{
+			"source":          "googlechat",
+			"client::display": map[string]interface{}{"contentType": "text/markdown"},
+		},
+	}
+}
+
+// googleChatWidget renders a single card widget as markdown lines.
+func googleChatWidget(widget map[string]interface{}) []string {
+	var lines []string
+	if w, ok := widget["textParagraph"].(map[string]interface{}); ok {
+		lines = append(lines, googleChatMarkdown(stringField(w, "text")))
+	}
+	for _, key := range []string{"decoratedText", "keyValue"} {
+		if w, ok := widget[key].(map[string]interface{}); ok {
+			label := stringField(w, "topLabel")
+			text := googleChatMarkdown(stringField(w, "text", "content"))
+			if label != "" {
+				lines = append(lines, fmt.Sprintf("- **%s**: %s", label, text))
+			} else {
+				lines = append(lines, "- "+text)
+			}
+			if bottom := stringField(w, "bottomLabel"); bottom != "" {
+				lines = append(lines, "  "+bottom)
+			}
+		}
+	}
+	if w, ok := widget["image"].(map[string]interface{}); ok {
+		if url := stringField(w, "imageUrl"); url != "" {
+			lines = append(lines, fmt.Sprintf("![%s](%s)", stringField(w, "altText"), url))
+		}
+	}
+
+	buttons := mapSlice(widget["buttons"])
+	if w, ok := widget["buttonList"].(map[string]interface{}); ok {
+		buttons = append(buttons, mapSlice(w["buttons"])...)
+	}
+	for _, button := range buttons {
+		if textButton, ok := button["textButton"].(map[string]interface{}); ok {
+			button = textButton
+		}
+		onClick, _ := button["onClick"].(map[string]interface{})
+		openLink, _ := onClick["openLink"].(map[string]interface{})
+		if url := stringField(openLink, "url"); url != "" {
+			lines = append(lines, fmt.Sprintf("[%s](%s)", stringField(button, "text"), url))
+		}
+	}
+	return lines
+}
+
+var (
+	googleChatLink     = regexp.MustCompile(`<(https?://[^|>]+)\|([^>]+)>`)
+	googleChatBold     = regexp.MustCompile(`(?i)<b>(.*?)</b>`)
+	googleChatItalic   = regexp.MustCompile(`(?i)<i>(.*?)</i>`)
+	googleChatAnchor   = regexp.MustCompile(`(?i)<a href="([^"]+)">(.*?)</a>`)
+	googleChatLineBrk  = regexp.MustCompile(`(?i)<br\s*/?>`)
+	googleChatOtherTag = regexp.MustCompile(`</?[a-zA-Z][^>]*>`)
+)
+
+// googleChatMarkdown converts Google Chat text formatting (<url|text> links
+// and the HTML subset used in card widgets) into markdown.
+func googleChatMarkdown(text string) string {
+	text = googleChatLink.ReplaceAllString(text, "[$2]($1)")
+	text = googleChatAnchor.ReplaceAllString(text, "[$2]($1)")
+	text = googleChatBold.ReplaceAllString(text, "**$1**")
+	text = googleChatItalic.ReplaceAllString(text, "_${1}_")
+	text = googleChatLineBrk.ReplaceAllString(text, "\n")
+	return googleChatOtherTag.ReplaceAllString(text, "")
+}
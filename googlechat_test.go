@@ -0,0 +1,54 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_GoogleChatCards(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	postWebhook(p, map[string]interface{}{
+		"text": "See <https://status.example.com|status page>",
+		"cardsV2": []interface{}{
+			map[string]interface{}{
+				"cardId": "alert",
+				"card": map[string]interface{}{
+					"header": map[string]interface{}{"title": "Deploy finished", "subtitle": "production"},
+					"sections": []interface{}{
+						map[string]interface{}{
+							"header": "Details",
+							"widgets": []interface{}{
+								map[string]interface{}{"textParagraph": map[string]interface{}{"text": "Version <b>1.4.2</b><br>is live"}},
+								map[string]interface{}{"decoratedText": map[string]interface{}{"topLabel": "Duration", "text": "4m"}},
+								map[string]interface{}{"buttonList": map[string]interface{}{
+									"buttons": []interface{}{
+										map[string]interface{}{
+											"text":    "Open",
+											"onClick": map[string]interface{}{"openLink": map[string]interface{}{"url": "https://ci.example.com"}},
+										},
+									},
+								}},
+							},
+						},
+					},
+				},
+			},
+		},
+	})
+
+	assert.Len(t, mockHandler.sentMessages, 1)
+	msg := mockHandler.sentMessages[0]
+	assert.Equal(t, "Deploy finished", msg.Title)
+	assert.Equal(t, "See [status page](https://status.example.com)\n\nproduction\n**Details**\nVersion **1.4.2**\nis live\n- **Duration**: 4m\n[Open](https://ci.example.com)", msg.Message)
+	assert.Equal(t, "googlechat", msg.Extras["source"])
+}
+
+func TestIsGoogleChatPayload(t *testing.T) {
+	assert.True(t, isGoogleChatPayload(map[string]interface{}{"text": "hello"}))
+	assert.True(t, isGoogleChatPayload(map[string]interface{}{"cards": []interface{}{}}))
+	assert.False(t, isGoogleChatPayload(map[string]interface{}{"text": "hello", "message": "x"}))
+	assert.False(t, isGoogleChatPayload(map[string]interface{}{"message": "hello"}))
+}
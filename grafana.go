@@ -0,0 +1,837 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gotify/plugin-api"
+)
+
+// handleGrafanaWebhook processes Grafana alert webhooks
+func (p *WebhookForwarderPlugin) handleGrafanaWebhook(c *gin.Context, rawBody map[string]interface{}) {
+	// Add panic recovery for Grafana webhook processing
+	defer func() {
+		if r := recover(); r != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Error processing Grafana webhook",
+				"details": "Unexpected error in Grafana webhook processing",
+			})
+		}
+	}()
+
+	// Decode as much of the Grafana payload as possible; fields with
+	// unexpected types are left empty
+	var grafanaMsg GrafanaWebhook
+	if isLegacyGrafanaPayload(rawBody) {
+		grafanaMsg = legacyGrafanaWebhook(rawBody)
+	} else {
+		decodePayload(rawBody, &grafanaMsg)
+	}
+
+	config := p.getConfig()
+
+	// Reject alerts of disabled organizations and label the messages of
+	// the others
+	org := config.Grafana.org(grafanaMsg.OrgId)
+	if !org.enabled() {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": fmt.Sprintf("Alerts of Grafana organization %d are disabled in the plugin configuration", org.ID),
+		})
+		return
+	}
+	c.Set(grafanaOrgContextKey, org)
+
+	// Apply the profile of the contact point the alerts were sent to
+	receiver := config.Grafana.receiver(grafanaMsg.Receiver)
+	if receiver != nil && receiver.Enabled != nil && !*receiver.Enabled {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": fmt.Sprintf("Alerts of Grafana contact point '%s' are disabled in the plugin configuration", grafanaMsg.Receiver),
+		})
+		return
+	}
+
+	// Track the alerts to detect flapping alerts and cleared groups,
+	// including resolved alerts that are not forwarded
+	now := timeNow()
+	observation := p.observeAlerts(&config.Grafana, grafanaMsg, now)
+
+	// Acknowledge resolved alerts without forwarding them if configured
+	resolved := grafanaMsg.Status == "resolved" || grafanaMsg.State == "ok"
+	if resolved && !config.Grafana.NotifyOnResolved {
+		p.skipMessage(c, "grafana", skipFiltered, "Resolved alerts are not forwarded")
+		return
+	}
+
+	// Look up previously notified alerts to suppress repeated notifications
+	var group *alertGroupState
+	if config.Grafana.tracksAlerts() {
+		group = p.alertGroup(grafanaMsg.groupKey())
+	}
+
+	profile := sourceProfile(c, config, "grafana")
+	if org.Priority > 0 {
+		profile.Priority = org.Priority
+	}
+	profile = profile.withOverrides(receiver)
+	digest := config.Grafana.digests(len(grafanaMsg.Alerts))
+	if config.Grafana.SplitAlerts && len(grafanaMsg.Alerts) > 0 && !digest {
+		p.forwardGrafanaAlerts(c, config, profile, grafanaMsg, group, observation, now)
+		return
+	}
+
+	// Announce flapping alerts and cleared groups, and leave the alerts
+	// covered by these announcements out of the notification
+	if len(grafanaMsg.Alerts) > 0 {
+		announcements, remaining := config.grafanaAnnouncements(grafanaMsg, observation)
+		for _, msg := range announcements {
+			if err := p.deliverMessage(c, msg); err != nil && err != errQuietHours {
+				p.writeDeliveryError(c, "grafana", err)
+				return
+			}
+		}
+		switch {
+		case len(remaining) > 0:
+		case len(announcements) > 0:
+			p.respondForwarded(c, "grafana")
+			return
+		case config.Grafana.AllClear && len(observation.flapping) == 0:
+			p.skipMessage(c, "grafana", skipFiltered, "Resolved alerts are summarised once all alerts of the group are resolved")
+			return
+		default:
+			p.skipMessage(c, "grafana", skipDuplicate, "Notifications of flapping alerts are suppressed")
+			return
+		}
+		grafanaMsg.Alerts = remaining
+	}
+
+	if len(grafanaMsg.Alerts) > 0 && config.Grafana.isDuplicate(group, grafanaMsg, grafanaMsg.Alerts, observation, now) {
+		p.skipMessage(c, "grafana", skipDuplicate, "Duplicate notification suppressed")
+		return
+	}
+
+	// Determine priority based on Grafana alert status
+	priority := config.Grafana.webhookPriority(grafanaMsg)
+	if profile.Priority > 0 {
+		priority = profile.Priority
+	}
+
+	// Name datasource problems clearly, otherwise use Grafana's title if
+	// available or construct one, counting the alerts by status
+	title := grafanaMsg.Title
+	datasourceTitle := datasourceWebhookTitle(grafanaMsg)
+	switch {
+	case datasourceTitle != "":
+		title = datasourceTitle
+		if resolved {
+			title = "[RESOLVED] " + title
+		}
+	case config.Grafana.SummaryTitle && len(grafanaMsg.Alerts) > 0:
+		title = summaryTitle(grafanaMsg)
+	}
+	if title == "" {
+		fallback := "Grafana Alert"
+		switch {
+		case len(grafanaMsg.Alerts) > 0:
+			fallback = summaryTitle(grafanaMsg)
+		case grafanaMsg.Status != "":
+			fallback = "Grafana Alert: " + grafanaMsg.Status
+		}
+		title = config.defaultTitle(profile, fallback)
+	}
+
+	// Render the alerts as markdown unless Grafana's own message text is
+	// preferred, falling back to Grafana's message if available
+	message := grafanaMsg.Message
+	markdown := len(grafanaMsg.Alerts) > 0 && !config.Grafana.rawMessage()
+	if markdown && config.Grafana.digests(len(grafanaMsg.Alerts)) {
+		message = config.grafanaDigestBody(grafanaMsg)
+	} else if markdown && config.Grafana.annotationsOnly() {
+		// Only alerts without annotations fall back to Grafana's message
+		if annotations := config.grafanaAlertsBody(grafanaMsg, config.grafanaAnnotationsBody); annotations != "" || message == "" {
+			message = annotations
+		} else {
+			markdown = false
+		}
+	} else if markdown {
+		message = config.grafanaAlertsBody(grafanaMsg, config.grafanaAlertBody)
+	}
+	if message == "" {
+		message = "Alert notification from Grafana"
+	}
+
+	title = config.withContext(config.commonContext(grafanaMsg.Alerts), title)
+
+	// Apply user templates, hiding filtered labels and annotations
+	title, message = config.renderProfileTemplates(profile, config.Labels.filterGrafanaLabels(grafanaMsg), title, message)
+	datasource := ""
+	if datasourceTitle != "" {
+		datasource = datasourceState(grafanaMsg.Alerts[0])
+	}
+	title = config.Grafana.withStatusEmoji(grafanaMsg.Status, datasource, title)
+
+	// Build extras with relevant Grafana data
+	extras := make(map[string]interface{})
+	extras["source"] = "grafana"
+	if grafanaMsg.Status != "" {
+		extras["status"] = grafanaMsg.Status
+	}
+	if grafanaMsg.State != "" {
+		extras["state"] = grafanaMsg.State
+	}
+	if grafanaMsg.Receiver != "" {
+		extras["receiver"] = grafanaMsg.Receiver
+	}
+	if grafanaMsg.GroupKey != "" {
+		extras["groupKey"] = grafanaMsg.GroupKey
+	}
+	if len(grafanaMsg.Alerts) > 0 {
+		alerts := make([]interface{}, 0, len(grafanaMsg.Alerts))
+		for _, alert := range grafanaMsg.Alerts {
+			alerts = append(alerts, grafanaMsg.alertCorrelation(alert))
+		}
+		extras["alerts"] = alerts
+	}
+	if datasourceTitle != "" {
+		extras["datasourceState"] = datasourceState(grafanaMsg.Alerts[0])
+	}
+	if externalURL, ok := rawBody["externalURL"].(string); ok && externalURL != "" {
+		extras["externalURL"] = externalURL
+	}
+	if dashboardURL, ok := rawBody["dashboardURL"].(string); ok && dashboardURL != "" {
+		extras["dashboardURL"] = dashboardURL
+	}
+	if silenceURL, ok := rawBody["silenceURL"].(string); ok && silenceURL != "" {
+		extras["silenceURL"] = silenceURL
+	}
+	if runbookURL := runbookURL(grafanaMsg.Alerts...); runbookURL != "" {
+		extras["runbookURL"] = runbookURL
+	}
+	if notification := config.Grafana.notificationExtras(grafanaMsg, grafanaMsg.Alerts...); notification != nil {
+		extras["client::notification"] = notification
+	}
+	if markdown {
+		extras["client::display"] = map[string]interface{}{"contentType": "text/markdown"}
+	}
+
+	// Forward message to Gotify user
+	if !p.sendMessage(c, "grafana", plugin.Message{
+		Title:    title,
+		Message:  message,
+		Priority: priority,
+		Extras:   extras,
+	}) {
+		return
+	}
+	if config.Grafana.tracksAlerts() {
+		p.recordNotified(grafanaMsg, grafanaMsg.Alerts, now)
+	}
+	p.respondForwarded(c, "grafana")
+}
+
+// Click targets for Grafana notifications.
+const (
+	clickDashboard = "dashboard"
+	clickPanel     = "panel"
+	clickSilence   = "silence"
+	clickAlert     = "alert"
+	clickNone      = "none"
+)
+
+// validate checks the Grafana options.
+func (g *GrafanaConfig) validate() error {
+	switch g.ClickTarget {
+	case "", clickDashboard, clickPanel, clickSilence, clickAlert, clickNone:
+	default:
+		return fmt.Errorf("invalid grafana.clickTarget %q, expected dashboard, panel, silence, alert or none", g.ClickTarget)
+	}
+	g.dedupWindow = 0
+	if g.DedupWindow != "" {
+		window, err := time.ParseDuration(g.DedupWindow)
+		if err != nil || window < 0 {
+			return fmt.Errorf("invalid grafana.dedupWindow %q, expected a duration such as 4h", g.DedupWindow)
+		}
+		g.dedupWindow = window
+	}
+	if g.DigestThreshold < 0 {
+		return fmt.Errorf("invalid grafana.digestThreshold %d, must not be negative", g.DigestThreshold)
+	}
+	if err := g.Flapping.validate(); err != nil {
+		return err
+	}
+	if err := g.Escalation.validate(); err != nil {
+		return err
+	}
+	if err := g.validateVerbosity(); err != nil {
+		return err
+	}
+	if err := g.validateContext(); err != nil {
+		return err
+	}
+	if err := g.Signature.validate(); err != nil {
+		return err
+	}
+	if err := g.validateDatasourcePriorities(); err != nil {
+		return err
+	}
+	if err := g.validateOrgs(); err != nil {
+		return err
+	}
+	for severity, priority := range g.SeverityPriorities {
+		if priority < 1 || priority > 10 {
+			return fmt.Errorf("invalid grafana.severityPriorities.%s: priority must be between 1 and 10", severity)
+		}
+	}
+	return nil
+}
+
+// clickURL returns the URL opened when a notification for the alerts is
+// clicked. The configured target of the first alert providing it is used,
+// falling back to the panel and dashboard URLs.
+func (g *GrafanaConfig) clickURL(webhook GrafanaWebhook, alerts ...GrafanaAlert) string {
+	if g.ClickTarget == clickNone {
+		return ""
+	}
+	targets := []string{g.ClickTarget, clickPanel, clickDashboard, clickAlert}
+	for _, target := range targets {
+		for _, alert := range alerts {
+			var url string
+			switch target {
+			case clickDashboard:
+				url = alert.DashboardURL
+			case clickPanel:
+				url = alert.PanelURL
+			case clickSilence:
+				url = alert.SilenceURL
+			case clickAlert:
+				url = alert.GeneratorURL
+			}
+			if url != "" {
+				return url
+			}
+		}
+		if target == clickAlert {
+			if url := webhook.alertingURL(); url != "" {
+				return url
+			}
+		}
+	}
+	return ""
+}
+
+// alertingURL links to the alert group of a notification in the Grafana
+// Alerting UI, built from the external URL and the group labels or the
+// matchers of the groupKey. It returns "" without an external URL.
+func (w GrafanaWebhook) alertingURL() string {
+	if w.ExternalURL == "" {
+		return ""
+	}
+	link := strings.TrimSuffix(w.ExternalURL, "/") + "/alerting/groups"
+
+	names := make([]string, 0, len(w.GroupLabels))
+	for name := range w.GroupLabels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	matchers := make([]string, 0, len(names))
+	for _, name := range names {
+		matchers = append(matchers, fmt.Sprintf("%s=%q", name, w.GroupLabels[name]))
+	}
+	query := strings.Join(matchers, ",")
+	if i := strings.LastIndex(w.GroupKey, ":{"); query == "" && i >= 0 && strings.HasSuffix(w.GroupKey, "}") {
+		query = w.GroupKey[i+2 : len(w.GroupKey)-1]
+	}
+	if query != "" {
+		link += "?queryString=" + url.QueryEscape(query)
+	}
+	return link
+}
+
+// tracksAlerts reports whether notified alerts need to be tracked.
+func (g *GrafanaConfig) tracksAlerts() bool {
+	return g.dedupWindow > 0 || g.StateChangesOnly || g.Flapping.Threshold > 0 || g.AllClear || g.Escalation.after > 0
+}
+
+// isDuplicate reports whether notifications for all alerts were already
+// sent with their current status within the dedup window, or in state
+// change mode since their status last changed.
+func (g *GrafanaConfig) isDuplicate(group *alertGroupState, webhook GrafanaWebhook, alerts []GrafanaAlert, observation alertObservation, now time.Time) bool {
+	for _, alert := range alerts {
+		fingerprint, status := alertFingerprint(alert), webhook.alertStatus(alert)
+		// In state change mode, alerts are only notified again after their
+		// status changed, even if the change itself was not forwarded
+		if g.StateChangesOnly && observation.repeated[fingerprint] && group.isDuplicate(fingerprint, status, now, alertStateRetention) {
+			continue
+		}
+		if !group.isDuplicate(fingerprint, status, now, g.dedupWindow) {
+			return false
+		}
+	}
+	return len(alerts) > 0
+}
+
+// notificationExtras returns the client::notification extras for the
+// alerts: the click action and the panel image rendered by Grafana, which
+// Android clients show inline. It returns nil if neither is available.
+func (g *GrafanaConfig) notificationExtras(webhook GrafanaWebhook, alerts ...GrafanaAlert) map[string]interface{} {
+	notification := map[string]interface{}{}
+	if click := g.clickURL(webhook, alerts...); click != "" {
+		notification["click"] = map[string]interface{}{"url": click}
+	}
+	for _, alert := range alerts {
+		if alert.ImageURL != "" {
+			notification["bigImageUrl"] = alert.ImageURL
+			break
+		}
+	}
+	if len(notification) == 0 {
+		return nil
+	}
+	return notification
+}
+
+// isGrafanaPayload reports whether the payload is a Grafana alert webhook,
+// either of unified alerting (alerts field) or of legacy alerting.
+func isGrafanaPayload(body map[string]interface{}) bool {
+	_, hasAlerts := body["alerts"]
+	return hasAlerts || isLegacyGrafanaPayload(body)
+}
+
+// isLegacyGrafanaPayload detects webhooks of Grafana's legacy alerting
+// (before Grafana 8), which describe a single rule.
+func isLegacyGrafanaPayload(body map[string]interface{}) bool {
+	if _, hasAlerts := body["alerts"]; hasAlerts || stringField(body, "ruleName") == "" {
+		return false
+	}
+	return hasAnyField(body, "evalMatches", "ruleUrl", "ruleId")
+}
+
+// legacyGrafanaWebhook converts a legacy alerting webhook into the unified
+// alerting format, with the rule as the single alert.
+func legacyGrafanaWebhook(body map[string]interface{}) GrafanaWebhook {
+	state := stringField(body, "state")
+	status := state
+	switch state {
+	case "alerting":
+		status = "firing"
+	case "ok":
+		status = "resolved"
+	}
+
+	labels := map[string]string{"alertname": stringField(body, "ruleName")}
+	if tags, ok := body["tags"].(map[string]interface{}); ok {
+		for name := range tags {
+			if value := stringField(tags, name); value != "" {
+				labels[name] = value
+			}
+		}
+	}
+	annotations := map[string]string{}
+	if message := stringField(body, "message"); message != "" {
+		annotations["summary"] = message
+	}
+
+	var values []string
+	for _, match := range mapSlice(body["evalMatches"]) {
+		tags, _ := match["tags"].(map[string]interface{})
+		names := make([]string, 0, len(tags))
+		for name := range tags {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		pairs := make([]string, 0, len(names))
+		for _, name := range names {
+			pairs = append(pairs, name+"="+stringField(tags, name))
+		}
+		value := stringField(match, "value")
+		if value == "" {
+			value = "null"
+		}
+		values = append(values, fmt.Sprintf("[ metric='%s' labels={%s} value=%s ]", stringField(match, "metric"), strings.Join(pairs, ", "), value))
+	}
+
+	ruleURL := stringField(body, "ruleUrl")
+	return GrafanaWebhook{
+		Status:  status,
+		State:   state,
+		Title:   stringField(body, "title"),
+		Message: stringField(body, "message"),
+		OrgId:   intField(body, "orgId"),
+		Alerts: []GrafanaAlert{{
+			Status:       status,
+			Labels:       labels,
+			Annotations:  annotations,
+			DashboardURL: ruleURL,
+			ValueString:  strings.Join(values, ", "),
+			ImageURL:     stringField(body, "imageUrl"),
+		}},
+	}
+}
+
+// severityPriority returns the priority configured for the severity label
+// of the alert, or 0 if the alert has no known severity.
+func (g *GrafanaConfig) severityPriority(alert GrafanaAlert) int {
+	if g.SeverityLabel == "" {
+		return 0
+	}
+	severity := strings.ToLower(alert.Labels[g.SeverityLabel])
+	for name, priority := range g.SeverityPriorities {
+		if strings.ToLower(name) == severity {
+			return priority
+		}
+	}
+	return 0
+}
+
+// alertPriority derives the priority of a single alert from its datasource
+// state or its severity while firing, falling back to its status.
+func (g *GrafanaConfig) alertPriority(alert GrafanaAlert, status string) int {
+	if status != "resolved" {
+		if priority := g.datasourcePriority(datasourceState(alert)); priority > 0 {
+			return priority
+		}
+	}
+	if status == "firing" {
+		if priority := g.severityPriority(alert); priority > 0 {
+			return priority
+		}
+	}
+	return grafanaPriority(status, "")
+}
+
+// webhookPriority derives the priority of a grouped notification from the
+// most severe firing alert or datasource problem, falling back to the
+// notification status.
+func (g *GrafanaConfig) webhookPriority(webhook GrafanaWebhook) int {
+	priority := 0
+	for _, alert := range webhook.Alerts {
+		status := webhook.alertStatus(alert)
+		if status == "resolved" {
+			continue
+		}
+		if p := g.datasourcePriority(datasourceState(alert)); p > priority {
+			priority = p
+		}
+		if p := g.severityPriority(alert); p > priority && status == "firing" {
+			priority = p
+		}
+	}
+	if priority > 0 {
+		return priority
+	}
+	return grafanaPriority(webhook.Status, webhook.State)
+}
+
+// grafanaPriority derives the message priority from a Grafana status or
+// legacy state.
+func grafanaPriority(status, state string) int {
+	switch {
+	case status == "firing" || state == "alerting":
+		return 8 // High priority for firing alerts
+	case status == "resolved" || state == "ok":
+		return 3 // Lower priority for resolved alerts
+	}
+	return 5
+}
+
+// forwardGrafanaAlerts sends one message per alert of a Grafana webhook and
+// writes a single response summarising the result.
+func (p *WebhookForwarderPlugin) forwardGrafanaAlerts(c *gin.Context, config *Config, profile *SourceConfig, webhook GrafanaWebhook, group *alertGroupState, observation alertObservation, now time.Time) {
+	var sent []GrafanaAlert
+	summaries, muted, duplicates := 0, 0, 0
+	defer func() {
+		if config.Grafana.tracksAlerts() {
+			p.recordNotified(webhook, sent, now)
+		}
+	}()
+
+	// The group summary replaces the individual resolved notifications
+	if observation.cleared > 0 {
+		switch err := p.deliverMessage(c, config.allClearMessage(webhook, observation.cleared)); err {
+		case nil:
+			summaries++
+		case errQuietHours:
+			muted++
+		default:
+			p.writeDeliveryError(c, "grafana", err)
+			return
+		}
+	}
+
+	for _, alert := range webhook.Alerts {
+		status := webhook.alertStatus(alert)
+		if status == "resolved" && (!config.Grafana.NotifyOnResolved || config.Grafana.AllClear) {
+			continue
+		}
+		msg := config.grafanaAlertMessage(profile, webhook, alert)
+		switch observation.flapping[alertFingerprint(alert)] {
+		case flapStarted:
+			msg = config.flappingMessage(webhook, []GrafanaAlert{alert})
+		case flapOngoing:
+			duplicates++
+			continue
+		default:
+			if config.Grafana.isDuplicate(group, webhook, []GrafanaAlert{alert}, observation, now) {
+				duplicates++
+				continue
+			}
+		}
+		err := p.deliverMessage(c, msg)
+		switch err {
+		case nil:
+			sent = append(sent, alert)
+		case errQuietHours:
+			muted++
+		default:
+			p.writeDeliveryError(c, "grafana", err)
+			return
+		}
+	}
+
+	switch {
+	case len(sent)+summaries > 0:
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": fmt.Sprintf("%d message(s) forwarded successfully", len(sent)+summaries),
+			"type":    "grafana",
+			"count":   len(sent) + summaries,
+		})
+	case muted > 0:
+		p.skipMessage(c, "grafana", skipMuted, "Messages dropped during quiet hours")
+	case duplicates > 0:
+		p.skipMessage(c, "grafana", skipDuplicate, "Duplicate notification suppressed")
+	default:
+		p.skipMessage(c, "grafana", skipFiltered, "Resolved alerts are not forwarded")
+	}
+}
+
+// grafanaAlertMessage builds the message for a single alert of a Grafana
+// webhook. Templates are rendered with the webhook reduced to that alert.
+func (c *Config) grafanaAlertMessage(profile *SourceConfig, webhook GrafanaWebhook, alert GrafanaAlert) plugin.Message {
+	status := webhook.alertStatus(alert)
+	priority := c.Grafana.alertPriority(alert, status)
+	if profile.Priority > 0 {
+		priority = profile.Priority
+	}
+
+	name := alertName(alert, c.defaultTitle(profile, "Grafana Alert"))
+	title := name
+	if status != "" {
+		title = fmt.Sprintf("[%s] %s", strings.ToUpper(status), name)
+	}
+
+	title = c.withContext(c.alertContext(alert), title)
+
+	single := webhook
+	single.Status = status
+	single.Alerts = []GrafanaAlert{alert}
+	body := c.grafanaAlertBody(alert)
+	if annotations := c.grafanaAnnotationsBody(alert); annotations != "" && c.Grafana.annotationsOnly() {
+		body = annotations
+	}
+	title, message := c.renderProfileTemplates(profile, c.Labels.filterGrafanaLabels(single), title, body)
+	title = c.Grafana.withStatusEmoji(status, datasourceState(alert), title)
+
+	extras := map[string]interface{}{
+		"source":          "grafana",
+		"client::display": map[string]interface{}{"contentType": "text/markdown"},
+	}
+	if status != "" {
+		extras["status"] = status
+	}
+	if webhook.Receiver != "" {
+		extras["receiver"] = webhook.Receiver
+	}
+	if webhook.GroupKey != "" {
+		extras["groupKey"] = webhook.GroupKey
+	}
+	for key, value := range webhook.alertCorrelation(alert) {
+		if key != "status" {
+			extras[key] = value
+		}
+	}
+	if alertname := alert.Labels["alertname"]; alertname != "" {
+		extras["alertname"] = alertname
+	}
+	if state := datasourceState(alert); state != "" {
+		extras["datasourceState"] = state
+	}
+	if notification := c.Grafana.notificationExtras(webhook, alert); notification != nil {
+		extras["client::notification"] = notification
+	}
+	for key, value := range map[string]string{
+		"externalURL":  webhook.ExternalURL,
+		"dashboardURL": alert.DashboardURL,
+		"panelURL":     alert.PanelURL,
+		"silenceURL":   alert.SilenceURL,
+		"generatorURL": alert.GeneratorURL,
+		"runbookURL":   runbookURL(alert),
+	} {
+		if value != "" {
+			extras[key] = value
+		}
+	}
+
+	return plugin.Message{
+		Title:    title,
+		Message:  message,
+		Priority: priority,
+		Extras:   extras,
+	}
+}
+
+// grafanaAlertsBody renders all alerts of a webhook as markdown with render,
+// each headed by its status and name when there is more than one. Alerts
+// rendered empty are left out.
+func (c *Config) grafanaAlertsBody(webhook GrafanaWebhook, render func(GrafanaAlert) string) string {
+	if len(webhook.Alerts) == 1 {
+		return render(webhook.Alerts[0])
+	}
+	sections := make([]string, 0, len(webhook.Alerts))
+	for _, alert := range webhook.Alerts {
+		body := render(alert)
+		if body == "" {
+			continue
+		}
+		heading := alertName(alert, "Alert")
+		if alert.Status != "" {
+			heading = fmt.Sprintf("[%s] %s", strings.ToUpper(alert.Status), heading)
+		}
+		sections = append(sections, "### "+heading+"\n"+body)
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+// grafanaAnnotations renders the summary and description annotations of an
+// alert followed by its log line, or "" if it has none of them.
+func (c *Config) grafanaAnnotations(alert GrafanaAlert) string {
+	var paragraphs []string
+	for _, key := range []string{"summary", "description"} {
+		if text := strings.TrimSpace(alert.Annotations[key]); text != "" && c.Labels.allows(key) {
+			paragraphs = append(paragraphs, text)
+		}
+	}
+	if block := c.logLineBlock(alert); block != "" {
+		paragraphs = append(paragraphs, block)
+	}
+	return strings.Join(paragraphs, "\n\n")
+}
+
+// grafanaAnnotationsBody renders the summary and description annotations
+// of an alert followed by its runbook link, or "" if it has none of them.
+func (c *Config) grafanaAnnotationsBody(alert GrafanaAlert) string {
+	paragraphs := []string{c.grafanaAnnotations(alert)}
+	if link := c.runbookLink(alert); link != "" {
+		paragraphs = append(paragraphs, link)
+	}
+	return strings.TrimSpace(strings.Join(paragraphs, "\n\n"))
+}
+
+// runbookURL returns the runbook_url annotation of the first alert that
+// has one.
+func runbookURL(alerts ...GrafanaAlert) string {
+	for _, alert := range alerts {
+		if url := strings.TrimSpace(alert.Annotations["runbook_url"]); url != "" {
+			return url
+		}
+	}
+	return ""
+}
+
+// runbookLink renders the runbook of an alert as a markdown link, or ""
+// if it has none or the annotation is hidden.
+func (c *Config) runbookLink(alert GrafanaAlert) string {
+	url := runbookURL(alert)
+	if url == "" || !c.Labels.allows("runbook_url") {
+		return ""
+	}
+	return fmt.Sprintf("[📖 Runbook](%s)", url)
+}
+
+// grafanaAlertBody renders a single alert as markdown: the summary and
+// description annotations first, followed by a list of labels, the value and
+// timestamps, links to the dashboard, panel and runbook, and for firing
+// alerts a link to silence the alert.
+func (c *Config) grafanaAlertBody(alert GrafanaAlert) string {
+	var paragraphs []string
+	context := c.alertContext(alert)
+	if context != "" && c.contextInMessage() {
+		paragraphs = append(paragraphs, "📁 "+context)
+	}
+	if annotations := c.grafanaAnnotations(alert); annotations != "" {
+		paragraphs = append(paragraphs, annotations)
+	}
+
+	var items []string
+	labels := c.Labels.filter(alert.Labels)
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		if context != "" && c.contextLabel(name) {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		items = append(items, fmt.Sprintf("- **%s**: %s", name, labels[name]))
+	}
+	if values := humanizeValueString(alert.ValueString); len(values) == 1 && values[0] != "" {
+		items = append(items, "- **Value**: "+values[0])
+	} else if len(values) > 1 {
+		items = append(items, "- **Values**:")
+		for _, value := range values {
+			items = append(items, "  - "+value)
+		}
+	}
+	if started := c.formatTimestamp(alert.StartsAt); started != "" {
+		items = append(items, "- **Started**: "+started)
+	}
+	if alert.Status == "resolved" {
+		if ended := c.formatTimestamp(alert.EndsAt); ended != "" {
+			if duration, ok := timestampDuration(alert.StartsAt, alert.EndsAt); ok {
+				ended += " (was firing for " + humanizeDuration(duration) + ")"
+			}
+			items = append(items, "- **Ended**: "+ended)
+		}
+	}
+	if len(items) > 0 {
+		paragraphs = append(paragraphs, strings.Join(items, "\n"))
+	}
+	if c.Grafana.detailed() {
+		if annotations := c.extraAnnotations(alert); annotations != "" {
+			paragraphs = append(paragraphs, annotations)
+		}
+	}
+
+	var links []string
+	for _, link := range []struct{ label, url string }{
+		{"Dashboard", alert.DashboardURL},
+		{"Panel", alert.PanelURL},
+	} {
+		if link.url != "" {
+			links = append(links, fmt.Sprintf("[%s](%s)", link.label, link.url))
+		}
+	}
+	if link := c.runbookLink(alert); link != "" {
+		links = append(links, link)
+	}
+	if alert.GeneratorURL != "" && c.Grafana.detailed() {
+		links = append(links, fmt.Sprintf("[Alert rule](%s)", alert.GeneratorURL))
+	}
+	if len(links) > 0 {
+		paragraphs = append(paragraphs, strings.Join(links, " | "))
+	}
+	// Let on-call users silence noisy alerts directly from the notification
+	if alert.SilenceURL != "" && alert.Status != "resolved" {
+		paragraphs = append(paragraphs, fmt.Sprintf("[🔕 Silence this alert](%s)", alert.SilenceURL))
+	}
+
+	if len(paragraphs) == 0 {
+		return "Alert notification from Grafana"
+	}
+	return strings.Join(paragraphs, "\n\n")
+}
@@ -0,0 +1,333 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gotify/plugin-api"
+	"github.com/stretchr/testify/assert"
+)
+
+// grafanaAlert builds an alert of a Grafana webhook notification, with the
+// labels given as name/value pairs.
+func grafanaAlert(status string, labels ...string) map[string]interface{} {
+	alertLabels := make(map[string]interface{}, len(labels)/2)
+	for i := 0; i+1 < len(labels); i += 2 {
+		alertLabels[labels[i]] = labels[i+1]
+	}
+	return map[string]interface{}{"status": status, "labels": alertLabels}
+}
+
+// grafanaNotification builds a Grafana webhook notification of alerts, which
+// is firing while any of its alerts is.
+func grafanaNotification(alerts ...map[string]interface{}) map[string]interface{} {
+	status := "resolved"
+	list := make([]interface{}, 0, len(alerts))
+	for _, alert := range alerts {
+		if alert["status"] == "firing" {
+			status = "firing"
+		}
+		list = append(list, alert)
+	}
+	return map[string]interface{}{"status": status, "alerts": list}
+}
+
+// notificationAlert returns the i-th alert of a Grafana webhook notification.
+func notificationAlert(payload map[string]interface{}, i int) map[string]interface{} {
+	return payload["alerts"].([]interface{})[i].(map[string]interface{})
+}
+
+func splitGrafanaPayload() map[string]interface{} {
+	cpu := grafanaAlert("firing", "alertname", "HighCPU", "instance", "web1")
+	cpu["annotations"] = map[string]interface{}{"summary": "CPU usage above 90%"}
+	cpu["startsAt"] = "2024-05-01T13:45:00Z"
+	cpu["endsAt"] = "0001-01-01T00:00:00Z"
+	cpu["dashboardURL"] = "https://grafana.example.com/d/abc"
+	cpu["silenceURL"] = "https://grafana.example.com/alerting/silence/new"
+	cpu["valueString"] = "[ var='A' labels={instance=web1} value=93.5 ]"
+
+	disk := grafanaAlert("resolved", "alertname", "DiskFull", "instance", "db1")
+	disk["annotations"] = map[string]interface{}{"summary": "Disk usage back to normal"}
+	disk["startsAt"] = "2024-05-01T12:00:00Z"
+	disk["endsAt"] = "2024-05-01T13:00:00Z"
+	disk["silenceURL"] = "https://grafana.example.com/alerting/silence/new?alertname=DiskFull"
+
+	payload := grafanaNotification(cpu, disk)
+	payload["title"] = "[FIRING:2] HighCPU"
+	payload["message"] = "grouped message"
+	payload["externalURL"] = "https://grafana.example.com/"
+	return payload
+}
+
+func TestWebhookForwarderPlugin_GrafanaSplitAlerts(t *testing.T) {
+	config := defaultConfig()
+	config.Grafana.SplitAlerts = true
+	config.Timezone = "UTC"
+	p, mockHandler := newTestPlugin(t, config)
+
+	w := postWebhook(p, splitGrafanaPayload())
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"count":2`)
+	assert.Len(t, mockHandler.sentMessages, 2)
+	assert.Equal(t, plugin.Message{
+		Title:    "[FIRING] HighCPU",
+		Message:  "CPU usage above 90%\n\n- **alertname**: HighCPU\n- **instance**: web1\n- **Value**: A = 93.5 (instance=web1)\n- **Started**: 2024-05-01 13:45:00 UTC\n\n[Dashboard](https://grafana.example.com/d/abc)\n\n[🔕 Silence this alert](https://grafana.example.com/alerting/silence/new)",
+		Priority: 8,
+		Extras: map[string]interface{}{
+			"source":          "grafana",
+			"status":          "firing",
+			"client::display": map[string]interface{}{"contentType": "text/markdown"},
+			"client::notification": map[string]interface{}{
+				"click": map[string]interface{}{"url": "https://grafana.example.com/d/abc"},
+			},
+			"alertname":    "HighCPU",
+			"fingerprint":  "88806d2231e6eb64",
+			"externalURL":  "https://grafana.example.com/",
+			"dashboardURL": "https://grafana.example.com/d/abc",
+			"silenceURL":   "https://grafana.example.com/alerting/silence/new",
+		},
+	}, mockHandler.sentMessages[0])
+	assert.Equal(t, "[RESOLVED] DiskFull", mockHandler.sentMessages[1].Title)
+	assert.Equal(t, 3, mockHandler.sentMessages[1].Priority)
+	assert.Contains(t, mockHandler.sentMessages[1].Message, "- **Ended**: 2024-05-01 13:00:00 UTC (was firing for 1h)")
+}
+
+func TestWebhookForwarderPlugin_GrafanaMarkdownBody(t *testing.T) {
+	config := defaultConfig()
+	config.Timezone = "UTC"
+	config.Labels.Exclude = []string{"instance"}
+	p, mockHandler := newTestPlugin(t, config)
+
+	postWebhook(p, splitGrafanaPayload())
+
+	assert.Len(t, mockHandler.sentMessages, 1)
+	msg := mockHandler.sentMessages[0]
+	assert.Equal(t, "[FIRING:2] HighCPU", msg.Title)
+	assert.Equal(t, "### [FIRING] HighCPU\n"+
+		"CPU usage above 90%\n\n"+
+		"- **alertname**: HighCPU\n"+
+		"- **Value**: A = 93.5 (instance=web1)\n"+
+		"- **Started**: 2024-05-01 13:45:00 UTC\n\n"+
+		"[Dashboard](https://grafana.example.com/d/abc)\n\n[🔕 Silence this alert](https://grafana.example.com/alerting/silence/new)\n\n"+
+		"### [RESOLVED] DiskFull\n"+
+		"Disk usage back to normal\n\n"+
+		"- **alertname**: DiskFull\n"+
+		"- **Started**: 2024-05-01 12:00:00 UTC\n"+
+		"- **Ended**: 2024-05-01 13:00:00 UTC (was firing for 1h)", msg.Message)
+	assert.Equal(t, map[string]interface{}{"contentType": "text/markdown"}, msg.Extras["client::display"])
+
+	// Grafana's own message text is kept if configured
+	config = defaultConfig()
+	config.Grafana.RawMessage = true
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+	postWebhook(p, splitGrafanaPayload())
+	assert.Equal(t, "grouped message", mockHandler.sentMessages[1].Message)
+	assert.NotContains(t, mockHandler.sentMessages[1].Extras, "client::display")
+}
+
+func TestWebhookForwarderPlugin_GrafanaSplitAlertsFiltering(t *testing.T) {
+	config := defaultConfig()
+	config.Grafana.SplitAlerts = true
+	config.Grafana.NotifyOnResolved = false
+	config.TitleTemplate = `{{ range .Alerts }}{{ .Labels.instance }}{{ end }}: {{ .Status }}`
+	p, mockHandler := newTestPlugin(t, config)
+
+	postWebhook(p, splitGrafanaPayload())
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, "web1: firing", mockHandler.sentMessages[0].Title)
+
+	// Every alert dropped by quiet hours
+	defer func() { timeNow = time.Now }()
+	timeNow = func() time.Time { return time.Date(2024, 5, 1, 23, 0, 0, 0, time.Local) }
+	config = defaultConfig()
+	config.Grafana.SplitAlerts = true
+	config.QuietHours = QuietHoursConfig{Start: "22:00", End: "07:00", MinPriority: 9, Action: "drop"}
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+
+	w := postWebhook(p, splitGrafanaPayload())
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"forwarded":false`)
+	assert.Len(t, mockHandler.sentMessages, 1)
+}
+
+func TestGrafanaConfig_ClickURL(t *testing.T) {
+	alerts := []GrafanaAlert{
+		{SilenceURL: "https://grafana/silence/1"},
+		{DashboardURL: "https://grafana/d/abc", PanelURL: "https://grafana/d/abc?viewPanel=2"},
+	}
+
+	assert.Equal(t, "https://grafana/d/abc", (&GrafanaConfig{ClickTarget: clickDashboard}).clickURL(GrafanaWebhook{}, alerts...))
+	assert.Equal(t, "https://grafana/d/abc?viewPanel=2", (&GrafanaConfig{ClickTarget: clickPanel}).clickURL(GrafanaWebhook{}, alerts...))
+	assert.Equal(t, "https://grafana/silence/1", (&GrafanaConfig{ClickTarget: clickSilence}).clickURL(GrafanaWebhook{}, alerts...))
+	assert.Equal(t, "", (&GrafanaConfig{ClickTarget: clickNone}).clickURL(GrafanaWebhook{}, alerts...))
+	// Falls back to the panel or dashboard
+	assert.Equal(t, "https://grafana/d/abc?viewPanel=2", (&GrafanaConfig{ClickTarget: clickSilence}).clickURL(GrafanaWebhook{}, alerts[1]))
+	assert.Equal(t, "", (&GrafanaConfig{}).clickURL(GrafanaWebhook{}))
+
+	// Links to the alert rule or the alert group in Grafana Alerting
+	webhook := GrafanaWebhook{ExternalURL: "https://grafana/", GroupLabels: map[string]string{"alertname": "HighCPU"}}
+	rule := GrafanaAlert{GeneratorURL: "https://grafana/alerting/grafana/abc/view"}
+	assert.Equal(t, "https://grafana/alerting/grafana/abc/view", (&GrafanaConfig{ClickTarget: clickAlert}).clickURL(webhook, rule, alerts[1]))
+	assert.Equal(t, "https://grafana/alerting/groups?queryString=alertname%3D%22HighCPU%22", (&GrafanaConfig{ClickTarget: clickAlert}).clickURL(webhook, alerts[1]))
+	assert.Equal(t, "https://grafana/alerting/groups?queryString=alertname%3D%22HighCPU%22", (&GrafanaConfig{ClickTarget: clickDashboard}).clickURL(webhook))
+
+	config := defaultConfig()
+	config.Grafana.ClickTarget = "home"
+	assert.Error(t, config.validate())
+}
+
+func TestGrafanaWebhook_AlertingURL(t *testing.T) {
+	assert.Equal(t, "", GrafanaWebhook{GroupKey: "{}:{}"}.alertingURL())
+	assert.Equal(t, "https://grafana/alerting/groups", GrafanaWebhook{ExternalURL: "https://grafana"}.alertingURL())
+	assert.Equal(t, "https://grafana/alerting/groups?queryString=alertname%3D%22HighCPU%22%2C+instance%3D%22web1%22",
+		GrafanaWebhook{ExternalURL: "https://grafana/", GroupKey: `{}/{__grafana_autogenerated__="true"}:{alertname="HighCPU", instance="web1"}`}.alertingURL())
+	assert.Equal(t, "https://grafana/alerting/groups?queryString=env%3D%22prod%22%2Cteam%3D%22db%22",
+		GrafanaWebhook{ExternalURL: "https://grafana/", GroupLabels: map[string]string{"team": "db", "env": "prod"}}.alertingURL())
+}
+
+func TestWebhookForwarderPlugin_GrafanaClickAction(t *testing.T) {
+	config := defaultConfig()
+	config.Grafana.ClickTarget = clickSilence
+	p, mockHandler := newTestPlugin(t, config)
+
+	postWebhook(p, splitGrafanaPayload())
+
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, map[string]interface{}{
+		"click": map[string]interface{}{"url": "https://grafana.example.com/alerting/silence/new"},
+	}, mockHandler.sentMessages[0].Extras["client::notification"])
+}
+
+func TestWebhookForwarderPlugin_LegacyGrafanaWebhook(t *testing.T) {
+	p, mockHandler := newTestPlugin(t, defaultConfig())
+
+	w := postWebhook(p, map[string]interface{}{
+		"dashboardId": 1,
+		"evalMatches": []interface{}{
+			map[string]interface{}{"value": 98.2, "metric": "cpu", "tags": map[string]interface{}{"host": "web1"}},
+			map[string]interface{}{"value": nil, "metric": "load", "tags": nil},
+		},
+		"message":  "CPU is too high",
+		"orgId":    1,
+		"panelId":  2,
+		"ruleId":   1,
+		"ruleName": "CPU alert",
+		"ruleUrl":  "http://grafana:3000/d/abc/test?tab=alert&panelId=2&orgId=1",
+		"state":    "alerting",
+		"tags":     map[string]interface{}{"team": "ops"},
+		"title":    "[Alerting] CPU alert",
+		"imageUrl": "https://grafana.example.com/render/cpu.png",
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	msg := mockHandler.sentMessages[0]
+	assert.Equal(t, "[Alerting] CPU alert", msg.Title)
+	assert.Equal(t, 8, msg.Priority)
+	assert.Equal(t, "CPU is too high\n\n"+
+		"- **alertname**: CPU alert\n"+
+		"- **team**: ops\n"+
+		"- **Values**:\n"+
+		"  - cpu = 98.2 (host=web1)\n"+
+		"  - load = null\n\n"+
+		"[Dashboard](http://grafana:3000/d/abc/test?tab=alert&panelId=2&orgId=1)", msg.Message)
+	assert.Equal(t, "grafana", msg.Extras["source"])
+	assert.Equal(t, "alerting", msg.Extras["state"])
+	assert.Equal(t, map[string]interface{}{
+		"click":       map[string]interface{}{"url": "http://grafana:3000/d/abc/test?tab=alert&panelId=2&orgId=1"},
+		"bigImageUrl": "https://grafana.example.com/render/cpu.png",
+	}, msg.Extras["client::notification"])
+
+	postWebhook(p, map[string]interface{}{"ruleName": "CPU alert", "ruleId": 1, "state": "ok", "title": "[OK] CPU alert"})
+	assert.Equal(t, 3, mockHandler.sentMessages[1].Priority)
+}
+
+func TestIsLegacyGrafanaPayload(t *testing.T) {
+	assert.True(t, isLegacyGrafanaPayload(map[string]interface{}{"ruleName": "x", "evalMatches": []interface{}{}}))
+	assert.False(t, isLegacyGrafanaPayload(map[string]interface{}{"ruleName": "x"}))
+	assert.False(t, isLegacyGrafanaPayload(map[string]interface{}{"ruleName": "x", "ruleId": 1, "alerts": []interface{}{}}))
+}
+
+func TestWebhookForwarderPlugin_GrafanaSeverityPriority(t *testing.T) {
+	p, mockHandler := newTestPlugin(t, defaultConfig())
+
+	payload := splitGrafanaPayload()
+	notificationAlert(payload, 0)["labels"].(map[string]interface{})["severity"] = "Warning"
+	notificationAlert(payload, 1)["labels"].(map[string]interface{})["severity"] = "critical"
+
+	// The resolved critical alert does not raise the priority
+	postWebhook(p, payload)
+	assert.Equal(t, 6, mockHandler.sentMessages[0].Priority)
+
+	config := defaultConfig()
+	config.Grafana.SplitAlerts = true
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+	postWebhook(p, payload)
+	assert.Len(t, mockHandler.sentMessages, 3)
+	assert.Equal(t, 6, mockHandler.sentMessages[1].Priority)
+	assert.Equal(t, 3, mockHandler.sentMessages[2].Priority)
+
+	// Unknown severities fall back to the status
+	notificationAlert(payload, 0)["labels"].(map[string]interface{})["severity"] = "page"
+	postWebhook(p, payload)
+	assert.Equal(t, 8, mockHandler.sentMessages[3].Priority)
+
+	config = defaultConfig()
+	config.Grafana.SeverityPriorities["page"] = 11
+	assert.Error(t, p.ValidateAndSetConfig(config))
+}
+
+func TestGrafanaConfig_NotificationExtras(t *testing.T) {
+	g := &GrafanaConfig{ClickTarget: clickNone}
+	assert.Nil(t, g.notificationExtras(GrafanaWebhook{}, GrafanaAlert{DashboardURL: "https://grafana/d/abc"}))
+	assert.Equal(t, map[string]interface{}{"bigImageUrl": "https://grafana/render/2.png"},
+		g.notificationExtras(GrafanaWebhook{}, GrafanaAlert{}, GrafanaAlert{ImageURL: "https://grafana/render/2.png"}))
+}
+
+func TestWebhookForwarderPlugin_GrafanaAnnotationsOnly(t *testing.T) {
+	config := defaultConfig()
+	config.Grafana.AnnotationsOnly = true
+	p, mockHandler := newTestPlugin(t, config)
+
+	payload := splitGrafanaPayload()
+	postWebhook(p, payload)
+	msg := mockHandler.sentMessages[0]
+	assert.Equal(t, "### [FIRING] HighCPU\nCPU usage above 90%\n\n### [RESOLVED] DiskFull\nDisk usage back to normal", msg.Message)
+	assert.Equal(t, map[string]interface{}{"contentType": "text/markdown"}, msg.Extras["client::display"])
+
+	// Without annotations Grafana's message is forwarded
+	delete(notificationAlert(payload, 0), "annotations")
+	delete(notificationAlert(payload, 1), "annotations")
+	postWebhook(p, payload)
+	msg = mockHandler.sentMessages[1]
+	assert.Equal(t, "grouped message", msg.Message)
+	assert.NotContains(t, msg.Extras, "client::display")
+
+	config.Grafana.SplitAlerts = true
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+	postWebhook(p, splitGrafanaPayload())
+	assert.Equal(t, "CPU usage above 90%", mockHandler.sentMessages[2].Message)
+}
+
+func TestWebhookForwarderPlugin_GrafanaRunbookLink(t *testing.T) {
+	p, mockHandler := newTestPlugin(t, defaultConfig())
+
+	payload := splitGrafanaPayload()
+	notificationAlert(payload, 0)["annotations"].(map[string]interface{})["runbook_url"] = "https://wiki.example.com/runbooks/cpu"
+	postWebhook(p, payload)
+	msg := mockHandler.sentMessages[0]
+	assert.Contains(t, msg.Message, "[Dashboard](https://grafana.example.com/d/abc) | [📖 Runbook](https://wiki.example.com/runbooks/cpu)")
+	assert.Equal(t, "https://wiki.example.com/runbooks/cpu", msg.Extras["runbookURL"])
+
+	config := defaultConfig()
+	config.Grafana.SplitAlerts = true
+	config.Grafana.AnnotationsOnly = true
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+	postWebhook(p, payload)
+	msg = mockHandler.sentMessages[1]
+	assert.Equal(t, "CPU usage above 90%\n\n[📖 Runbook](https://wiki.example.com/runbooks/cpu)", msg.Message)
+	assert.Equal(t, "https://wiki.example.com/runbooks/cpu", msg.Extras["runbookURL"])
+	assert.NotContains(t, mockHandler.sentMessages[2].Extras, "runbookURL")
+}
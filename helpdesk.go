@@ -0,0 +1,219 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gotify/plugin-api"
+)
+
+// zammadPriorities maps Zammad's default ticket priorities to Gotify priorities.
+var zammadPriorities = map[string]int{
+	"1 low":    3,
+	"2 normal": 5,
+	"3 high":   8,
+}
+
+// freshdeskPriorities maps Freshdesk ticket priorities to Gotify priorities.
+var freshdeskPriorities = map[string]int{
+	"low":    3,
+	"medium": 5,
+	"high":   7,
+	"urgent": 9,
+}
+
+// slaBreachPriority is used for tickets that breached their SLA.
+const slaBreachPriority = 9
+
+// isZammadPayload detects the default payload of Zammad trigger webhooks.
+func isZammadPayload(body map[string]interface{}) bool {
+	ticket, ok := body["ticket"].(map[string]interface{})
+	return ok && stringField(ticket, "number") != "" && hasAnyField(ticket, "state", "state_id", "priority", "priority_id")
+}
+
+// formatZammadPayload renders a Zammad ticket notification. Escalated
+// tickets are reported as SLA breaches.
+func formatZammadPayload(body map[string]interface{}, _ *Config) plugin.Message {
+	ticket, _ := body["ticket"].(map[string]interface{})
+	number := stringField(ticket, "number")
+	subject := stringField(ticket, "title")
+	priorityName := namedField(ticket, "priority")
+	state := namedField(ticket, "state")
+
+	priority, ok := zammadPriorities[strings.ToLower(priorityName)]
+	if !ok {
+		priority = 5
+	}
+	title := fmt.Sprintf("Zammad: Ticket #%s", number)
+	breached := zammadEscalated(ticket)
+	if breached {
+		priority = slaBreachPriority
+		title = fmt.Sprintf("Zammad: SLA breached for ticket #%s", number)
+	}
+	if subject != "" {
+		title += " - " + subject
+	}
+
+	var lines []string
+	if article, ok := body["article"].(map[string]interface{}); ok {
+		if text := strings.TrimSpace(stringField(article, "body")); text != "" && stringField(article, "content_type") != "text/html" {
+			lines = append(lines, text, "")
+		}
+	}
+	if state != "" {
+		lines = append(lines, fmt.Sprintf("State: %s", state))
+	}
+	if priorityName != "" {
+		lines = append(lines, fmt.Sprintf("Priority: %s", priorityName))
+	}
+	if group := namedField(ticket, "group"); group != "" {
+		lines = append(lines, fmt.Sprintf("Group: %s", group))
+	}
+	if customer := zammadUser(ticket["customer"]); customer != "" {
+		lines = append(lines, fmt.Sprintf("Customer: %s", customer))
+	}
+	if owner := zammadUser(ticket["owner"]); owner != "" && owner != "-" {
+		lines = append(lines, fmt.Sprintf("Owner: %s", owner))
+	}
+	if breached {
+		lines = append(lines, fmt.Sprintf("Escalated at: %s", stringField(ticket, "escalation_at")))
+	}
+
+	extras := map[string]interface{}{"source": "zammad", "ticket": number}
+	if state != "" {
+		extras["state"] = state
+	}
+	if breached {
+		extras["slaBreached"] = true
+	}
+
+	return plugin.Message{
+		Title:    title,
+		Message:  strings.TrimSpace(strings.Join(lines, "\n")),
+		Priority: priority,
+		Extras:   extras,
+	}
+}
+
+// zammadEscalated reports whether the ticket's escalation time has passed.
+func zammadEscalated(ticket map[string]interface{}) bool {
+	escalation, err := time.Parse(time.RFC3339Nano, stringField(ticket, "escalation_at"))
+	return err == nil && !escalation.After(timeNow())
+}
+
+// zammadUser returns the name of a user object of the Zammad payload.
+func zammadUser(value interface{}) string {
+	switch user := value.(type) {
+	case string:
+		return user
+	case map[string]interface{}:
+		name := strings.TrimSpace(stringField(user, "firstname") + " " + stringField(user, "lastname"))
+		if name == "" {
+			name = stringField(user, "login", "email")
+		}
+		return name
+	}
+	return ""
+}
+
+// isFreshdeskPayload detects Freshdesk automation webhooks, either in the
+// "simple" format wrapped in freshdesk_webhook or as custom JSON with
+// ticket_* placeholders.
+func isFreshdeskPayload(body map[string]interface{}) bool {
+	if _, ok := body["freshdesk_webhook"].(map[string]interface{}); ok {
+		return true
+	}
+	return hasFields(body, "ticket_id", "ticket_subject")
+}
+
+// formatFreshdeskPayload renders a Freshdesk ticket notification.
+func formatFreshdeskPayload(body map[string]interface{}, _ *Config) plugin.Message {
+	if wrapped, ok := body["freshdesk_webhook"].(map[string]interface{}); ok {
+		body = wrapped
+	}
+	id := stringField(body, "ticket_id")
+	subject := stringField(body, "ticket_subject")
+	status := stringField(body, "ticket_status")
+	priorityName := stringField(body, "ticket_priority")
+	event := strings.ToLower(stringField(body, "triggered_event", "event"))
+
+	priority, ok := freshdeskPriorities[strings.ToLower(priorityName)]
+	if !ok {
+		priority = 5
+	}
+
+	title := fmt.Sprintf("Freshdesk: Ticket #%s", id)
+	breached := strings.Contains(event, "sla") || strings.Contains(event, "overdue") || strings.EqualFold(status, "overdue")
+	switch {
+	case breached:
+		priority = slaBreachPriority
+		title = fmt.Sprintf("Freshdesk: SLA breached for ticket #%s", id)
+	case strings.Contains(event, "created"):
+		title = fmt.Sprintf("Freshdesk: New ticket #%s", id)
+	case strings.Contains(event, "priority"):
+		title = fmt.Sprintf("Freshdesk: Priority of ticket #%s changed", id)
+	}
+	if subject != "" {
+		title += " - " + subject
+	}
+
+	var lines []string
+	if text := strings.TrimSpace(stringField(body, "ticket_description_text", "ticket_latest_public_comment")); text != "" {
+		lines = append(lines, text, "")
+	}
+	if status != "" {
+		lines = append(lines, fmt.Sprintf("Status: %s", status))
+	}
+	if priorityName != "" {
+		lines = append(lines, fmt.Sprintf("Priority: %s", priorityName))
+	}
+	if requester := stringField(body, "ticket_requester_name", "ticket_contact_name"); requester != "" {
+		lines = append(lines, fmt.Sprintf("Requester: %s", requester))
+	}
+	if agent := stringField(body, "ticket_agent_name"); agent != "" {
+		lines = append(lines, fmt.Sprintf("Agent: %s", agent))
+	}
+	if due := stringField(body, "ticket_due_by_time"); due != "" {
+		lines = append(lines, fmt.Sprintf("Due by: %s", due))
+	}
+
+	extras := map[string]interface{}{"source": "freshdesk", "ticket": id}
+	if status != "" {
+		extras["status"] = status
+	}
+	if breached {
+		extras["slaBreached"] = true
+	}
+	if url := stringField(body, "ticket_url", "ticket_portal_url"); url != "" {
+		extras["client::notification"] = map[string]interface{}{
+			"click": map[string]interface{}{"url": url},
+		}
+	}
+
+	return plugin.Message{
+		Title:    title,
+		Message:  strings.TrimSpace(strings.Join(lines, "\n")),
+		Priority: priority,
+		Extras:   extras,
+	}
+}
+
+// namedField returns a string field, or the name of an expanded object as
+// used by Zammad for associations such as the priority or state.
+func namedField(body map[string]interface{}, key string) string {
+	if object, ok := body[key].(map[string]interface{}); ok {
+		return stringField(object, "name")
+	}
+	return stringField(body, key)
+}
+
+// hasAnyField reports whether at least one of keys is present in the payload.
+func hasAnyField(body map[string]interface{}, keys ...string) bool {
+	for _, key := range keys {
+		if _, ok := body[key]; ok {
+			return true
+		}
+	}
+	return false
+}
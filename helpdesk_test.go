@@ -0,0 +1,104 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gotify/plugin-api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_ZammadWebhook(t *testing.T) {
+	defer func() { timeNow = time.Now }()
+	timeNow = func() time.Time { return time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC) }
+
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	ticket := map[string]interface{}{
+		"id":            42,
+		"number":        "31042",
+		"title":         "Shop checkout broken",
+		"state":         "new",
+		"priority":      map[string]interface{}{"name": "3 high"},
+		"group":         "Support",
+		"customer":      map[string]interface{}{"firstname": "Jane", "lastname": "Doe"},
+		"escalation_at": "2024-05-01T14:00:00Z",
+	}
+	w := postWebhook(p, map[string]interface{}{
+		"ticket":  ticket,
+		"article": map[string]interface{}{"body": "Payments fail with error 500", "content_type": "text/plain"},
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title:    "Zammad: Ticket #31042 - Shop checkout broken",
+		Message:  "Payments fail with error 500\n\nState: new\nPriority: 3 high\nGroup: Support\nCustomer: Jane Doe",
+		Priority: 8,
+		Extras:   map[string]interface{}{"source": "zammad", "ticket": "31042", "state": "new"},
+	}, mockHandler.sentMessages[0])
+
+	// Escalated tickets are SLA breaches
+	ticket["escalation_at"] = "2024-05-01T11:00:00Z"
+	ticket["priority"] = "1 low"
+	postWebhook(p, map[string]interface{}{"ticket": ticket})
+	assert.Len(t, mockHandler.sentMessages, 2)
+	assert.Equal(t, "Zammad: SLA breached for ticket #31042 - Shop checkout broken", mockHandler.sentMessages[1].Title)
+	assert.Equal(t, 9, mockHandler.sentMessages[1].Priority)
+	assert.Equal(t, true, mockHandler.sentMessages[1].Extras["slaBreached"])
+}
+
+func TestWebhookForwarderPlugin_FreshdeskWebhook(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	w := postWebhook(p, map[string]interface{}{
+		"freshdesk_webhook": map[string]interface{}{
+			"ticket_id":             "1234",
+			"ticket_subject":        "Cannot log in",
+			"ticket_status":         "Open",
+			"ticket_priority":       "Urgent",
+			"ticket_requester_name": "John Smith",
+			"ticket_url":            "https://acme.freshdesk.com/helpdesk/tickets/1234",
+			"triggered_event":       "{ticket_action:created}",
+		},
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title:    "Freshdesk: New ticket #1234 - Cannot log in",
+		Message:  "Status: Open\nPriority: Urgent\nRequester: John Smith",
+		Priority: 9,
+		Extras: map[string]interface{}{
+			"source": "freshdesk",
+			"ticket": "1234",
+			"status": "Open",
+			"client::notification": map[string]interface{}{
+				"click": map[string]interface{}{"url": "https://acme.freshdesk.com/helpdesk/tickets/1234"},
+			},
+		},
+	}, mockHandler.sentMessages[0])
+}
+
+func TestFormatFreshdeskPayload_Events(t *testing.T) {
+	msg := formatFreshdeskPayload(map[string]interface{}{
+		"ticket_id":       "7",
+		"ticket_subject":  "Refund",
+		"ticket_priority": "Low",
+		"triggered_event": "{ticket_action:sla_violated}",
+	}, defaultConfig())
+	assert.Equal(t, "Freshdesk: SLA breached for ticket #7 - Refund", msg.Title)
+	assert.Equal(t, 9, msg.Priority)
+
+	msg = formatFreshdeskPayload(map[string]interface{}{
+		"ticket_id":       "7",
+		"ticket_subject":  "Refund",
+		"ticket_priority": "High",
+		"triggered_event": "{priority:from Low to High}",
+	}, defaultConfig())
+	assert.Equal(t, "Freshdesk: Priority of ticket #7 changed - Refund", msg.Title)
+	assert.Equal(t, 7, msg.Priority)
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/gotify/plugin-api"
+)
+
+// isHomeAssistantPayload detects notifications of Home Assistant's RESTful
+// notify platform: a message with the service call's data block or targets.
+func isHomeAssistantPayload(body map[string]interface{}) bool {
+	if stringField(body, "message") == "" || hasAnyField(body, "alerts", "extras") {
+		return false
+	}
+	if sourceIs(body, "homeassistant") || sourceIs(body, "home-assistant") {
+		return true
+	}
+	_, ok := body["data"].(map[string]interface{})
+	return ok || hasAnyField(body, "target")
+}
+
+// formatHomeAssistantPayload renders a Home Assistant notification. The data
+// block sets the priority, click URL, image and markdown rendering; keys of
+// Gotify extras (e.g. "client::display") are passed through and the
+// remaining data is kept in the "data" extra for clients.
+func formatHomeAssistantPayload(body map[string]interface{}, _ *Config) plugin.Message {
+	title := stringField(body, "title")
+	if title == "" {
+		title = "Home Assistant"
+	}
+	data, _ := body["data"].(map[string]interface{})
+
+	extras := map[string]interface{}{"source": "homeassistant"}
+	if target := body["target"]; target != nil {
+		extras["target"] = target
+	}
+	notification := map[string]interface{}{}
+	if url := stringField(data, "url", "clickAction", "click_url"); url != "" {
+		notification["click"] = map[string]interface{}{"url": url}
+	}
+	if image := stringField(data, "image", "image_url"); image != "" {
+		notification["bigImageUrl"] = image
+	}
+	if len(notification) > 0 {
+		extras["client::notification"] = notification
+	}
+	if markdown, _ := data["markdown"].(bool); markdown {
+		extras["client::display"] = map[string]interface{}{"contentType": "text/markdown"}
+	}
+
+	passthrough := make(map[string]interface{})
+	rest := make(map[string]interface{})
+	for key, value := range data {
+		if strings.Contains(key, "::") {
+			passthrough[key] = value
+		} else {
+			rest[key] = value
+		}
+	}
+	if len(rest) > 0 {
+		extras["data"] = rest
+	}
+
+	return plugin.Message{
+		Title:    title,
+		Message:  stringField(body, "message"),
+		Priority: homeAssistantPriority(data),
+		Extras:   mergeExtras(extras, passthrough),
+	}
+}
+
+// homeAssistantPriority reads the priority from the data block, either a
+// Gotify priority (1-10) or a name like "high" as used by the companion
+// apps. It defaults to 5.
+func homeAssistantPriority(data map[string]interface{}) int {
+	if priority := intField(data, "priority"); priority >= 1 && priority <= 10 {
+		return priority
+	}
+	if priority, ok := ntfyPriorities[strings.ToLower(stringField(data, "priority", "importance"))]; ok {
+		return priority
+	}
+	return 5
+}
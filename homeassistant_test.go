@@ -0,0 +1,62 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gotify/plugin-api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_HomeAssistantWebhook(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	w := postWebhook(p, map[string]interface{}{
+		"message": "The front door was opened",
+		"title":   "Front door",
+		"target":  []interface{}{"phone"},
+		"data": map[string]interface{}{
+			"priority": "high",
+			"url":      "https://ha.example.com/lovelace/doors",
+			"image":    "https://ha.example.com/api/camera_proxy/camera.front",
+			"tag":      "front-door",
+			"client::display": map[string]interface{}{
+				"contentType": "text/markdown",
+			},
+		},
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title:    "Front door",
+		Message:  "The front door was opened",
+		Priority: 8,
+		Extras: map[string]interface{}{
+			"source": "homeassistant",
+			"target": []interface{}{"phone"},
+			"data": map[string]interface{}{
+				"priority": "high",
+				"url":      "https://ha.example.com/lovelace/doors",
+				"image":    "https://ha.example.com/api/camera_proxy/camera.front",
+				"tag":      "front-door",
+			},
+			"client::notification": map[string]interface{}{
+				"click":       map[string]interface{}{"url": "https://ha.example.com/lovelace/doors"},
+				"bigImageUrl": "https://ha.example.com/api/camera_proxy/camera.front",
+			},
+			"client::display": map[string]interface{}{"contentType": "text/markdown"},
+		},
+	}, mockHandler.sentMessages[0])
+
+	msg := formatHomeAssistantPayload(map[string]interface{}{
+		"message": "Washing machine finished",
+		"data":    map[string]interface{}{"priority": 3.0},
+	}, defaultConfig())
+	assert.Equal(t, "Home Assistant", msg.Title)
+	assert.Equal(t, 3, msg.Priority)
+
+	// Generic messages without a data block are not affected
+	assert.False(t, isHomeAssistantPayload(map[string]interface{}{"message": "Hello", "title": "Test"}))
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gotify/plugin-api"
+)
+
+// icingaStatePriorities maps Icinga2/Nagios host and service states to
+// priorities for problem notifications.
+var icingaStatePriorities = map[string]int{
+	"DOWN":        9,
+	"CRITICAL":    8,
+	"UNREACHABLE": 7,
+	"WARNING":     6,
+	"UNKNOWN":     5,
+	"UP":          3,
+	"OK":          3,
+}
+
+// isIcingaPayload detects host and service notifications of Icinga2 webhook
+// notification scripts, using either Icinga2's attribute names (host_name,
+// service_state, ...) or the Nagios macro names (HOSTNAME, SERVICESTATE, ...).
+func isIcingaPayload(body map[string]interface{}) bool {
+	if sourceIs(body, "icinga") || sourceIs(body, "icinga2") || sourceIs(body, "nagios") {
+		return true
+	}
+	return hasAnyField(body, "host_name", "HOSTNAME") &&
+		hasAnyField(body, "host_state", "service_state", "HOSTSTATE", "SERVICESTATE")
+}
+
+// formatIcingaPayload renders an Icinga2/Nagios problem, recovery or other
+// notification with the host and service in the title and the check output
+// as message.
+func formatIcingaPayload(body map[string]interface{}, _ *Config) plugin.Message {
+	host := stringField(body, "host_display_name", "host_name", "HOSTALIAS", "HOSTNAME")
+	service := stringField(body, "service_display_name", "service_name", "SERVICEDESC")
+	notificationType := strings.ToUpper(stringField(body, "notification_type", "NOTIFICATIONTYPE"))
+
+	state := strings.ToUpper(stringField(body, "host_state", "HOSTSTATE"))
+	output := stringField(body, "host_output", "HOSTOUTPUT")
+	subject := host
+	if service != "" {
+		state = strings.ToUpper(stringField(body, "service_state", "SERVICESTATE"))
+		output = stringField(body, "service_output", "SERVICEOUTPUT")
+		subject = service + " on " + host
+	}
+	if subject == "" {
+		subject = "Icinga check"
+	}
+
+	title := subject
+	if state != "" {
+		title += " is " + state
+	}
+	if notificationType != "" {
+		title = icingaNotificationType(notificationType) + ": " + title
+	}
+
+	var paragraphs []string
+	if text := strings.TrimSpace(output); text != "" {
+		paragraphs = append(paragraphs, text)
+	}
+	var lines []string
+	if address := stringField(body, "host_address", "HOSTADDRESS"); address != "" {
+		lines = append(lines, fmt.Sprintf("Address: %s", address))
+	}
+	if date := stringField(body, "long_date_time", "LONGDATETIME"); date != "" {
+		lines = append(lines, fmt.Sprintf("Time: %s", date))
+	}
+	if len(lines) > 0 {
+		paragraphs = append(paragraphs, strings.Join(lines, "\n"))
+	}
+	if comment := strings.TrimSpace(stringField(body, "notification_comment", "NOTIFICATIONCOMMENT")); comment != "" {
+		if author := stringField(body, "notification_author", "NOTIFICATIONAUTHOR"); author != "" {
+			comment = author + ": " + comment
+		}
+		paragraphs = append(paragraphs, "💬 "+comment)
+	}
+	message := strings.Join(paragraphs, "\n\n")
+	if message == "" {
+		message = title
+	}
+
+	extras := map[string]interface{}{"source": "icinga"}
+	for key, value := range map[string]string{
+		"host":             host,
+		"service":          service,
+		"state":            state,
+		"notificationType": notificationType,
+	} {
+		if value != "" {
+			extras[key] = value
+		}
+	}
+	if url := stringField(body, "icingaweb2_url", "url"); url != "" {
+		extras["client::notification"] = map[string]interface{}{
+			"click": map[string]interface{}{"url": url},
+		}
+	}
+
+	return plugin.Message{
+		Title:    title,
+		Message:  message,
+		Priority: icingaPriority(notificationType, state),
+		Extras:   extras,
+	}
+}
+
+// icingaNotificationType returns a readable name for a notification type,
+// e.g. "Downtime start" for DOWNTIMESTART.
+func icingaNotificationType(notificationType string) string {
+	switch notificationType {
+	case "FLAPPINGSTART":
+		return "Flapping start"
+	case "FLAPPINGEND":
+		return "Flapping end"
+	case "FLAPPINGDISABLED":
+		return "Flapping disabled"
+	case "DOWNTIMESTART":
+		return "Downtime start"
+	case "DOWNTIMEEND":
+		return "Downtime end"
+	case "DOWNTIMECANCELLED", "DOWNTIMEREMOVED":
+		return "Downtime cancelled"
+	}
+	return capitalize(strings.ToLower(notificationType))
+}
+
+// icingaPriority derives the priority from the state for problem
+// notifications. Recoveries get priority 3, other notifications such as
+// acknowledgements and downtimes 4.
+func icingaPriority(notificationType, state string) int {
+	switch notificationType {
+	case "", "PROBLEM", "CUSTOM":
+		if priority, ok := icingaStatePriorities[state]; ok {
+			return priority
+		}
+		return 5
+	case "RECOVERY":
+		return 3
+	}
+	return 4
+}
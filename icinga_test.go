@@ -0,0 +1,73 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gotify/plugin-api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_IcingaWebhook(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	w := postWebhook(p, map[string]interface{}{
+		"notification_type":    "PROBLEM",
+		"host_name":            "web1",
+		"host_address":         "10.0.0.5",
+		"service_name":         "http",
+		"service_display_name": "HTTP",
+		"service_state":        "CRITICAL",
+		"service_output":       "HTTP CRITICAL - Socket timeout after 10 seconds",
+		"long_date_time":       "2024-05-01 13:45:00 +0000",
+		"icingaweb2_url":       "https://icinga.example.com/icingaweb2",
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title:    "Problem: HTTP on web1 is CRITICAL",
+		Message:  "HTTP CRITICAL - Socket timeout after 10 seconds\n\nAddress: 10.0.0.5\nTime: 2024-05-01 13:45:00 +0000",
+		Priority: 8,
+		Extras: map[string]interface{}{
+			"source":           "icinga",
+			"host":             "web1",
+			"service":          "HTTP",
+			"state":            "CRITICAL",
+			"notificationType": "PROBLEM",
+			"client::notification": map[string]interface{}{
+				"click": map[string]interface{}{"url": "https://icinga.example.com/icingaweb2"},
+			},
+		},
+	}, mockHandler.sentMessages[0])
+}
+
+func TestFormatIcingaPayload_Nagios(t *testing.T) {
+	body := map[string]interface{}{
+		"NOTIFICATIONTYPE": "PROBLEM",
+		"HOSTNAME":         "db1",
+		"HOSTSTATE":        "DOWN",
+		"HOSTOUTPUT":       "PING CRITICAL - Packet loss = 100%",
+	}
+	assert.True(t, isIcingaPayload(body))
+	msg := formatIcingaPayload(body, defaultConfig())
+	assert.Equal(t, "Problem: db1 is DOWN", msg.Title)
+	assert.Equal(t, "PING CRITICAL - Packet loss = 100%", msg.Message)
+	assert.Equal(t, 9, msg.Priority)
+
+	body["NOTIFICATIONTYPE"] = "RECOVERY"
+	body["HOSTSTATE"] = "UP"
+	assert.Equal(t, 3, formatIcingaPayload(body, defaultConfig()).Priority)
+
+	body["NOTIFICATIONTYPE"] = "ACKNOWLEDGEMENT"
+	body["NOTIFICATIONAUTHOR"] = "alice"
+	body["NOTIFICATIONCOMMENT"] = "Looking into it"
+	msg = formatIcingaPayload(body, defaultConfig())
+	assert.Equal(t, "Acknowledgement: db1 is UP", msg.Title)
+	assert.Contains(t, msg.Message, "💬 alice: Looking into it")
+	assert.Equal(t, 4, msg.Priority)
+
+	body["NOTIFICATIONTYPE"] = "DOWNTIMESTART"
+	assert.Equal(t, "Downtime start: db1 is UP", formatIcingaPayload(body, defaultConfig()).Title)
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gotify/plugin-api"
+)
+
+// IFTTTConfig maps the fields of IFTTT/Zapier payloads to the message.
+type IFTTTConfig struct {
+	TitleField    string `yaml:"titleField"`
+	MessageField  string `yaml:"messageField"`
+	PriorityField string `yaml:"priorityField"`
+}
+
+// iftttMapping returns the configured field mapping, falling back to the
+// value1-value3 fields of IFTTT Webhooks.
+func iftttMapping(config *Config) IFTTTConfig {
+	mapping := IFTTTConfig{TitleField: "value1", MessageField: "value2", PriorityField: "value3"}
+	if config != nil {
+		if config.IFTTT.TitleField != "" {
+			mapping.TitleField = config.IFTTT.TitleField
+		}
+		if config.IFTTT.MessageField != "" {
+			mapping.MessageField = config.IFTTT.MessageField
+		}
+		if config.IFTTT.PriorityField != "" {
+			mapping.PriorityField = config.IFTTT.PriorityField
+		}
+	}
+	return mapping
+}
+
+// isIFTTTPayload detects IFTTT Webhooks style payloads with value1-value3
+// fields or the fields of the configured mapping that are not generic
+// webhooks.
+func isIFTTTPayload(body map[string]interface{}, config *Config) bool {
+	if _, ok := body["message"]; ok {
+		return false
+	}
+	mapping := iftttMapping(config)
+	for _, key := range []string{"value1", "value2", "value3", mapping.TitleField, mapping.MessageField, mapping.PriorityField} {
+		if _, ok := body[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// formatIFTTTPayload maps the configured fields to title, message and
+// priority. Values of any JSON type are accepted.
+func formatIFTTTPayload(body map[string]interface{}, config *Config) plugin.Message {
+	mapping := iftttMapping(config)
+
+	title := looseString(body[mapping.TitleField])
+	if title == "" {
+		title = "IFTTT"
+	}
+
+	message := looseString(body[mapping.MessageField])
+	if message == "" {
+		// Fall back to listing all fields so nothing is lost
+		var lines []string
+		for _, key := range []string{"value1", "value2", "value3"} {
+			if value := looseString(body[key]); value != "" && key != mapping.TitleField {
+				lines = append(lines, value)
+			}
+		}
+		message = strings.Join(lines, "\n")
+	}
+	if message == "" {
+		message = "Triggered"
+	}
+
+	priority := intField(body, mapping.PriorityField)
+	if priority <= 0 || priority > 10 {
+		priority = 5
+	}
+
+	return plugin.Message{
+		Title:    title,
+		Message:  message,
+		Priority: priority,
+		Extras:   map[string]interface{}{"source": "ifttt"},
+	}
+}
+
+// looseString converts a loosely typed JSON value into text.
+func looseString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return strings.TrimSpace(fmt.Sprint(v))
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gotify/plugin-api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_IFTTTWebhook(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	postWebhook(p, map[string]interface{}{"value1": "Doorbell", "value2": 42, "value3": "8"})
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title:    "Doorbell",
+		Message:  "42",
+		Priority: 8,
+		Extras:   map[string]interface{}{"source": "ifttt"},
+	}, mockHandler.sentMessages[0])
+
+	config := defaultConfig()
+	config.IFTTT = IFTTTConfig{TitleField: "value2", MessageField: "value1", PriorityField: "level"}
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+	postWebhook(p, map[string]interface{}{"value1": "Front door opened", "value2": "Home", "level": 9.0})
+	assert.Len(t, mockHandler.sentMessages, 2)
+	assert.Equal(t, "Home", mockHandler.sentMessages[1].Title)
+	assert.Equal(t, "Front door opened", mockHandler.sentMessages[1].Message)
+	assert.Equal(t, 9, mockHandler.sentMessages[1].Priority)
+}
+
+func TestWebhookForwarderPlugin_IFTTTCustomFields(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+	config := defaultConfig()
+	config.IFTTT = IFTTTConfig{TitleField: "subject", MessageField: "body", PriorityField: "urgency"}
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+
+	w := postWebhook(p, map[string]interface{}{"subject": "New lead", "body": "Jane Doe signed up", "urgency": "7"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title:    "New lead",
+		Message:  "Jane Doe signed up",
+		Priority: 7,
+		Extras:   map[string]interface{}{"source": "ifttt"},
+	}, mockHandler.sentMessages[0])
+}
+
+func TestWebhookForwarderPlugin_NonJSONBodies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+	router := gin.New()
+	router.POST("/message", p.handleWebhookMessage)
+
+	send := func(contentType, body string) int {
+		req := httptest.NewRequest("POST", "/message", strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	form := url.Values{"title": {"Zap"}, "message": {"From Zapier"}, "priority": {"7"}}
+	assert.Equal(t, http.StatusOK, send("application/x-www-form-urlencoded", form.Encode()))
+	assert.Equal(t, http.StatusOK, send("text/plain", `{"value1":"IFTTT","value2":"text/plain JSON"}`))
+	assert.Equal(t, http.StatusOK, send("text/plain; charset=utf-8", "just some text"))
+	assert.Equal(t, http.StatusOK, send("application/json; charset=utf-8", `{"message":"with charset"}`))
+	assert.Equal(t, http.StatusBadRequest, send("application/xml", "<message/>"))
+
+	assert.Len(t, mockHandler.sentMessages, 4)
+	assert.Equal(t, "Zap", mockHandler.sentMessages[0].Title)
+	assert.Equal(t, 7, mockHandler.sentMessages[0].Priority)
+	assert.Equal(t, "text/plain JSON", mockHandler.sentMessages[1].Message)
+	assert.Equal(t, "just some text", mockHandler.sentMessages[2].Message)
+	assert.Equal(t, "with charset", mockHandler.sentMessages[3].Message)
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gotify/plugin-api"
+)
+
+// incidentSeverityPriorities maps Grafana Incident severities to priorities.
+var incidentSeverityPriorities = map[string]int{
+	"critical": 10,
+	"major":    8,
+	"minor":    6,
+	"pending":  5,
+}
+
+// isIncidentPayload detects Grafana Incident outgoing webhooks.
+func isIncidentPayload(body map[string]interface{}) bool {
+	_, hasIncident := body["incident"].(map[string]interface{})
+	return hasIncident && strings.HasPrefix(stringField(body, "event"), "grafana.incident.")
+}
+
+// formatIncidentPayload renders a Grafana Incident lifecycle event with the
+// incident title, severity and commander. Relative incident links are
+// completed with grafana.url.
+func formatIncidentPayload(body map[string]interface{}, config *Config) plugin.Message {
+	incident, _ := body["incident"].(map[string]interface{})
+	event := strings.TrimPrefix(stringField(body, "event"), "grafana.incident.")
+	status := strings.ToLower(stringField(incident, "status"))
+	severity := strings.ToLower(stringField(incident, "severity"))
+
+	priority, known := incidentSeverityPriorities[severity]
+	if !known {
+		priority = 8
+	}
+	heading := "Incident updated"
+	switch {
+	case status == "resolved" || event == "closed" || event == "deleted":
+		heading, priority = "Incident resolved", 3
+	case event == "created":
+		heading = "Incident declared"
+	case event == "updated.severity":
+		heading = "Incident severity changed"
+	case event == "updated.status":
+		heading = "Incident status changed"
+	case event == "updated.role":
+		heading = "Incident roles changed"
+	}
+	if incident["isDrill"] == true {
+		heading = "[Drill] " + heading
+	}
+
+	incidentTitle := stringField(incident, "title")
+	if incidentTitle == "" {
+		incidentTitle = "Incident " + stringField(incident, "incidentID")
+	}
+
+	var lines []string
+	if summary := stringField(incident, "summary"); summary != "" {
+		lines = append(lines, summary, "")
+	}
+	if severity != "" {
+		lines = append(lines, "Severity: "+stringField(incident, "severity"))
+	}
+	if status != "" {
+		lines = append(lines, "Status: "+stringField(incident, "status"))
+	}
+	commander := incidentCommander(incident)
+	if commander != "" {
+		lines = append(lines, "Commander: "+commander)
+	}
+	if user, ok := incident["createdByUser"].(map[string]interface{}); ok && event == "created" {
+		if name := stringField(user, "name"); name != "" {
+			lines = append(lines, "Declared by: "+name)
+		}
+	}
+
+	extras := map[string]interface{}{
+		"source":   "grafana-incident",
+		"event":    event,
+		"incident": stringField(incident, "incidentID"),
+	}
+	if severity != "" {
+		extras["severity"] = severity
+	}
+	if commander != "" {
+		extras["commander"] = commander
+	}
+	if link := config.Grafana.absoluteURL(stringField(incident, "overviewURL")); link != "" {
+		extras["client::notification"] = map[string]interface{}{
+			"click": map[string]interface{}{"url": link},
+		}
+	}
+
+	return plugin.Message{
+		Title:    fmt.Sprintf("%s: %s", heading, incidentTitle),
+		Message:  strings.Join(lines, "\n"),
+		Priority: priority,
+		Extras:   extras,
+	}
+}
+
+// incidentCommander returns the name of the user assigned the commander
+// role of an incident.
+func incidentCommander(incident map[string]interface{}) string {
+	membership, _ := incident["incidentMembership"].(map[string]interface{})
+	for _, assignment := range mapSlice(membership["assignments"]) {
+		role := stringField(assignment, "roleName", "role")
+		if details, ok := assignment["role"].(map[string]interface{}); ok {
+			role = stringField(details, "name")
+		}
+		if !strings.EqualFold(role, "commander") {
+			continue
+		}
+		if user, ok := assignment["user"].(map[string]interface{}); ok {
+			return stringField(user, "name", "login", "email")
+		}
+	}
+	return ""
+}
+
+// absoluteURL completes a link relative to Grafana with grafana.url. It
+// returns "" for relative links if no URL is configured.
+func (g *GrafanaConfig) absoluteURL(link string) string {
+	switch {
+	case link == "":
+		return ""
+	case strings.HasPrefix(link, "http://"), strings.HasPrefix(link, "https://"):
+		return link
+	case g.URL == "":
+		return ""
+	}
+	return strings.TrimSuffix(g.URL, "/") + "/" + strings.TrimPrefix(link, "/")
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gotify/plugin-api"
+	"github.com/stretchr/testify/assert"
+)
+
+func incidentPayload(event string) map[string]interface{} {
+	return map[string]interface{}{
+		"version": "v1.0.0",
+		"event":   event,
+		"incident": map[string]interface{}{
+			"incidentID":  "42",
+			"title":       "Checkout is down",
+			"severity":    "Critical",
+			"status":      "active",
+			"overviewURL": "/a/grafana-incident-app/incidents/42/checkout-is-down",
+			"createdByUser": map[string]interface{}{
+				"name": "Alice",
+			},
+			"incidentMembership": map[string]interface{}{
+				"assignments": []interface{}{
+					map[string]interface{}{"roleName": "investigator", "user": map[string]interface{}{"name": "Bob"}},
+					map[string]interface{}{"roleName": "commander", "user": map[string]interface{}{"name": "Carol"}},
+				},
+			},
+		},
+	}
+}
+
+func TestWebhookForwarderPlugin_GrafanaIncidentWebhook(t *testing.T) {
+	config := defaultConfig()
+	config.Grafana.URL = "https://grafana.example.com/"
+	p, mockHandler := newTestPlugin(t, config)
+
+	w := postWebhook(p, incidentPayload("grafana.incident.created"))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, plugin.Message{
+		Title:    "Incident declared: Checkout is down",
+		Message:  "Severity: Critical\nStatus: active\nCommander: Carol\nDeclared by: Alice",
+		Priority: 10,
+		Extras: map[string]interface{}{
+			"source":    "grafana-incident",
+			"event":     "created",
+			"incident":  "42",
+			"severity":  "critical",
+			"commander": "Carol",
+			"client::notification": map[string]interface{}{
+				"click": map[string]interface{}{"url": "https://grafana.example.com/a/grafana-incident-app/incidents/42/checkout-is-down"},
+			},
+		},
+	}, mockHandler.sentMessages[0])
+}
+
+func TestFormatIncidentPayload_Events(t *testing.T) {
+	config := defaultConfig()
+
+	payload := incidentPayload("grafana.incident.updated.severity")
+	payload["incident"].(map[string]interface{})["severity"] = "minor"
+	msg := formatIncidentPayload(payload, config)
+	assert.Equal(t, "Incident severity changed: Checkout is down", msg.Title)
+	assert.Equal(t, 6, msg.Priority)
+	// Relative links need grafana.url
+	assert.NotContains(t, msg.Extras, "client::notification")
+
+	payload = incidentPayload("grafana.incident.updated.status")
+	payload["incident"].(map[string]interface{})["status"] = "resolved"
+	payload["incident"].(map[string]interface{})["isDrill"] = true
+	msg = formatIncidentPayload(payload, config)
+	assert.Equal(t, "[Drill] Incident resolved: Checkout is down", msg.Title)
+	assert.Equal(t, 3, msg.Priority)
+}
+
+func TestIsIncidentPayload(t *testing.T) {
+	assert.True(t, isIncidentPayload(incidentPayload("grafana.incident.created")))
+	assert.False(t, isIncidentPayload(map[string]interface{}{"event": "grafana.incident.created"}))
+	assert.False(t, isIncidentPayload(map[string]interface{}{"event": "created", "incident": map[string]interface{}{}}))
+}
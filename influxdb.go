@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gotify/plugin-api"
+)
+
+// influxLevelPriorities maps InfluxDB check levels and Kapacitor alert
+// levels to priorities.
+var influxLevelPriorities = map[string]int{
+	"crit":     9,
+	"critical": 9,
+	"warn":     6,
+	"warning":  6,
+	"info":     4,
+	"ok":       3,
+}
+
+// isInfluxDBPayload detects notifications of InfluxDB 2.x HTTP notification
+// endpoints, which describe a check status with underscore-prefixed fields.
+func isInfluxDBPayload(body map[string]interface{}) bool {
+	return hasAnyField(body, "_check_name", "_check_id") && hasAnyField(body, "_level", "_message")
+}
+
+// formatInfluxDBPayload renders an InfluxDB check status with the check name
+// and level in the title and the check message as body.
+func formatInfluxDBPayload(body map[string]interface{}, config *Config) plugin.Message {
+	name := stringField(body, "_check_name", "_check_id")
+	level := strings.ToLower(stringField(body, "_level"))
+
+	title := "InfluxDB: " + name
+	if level != "" {
+		title = fmt.Sprintf("[%s] %s", strings.ToUpper(level), name)
+	}
+
+	var paragraphs, lines []string
+	if text := strings.TrimSpace(stringField(body, "_message")); text != "" {
+		paragraphs = append(paragraphs, text)
+	}
+	if measurement := stringField(body, "_source_measurement"); measurement != "" {
+		lines = append(lines, fmt.Sprintf("Measurement: %s", measurement))
+	}
+	if when := config.formatTimestamp(stringField(body, "_time")); when != "" {
+		lines = append(lines, fmt.Sprintf("Time: %s", when))
+	}
+	if rule := stringField(body, "_notification_rule_name"); rule != "" {
+		lines = append(lines, fmt.Sprintf("Notification rule: %s", rule))
+	}
+	if len(lines) > 0 {
+		paragraphs = append(paragraphs, strings.Join(lines, "\n"))
+	}
+
+	// Columns without the underscore prefix are the tags and fields of the
+	// checked series
+	var tags []string
+	for key := range body {
+		if !strings.HasPrefix(key, "_") {
+			if value := stringField(body, key); value != "" {
+				tags = append(tags, fmt.Sprintf("%s=%s", key, value))
+			}
+		}
+	}
+	if len(tags) > 0 {
+		sort.Strings(tags)
+		paragraphs = append(paragraphs, "Tags: "+strings.Join(tags, ", "))
+	}
+	message := strings.Join(paragraphs, "\n\n")
+	if message == "" {
+		message = title
+	}
+
+	extras := map[string]interface{}{"source": "influxdb"}
+	for key, value := range map[string]string{
+		"checkId":   stringField(body, "_check_id"),
+		"checkName": stringField(body, "_check_name"),
+		"level":     level,
+	} {
+		if value != "" {
+			extras[key] = value
+		}
+	}
+
+	return plugin.Message{
+		Title:    title,
+		Message:  message,
+		Priority: influxPriority(level),
+		Extras:   extras,
+	}
+}
+
+// isKapacitorPayload detects alerts of Kapacitor's HTTP POST alert handler.
+func isKapacitorPayload(body map[string]interface{}) bool {
+	if !hasFields(body, "id", "level", "data") {
+		return false
+	}
+	switch stringField(body, "level") {
+	case "OK", "INFO", "WARNING", "CRITICAL":
+		return true
+	}
+	return false
+}
+
+// formatKapacitorPayload renders a Kapacitor alert with the alert ID and
+// level in the title. Recovered alerts show how long the alert lasted.
+func formatKapacitorPayload(body map[string]interface{}, config *Config) plugin.Message {
+	id := stringField(body, "id")
+	level := strings.ToLower(stringField(body, "level"))
+	title := fmt.Sprintf("[%s] %s", strings.ToUpper(level), id)
+
+	var paragraphs, lines []string
+	if text := strings.TrimSpace(stringField(body, "message")); text != "" {
+		paragraphs = append(paragraphs, text)
+	}
+	if previous := stringField(body, "previousLevel"); previous != "" {
+		lines = append(lines, fmt.Sprintf("Previous level: %s", previous))
+	}
+	if duration := intField(body, "duration"); duration > 0 && level == "ok" {
+		lines = append(lines, fmt.Sprintf("Duration: %s", humanizeDuration(time.Duration(duration))))
+	}
+	if when := config.formatTimestamp(stringField(body, "time")); when != "" {
+		lines = append(lines, fmt.Sprintf("Time: %s", when))
+	}
+	if data, ok := body["data"].(map[string]interface{}); ok {
+		for _, series := range mapSlice(data["series"]) {
+			tags, _ := series["tags"].(map[string]interface{})
+			pairs := make([]string, 0, len(tags))
+			for key := range tags {
+				pairs = append(pairs, fmt.Sprintf("%s=%s", key, stringField(tags, key)))
+			}
+			sort.Strings(pairs)
+			line := "Series: " + stringField(series, "name")
+			if len(pairs) > 0 {
+				line += " (" + strings.Join(pairs, ", ") + ")"
+			}
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) > 0 {
+		paragraphs = append(paragraphs, strings.Join(lines, "\n"))
+	}
+	if details := strings.TrimSpace(stringField(body, "details")); details != "" && details != stringField(body, "message") {
+		paragraphs = append(paragraphs, details)
+	}
+	message := strings.Join(paragraphs, "\n\n")
+	if message == "" {
+		message = title
+	}
+
+	extras := map[string]interface{}{
+		"source":  "kapacitor",
+		"alertId": id,
+		"level":   level,
+	}
+
+	return plugin.Message{
+		Title:    title,
+		Message:  message,
+		Priority: influxPriority(level),
+		Extras:   extras,
+	}
+}
+
+// influxPriority maps a check or alert level to a priority, 5 for unknown
+// levels.
+func influxPriority(level string) int {
+	if priority, ok := influxLevelPriorities[level]; ok {
+		return priority
+	}
+	return 5
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gotify/plugin-api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_InfluxDBWebhook(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+	config := defaultConfig()
+	config.Timezone = "UTC"
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+
+	w := postWebhook(p, map[string]interface{}{
+		"_check_id":               "0a1b2c3d4e5f6789",
+		"_check_name":             "CPU check",
+		"_level":                  "crit",
+		"_message":                "Check: CPU check is: crit",
+		"_notification_rule_name": "Critical to Gotify",
+		"_source_measurement":     "cpu",
+		"_time":                   "2024-05-01T13:45:00Z",
+		"_type":                   "threshold",
+		"host":                    "web1",
+		"usage_idle":              3.5,
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title:    "[CRIT] CPU check",
+		Message:  "Check: CPU check is: crit\n\nMeasurement: cpu\nTime: 2024-05-01 13:45:00 UTC\nNotification rule: Critical to Gotify\n\nTags: host=web1, usage_idle=3.5",
+		Priority: 9,
+		Extras: map[string]interface{}{
+			"source":    "influxdb",
+			"checkId":   "0a1b2c3d4e5f6789",
+			"checkName": "CPU check",
+			"level":     "crit",
+		},
+	}, mockHandler.sentMessages[0])
+}
+
+func TestWebhookForwarderPlugin_KapacitorWebhook(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	postWebhook(p, map[string]interface{}{
+		"id":            "cpu:host=web1",
+		"message":       "cpu:host=web1 is OK",
+		"details":       "",
+		"time":          "2024-05-01T13:45:00Z",
+		"duration":      1_800_000_000_000.0,
+		"level":         "OK",
+		"previousLevel": "CRITICAL",
+		"data": map[string]interface{}{
+			"series": []interface{}{
+				map[string]interface{}{"name": "cpu", "tags": map[string]interface{}{"host": "web1"}},
+			},
+		},
+	})
+
+	assert.Len(t, mockHandler.sentMessages, 1)
+	msg := mockHandler.sentMessages[0]
+	assert.Equal(t, "[OK] cpu:host=web1", msg.Title)
+	assert.Equal(t, 3, msg.Priority)
+	assert.Contains(t, msg.Message, "Previous level: CRITICAL\nDuration: 30m")
+	assert.Contains(t, msg.Message, "Series: cpu (host=web1)")
+	assert.Equal(t, "kapacitor", msg.Extras["source"])
+
+	assert.False(t, isKapacitorPayload(map[string]interface{}{"id": "x", "level": "high", "data": map[string]interface{}{}}))
+	assert.Equal(t, 6, influxPriority("warn"))
+	assert.Equal(t, 5, influxPriority(""))
+}
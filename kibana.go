@@ -0,0 +1,206 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gotify/plugin-api"
+)
+
+// kibanaSeverityPriorities maps severities of Elastic rules (Security rule
+// severities and common action group names) to priorities.
+var kibanaSeverityPriorities = map[string]int{
+	"critical": 10,
+	"high":     8,
+	"medium":   6,
+	"warning":  6,
+	"low":      4,
+}
+
+// isKibanaPayload detects Kibana alerting webhook connector payloads built
+// from the rule, context and alert action variables, and Elasticsearch
+// Watcher webhook actions identified by their watch_id.
+func isKibanaPayload(body map[string]interface{}) bool {
+	if sourceIs(body, "kibana") || sourceIs(body, "elastic") {
+		return true
+	}
+	if rule, ok := body["rule"].(map[string]interface{}); ok && stringField(rule, "name") != "" {
+		return hasAnyField(body, "context", "alert", "alerts")
+	}
+	return stringField(body, "watch_id") != "" && hasAnyField(body, "ctx", "payload")
+}
+
+// formatKibanaPayload renders a Kibana rule or Watcher alert with the rule
+// name as title and the context reason or message as body. The severity or
+// action group sets the priority.
+func formatKibanaPayload(body map[string]interface{}, _ *Config) plugin.Message {
+	rule, _ := body["rule"].(map[string]interface{})
+	alert, _ := body["alert"].(map[string]interface{})
+	context, _ := body["context"].(map[string]interface{})
+	if ctx, ok := body["ctx"].(map[string]interface{}); ok && context == nil {
+		context = ctx
+	}
+
+	name := stringField(rule, "name")
+	if name == "" {
+		name = stringField(body, "rule_name", "ruleName", "watch_id")
+	}
+	if name == "" {
+		name = "Elastic alert"
+	}
+	actionGroup := stringField(alert, "actionGroupName", "actionGroup")
+	if actionGroup == "" {
+		actionGroup = stringField(body, "actionGroup", "action_group")
+	}
+	recovered := strings.EqualFold(actionGroup, "recovered")
+
+	title := "Kibana: " + name
+	if stringField(body, "watch_id") != "" && rule == nil {
+		title = "Watcher: " + name
+	}
+	if recovered {
+		title = "Recovered: " + title
+	}
+
+	var paragraphs []string
+	if text := strings.TrimSpace(stringField(context, "reason", "message")); text != "" {
+		paragraphs = append(paragraphs, text)
+	} else if text := strings.TrimSpace(stringField(body, "message", "reason")); text != "" {
+		paragraphs = append(paragraphs, text)
+	}
+	var lines []string
+	for _, field := range []struct{ label, key string }{
+		{"Value", "value"},
+		{"Conditions", "conditions"},
+		{"Hits", "hits"},
+	} {
+		if value := stringField(context, field.key); value != "" {
+			lines = append(lines, fmt.Sprintf("%s: %s", field.label, value))
+		}
+	}
+	if payload, ok := context["payload"].(map[string]interface{}); ok {
+		if hits, ok := payload["hits"].(map[string]interface{}); ok {
+			if total := kibanaHitsTotal(hits["total"]); total != "" {
+				lines = append(lines, fmt.Sprintf("Hits: %s", total))
+			}
+		}
+	}
+	if actionGroup != "" {
+		lines = append(lines, fmt.Sprintf("Action group: %s", actionGroup))
+	}
+	if id := stringField(alert, "id"); id != "" {
+		lines = append(lines, fmt.Sprintf("Alert: %s", id))
+	}
+	if tags := joinStrings(rule["tags"]); tags != "" {
+		lines = append(lines, fmt.Sprintf("Tags: %s", tags))
+	}
+	if len(lines) > 0 {
+		paragraphs = append(paragraphs, strings.Join(lines, "\n"))
+	}
+	if alerts := kibanaAlerts(body["alerts"]); alerts != "" {
+		paragraphs = append(paragraphs, alerts)
+	}
+	message := strings.Join(paragraphs, "\n\n")
+	if message == "" {
+		message = title
+	}
+
+	extras := map[string]interface{}{"source": "kibana"}
+	for key, value := range map[string]string{
+		"ruleId":      stringField(rule, "id"),
+		"ruleName":    stringField(rule, "name"),
+		"watchId":     stringField(body, "watch_id"),
+		"actionGroup": actionGroup,
+		"spaceId":     stringField(rule, "spaceId"),
+	} {
+		if value != "" {
+			extras[key] = value
+		}
+	}
+	link := stringField(context, "link", "url")
+	if link == "" {
+		link = stringField(rule, "url")
+	}
+	if link == "" {
+		link = stringField(body, "kibanaBaseUrl", "url")
+	}
+	if link != "" {
+		extras["client::notification"] = map[string]interface{}{
+			"click": map[string]interface{}{"url": link},
+		}
+	}
+
+	return plugin.Message{
+		Title:    title,
+		Message:  message,
+		Priority: kibanaPriority(body, context, actionGroup),
+		Extras:   extras,
+	}
+}
+
+// kibanaPriority derives the priority from a severity field of the payload
+// or context, falling back to the action group. Recovered alerts get
+// priority 3, other alerts 8.
+func kibanaPriority(body, context map[string]interface{}, actionGroup string) int {
+	if strings.EqualFold(actionGroup, "recovered") {
+		return 3
+	}
+	severity := strings.ToLower(stringField(context, "severity"))
+	if severity == "" {
+		severity = strings.ToLower(stringField(body, "severity"))
+	}
+	if priority, ok := kibanaSeverityPriorities[severity]; ok {
+		return priority
+	}
+	if priority, ok := kibanaSeverityPriorities[strings.ToLower(actionGroup)]; ok {
+		return priority
+	}
+	return 8
+}
+
+// kibanaHitsTotal returns the number of hits of a search result, which is
+// a number or an object with a value in Elasticsearch 7 and later.
+func kibanaHitsTotal(total interface{}) string {
+	switch v := total.(type) {
+	case map[string]interface{}:
+		return stringField(v, "value")
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return ""
+}
+
+// kibanaAlerts lists the alerts of a summary action, given as a list of
+// alert objects or as counts of new, ongoing and recovered alerts.
+func kibanaAlerts(value interface{}) string {
+	if counts, ok := value.(map[string]interface{}); ok {
+		var parts []string
+		for _, group := range []string{"new", "ongoing", "recovered"} {
+			if object, ok := counts[group].(map[string]interface{}); ok {
+				if count := intField(object, "count"); count > 0 {
+					parts = append(parts, fmt.Sprintf("%d %s", count, group))
+				}
+			}
+		}
+		if len(parts) == 0 {
+			return ""
+		}
+		return "Alerts: " + strings.Join(parts, ", ")
+	}
+	var lines []string
+	for _, alert := range mapSlice(value) {
+		text := stringField(alert, "reason", "message")
+		if id := stringField(alert, "id", "_id"); id != "" {
+			if text != "" {
+				text = id + ": " + text
+			} else {
+				text = id
+			}
+		}
+		if text != "" {
+			lines = append(lines, "- "+text)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
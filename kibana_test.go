@@ -0,0 +1,88 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gotify/plugin-api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_KibanaWebhook(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	w := postWebhook(p, map[string]interface{}{
+		"rule": map[string]interface{}{
+			"id":      "7f1c2a",
+			"name":    "High error rate",
+			"tags":    []interface{}{"prod", "api"},
+			"spaceId": "default",
+			"url":     "https://kibana.example.com/app/observability/alerts/rules/7f1c2a",
+		},
+		"alert": map[string]interface{}{"id": "api-gateway", "actionGroup": "threshold met"},
+		"context": map[string]interface{}{
+			"reason": "Document count is 1523 in the last 5m for api-gateway. Alert when > 1000.",
+			"value":  "1523",
+		},
+		"date": "2024-05-01T13:45:00.000Z",
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title:    "Kibana: High error rate",
+		Message:  "Document count is 1523 in the last 5m for api-gateway. Alert when > 1000.\n\nValue: 1523\nAction group: threshold met\nAlert: api-gateway\nTags: prod, api",
+		Priority: 8,
+		Extras: map[string]interface{}{
+			"source":      "kibana",
+			"ruleId":      "7f1c2a",
+			"ruleName":    "High error rate",
+			"actionGroup": "threshold met",
+			"spaceId":     "default",
+			"client::notification": map[string]interface{}{
+				"click": map[string]interface{}{"url": "https://kibana.example.com/app/observability/alerts/rules/7f1c2a"},
+			},
+		},
+	}, mockHandler.sentMessages[0])
+}
+
+func TestFormatKibanaPayload(t *testing.T) {
+	// Recovered alerts
+	body := map[string]interface{}{
+		"rule":  map[string]interface{}{"name": "Disk usage"},
+		"alert": map[string]interface{}{"actionGroup": "recovered"},
+	}
+	assert.True(t, isKibanaPayload(body))
+	msg := formatKibanaPayload(body, defaultConfig())
+	assert.Equal(t, "Recovered: Kibana: Disk usage", msg.Title)
+	assert.Equal(t, 3, msg.Priority)
+
+	// Security rule severity and summary alerts
+	body = map[string]interface{}{
+		"rule":    map[string]interface{}{"name": "Brute force"},
+		"context": map[string]interface{}{"severity": "critical"},
+		"alerts": []interface{}{
+			map[string]interface{}{"_id": "a1", "reason": "5 failed logins for root"},
+		},
+	}
+	msg = formatKibanaPayload(body, defaultConfig())
+	assert.Equal(t, 10, msg.Priority)
+	assert.Equal(t, "- a1: 5 failed logins for root", msg.Message)
+
+	body["alerts"] = map[string]interface{}{"new": map[string]interface{}{"count": 2}, "recovered": map[string]interface{}{"count": 1}}
+	assert.Equal(t, "Alerts: 2 new, 1 recovered", formatKibanaPayload(body, defaultConfig()).Message)
+
+	// Watcher webhook action
+	body = map[string]interface{}{
+		"watch_id": "log_errors",
+		"payload":  map[string]interface{}{},
+		"ctx": map[string]interface{}{
+			"payload": map[string]interface{}{"hits": map[string]interface{}{"total": map[string]interface{}{"value": 42.0}}},
+		},
+	}
+	assert.True(t, isKibanaPayload(body))
+	msg = formatKibanaPayload(body, defaultConfig())
+	assert.Equal(t, "Watcher: log_errors", msg.Title)
+	assert.Equal(t, "Hits: 42", msg.Message)
+}
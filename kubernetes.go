@@ -0,0 +1,210 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gotify/plugin-api"
+)
+
+// isKubernetesEventPayload detects Kubernetes events as sent by the webhook
+// sinks of kubernetes-event-exporter and Botkube.
+func isKubernetesEventPayload(body map[string]interface{}) bool {
+	return kubernetesEvent(body) != nil
+}
+
+// kubernetesEvent returns the event of the payload in the layout of the
+// Kubernetes Event API, or nil if the payload is not a Kubernetes event.
+// kubernetes-event-exporter sends events in this layout; flat events with
+// the kind and Botkube's webhook events are converted.
+func kubernetesEvent(body map[string]interface{}) map[string]interface{} {
+	if _, ok := body["involvedObject"].(map[string]interface{}); ok {
+		if stringField(body, "reason") == "" {
+			return nil
+		}
+		return body
+	}
+
+	// Botkube sends its events under data, older versions split them into
+	// meta and status
+	if data, ok := body["data"].(map[string]interface{}); ok && stringField(data, "Kind") != "" &&
+		hasAnyField(data, "Reason", "Messages", "Level") {
+		return botkubeEvent(data, "Kind", "Name", "Namespace", "Cluster", "Type", "Level", "Reason", "Messages", "Count")
+	}
+	meta, _ := body["meta"].(map[string]interface{})
+	if status, ok := body["status"].(map[string]interface{}); ok && stringField(meta, "kind") != "" {
+		event := map[string]interface{}{}
+		for key, value := range meta {
+			event[key] = value
+		}
+		for key, value := range status {
+			event[key] = value
+		}
+		if text := stringField(status, "error"); text != "" && !hasAnyField(status, "messages") {
+			event["messages"] = []interface{}{text}
+		}
+		return botkubeEvent(event, "kind", "name", "namespace", "cluster", "type", "level", "reason", "messages", "count")
+	}
+
+	// Flat events with the object kind next to the reason and type
+	switch stringField(body, "type") {
+	case "Warning", "Normal":
+		if stringField(body, "kind") == "" || stringField(body, "reason") == "" {
+			return nil
+		}
+		event := map[string]interface{}{
+			"involvedObject": map[string]interface{}{
+				"kind":      stringField(body, "kind"),
+				"name":      stringField(body, "name"),
+				"namespace": stringField(body, "namespace"),
+			},
+		}
+		for _, key := range []string{"reason", "message", "type", "count", "clusterName", "cluster", "lastTimestamp", "eventTime"} {
+			if value, ok := body[key]; ok {
+				event[key] = value
+			}
+		}
+		return event
+	}
+	return nil
+}
+
+// botkubeEvent converts a Botkube event, given the names of its fields, to
+// the layout of the Kubernetes Event API. Errors and warnings become Warning
+// events; events without a reason are named by their type, e.g. "Create".
+func botkubeEvent(data map[string]interface{}, kind, name, namespace, cluster, eventType, level, reason, messages, count string) map[string]interface{} {
+	warning := false
+	for _, value := range []string{stringField(data, eventType), stringField(data, level)} {
+		switch strings.ToLower(value) {
+		case "error", "warning", "warn", "critical":
+			warning = true
+		}
+	}
+	eventReason := stringField(data, reason)
+	if eventReason == "" {
+		eventReason = capitalize(stringField(data, eventType))
+	}
+	if eventReason == "" {
+		return nil
+	}
+
+	event := map[string]interface{}{
+		"involvedObject": map[string]interface{}{
+			"kind":      stringField(data, kind),
+			"name":      stringField(data, name),
+			"namespace": stringField(data, namespace),
+		},
+		"reason":      eventReason,
+		"type":        "Normal",
+		"message":     joinLines(data[messages]),
+		"clusterName": stringField(data, cluster),
+	}
+	if warning {
+		event["type"] = "Warning"
+	}
+	if value, ok := data[count]; ok {
+		event["count"] = value
+	}
+	return event
+}
+
+// joinLines joins the strings of a decoded JSON array with newlines.
+func joinLines(value interface{}) string {
+	items, _ := value.([]interface{})
+	lines := make([]string, 0, len(items))
+	for _, item := range items {
+		if line, ok := item.(string); ok && line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatKubernetesEventPayload renders a Kubernetes event with namespace and
+// object context. Warning events are forwarded at elevated priority.
+func formatKubernetesEventPayload(body map[string]interface{}, _ *Config) plugin.Message {
+	if event := kubernetesEvent(body); event != nil {
+		body = event
+	}
+	object, _ := body["involvedObject"].(map[string]interface{})
+	eventType := stringField(body, "type")
+	reason := stringField(body, "reason")
+	kind := stringField(object, "kind")
+	name := stringField(object, "name")
+	namespace := stringField(object, "namespace")
+	if namespace == "" {
+		if metadata, ok := body["metadata"].(map[string]interface{}); ok {
+			namespace = stringField(metadata, "namespace")
+		}
+	}
+
+	objectName := name
+	if namespace != "" && name != "" {
+		objectName = namespace + "/" + name
+	}
+	if kind != "" && objectName != "" {
+		objectName = kind + " " + objectName
+	}
+
+	priority := 4
+	title := "Kubernetes: " + reason
+	if strings.EqualFold(eventType, "Warning") {
+		priority = 7
+		title = "Kubernetes Warning: " + reason
+	}
+	if objectName != "" {
+		title += " on " + objectName
+	}
+
+	var lines []string
+	if text := strings.TrimSpace(stringField(body, "message", "note")); text != "" {
+		lines = append(lines, text, "")
+	}
+	if cluster := stringField(body, "clusterName", "cluster"); cluster != "" {
+		lines = append(lines, fmt.Sprintf("Cluster: %s", cluster))
+	}
+	if namespace != "" {
+		lines = append(lines, fmt.Sprintf("Namespace: %s", namespace))
+	}
+	if kind != "" || name != "" {
+		lines = append(lines, fmt.Sprintf("Object: %s", strings.TrimPrefix(kind+"/"+name, "/")))
+	}
+	if eventSource, ok := body["source"].(map[string]interface{}); ok {
+		component := stringField(eventSource, "component")
+		if host := stringField(eventSource, "host"); host != "" {
+			component = strings.TrimSpace(component + " on " + host)
+		}
+		if component != "" {
+			lines = append(lines, fmt.Sprintf("Source: %s", component))
+		}
+	} else if component := stringField(body, "reportingComponent"); component != "" {
+		lines = append(lines, fmt.Sprintf("Source: %s", component))
+	}
+	if count := intField(body, "count"); count > 1 {
+		lines = append(lines, fmt.Sprintf("Count: %d", count))
+	}
+	if lastSeen := stringField(body, "lastTimestamp", "eventTime"); lastSeen != "" {
+		lines = append(lines, fmt.Sprintf("Last seen: %s", lastSeen))
+	}
+
+	extras := map[string]interface{}{"source": "kubernetes", "reason": reason}
+	if eventType != "" {
+		extras["type"] = eventType
+	}
+	if namespace != "" {
+		extras["namespace"] = namespace
+	}
+	if kind != "" {
+		extras["kind"] = kind
+	}
+	if name != "" {
+		extras["name"] = name
+	}
+
+	return plugin.Message{
+		Title:    title,
+		Message:  strings.TrimSpace(strings.Join(lines, "\n")),
+		Priority: priority,
+		Extras:   extras,
+	}
+}
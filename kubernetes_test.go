@@ -0,0 +1,116 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gotify/plugin-api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_KubernetesEventWebhook(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	w := postWebhook(p, map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "web-1.17a", "namespace": "shop"},
+		"reason":   "BackOff",
+		"message":  "Back-off restarting failed container",
+		"type":     "Warning",
+		"count":    12,
+		"source":   map[string]interface{}{"component": "kubelet", "host": "node-2"},
+		"involvedObject": map[string]interface{}{
+			"kind":      "Pod",
+			"namespace": "shop",
+			"name":      "web-1",
+		},
+		"lastTimestamp": "2024-01-01T12:00:00Z",
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title:    "Kubernetes Warning: BackOff on Pod shop/web-1",
+		Message:  "Back-off restarting failed container\n\nNamespace: shop\nObject: Pod/web-1\nSource: kubelet on node-2\nCount: 12\nLast seen: 2024-01-01T12:00:00Z",
+		Priority: 7,
+		Extras: map[string]interface{}{
+			"source":    "kubernetes",
+			"reason":    "BackOff",
+			"type":      "Warning",
+			"namespace": "shop",
+			"kind":      "Pod",
+			"name":      "web-1",
+		},
+	}, mockHandler.sentMessages[0])
+}
+
+func TestWebhookForwarderPlugin_KubernetesNormalEvent(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	postWebhook(p, map[string]interface{}{
+		"reason":         "ScalingReplicaSet",
+		"message":        "Scaled up replica set web-7c9 to 3",
+		"type":           "Normal",
+		"involvedObject": map[string]interface{}{"kind": "Deployment", "namespace": "shop", "name": "web"},
+	})
+
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, "Kubernetes: ScalingReplicaSet on Deployment shop/web", mockHandler.sentMessages[0].Title)
+	assert.Equal(t, 4, mockHandler.sentMessages[0].Priority)
+}
+
+func TestIsKubernetesEventPayload(t *testing.T) {
+	assert.True(t, isKubernetesEventPayload(map[string]interface{}{"reason": "Killing", "involvedObject": map[string]interface{}{}}))
+	assert.False(t, isKubernetesEventPayload(map[string]interface{}{"reason": "Killing"}))
+	assert.False(t, isKubernetesEventPayload(map[string]interface{}{"message": "hello"}))
+}
+
+func TestWebhookForwarderPlugin_BotkubeEvent(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	w := postWebhook(p, map[string]interface{}{
+		"source": "k8s-err-events",
+		"data": map[string]interface{}{
+			"Kind":      "Pod",
+			"Name":      "web-1",
+			"Namespace": "shop",
+			"Cluster":   "prod",
+			"Type":      "error",
+			"Level":     "error",
+			"Reason":    "BackOff",
+			"Messages":  []interface{}{"Back-off restarting failed container"},
+		},
+		"timeStamp": "2024-01-01T12:00:00Z",
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	msg := mockHandler.sentMessages[0]
+	assert.Equal(t, "Kubernetes Warning: BackOff on Pod shop/web-1", msg.Title)
+	assert.Equal(t, "Back-off restarting failed container\n\nCluster: prod\nNamespace: shop\nObject: Pod/web-1", msg.Message)
+	assert.Equal(t, 7, msg.Priority)
+
+	// Older Botkube versions split the event into meta and status
+	msg = formatKubernetesEventPayload(map[string]interface{}{
+		"meta":   map[string]interface{}{"kind": "Deployment", "name": "web", "namespace": "shop", "cluster": "prod"},
+		"status": map[string]interface{}{"type": "create", "level": "info"},
+	}, defaultConfig())
+	assert.Equal(t, "Kubernetes: Create on Deployment shop/web", msg.Title)
+	assert.Equal(t, 4, msg.Priority)
+}
+
+func TestWebhookForwarderPlugin_FlatKubernetesEvent(t *testing.T) {
+	msg := formatKubernetesEventPayload(map[string]interface{}{
+		"kind":      "Node",
+		"name":      "node-2",
+		"reason":    "NodeNotReady",
+		"message":   "Node node-2 status is now: NodeNotReady",
+		"type":      "Warning",
+		"namespace": "",
+	}, defaultConfig())
+	assert.Equal(t, "Kubernetes Warning: NodeNotReady on Node node-2", msg.Title)
+	assert.Equal(t, 7, msg.Priority)
+	assert.True(t, isKubernetesEventPayload(map[string]interface{}{"kind": "Pod", "reason": "Pulled", "type": "Normal"}))
+}
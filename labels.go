@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"path"
+)
+
+// LabelFilterConfig selects which labels and annotations are rendered in
+// notifications. Entries are glob patterns such as "__*__".
+type LabelFilterConfig struct {
+	// Include lists the labels to show; all labels are shown when empty.
+	Include []string `yaml:"include"`
+	// Exclude lists labels that are never shown, even if included.
+	Exclude []string `yaml:"exclude"`
+}
+
+// validate checks that all patterns are well-formed.
+func (f *LabelFilterConfig) validate() error {
+	for _, pattern := range append(append([]string{}, f.Include...), f.Exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid label pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// allows reports whether the label name should be rendered.
+func (f *LabelFilterConfig) allows(name string) bool {
+	if matchesAny(f.Exclude, name) {
+		return false
+	}
+	return len(f.Include) == 0 || matchesAny(f.Include, name)
+}
+
+// filter returns a copy of labels containing only the allowed entries.
+func (f *LabelFilterConfig) filter(labels map[string]string) map[string]string {
+	if labels == nil {
+		return nil
+	}
+	filtered := make(map[string]string, len(labels))
+	for name, value := range labels {
+		if f.allows(name) {
+			filtered[name] = value
+		}
+	}
+	return filtered
+}
+
+// filterGrafanaLabels returns a copy of the webhook with the labels and
+// annotations of every alert filtered for rendering.
+func (f *LabelFilterConfig) filterGrafanaLabels(webhook GrafanaWebhook) GrafanaWebhook {
+	webhook.CommonLabels = f.filter(webhook.CommonLabels)
+	webhook.CommonAnnotations = f.filter(webhook.CommonAnnotations)
+	webhook.GroupLabels = f.filter(webhook.GroupLabels)
+
+	alerts := make([]GrafanaAlert, len(webhook.Alerts))
+	for i, alert := range webhook.Alerts {
+		alert.Labels = f.filter(alert.Labels)
+		alert.Annotations = f.filter(alert.Annotations)
+		alerts[i] = alert
+	}
+	webhook.Alerts = alerts
+	return webhook
+}
+
+// matchesAny reports whether name matches one of the glob patterns.
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
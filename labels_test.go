@@ -0,0 +1,47 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLabelFilterConfig_Filter(t *testing.T) {
+	labels := map[string]string{
+		"alertname":          "HighCPU",
+		"instance":           "web1",
+		"grafana_folder":     "Infra",
+		"__alert_rule_uid__": "abc",
+	}
+
+	f := LabelFilterConfig{Exclude: []string{"__*__", "grafana_folder"}}
+	assert.NoError(t, f.validate())
+	assert.Equal(t, map[string]string{"alertname": "HighCPU", "instance": "web1"}, f.filter(labels))
+
+	f = LabelFilterConfig{Include: []string{"alert*", "grafana_folder"}, Exclude: []string{"grafana_*"}}
+	assert.Equal(t, map[string]string{"alertname": "HighCPU"}, f.filter(labels))
+
+	assert.Error(t, (&LabelFilterConfig{Include: []string{"["}}).validate())
+}
+
+func TestWebhookForwarderPlugin_LabelFilterInTemplates(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+	config := defaultConfig()
+	config.Labels.Exclude = []string{"__*__", "grafana_folder"}
+	config.MessageTemplate = `{{ range .Alerts }}{{ range $k, $v := .Labels }}{{ $k }}={{ $v }} {{ end }}{{ end }}`
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+
+	rawAlert := map[string]interface{}{
+		"status": "firing",
+		"labels": map[string]interface{}{
+			"alertname":          "HighCPU",
+			"grafana_folder":     "Infra",
+			"__alert_rule_uid__": "abc",
+		},
+	}
+	postWebhook(p, map[string]interface{}{"status": "firing", "alerts": []interface{}{rawAlert}})
+
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, "alertname=HighCPU", mockHandler.sentMessages[0].Message)
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"strings"
+)
+
+// logLine returns the first configured log line annotation of an alert,
+// as set by Loki and Mimir ruler alerts, or "" if it has none.
+func (c *Config) logLine(alert GrafanaAlert) string {
+	for _, name := range c.LogAnnotations {
+		if line := strings.TrimSpace(alert.Annotations[name]); line != "" && c.Labels.allows(name) {
+			return line
+		}
+	}
+	return ""
+}
+
+// logAnnotation reports whether the annotation holds a log line.
+func (c *Config) logAnnotation(name string) bool {
+	for _, annotation := range c.LogAnnotations {
+		if annotation == name {
+			return true
+		}
+	}
+	return false
+}
+
+// logLineBlock renders the log line of an alert as a markdown code block,
+// or "" if it has none.
+func (c *Config) logLineBlock(alert GrafanaAlert) string {
+	line := c.logLine(alert)
+	if line == "" {
+		return ""
+	}
+	fence := "```"
+	if strings.Contains(line, fence) {
+		fence = "~~~"
+	}
+	return "📜 **Log line**\n" + fence + "\n" + line + "\n" + fence
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_LokiLogLine(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	// Loki ruler alert delivered by Alertmanager
+	payload := alertmanagerPayload()
+	alerts := payload["alerts"].([]interface{})
+	alerts[0].(map[string]interface{})["annotations"] = map[string]interface{}{
+		"summary":  "Panic in checkout",
+		"log_line": `level=error msg="panic: runtime error: index out of range"`,
+	}
+	payload["alerts"] = alerts[:1]
+	postWebhook(p, payload)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Contains(t, mockHandler.sentMessages[0].Message, "Panic in checkout\n\n📜 **Log line**\n```\nlevel=error msg=\"panic: runtime error: index out of range\"\n```\n\n- **alertname**")
+
+	// Grafana notifications and the minimal preset show it too
+	config := defaultConfig()
+	config.Grafana.Verbosity = verbosityMinimal
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+	postWebhook(p, map[string]interface{}{
+		"status": "firing",
+		"alerts": []interface{}{
+			map[string]interface{}{
+				"status":      "firing",
+				"labels":      map[string]interface{}{"alertname": "Errors"},
+				"annotations": map[string]interface{}{"logline": "x ``` y"},
+			},
+		},
+	})
+	assert.Equal(t, "📜 **Log line**\n~~~\nx ``` y\n~~~", mockHandler.sentMessages[1].Message)
+}
+
+func TestConfig_LogLine(t *testing.T) {
+	config := defaultConfig()
+	alert := GrafanaAlert{Annotations: map[string]string{"log": "first", "log_line": "second"}}
+	assert.Equal(t, "second", config.logLine(alert))
+
+	config.Labels.Exclude = []string{"log_line"}
+	assert.Equal(t, "first", config.logLine(alert))
+
+	config.LogAnnotations = nil
+	assert.Equal(t, "", config.logLineBlock(alert))
+}
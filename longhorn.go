@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gotify/plugin-api"
+)
+
+// longhornAlerts describes the alerts of Longhorn's example Prometheus rules.
+var longhornAlerts = map[string]string{
+	"LonghornVolumeActualSpaceUsedWarning":   "Volume space usage high",
+	"LonghornVolumeStatusCritical":           "Volume faulted",
+	"LonghornVolumeStatusWarning":            "Volume degraded",
+	"LonghornNodeStorageWarning":             "Node storage low",
+	"LonghornDiskStorageWarning":             "Disk storage low",
+	"LonghornNodeDown":                       "Node down",
+	"LonghornInstanceManagerCPUUsageWarning": "Instance manager CPU usage high",
+	"LonghornIntanceManagerCPUUsageWarning":  "Instance manager CPU usage high",
+	"LonghornNodeCPUUsageWarning":            "Node CPU usage high",
+	"LonghornBackupFailed":                   "Backup failed",
+	"LonghornBackupError":                    "Backup failed",
+}
+
+// camelCaseBoundary matches the start of a word in a CamelCase alert name.
+var camelCaseBoundary = regexp.MustCompile(`([a-z0-9])([A-Z])`)
+
+// isLonghornPayload detects Alertmanager notifications whose alerts all come
+// from Longhorn rules. Grafana notifications of such rules are left to the
+// Grafana handler.
+func isLonghornPayload(body map[string]interface{}) bool {
+	alerts := mapSlice(body["alerts"])
+	if len(alerts) == 0 || !isAlertmanagerPayload(body) {
+		return false
+	}
+	for _, alert := range alerts {
+		labels, _ := alert["labels"].(map[string]interface{})
+		if !strings.HasPrefix(stringField(labels, "alertname"), "Longhorn") {
+			return false
+		}
+	}
+	return true
+}
+
+// formatLonghornPayload renders Longhorn alerts with the affected volume or
+// node in the title. Priorities follow alertmanager.severityPriorities.
+func formatLonghornPayload(body map[string]interface{}, config *Config) plugin.Message {
+	alerts := mapSlice(body["alerts"])
+	status := stringField(body, "status")
+
+	priority := 0
+	var lines, subjects []string
+	for _, alert := range alerts {
+		labels, _ := alert["labels"].(map[string]interface{})
+		annotations, _ := alert["annotations"].(map[string]interface{})
+		alertStatus := stringField(alert, "status")
+		if alertStatus == "" {
+			alertStatus = status
+		}
+
+		subject := longhornSubject(labels)
+		if subject != "" {
+			subjects = append(subjects, subject)
+		}
+		line := "- " + longhornDescription(stringField(labels, "alertname"))
+		if subject != "" {
+			line += ": " + subject
+		}
+		if alertStatus == "resolved" {
+			line += " (resolved)"
+		}
+		lines = append(lines, line)
+		if text := stringField(annotations, "description", "summary", "message"); text != "" {
+			lines = append(lines, "  "+strings.TrimSpace(text))
+		}
+
+		if p := config.Alertmanager.alertPriority(labelsAlert(labels), alertStatus); p > priority {
+			priority = p
+		}
+	}
+
+	var title string
+	if len(alerts) == 1 {
+		labels, _ := alerts[0]["labels"].(map[string]interface{})
+		title = "Longhorn: " + longhornDescription(stringField(labels, "alertname"))
+		if len(subjects) == 1 {
+			title = fmt.Sprintf("Longhorn %s: %s", subjects[0], longhornDescription(stringField(labels, "alertname")))
+		}
+	} else {
+		title = fmt.Sprintf("Longhorn: %d alerts", len(alerts))
+		if len(subjects) > 0 {
+			title += " (" + strings.Join(uniqueStrings(subjects), ", ") + ")"
+		}
+	}
+	if status == "resolved" {
+		title = "Resolved: " + title
+	}
+
+	extras := map[string]interface{}{"source": "longhorn"}
+	if status != "" {
+		extras["status"] = status
+	}
+	if len(alerts) == 1 {
+		labels, _ := alerts[0]["labels"].(map[string]interface{})
+		for _, key := range []string{"alertname", "volume", "node", "disk"} {
+			if value := stringField(labels, key); value != "" {
+				extras[key] = value
+			}
+		}
+	}
+	if externalURL := stringField(body, "externalURL"); externalURL != "" {
+		extras["externalURL"] = externalURL
+	}
+
+	return plugin.Message{
+		Title:    title,
+		Message:  strings.Join(lines, "\n"),
+		Priority: priority,
+		Extras:   extras,
+	}
+}
+
+// longhornDescription returns a readable description for a Longhorn alert name.
+func longhornDescription(alertname string) string {
+	if description, ok := longhornAlerts[alertname]; ok {
+		return description
+	}
+	name := strings.TrimPrefix(alertname, "Longhorn")
+	if name == "" {
+		return "Alert"
+	}
+	words := camelCaseBoundary.ReplaceAllString(name, "$1 $2")
+	return capitalize(strings.ToLower(words))
+}
+
+// longhornSubject identifies the affected volume, node or backup from the
+// alert labels.
+func longhornSubject(labels map[string]interface{}) string {
+	if volume := stringField(labels, "volume"); volume != "" {
+		if pvc := stringField(labels, "pvc"); pvc != "" {
+			if namespace := stringField(labels, "pvc_namespace"); namespace != "" {
+				pvc = namespace + "/" + pvc
+			}
+			return fmt.Sprintf("%s (%s)", volume, pvc)
+		}
+		return volume
+	}
+	if node := stringField(labels, "node"); node != "" {
+		if disk := stringField(labels, "disk"); disk != "" {
+			return node + " " + disk
+		}
+		return node
+	}
+	return stringField(labels, "backup", "recurring_job")
+}
+
+// uniqueStrings returns values without duplicates, keeping the first occurrence.
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+	return result
+}
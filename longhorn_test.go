@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gotify/plugin-api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_LonghornWebhook(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	w := postWebhook(p, map[string]interface{}{
+		"version":  "4",
+		"receiver": "gotify",
+		"status":   "firing",
+		"alerts": []interface{}{
+			map[string]interface{}{
+				"status": "firing",
+				"labels": map[string]interface{}{
+					"alertname":     "LonghornVolumeStatusWarning",
+					"volume":        "pvc-1a2b",
+					"pvc":           "data-postgres-0",
+					"pvc_namespace": "db",
+					"severity":      "warning",
+				},
+				"annotations": map[string]interface{}{"description": "Longhorn volume pvc-1a2b is Degraded for more than 5 minutes."},
+			},
+		},
+		"externalURL": "http://alertmanager:9093",
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title:    "Longhorn pvc-1a2b (db/data-postgres-0): Volume degraded",
+		Message:  "- Volume degraded: pvc-1a2b (db/data-postgres-0)\n  Longhorn volume pvc-1a2b is Degraded for more than 5 minutes.",
+		Priority: 6,
+		Extras: map[string]interface{}{
+			"source":      "longhorn",
+			"status":      "firing",
+			"alertname":   "LonghornVolumeStatusWarning",
+			"volume":      "pvc-1a2b",
+			"externalURL": "http://alertmanager:9093",
+		},
+	}, mockHandler.sentMessages[0])
+}
+
+func TestFormatLonghornPayload_MultipleAlerts(t *testing.T) {
+	msg := formatLonghornPayload(map[string]interface{}{
+		"status": "firing",
+		"alerts": []interface{}{
+			map[string]interface{}{"labels": map[string]interface{}{"alertname": "LonghornNodeDown", "node": "node-2", "severity": "critical"}},
+			map[string]interface{}{"labels": map[string]interface{}{"alertname": "LonghornDiskStorageWarning", "node": "node-3", "disk": "disk-1"}},
+			map[string]interface{}{"status": "resolved", "labels": map[string]interface{}{"alertname": "LonghornBackupTargetUnavailable"}},
+		},
+	}, defaultConfig())
+
+	assert.Equal(t, "Longhorn: 3 alerts (node-2, node-3 disk-1)", msg.Title)
+	assert.Equal(t, "- Node down: node-2\n- Disk storage low: node-3 disk-1\n- Backup target unavailable (resolved)", msg.Message)
+	assert.Equal(t, 10, msg.Priority)
+}
+
+func longhornNotification(status string, alertnames ...string) map[string]interface{} {
+	alerts := make([]interface{}, len(alertnames))
+	for i, alertname := range alertnames {
+		alerts[i] = map[string]interface{}{
+			"status": status,
+			"labels": map[string]interface{}{"alertname": alertname, "node": fmt.Sprintf("node-%d", i+1), "severity": "critical"},
+		}
+	}
+	return map[string]interface{}{"version": "4", "status": status, "alerts": alerts}
+}
+
+func TestWebhookForwarderPlugin_LonghornAlertmanagerSettings(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+	config := defaultConfig()
+	config.Alertmanager.NotifyOnResolved = false
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+
+	w := postWebhook(p, longhornNotification("resolved", "LonghornNodeDown"))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Resolved alerts are not forwarded")
+	assert.Empty(t, mockHandler.sentMessages)
+
+	// Alerts are split like other Alertmanager notifications
+	w = postWebhook(p, longhornNotification("firing", "LonghornNodeDown", "LonghornNodeStorageWarning"))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 2)
+	assert.Equal(t, "Longhorn node-1: Node down", mockHandler.sentMessages[0].Title)
+	assert.Equal(t, "Longhorn node-2: Node storage low", mockHandler.sentMessages[1].Title)
+	assert.Equal(t, 10, mockHandler.sentMessages[0].Priority)
+
+	config.Alertmanager.SplitAlerts = false
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+	postWebhook(p, longhornNotification("firing", "LonghornNodeDown", "LonghornNodeStorageWarning"))
+	assert.Len(t, mockHandler.sentMessages, 3)
+	assert.Equal(t, "Longhorn: 2 alerts (node-1, node-2)", mockHandler.sentMessages[2].Title)
+
+	disabled := false
+	config.Sources = map[string]*SourceConfig{"alertmanager": {Enabled: &disabled}}
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+	w = postWebhook(p, longhornNotification("firing", "LonghornNodeDown"))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "'alertmanager'")
+	assert.Len(t, mockHandler.sentMessages, 3)
+}
+
+func TestIsLonghornPayload(t *testing.T) {
+	longhorn := map[string]interface{}{"labels": map[string]interface{}{"alertname": "LonghornNodeDown"}}
+	other := map[string]interface{}{"labels": map[string]interface{}{"alertname": "HighCPU"}}
+	assert.True(t, isLonghornPayload(map[string]interface{}{"version": "4", "alerts": []interface{}{longhorn}}))
+	assert.False(t, isLonghornPayload(map[string]interface{}{"version": "4", "alerts": []interface{}{longhorn, other}}))
+	assert.False(t, isLonghornPayload(map[string]interface{}{"version": "4", "alerts": []interface{}{}}))
+	// Grafana notifications carry version "1" and the orgId
+	assert.False(t, isLonghornPayload(map[string]interface{}{"version": "1", "orgId": 1, "alerts": []interface{}{longhorn}}))
+	assert.False(t, isLonghornPayload(map[string]interface{}{"alerts": []interface{}{longhorn}}))
+}
+
+func TestWebhookForwarderPlugin_GrafanaLonghornAlert(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	w := postWebhook(p, map[string]interface{}{
+		"version":  "1",
+		"orgId":    1,
+		"receiver": "gotify",
+		"status":   "firing",
+		"title":    "[FIRING:1] LonghornNodeDown",
+		"message":  "Longhorn node node-2 is down",
+		"alerts": []interface{}{
+			map[string]interface{}{
+				"status": "firing",
+				"labels": map[string]interface{}{"alertname": "LonghornNodeDown", "node": "node-2"},
+			},
+		},
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, "[FIRING:1] LonghornNodeDown", mockHandler.sentMessages[0].Title)
+	assert.Equal(t, "grafana", mockHandler.sentMessages[0].Extras["source"])
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gotify/plugin-api"
+)
+
+// isOutgoingChatPayload detects Mattermost and Rocket.Chat outgoing webhooks.
+func isOutgoingChatPayload(body map[string]interface{}) bool {
+	return hasFields(body, "token", "text") &&
+		(hasFields(body, "channel_name") || hasFields(body, "user_name"))
+}
+
+// formatOutgoingChatPayload renders a chat message that triggered an
+// outgoing webhook.
+func formatOutgoingChatPayload(body map[string]interface{}, _ *Config) plugin.Message {
+	service := "Mattermost"
+	if hasFields(body, "siteUrl") || hasFields(body, "message_id") || hasFields(body, "bot") {
+		service = "Rocket.Chat"
+	}
+
+	user := stringField(body, "user_name")
+	channel := strings.TrimPrefix(stringField(body, "channel_name"), "#")
+
+	title := service
+	switch {
+	case user != "" && channel != "":
+		title = fmt.Sprintf("%s: @%s in #%s", service, user, channel)
+	case user != "":
+		title = fmt.Sprintf("%s: @%s", service, user)
+	case channel != "":
+		title = fmt.Sprintf("%s: #%s", service, channel)
+	}
+
+	extras := map[string]interface{}{"source": strings.ToLower(service)}
+	if channel != "" {
+		extras["channel"] = channel
+	}
+	if user != "" {
+		extras["user"] = user
+	}
+	if trigger := stringField(body, "trigger_word"); trigger != "" {
+		extras["triggerWord"] = trigger
+	}
+
+	return plugin.Message{
+		Title:    title,
+		Message:  stringField(body, "text"),
+		Priority: 5,
+		Extras:   extras,
+	}
+}
+
+// respondOutgoingChat replies with an empty JSON object so the chat server
+// does not post a response message to the channel.
+func respondOutgoingChat(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{})
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gotify/plugin-api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_OutgoingChatWebhook(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	w := postWebhook(p, map[string]interface{}{
+		"token":        "abc123",
+		"team_domain":  "ops",
+		"channel_name": "alerts",
+		"user_name":    "alice",
+		"text":         "!page database is down",
+		"trigger_word": "!page",
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{}`, w.Body.String())
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title:    "Mattermost: @alice in #alerts",
+		Message:  "!page database is down",
+		Priority: 5,
+		Extras: map[string]interface{}{
+			"source":      "mattermost",
+			"channel":     "alerts",
+			"user":        "alice",
+			"triggerWord": "!page",
+		},
+	}, mockHandler.sentMessages[0])
+
+	postWebhook(p, map[string]interface{}{
+		"token":        "abc123",
+		"bot":          false,
+		"channel_name": "general",
+		"user_name":    "bob",
+		"text":         "hello",
+		"siteUrl":      "https://chat.example.com",
+	})
+	assert.Len(t, mockHandler.sentMessages, 2)
+	assert.Equal(t, "Rocket.Chat: @bob in #general", mockHandler.sentMessages[1].Title)
+	assert.Equal(t, "rocket.chat", mockHandler.sentMessages[1].Extras["source"])
+}
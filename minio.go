@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/gotify/plugin-api"
+)
+
+// MinIOConfig holds options for MinIO bucket notifications.
+type MinIOConfig struct {
+	// Events limits the forwarded bucket events, e.g.
+	// ["s3:ObjectCreated:*", "s3:ObjectRemoved:Delete"]. A trailing "*"
+	// matches any event with that prefix. Empty forwards all events.
+	Events []string `yaml:"events"`
+}
+
+// allows reports whether a bucket event passes the event filter.
+func (m *MinIOConfig) allows(event string) bool {
+	return matchesEvent(m.Events, event)
+}
+
+// minioActions maps the categories of bucket events onto a past tense
+// action and a priority. Failed replications get 7.
+var minioActions = map[string]struct {
+	action   string
+	priority int
+}{
+	"ObjectCreated":     {"created", 3},
+	"ObjectRemoved":     {"removed", 4},
+	"ObjectAccessed":    {"accessed", 1},
+	"ObjectRestore":     {"restored", 3},
+	"ObjectTransition":  {"transitioned", 2},
+	"ILM":               {"expired", 2},
+	"Scanner":           {"flagged by the scanner", 5},
+	"Replication":       {"replicated", 3},
+	"ObjectReplication": {"replicated", 3},
+}
+
+// isMinIOPayload detects MinIO bucket notifications, which carry the
+// S3 event records together with the event name and object key.
+func isMinIOPayload(body map[string]interface{}) bool {
+	records := mapSlice(body["Records"])
+	if len(records) == 0 {
+		return false
+	}
+	return strings.HasPrefix(stringField(records[0], "eventSource"), "minio:") ||
+		(strings.HasPrefix(stringField(body, "EventName"), "s3:") && hasFields(body, "Key"))
+}
+
+// minioRecords returns the records of a notification passing the event
+// filter.
+func minioRecords(body map[string]interface{}, config *Config) []map[string]interface{} {
+	var records []map[string]interface{}
+	for _, record := range mapSlice(body["Records"]) {
+		if config.MinIO.allows(stringField(record, "eventName")) {
+			records = append(records, record)
+		}
+	}
+	return records
+}
+
+// skipMinIOPayload drops notifications without events passing minio.events.
+func skipMinIOPayload(body map[string]interface{}, config *Config) string {
+	if len(minioRecords(body, config)) == 0 {
+		return "No MinIO events matching minio.events"
+	}
+	return ""
+}
+
+// formatMinIOPayload renders bucket events, e.g. "MinIO: photos/2024/a.jpg
+// created". Several records are listed, titled like "MinIO: 3 objects
+// created in photos".
+func formatMinIOPayload(body map[string]interface{}, config *Config) plugin.Message {
+	records := minioRecords(body, config)
+
+	var objects, lines, buckets []string
+	action, priority := "", 0
+	for _, record := range records {
+		s3, _ := record["s3"].(map[string]interface{})
+		bucket, _ := s3["bucket"].(map[string]interface{})
+		object, _ := s3["object"].(map[string]interface{})
+
+		name := stringField(bucket, "name")
+		if len(buckets) == 0 || buckets[len(buckets)-1] != name {
+			buckets = append(buckets, name)
+		}
+		key := stringField(object, "key")
+		if decoded, err := url.QueryUnescape(key); err == nil {
+			key = decoded
+		}
+		recordAction, recordPriority := minioAction(stringField(record, "eventName"))
+		if recordPriority > priority || action == "" {
+			action, priority = recordAction, recordPriority
+		}
+
+		objects = append(objects, name+"/"+key+" "+recordAction)
+		line := objects[len(objects)-1]
+		if size := intField(object, "size"); size > 0 {
+			line += " (" + humanizeBytes(int64(size)) + ")"
+		}
+		identity, _ := record["userIdentity"].(map[string]interface{})
+		if user := stringField(identity, "principalId"); user != "" {
+			line += " by " + user
+		}
+		lines = append(lines, line)
+	}
+
+	extras := map[string]interface{}{"source": "minio"}
+	if len(buckets) == 1 {
+		extras["bucket"] = buckets[0]
+	}
+
+	var title string
+	switch {
+	case len(objects) == 1:
+		title = "MinIO: " + objects[0]
+		extras["event"] = stringField(records[0], "eventName")
+	case len(buckets) == 1:
+		title = fmt.Sprintf("MinIO: %d objects %s in %s", len(objects), action, buckets[0])
+	default:
+		title = fmt.Sprintf("MinIO: %d objects %s", len(objects), action)
+	}
+	if len(lines) > 1 {
+		for i := range lines {
+			lines[i] = "- " + lines[i]
+		}
+	}
+
+	return plugin.Message{
+		Title:    title,
+		Message:  strings.Join(lines, "\n"),
+		Priority: priority,
+		Extras:   extras,
+	}
+}
+
+// minioAction describes an event name like "s3:ObjectCreated:Put" as an
+// action with its priority.
+func minioAction(event string) (string, int) {
+	parts := strings.Split(event, ":")
+	if len(parts) < 2 {
+		return event, 3
+	}
+	if strings.Contains(event, "Failed") {
+		return "replication failed", 7
+	}
+	if known, ok := minioActions[parts[1]]; ok {
+		return known.action, known.priority
+	}
+	return fluxReason(parts[len(parts)-1]), 3
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gotify/plugin-api"
+	"github.com/stretchr/testify/assert"
+)
+
+func minioRecord(event, bucket, key string, size float64) map[string]interface{} {
+	return map[string]interface{}{
+		"eventVersion": "2.0",
+		"eventSource":  "minio:s3",
+		"eventTime":    "2024-03-01T10:15:00.000Z",
+		"eventName":    event,
+		"userIdentity": map[string]interface{}{"principalId": "backup-user"},
+		"s3": map[string]interface{}{
+			"bucket": map[string]interface{}{"name": bucket},
+			"object": map[string]interface{}{"key": key, "size": size},
+		},
+	}
+}
+
+func TestWebhookForwarderPlugin_MinIOObjectCreated(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	w := postWebhook(p, map[string]interface{}{
+		"EventName": "s3:ObjectCreated:Put",
+		"Key":       "photos/2024/beach day.jpg",
+		"Records": []interface{}{
+			minioRecord("s3:ObjectCreated:Put", "photos", "2024%2Fbeach+day.jpg", 2097152),
+		},
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title:    "MinIO: photos/2024/beach day.jpg created",
+		Message:  "photos/2024/beach day.jpg created (2.0 MiB) by backup-user",
+		Priority: 3,
+		Extras: map[string]interface{}{
+			"source": "minio",
+			"bucket": "photos",
+			"event":  "s3:ObjectCreated:Put",
+		},
+	}, mockHandler.sentMessages[0])
+}
+
+func TestWebhookForwarderPlugin_MinIOEventFilter(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+	p.SetStorageHandler(&MockStorageHandler{})
+	config := defaultConfig()
+	config.MinIO.Events = []string{"s3:ObjectRemoved:*"}
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+
+	w := postWebhook(p, map[string]interface{}{
+		"EventName": "s3:ObjectAccessed:Get",
+		"Key":       "logs/app.log",
+		"Records":   []interface{}{minioRecord("s3:ObjectAccessed:Get", "logs", "app.log", 10)},
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "No MinIO events matching minio.events")
+	assert.Empty(t, mockHandler.sentMessages)
+
+	w = postWebhook(p, map[string]interface{}{
+		"Records": []interface{}{
+			minioRecord("s3:ObjectRemoved:Delete", "logs", "a.log", 0),
+			minioRecord("s3:ObjectCreated:Put", "logs", "b.log", 0),
+			minioRecord("s3:ObjectRemoved:Delete", "logs", "c.log", 0),
+		},
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, "MinIO: 2 objects removed in logs", mockHandler.sentMessages[0].Title)
+	assert.Equal(t, "- logs/a.log removed by backup-user\n- logs/c.log removed by backup-user", mockHandler.sentMessages[0].Message)
+	assert.Equal(t, 4, mockHandler.sentMessages[0].Priority)
+}
+
+func TestMinIOAction(t *testing.T) {
+	action, priority := minioAction("s3:Replication:OperationFailedReplication")
+	assert.Equal(t, "replication failed", action)
+	assert.Equal(t, 7, priority)
+	action, priority = minioAction("s3:ObjectAccessed:Head")
+	assert.Equal(t, "accessed", action)
+	assert.Equal(t, 1, priority)
+}
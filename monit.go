@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gotify/plugin-api"
+)
+
+// monitEventPriorities maps words of Monit events to priorities: failures
+// 8, matched resource limits and changes 6 and recoveries 3.
+var monitEventPriorities = keywordPriorities{
+	{[]string{"failed", "does not exist", "timeout", "error"}, 8},
+	{[]string{"limit matched", "changed"}, 6},
+	{[]string{"succeeded", "exists", "action done"}, 3},
+}
+
+// isMonitPayload detects Monit alerts as sent by OPNsense and pfSense HTTP
+// notifications, built from Monit's $SERVICE, $EVENT and $DESCRIPTION
+// variables.
+func isMonitPayload(body map[string]interface{}) bool {
+	if sourceIs(body, "monit") || sourceIs(body, "opnsense") || sourceIs(body, "pfsense") {
+		return hasAnyField(body, "service", "event", "description")
+	}
+	return hasFields(body, "service", "event") && hasAnyField(body, "description", "host", "action") &&
+		!hasAnyField(body, "alerts", "message", "extras")
+}
+
+// formatMonitPayload renders a Monit alert with the service and event in
+// the title and the description as message.
+func formatMonitPayload(body map[string]interface{}, config *Config) plugin.Message {
+	service := stringField(body, "service")
+	event := stringField(body, "event")
+
+	title := "Monit: " + service
+	if event != "" {
+		title = fmt.Sprintf("Monit: %s %s", service, strings.ToLower(event))
+	}
+
+	var paragraphs, lines []string
+	if description := strings.TrimSpace(stringField(body, "description")); description != "" {
+		paragraphs = append(paragraphs, description)
+	}
+	host := stringField(body, "host", "hostname")
+	if host != "" {
+		lines = append(lines, "Host: "+host)
+	}
+	if action := stringField(body, "action"); action != "" {
+		lines = append(lines, "Action: "+action)
+	}
+	if date := stringField(body, "date"); date != "" {
+		lines = append(lines, "Date: "+config.formatTimestamp(date))
+	}
+	if len(lines) > 0 {
+		paragraphs = append(paragraphs, strings.Join(lines, "\n"))
+	}
+	message := strings.Join(paragraphs, "\n\n")
+	if message == "" {
+		message = title
+	}
+
+	extras := map[string]interface{}{"source": "monit"}
+	for key, value := range map[string]string{
+		"service": service,
+		"event":   event,
+		"host":    host,
+	} {
+		if value != "" {
+			extras[key] = value
+		}
+	}
+
+	return plugin.Message{
+		Title:    title,
+		Message:  message,
+		Priority: monitEventPriorities.priority(event, 5),
+		Extras:   extras,
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gotify/plugin-api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_MonitWebhook(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	w := postWebhook(p, map[string]interface{}{
+		"service":     "unbound",
+		"event":       "Does not exist",
+		"description": "process is not running",
+		"host":        "fw.example.com",
+		"action":      "restart",
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title:    "Monit: unbound does not exist",
+		Message:  "process is not running\n\nHost: fw.example.com\nAction: restart",
+		Priority: 8,
+		Extras: map[string]interface{}{
+			"source":  "monit",
+			"service": "unbound",
+			"event":   "Does not exist",
+			"host":    "fw.example.com",
+		},
+	}, mockHandler.sentMessages[0])
+
+	for event, priority := range map[string]int{
+		"Connection failed":       8,
+		"Resource limit matched":  6,
+		"Status succeeded":        3,
+		"Exists":                  3,
+		"Monit instance changed":  6,
+		"Something else happened": 5,
+	} {
+		assert.Equal(t, priority, monitEventPriorities.priority(event, 5), event)
+	}
+}
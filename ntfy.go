@@ -0,0 +1,184 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gotify/plugin-api"
+)
+
+// maxNtfyMessageSize limits the plain-text body of an ntfy publish request.
+const maxNtfyMessageSize = 64 << 10
+
+// ntfyPriorities maps ntfy priorities (1-5 and their names) to Gotify priorities.
+var ntfyPriorities = map[string]int{
+	"1": 1, "min": 1,
+	"2": 3, "low": 3,
+	"3": 5, "default": 5,
+	"4": 8, "high": 8,
+	"5": 10, "max": 10, "urgent": 10,
+}
+
+// ntfyEmojis maps common ntfy tag short codes to emojis shown in the title.
+var ntfyEmojis = map[string]string{
+	"+1":                 "👍",
+	"-1":                 "👎",
+	"warning":            "⚠️",
+	"rotating_light":     "🚨",
+	"fire":               "🔥",
+	"skull":              "💀",
+	"x":                  "❌",
+	"no_entry":           "⛔",
+	"white_check_mark":   "✅",
+	"heavy_check_mark":   "✔️",
+	"tada":               "🎉",
+	"partying_face":      "🥳",
+	"loudspeaker":        "📢",
+	"computer":           "💻",
+	"floppy_disk":        "💾",
+	"bell":               "🔔",
+	"information_source": "ℹ️",
+}
+
+// ntfyParam reads an ntfy publish parameter from the headers (X-Name or one
+// of the aliases) or the query string.
+func ntfyParam(c *gin.Context, names ...string) string {
+	for _, name := range names {
+		if value := c.GetHeader("X-" + name); value != "" {
+			return value
+		}
+		if value := c.GetHeader(name); value != "" {
+			return value
+		}
+		if value := c.Query(strings.ToLower(name)); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// handleNtfyPublish accepts requests in the format of ntfy's publish API:
+// a plain-text body with the title, priority, tags and click URL passed as
+// headers or query parameters.
+func (p *WebhookForwarderPlugin) handleNtfyPublish(c *gin.Context) {
+	config := p.getConfig()
+	if !config.sourceEnabled("ntfy") {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Webhooks of type 'ntfy' are disabled in the plugin configuration",
+		})
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxNtfyMessageSize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Could not read request body",
+		})
+		return
+	}
+
+	topic := c.Param("topic")
+	profile := sourceProfile(c, config, "ntfy")
+
+	message := ntfyParam(c, "Message", "m")
+	if message == "" {
+		message = strings.TrimSpace(string(body))
+	}
+	if message == "" {
+		message = "triggered"
+	}
+
+	title := ntfyParam(c, "Title", "t", "ti")
+	if title == "" {
+		fallback := "ntfy"
+		if topic != "" {
+			fallback = topic
+		}
+		title = config.defaultTitle(profile, fallback)
+	}
+
+	priority, ok := ntfyPriorities[strings.ToLower(ntfyParam(c, "Priority", "p", "prio"))]
+	if !ok {
+		priority = 5
+		if profile.Priority > 0 {
+			priority = profile.Priority
+		}
+	}
+
+	// Emoji tags are prepended to the title, other tags listed below the message
+	var tags, emojis, otherTags []string
+	for _, tag := range strings.Split(ntfyParam(c, "Tags", "Tag", "ta"), ",") {
+		if tag = strings.TrimSpace(tag); tag == "" {
+			continue
+		}
+		tags = append(tags, tag)
+		if emoji, ok := ntfyEmojis[tag]; ok {
+			emojis = append(emojis, emoji)
+		} else {
+			otherTags = append(otherTags, tag)
+		}
+	}
+	if len(emojis) > 0 {
+		title = strings.Join(emojis, " ") + " " + title
+	}
+	if len(otherTags) > 0 {
+		message += "\n\nTags: " + strings.Join(otherTags, ", ")
+	}
+
+	extras := map[string]interface{}{"source": "ntfy"}
+	if topic != "" {
+		extras["topic"] = topic
+	}
+	if len(tags) > 0 {
+		extras["tags"] = tags
+	}
+	click := ntfyParam(c, "Click")
+	if click != "" {
+		extras["client::notification"] = map[string]interface{}{
+			"click": map[string]interface{}{"url": click},
+		}
+	}
+	if markdown, _ := strconv.ParseBool(ntfyParam(c, "Markdown", "md")); markdown {
+		extras["client::display"] = map[string]interface{}{"contentType": "text/markdown"}
+	}
+
+	// Profile templates see the fields of an ntfy message, e.g. {{ .topic }}
+	title, message = profile.renderTemplates(map[string]interface{}{
+		"topic":    topic,
+		"title":    title,
+		"message":  message,
+		"priority": priority,
+		"tags":     tags,
+		"click":    click,
+	}, title, message)
+
+	if !p.sendMessage(c, "ntfy", plugin.Message{
+		Title:    title,
+		Message:  message,
+		Priority: priority,
+		Extras:   extras,
+	}) {
+		return
+	}
+
+	// Reply like ntfy so publishing clients accept the response
+	response := gin.H{
+		"id":       randomID(),
+		"time":     timeNow().Unix(),
+		"event":    "message",
+		"topic":    topic,
+		"message":  message,
+		"title":    title,
+		"priority": priority,
+	}
+	if len(tags) > 0 {
+		response["tags"] = tags
+	}
+	if click != "" {
+		response["click"] = click
+	}
+	c.JSON(http.StatusOK, response)
+}
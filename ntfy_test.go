@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gotify/plugin-api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_NtfyPublish(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+	router := gin.New()
+	p.RegisterWebhook("/plugin/1/custom/token", router.Group("/"))
+
+	req := httptest.NewRequest("POST", "/ntfy/backups", strings.NewReader("Backup of /home failed"))
+	req.Header.Set("X-Title", "Backup")
+	req.Header.Set("X-Priority", "urgent")
+	req.Header.Set("X-Tags", "warning,nas")
+	req.Header.Set("X-Click", "https://nas.example.com")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "message", response["event"])
+	assert.Equal(t, "backups", response["topic"])
+	assert.NotEmpty(t, response["id"])
+
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title:    "⚠️ Backup",
+		Message:  "Backup of /home failed\n\nTags: nas",
+		Priority: 10,
+		Extras: map[string]interface{}{
+			"source": "ntfy",
+			"topic":  "backups",
+			"tags":   []string{"warning", "nas"},
+			"client::notification": map[string]interface{}{
+				"click": map[string]interface{}{"url": "https://nas.example.com"},
+			},
+		},
+	}, mockHandler.sentMessages[0])
+
+	req = httptest.NewRequest("PUT", "/ntfy?title=Ping&priority=2", strings.NewReader(""))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 2)
+	assert.Equal(t, "Ping", mockHandler.sentMessages[1].Title)
+	assert.Equal(t, "triggered", mockHandler.sentMessages[1].Message)
+	assert.Equal(t, 3, mockHandler.sentMessages[1].Priority)
+}
+
+func TestWebhookForwarderPlugin_NtfyProfileTemplates(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+	config := defaultConfig()
+	config.Sources = map[string]*SourceConfig{
+		"ntfy": {TitleTemplate: "[{{ .topic }}] {{ .title }}", MessageTemplate: "{{ .message }} (priority {{ .priority }})"},
+	}
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+	router := gin.New()
+	p.RegisterWebhook("/plugin/1/custom/token", router.Group("/"))
+
+	req := httptest.NewRequest("POST", "/ntfy/backups?title=Backup&priority=high", strings.NewReader("Backup of /home failed"))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, "[backups] Backup", mockHandler.sentMessages[0].Title)
+	assert.Equal(t, "Backup of /home failed (priority 8)", mockHandler.sentMessages[0].Message)
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gotify/plugin-api"
+)
+
+// onCallEvent describes how a Grafana OnCall webhook event is presented.
+type onCallEvent struct {
+	title    string
+	priority int
+}
+
+// onCallEvents maps OnCall event types to their presentation.
+var onCallEvents = map[string]onCallEvent{
+	"alert group created": {title: "New alert group", priority: 8},
+	"firing":              {title: "New alert group", priority: 8},
+	"escalation":          {title: "Escalated", priority: 9},
+	"acknowledge":         {title: "Acknowledged", priority: 4},
+	"unacknowledge":       {title: "Unacknowledged", priority: 8},
+	"resolve":             {title: "Resolved", priority: 3},
+	"unresolve":           {title: "Unresolved", priority: 8},
+	"silence":             {title: "Silenced", priority: 4},
+	"unsilence":           {title: "Unsilenced", priority: 8},
+}
+
+// isOnCallPayload detects Grafana OnCall outgoing webhooks.
+func isOnCallPayload(body map[string]interface{}) bool {
+	_, hasGroup := body["alert_group"].(map[string]interface{})
+	_, hasEvent := body["event"].(map[string]interface{})
+	return hasGroup && hasEvent
+}
+
+// formatOnCallPayload renders an OnCall alert group event. The escalation
+// chain and notified users are added to the extras.
+func formatOnCallPayload(body map[string]interface{}, _ *Config) plugin.Message {
+	event, _ := body["event"].(map[string]interface{})
+	group, _ := body["alert_group"].(map[string]interface{})
+	eventType := strings.ToLower(stringField(event, "type"))
+
+	info, known := onCallEvents[eventType]
+	if !known {
+		info = onCallEvent{title: "Alert group update", priority: 5}
+		if eventType != "" {
+			info.title = capitalize(eventType)
+		}
+	}
+
+	groupTitle := stringField(group, "title")
+	if groupTitle == "" {
+		groupTitle = "Alert group " + stringField(group, "id")
+	}
+	title := fmt.Sprintf("OnCall %s: %s", info.title, groupTitle)
+
+	var lines []string
+	if state := stringField(group, "state"); state != "" {
+		lines = append(lines, fmt.Sprintf("State: %s", state))
+	}
+	if count := intField(group, "alerts_count"); count > 0 {
+		lines = append(lines, fmt.Sprintf("Alerts: %d", count))
+	}
+	if integration, ok := body["integration"].(map[string]interface{}); ok {
+		if name := stringField(integration, "name"); name != "" {
+			lines = append(lines, fmt.Sprintf("Integration: %s", name))
+		}
+	}
+	if user, ok := body["user"].(map[string]interface{}); ok {
+		if name := stringField(user, "username", "email"); name != "" {
+			lines = append(lines, fmt.Sprintf("By: %s", name))
+		}
+	}
+
+	escalation := map[string]interface{}{}
+	if chain, ok := body["escalation_chain"].(map[string]interface{}); ok {
+		if name := stringField(chain, "name"); name != "" {
+			escalation["chain"] = name
+			lines = append(lines, fmt.Sprintf("Escalation chain: %s", name))
+		}
+	}
+	if notified := onCallUsers(body["notified_users"]); len(notified) > 0 {
+		escalation["notifiedUsers"] = notified
+		lines = append(lines, fmt.Sprintf("Notified: %s", strings.Join(notified, ", ")))
+	}
+	if pending := onCallUsers(body["users_to_be_notified"]); len(pending) > 0 {
+		escalation["usersToBeNotified"] = pending
+		lines = append(lines, fmt.Sprintf("To be notified: %s", strings.Join(pending, ", ")))
+	}
+
+	extras := map[string]interface{}{
+		"source":     "oncall",
+		"event":      eventType,
+		"alertGroup": stringField(group, "id"),
+	}
+	if len(escalation) > 0 {
+		extras["escalation"] = escalation
+	}
+	if permalinks, ok := group["permalinks"].(map[string]interface{}); ok {
+		if web := stringField(permalinks, "web"); web != "" {
+			extras["client::notification"] = map[string]interface{}{
+				"click": map[string]interface{}{"url": web},
+			}
+		}
+	}
+
+	return plugin.Message{
+		Title:    title,
+		Message:  strings.Join(lines, "\n"),
+		Priority: info.priority,
+		Extras:   extras,
+	}
+}
+
+// onCallUsers returns the usernames of an OnCall user list.
+func onCallUsers(value interface{}) []string {
+	var names []string
+	for _, user := range mapSlice(value) {
+		if name := stringField(user, "username", "email"); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
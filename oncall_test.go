@@ -0,0 +1,62 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gotify/plugin-api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_OnCallWebhook(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	w := postWebhook(p, map[string]interface{}{
+		"event": map[string]interface{}{"type": "escalation", "time": "2024-05-01T13:45:00Z"},
+		"alert_group": map[string]interface{}{
+			"id":           "I6HNZGUFG4K11",
+			"title":        "HighCPU on web1",
+			"state":        "firing",
+			"alerts_count": 3,
+			"permalinks":   map[string]interface{}{"web": "https://oncall.example.com/alert-groups/I6HNZGUFG4K11"},
+		},
+		"integration":          map[string]interface{}{"name": "Grafana Alerting"},
+		"escalation_chain":     map[string]interface{}{"name": "Infra on-call"},
+		"notified_users":       []interface{}{map[string]interface{}{"username": "alice"}},
+		"users_to_be_notified": []interface{}{map[string]interface{}{"username": "bob"}},
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title:    "OnCall Escalated: HighCPU on web1",
+		Message:  "State: firing\nAlerts: 3\nIntegration: Grafana Alerting\nEscalation chain: Infra on-call\nNotified: alice\nTo be notified: bob",
+		Priority: 9,
+		Extras: map[string]interface{}{
+			"source":     "oncall",
+			"event":      "escalation",
+			"alertGroup": "I6HNZGUFG4K11",
+			"escalation": map[string]interface{}{
+				"chain":             "Infra on-call",
+				"notifiedUsers":     []string{"alice"},
+				"usersToBeNotified": []string{"bob"},
+			},
+			"client::notification": map[string]interface{}{
+				"click": map[string]interface{}{"url": "https://oncall.example.com/alert-groups/I6HNZGUFG4K11"},
+			},
+		},
+	}, mockHandler.sentMessages[0])
+}
+
+func TestFormatOnCallPayload_Acknowledge(t *testing.T) {
+	msg := formatOnCallPayload(map[string]interface{}{
+		"event":       map[string]interface{}{"type": "acknowledge"},
+		"user":        map[string]interface{}{"username": "alice"},
+		"alert_group": map[string]interface{}{"id": "X1", "state": "acknowledged"},
+	}, defaultConfig())
+
+	assert.Equal(t, "OnCall Acknowledged: Alert group X1", msg.Title)
+	assert.Equal(t, "State: acknowledged\nBy: alice", msg.Message)
+	assert.Equal(t, 4, msg.Priority)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gotify/plugin-api"
+)
+
+// grafanaOrgContextKey stores the grafanaOrg of a Grafana webhook in the
+// gin context.
+const grafanaOrgContextKey = "grafanaOrg"
+
+// GrafanaOrgConfig overrides the defaults for alerts of a single Grafana
+// organization, selected by the orgId of the webhook.
+type GrafanaOrgConfig struct {
+	// Name identifies the organization in the message extras.
+	Name string `yaml:"name"`
+	// Enabled accepts or rejects alerts of the organization, defaults to true.
+	Enabled *bool `yaml:"enabled"`
+	// TitlePrefix is added in front of every title of the organization.
+	TitlePrefix string `yaml:"titlePrefix"`
+	// Priority replaces the priority of the organization's alerts, 0 keeps
+	// the default.
+	Priority int `yaml:"priority"`
+}
+
+// grafanaOrg is the organization a Grafana webhook was sent from.
+type grafanaOrg struct {
+	ID int
+	*GrafanaOrgConfig
+}
+
+// validateOrgs checks the per-organization settings.
+func (g *GrafanaConfig) validateOrgs() error {
+	for id, org := range g.Orgs {
+		if org != nil && (org.Priority < 0 || org.Priority > 10) {
+			return fmt.Errorf("invalid grafana.orgs.%d.priority: priority must be between 0 and 10", id)
+		}
+	}
+	return nil
+}
+
+// org returns the settings of the organization with the given id, or empty
+// settings if none are configured.
+func (g *GrafanaConfig) org(id int) grafanaOrg {
+	if org, ok := g.Orgs[id]; ok && org != nil {
+		return grafanaOrg{ID: id, GrafanaOrgConfig: org}
+	}
+	return grafanaOrg{ID: id, GrafanaOrgConfig: &GrafanaOrgConfig{}}
+}
+
+// enabled reports whether alerts of the organization are accepted.
+func (o grafanaOrg) enabled() bool {
+	return o.Enabled == nil || *o.Enabled
+}
+
+// label adds the organization's title prefix and extras to a message.
+func (o grafanaOrg) label(msg plugin.Message) plugin.Message {
+	if prefix := strings.TrimSpace(o.TitlePrefix); prefix != "" {
+		msg.Title = prefix + " " + msg.Title
+	}
+	if o.ID != 0 {
+		msg.Extras = withExtra(msg.Extras, "orgId", o.ID)
+	}
+	if o.Name != "" {
+		msg.Extras = withExtra(msg.Extras, "org", o.Name)
+	}
+	return msg
+}
+
+// grafanaOrgFromContext returns the organization of a Grafana webhook, or
+// nil for other requests.
+func grafanaOrgFromContext(c *gin.Context) *grafanaOrg {
+	if c == nil {
+		return nil
+	}
+	if value, ok := c.Get(grafanaOrgContextKey); ok {
+		if org, ok := value.(grafanaOrg); ok {
+			return &org
+		}
+	}
+	return nil
+}
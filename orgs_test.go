@@ -0,0 +1,58 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func orgGrafanaPayload(orgID int) map[string]interface{} {
+	payload := grafanaNotification(grafanaAlert("firing", "alertname", "HighCPU"))
+	payload["orgId"] = orgID
+	payload["title"] = "HighCPU"
+	return payload
+}
+
+func TestWebhookForwarderPlugin_GrafanaOrgs(t *testing.T) {
+	config, err := parseConfigYAML([]byte(`
+grafana:
+  orgs:
+    2:
+      name: staging
+      titlePrefix: "[staging]"
+      priority: 4
+    3:
+      enabled: false
+`))
+	assert.NoError(t, err)
+	p, mockHandler := newTestPlugin(t, config)
+
+	w := postWebhook(p, orgGrafanaPayload(1))
+	assert.Equal(t, http.StatusOK, w.Code)
+	msg := mockHandler.sentMessages[0]
+	assert.Equal(t, "HighCPU", msg.Title)
+	assert.Equal(t, 1, msg.Extras["orgId"])
+	assert.NotContains(t, msg.Extras, "org")
+
+	w = postWebhook(p, orgGrafanaPayload(2))
+	assert.Equal(t, http.StatusOK, w.Code)
+	msg = mockHandler.sentMessages[1]
+	assert.Equal(t, "[staging] HighCPU", msg.Title)
+	assert.Equal(t, 4, msg.Priority)
+	assert.Equal(t, 2, msg.Extras["orgId"])
+	assert.Equal(t, "staging", msg.Extras["org"])
+
+	w = postWebhook(p, orgGrafanaPayload(3))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 2)
+}
+
+func TestGrafanaConfig_ValidateOrgs(t *testing.T) {
+	config := defaultConfig()
+	config.Grafana.Orgs = map[int]*GrafanaOrgConfig{1: {Priority: 11}}
+	assert.Error(t, config.validate())
+
+	config.Grafana.Orgs = map[int]*GrafanaOrgConfig{1: {Priority: 10}, 2: nil}
+	assert.NoError(t, config.validate())
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gotify/plugin-api"
+)
+
+// overseerrPriorities maps Overseerr and Jellyseerr notification types to
+// priorities, 4 for other types.
+var overseerrPriorities = map[string]int{
+	"MEDIA_FAILED":      8,
+	"ISSUE_CREATED":     6,
+	"ISSUE_REOPENED":    6,
+	"MEDIA_PENDING":     5,
+	"MEDIA_AVAILABLE":   5,
+	"ISSUE_RESOLVED":    3,
+	"TEST_NOTIFICATION": 2,
+}
+
+// isOverseerrPayload detects the default payload of the Overseerr and
+// Jellyseerr webhook agent.
+func isOverseerrPayload(body map[string]interface{}) bool {
+	return strings.Contains(stringField(body, "notification_type"), "_") && hasAnyField(body, "subject", "event")
+}
+
+// formatOverseerrPayload renders an Overseerr notification with the event
+// and media in the title and the poster as big image.
+func formatOverseerrPayload(body map[string]interface{}, _ *Config) plugin.Message {
+	notificationType := stringField(body, "notification_type")
+	subject := stringField(body, "subject")
+	title := subject
+	if event := stringField(body, "event"); event != "" {
+		title = event
+		if subject != "" {
+			title = event + ": " + subject
+		}
+	}
+	if title == "" {
+		title = "Overseerr"
+	}
+
+	var paragraphs, lines []string
+	if text := strings.TrimSpace(stringField(body, "message")); text != "" {
+		paragraphs = append(paragraphs, text)
+	}
+	request, _ := body["request"].(map[string]interface{})
+	if user := stringField(request, "requestedBy_username"); user != "" {
+		lines = append(lines, "Requested by: "+user)
+	}
+	issue, _ := body["issue"].(map[string]interface{})
+	if user := stringField(issue, "reportedBy_username"); user != "" {
+		lines = append(lines, "Reported by: "+user)
+	}
+	if issueType := stringField(issue, "issue_type"); issueType != "" {
+		lines = append(lines, "Issue: "+issueType)
+	}
+	comment, _ := body["comment"].(map[string]interface{})
+	if text := stringField(comment, "comment_message"); text != "" {
+		lines = append(lines, fmt.Sprintf("Comment by %s: %s", stringField(comment, "commentedBy_username"), text))
+	}
+	for _, extra := range mapSlice(body["extra"]) {
+		if name, value := stringField(extra, "name"), stringField(extra, "value"); name != "" && value != "" {
+			lines = append(lines, name+": "+value)
+		}
+	}
+	if len(lines) > 0 {
+		paragraphs = append(paragraphs, strings.Join(lines, "\n"))
+	}
+	message := strings.Join(paragraphs, "\n\n")
+	if message == "" {
+		message = title
+	}
+
+	extras := map[string]interface{}{
+		"source":           "overseerr",
+		"notificationType": notificationType,
+	}
+	media, _ := body["media"].(map[string]interface{})
+	if mediaType := stringField(media, "media_type"); mediaType != "" {
+		extras["mediaType"] = mediaType
+	}
+	if image := stringField(body, "image"); image != "" {
+		extras["client::notification"] = map[string]interface{}{"bigImageUrl": image}
+	}
+
+	priority, ok := overseerrPriorities[notificationType]
+	if !ok {
+		priority = 4
+	}
+
+	return plugin.Message{
+		Title:    title,
+		Message:  message,
+		Priority: priority,
+		Extras:   extras,
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gotify/plugin-api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_OverseerrWebhook(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	w := postWebhook(p, map[string]interface{}{
+		"notification_type": "MEDIA_APPROVED",
+		"event":             "Movie Request Approved",
+		"subject":           "Dune (2021)",
+		"message":           "Paul Atreides unites with Chani and the Fremen.",
+		"image":             "https://image.tmdb.org/t/p/w600_and_h900_bestv2/d5NXSklXo0qyIYkgV94XAgMIckC.jpg",
+		"media":             map[string]interface{}{"media_type": "movie", "tmdbId": "438631", "status": "PENDING"},
+		"request":           map[string]interface{}{"request_id": "12", "requestedBy_username": "alice"},
+		"issue":             nil,
+		"comment":           nil,
+		"extra":             []interface{}{},
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title:    "Movie Request Approved: Dune (2021)",
+		Message:  "Paul Atreides unites with Chani and the Fremen.\n\nRequested by: alice",
+		Priority: 4,
+		Extras: map[string]interface{}{
+			"source":           "overseerr",
+			"notificationType": "MEDIA_APPROVED",
+			"mediaType":        "movie",
+			"client::notification": map[string]interface{}{
+				"bigImageUrl": "https://image.tmdb.org/t/p/w600_and_h900_bestv2/d5NXSklXo0qyIYkgV94XAgMIckC.jpg",
+			},
+		},
+	}, mockHandler.sentMessages[0])
+
+	msg := formatOverseerrPayload(map[string]interface{}{
+		"notification_type": "ISSUE_CREATED",
+		"event":             "New Video Issue Reported",
+		"subject":           "The Office (2005)",
+		"issue":             map[string]interface{}{"issue_type": "VIDEO", "reportedBy_username": "bob"},
+		"extra": []interface{}{
+			map[string]interface{}{"name": "Affected Season", "value": "2"},
+		},
+	}, defaultConfig())
+	assert.Equal(t, "Reported by: bob\nIssue: VIDEO\nAffected Season: 2", msg.Message)
+	assert.Equal(t, 6, msg.Priority)
+}
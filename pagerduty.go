@@ -0,0 +1,215 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gotify/plugin-api"
+)
+
+// pagerDutySeverityPriorities maps the severities of PagerDuty trigger
+// events to priorities.
+var pagerDutySeverityPriorities = map[string]int{
+	"critical": 10,
+	"error":    8,
+	"warning":  6,
+	"info":     4,
+}
+
+// handlePagerDutyEvent accepts events in the format of the PagerDuty Events
+// API v2, so tools integrating with PagerDuty can notify Gotify by changing
+// the events URL. It replies like PagerDuty.
+func (p *WebhookForwarderPlugin) handlePagerDutyEvent(c *gin.Context) {
+	config := p.getConfig()
+	if !config.sourceEnabled("pagerduty") {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Webhooks of type 'pagerduty' are disabled in the plugin configuration",
+		})
+		return
+	}
+
+	var body map[string]interface{}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "invalid event",
+			"message": "Event object is invalid",
+			"errors":  []string{err.Error()},
+		})
+		return
+	}
+	if errs := validatePagerDutyEvent(body); len(errs) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"status":  "invalid event",
+			"message": "Event object is invalid",
+			"errors":  errs,
+		})
+		return
+	}
+
+	// PagerDuty generates a dedup key for trigger events without one
+	dedupKey := stringField(body, "dedup_key")
+	if dedupKey == "" {
+		dedupKey = randomID()
+	}
+
+	msg := formatPagerDutyEvent(body, dedupKey)
+	profile := sourceProfile(c, config, "pagerduty")
+	if profile.Title != "" {
+		msg.Title = profile.Title
+	}
+	if profile.Priority > 0 {
+		msg.Priority = profile.Priority
+	}
+	msg.Title, msg.Message = profile.renderTemplates(body, msg.Title, msg.Message)
+
+	if !p.sendMessage(c, "pagerduty", msg) {
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{
+		"status":    "success",
+		"message":   "Event processed",
+		"dedup_key": dedupKey,
+	})
+}
+
+// validatePagerDutyEvent returns the problems of an Events API v2 event,
+// following the fields PagerDuty requires.
+func validatePagerDutyEvent(body map[string]interface{}) []string {
+	var errs []string
+	action := stringField(body, "event_action")
+	switch action {
+	case "trigger":
+		payload, _ := body["payload"].(map[string]interface{})
+		for _, field := range []string{"summary", "source", "severity"} {
+			if stringField(payload, field) == "" {
+				errs = append(errs, fmt.Sprintf("'payload.%s' is missing", field))
+			}
+		}
+		if severity := stringField(payload, "severity"); severity != "" {
+			if _, ok := pagerDutySeverityPriorities[severity]; !ok {
+				errs = append(errs, "'payload.severity' must be one of critical, error, warning or info")
+			}
+		}
+	case "acknowledge", "resolve":
+		if stringField(body, "dedup_key") == "" {
+			errs = append(errs, "'dedup_key' is required for "+action+" events")
+		}
+	default:
+		errs = append(errs, "'event_action' must be one of trigger, acknowledge or resolve")
+	}
+	return errs
+}
+
+// formatPagerDutyEvent renders a PagerDuty event with its summary as title
+// and the source, component and custom details as message.
+func formatPagerDutyEvent(body map[string]interface{}, dedupKey string) plugin.Message {
+	action := stringField(body, "event_action")
+	payload, _ := body["payload"].(map[string]interface{})
+	severity := stringField(payload, "severity")
+
+	title := stringField(payload, "summary")
+	if title == "" {
+		title = dedupKey
+	}
+	priority := pagerDutySeverityPriorities[severity]
+	switch action {
+	case "acknowledge":
+		title = "Acknowledged: " + title
+		priority = 4
+	case "resolve":
+		title = "Resolved: " + title
+		priority = 3
+	}
+
+	var paragraphs, lines []string
+	for _, field := range []struct{ label, key string }{
+		{"Source", "source"},
+		{"Component", "component"},
+		{"Group", "group"},
+		{"Class", "class"},
+		{"Severity", "severity"},
+		{"Time", "timestamp"},
+	} {
+		if value := stringField(payload, field.key); value != "" {
+			lines = append(lines, fmt.Sprintf("%s: %s", field.label, value))
+		}
+	}
+	if len(lines) > 0 {
+		paragraphs = append(paragraphs, strings.Join(lines, "\n"))
+	}
+	if details := pagerDutyDetails(payload["custom_details"]); details != "" {
+		paragraphs = append(paragraphs, details)
+	}
+	var links []string
+	for _, link := range mapSlice(body["links"]) {
+		if href := stringField(link, "href"); href != "" {
+			text := stringField(link, "text")
+			if text == "" {
+				text = href
+			}
+			links = append(links, fmt.Sprintf("%s: %s", text, href))
+		}
+	}
+	if len(links) > 0 {
+		paragraphs = append(paragraphs, strings.Join(links, "\n"))
+	}
+	message := strings.Join(paragraphs, "\n\n")
+	if message == "" {
+		message = title
+	}
+
+	extras := map[string]interface{}{
+		"source":   "pagerduty",
+		"action":   action,
+		"dedupKey": dedupKey,
+	}
+	if severity != "" {
+		extras["severity"] = severity
+	}
+	notification := map[string]interface{}{}
+	if url := stringField(body, "client_url"); url != "" {
+		notification["click"] = map[string]interface{}{"url": url}
+	}
+	for _, image := range mapSlice(body["images"]) {
+		if src := stringField(image, "src"); src != "" {
+			notification["bigImageUrl"] = src
+			break
+		}
+	}
+	if len(notification) > 0 {
+		extras["client::notification"] = notification
+	}
+
+	return plugin.Message{
+		Title:    title,
+		Message:  message,
+		Priority: priority,
+		Extras:   extras,
+	}
+}
+
+// pagerDutyDetails renders the custom details of an event, which may be an
+// object or a plain value.
+func pagerDutyDetails(value interface{}) string {
+	switch details := value.(type) {
+	case string:
+		return strings.TrimSpace(details)
+	case map[string]interface{}:
+		keys := make([]string, 0, len(details))
+		for key := range details {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		lines := make([]string, 0, len(keys))
+		for _, key := range keys {
+			lines = append(lines, fmt.Sprintf("%s: %v", key, details[key]))
+		}
+		return strings.Join(lines, "\n")
+	case nil:
+		return ""
+	}
+	return fmt.Sprint(value)
+}
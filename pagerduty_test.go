@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gotify/plugin-api"
+	"github.com/stretchr/testify/assert"
+)
+
+func postPagerDutyEvent(p *WebhookForwarderPlugin, path string, event map[string]interface{}) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	p.RegisterWebhook("/plugin/1/custom/token", router.Group("/"))
+
+	body, _ := json.Marshal(event)
+	req := httptest.NewRequest("POST", path, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestWebhookForwarderPlugin_PagerDutyEvent(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	w := postPagerDutyEvent(p, "/pagerduty", map[string]interface{}{
+		"routing_key":  "R0123456789",
+		"event_action": "trigger",
+		"dedup_key":    "disk-nas01",
+		"payload": map[string]interface{}{
+			"summary":        "Disk almost full on nas01",
+			"source":         "nas01",
+			"severity":       "critical",
+			"component":      "zfs",
+			"custom_details": map[string]interface{}{"pool": "tank", "used": "97%"},
+		},
+		"links":      []interface{}{map[string]interface{}{"href": "https://nas.example.com", "text": "NAS"}},
+		"client_url": "https://monitor.example.com/nas01",
+	})
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "success", response["status"])
+	assert.Equal(t, "disk-nas01", response["dedup_key"])
+
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title:    "Disk almost full on nas01",
+		Message:  "Source: nas01\nComponent: zfs\nSeverity: critical\n\npool: tank\nused: 97%\n\nNAS: https://nas.example.com",
+		Priority: 10,
+		Extras: map[string]interface{}{
+			"source":   "pagerduty",
+			"action":   "trigger",
+			"dedupKey": "disk-nas01",
+			"severity": "critical",
+			"client::notification": map[string]interface{}{
+				"click": map[string]interface{}{"url": "https://monitor.example.com/nas01"},
+			},
+		},
+	}, mockHandler.sentMessages[0])
+
+	// Resolve events only need the dedup key
+	w = postPagerDutyEvent(p, "/pagerduty/v2/enqueue", map[string]interface{}{
+		"routing_key":  "R0123456789",
+		"event_action": "resolve",
+		"dedup_key":    "disk-nas01",
+	})
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 2)
+	assert.Equal(t, "Resolved: disk-nas01", mockHandler.sentMessages[1].Title)
+	assert.Equal(t, 3, mockHandler.sentMessages[1].Priority)
+}
+
+func TestWebhookForwarderPlugin_PagerDutyInvalidEvent(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	w := postPagerDutyEvent(p, "/pagerduty", map[string]interface{}{
+		"event_action": "trigger",
+		"payload":      map[string]interface{}{"summary": "Test", "severity": "fatal"},
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid event")
+	assert.Contains(t, w.Body.String(), "payload.source")
+	assert.Contains(t, w.Body.String(), "payload.severity")
+	assert.Empty(t, mockHandler.sentMessages)
+
+	w = postPagerDutyEvent(p, "/pagerduty", map[string]interface{}{"event_action": "acknowledge"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "dedup_key")
+
+	config := defaultConfig()
+	config.Routes = []RouteConfig{{Path: "pagerduty"}}
+	assert.Error(t, config.validate())
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxPayloadSize limits the size of webhook bodies that are not JSON.
+const maxPayloadSize = 1 << 20
+
+// readPayload parses the webhook body into a generic map. JSON bodies are
+// accepted with or without a Content-Type, with top-level arrays (as sent by
+// e.g. CrowdSec) wrapped as "items". Form-encoded bodies are converted to
+// string fields and plain-text bodies are parsed as JSON or used as the
+// message. On failure an error response has been written and ok is false.
+func readPayload(c *gin.Context) (map[string]interface{}, bool) {
+	mediaType := ""
+	if contentType := c.GetHeader("Content-Type"); contentType != "" {
+		parsed, _, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid Content-Type header",
+			})
+			return nil, false
+		}
+		mediaType = parsed
+	}
+
+	var rawBody map[string]interface{}
+	switch {
+	case mediaType == "" || mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		var decoded interface{}
+		if err := c.ShouldBindJSON(&decoded); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid JSON payload",
+				"details": err.Error(),
+			})
+			return nil, false
+		}
+		switch value := decoded.(type) {
+		case map[string]interface{}:
+			rawBody = value
+		case []interface{}:
+			rawBody = map[string]interface{}{"items": value}
+		case nil:
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid JSON payload",
+				"details": "expected a JSON object or array",
+			})
+			return nil, false
+		}
+	case mediaType == "application/x-www-form-urlencoded" || mediaType == "multipart/form-data":
+		if err := c.Request.ParseMultipartForm(maxPayloadSize); err != nil && err != http.ErrNotMultipart {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid form payload",
+				"details": err.Error(),
+			})
+			return nil, false
+		}
+		rawBody = formPayload(c.Request.PostForm)
+	case mediaType == "text/plain":
+		data, err := io.ReadAll(io.LimitReader(c.Request.Body, maxPayloadSize))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Could not read request body",
+			})
+			return nil, false
+		}
+		var decoded interface{}
+		_ = json.Unmarshal(data, &decoded)
+		switch value := decoded.(type) {
+		case map[string]interface{}:
+			rawBody = value
+		case []interface{}:
+			rawBody = map[string]interface{}{"items": value}
+		default:
+			rawBody = map[string]interface{}{"message": strings.TrimSpace(string(data))}
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Content-Type must be application/json, application/x-www-form-urlencoded or text/plain",
+		})
+		return nil, false
+	}
+	return rawBody, true
+}
+
+// formPayload converts form values into a payload map. Fields with a single
+// value become strings, repeated fields become lists. A "payload" field with
+// JSON content (as sent by some services) is decoded instead.
+func formPayload(values map[string][]string) map[string]interface{} {
+	if encoded := values["payload"]; len(values) == 1 && len(encoded) == 1 {
+		var decoded map[string]interface{}
+		if json.Unmarshal([]byte(encoded[0]), &decoded) == nil {
+			return decoded
+		}
+	}
+
+	payload := make(map[string]interface{}, len(values))
+	for key, items := range values {
+		if len(items) == 1 {
+			payload[key] = items[0]
+			continue
+		}
+		list := make([]interface{}, len(items))
+		for i, item := range items {
+			list[i] = item
+		}
+		payload[key] = list
+	}
+	return payload
+}
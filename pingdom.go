@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gotify/plugin-api"
+)
+
+// isPingdomPayload detects Pingdom state change webhooks.
+func isPingdomPayload(body map[string]interface{}) bool {
+	return hasFields(body, "check_name", "current_state") && hasAnyField(body, "check_id", "previous_state")
+}
+
+// formatPingdomPayload renders a Pingdom check state change with the check
+// in the title and the description and probes as message. Checks going down
+// get priority 8 (9 with high importance), recovered checks 3.
+func formatPingdomPayload(body map[string]interface{}, _ *Config) plugin.Message {
+	name := stringField(body, "check_name")
+	state := strings.ToUpper(stringField(body, "current_state"))
+	title := fmt.Sprintf("Pingdom: %s is %s", name, state)
+
+	priority := 5
+	switch state {
+	case "DOWN":
+		priority = 8
+		if strings.EqualFold(stringField(body, "importance_level"), "high") {
+			priority = 9
+		}
+	case "UP":
+		priority = 3
+	}
+
+	var paragraphs, lines []string
+	if text := strings.TrimSpace(stringField(body, "long_description", "description")); text != "" {
+		paragraphs = append(paragraphs, text)
+	}
+	params, _ := body["check_params"].(map[string]interface{})
+	if checkType := stringField(body, "check_type"); checkType != "" {
+		lines = append(lines, "Type: "+checkType)
+	}
+	url := stringField(params, "full_url")
+	if url != "" {
+		lines = append(lines, "URL: "+url)
+	} else if host := stringField(params, "hostname"); host != "" {
+		lines = append(lines, "Host: "+host)
+	}
+	for _, probe := range []struct{ label, key string }{
+		{"Probe", "first_probe"},
+		{"Confirmed by", "second_probe"},
+	} {
+		if p, ok := body[probe.key].(map[string]interface{}); ok {
+			if location := stringField(p, "location"); location != "" {
+				if ip := stringField(p, "ip", "ipv6"); ip != "" {
+					location += " (" + ip + ")"
+				}
+				lines = append(lines, fmt.Sprintf("%s: %s", probe.label, location))
+			}
+		}
+	}
+	if tags := joinStrings(body["tags"]); tags != "" {
+		lines = append(lines, "Tags: "+tags)
+	}
+	if len(lines) > 0 {
+		paragraphs = append(paragraphs, strings.Join(lines, "\n"))
+	}
+	message := strings.Join(paragraphs, "\n\n")
+	if message == "" {
+		message = title
+	}
+
+	extras := map[string]interface{}{"source": "pingdom"}
+	for key, value := range map[string]string{
+		"checkId":       stringField(body, "check_id"),
+		"state":         state,
+		"previousState": strings.ToUpper(stringField(body, "previous_state")),
+	} {
+		if value != "" {
+			extras[key] = value
+		}
+	}
+	if url != "" {
+		extras["client::notification"] = map[string]interface{}{
+			"click": map[string]interface{}{"url": url},
+		}
+	}
+
+	return plugin.Message{
+		Title:    title,
+		Message:  message,
+		Priority: priority,
+		Extras:   extras,
+	}
+}
+
+// pingdomCheck identifies the check of a Pingdom webhook and when it
+// changed its state, for reporting the downtime when it recovers.
+func pingdomCheck(body map[string]interface{}) (string, bool, time.Time) {
+	id := stringField(body, "check_id")
+	if id == "" {
+		id = stringField(body, "check_name")
+	}
+	var changed time.Time
+	if timestamp := intField(body, "state_changed_timestamp"); timestamp > 0 {
+		changed = time.Unix(int64(timestamp), 0)
+	}
+	switch strings.ToUpper(stringField(body, "current_state")) {
+	case "DOWN":
+		return id, true, changed
+	case "UP":
+		return id, false, changed
+	}
+	return "", false, changed
+}
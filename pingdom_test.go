@@ -0,0 +1,63 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gotify/plugin-api"
+	"github.com/stretchr/testify/assert"
+)
+
+func pingdomPayload(previous, current string, changed float64) map[string]interface{} {
+	return map[string]interface{}{
+		"check_id":   12345.0,
+		"check_name": "Website",
+		"check_type": "HTTP",
+		"check_params": map[string]interface{}{
+			"hostname": "example.com",
+			"full_url": "https://example.com/",
+		},
+		"tags":                    []interface{}{"prod"},
+		"previous_state":          previous,
+		"current_state":           current,
+		"importance_level":        "HIGH",
+		"state_changed_timestamp": changed,
+		"description":             "Timeout (> 30s)",
+		"first_probe":             map[string]interface{}{"ip": "185.70.196.1", "location": "Stockholm, Sweden"},
+	}
+}
+
+func TestWebhookForwarderPlugin_PingdomWebhook(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+	p.SetStorageHandler(&MockStorageHandler{})
+
+	w := postWebhook(p, pingdomPayload("UP", "DOWN", 1714567500))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title:    "Pingdom: Website is DOWN",
+		Message:  "Timeout (> 30s)\n\nType: HTTP\nURL: https://example.com/\nProbe: Stockholm, Sweden (185.70.196.1)\nTags: prod",
+		Priority: 9,
+		Extras: map[string]interface{}{
+			"source":        "pingdom",
+			"checkId":       "12345",
+			"state":         "DOWN",
+			"previousState": "UP",
+			"client::notification": map[string]interface{}{
+				"click": map[string]interface{}{"url": "https://example.com/"},
+			},
+		},
+	}, mockHandler.sentMessages[0])
+
+	// The recovery reports how long the check was down
+	payload := pingdomPayload("DOWN", "UP", 1714567500+754)
+	payload["description"] = "OK"
+	postWebhook(p, payload)
+	assert.Len(t, mockHandler.sentMessages, 2)
+	msg := mockHandler.sentMessages[1]
+	assert.Equal(t, "Pingdom: Website is UP", msg.Title)
+	assert.Equal(t, 3, msg.Priority)
+	assert.Contains(t, msg.Message, "\n\nDown for 12m")
+	assert.Equal(t, 754, msg.Extras["downtimeSeconds"])
+}
@@ -1,15 +1,25 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gotify/plugin-api"
 )
 
+var (
+	// errQuietHours reports a message dropped during quiet hours.
+	errQuietHours = errors.New("message dropped during quiet hours")
+	// errNoMessageHandler reports that Gotify has not set a message handler.
+	errNoMessageHandler = errors.New("message handler not available")
+)
+
 // GetGotifyPluginInfo returns gotify plugin info.
 func GetGotifyPluginInfo() plugin.Info {
 	return plugin.Info{
@@ -42,6 +52,9 @@ type GrafanaAlert struct {
 	DashboardURL string                `json:"dashboardURL"`
 	PanelURL    string                 `json:"panelURL"`
 	ValueString string                 `json:"valueString"`
+	ImageURL    string                 `json:"imageURL"`
+	Fingerprint string                 `json:"fingerprint"`
+	GeneratorURL string                `json:"generatorURL"`
 }
 
 // GrafanaWebhook represents Grafana's webhook payload structure
@@ -65,6 +78,17 @@ type GrafanaWebhook struct {
 type WebhookForwarderPlugin struct {
 	msgHandler plugin.MessageHandler
 	userCtx    plugin.UserContext
+
+	storageHandler plugin.StorageHandler
+	storageMu      sync.Mutex
+
+	mu      sync.RWMutex
+	config  *Config
+	enabled bool
+	// gotifyConfig fingerprints the config last set through the Gotify UI.
+	gotifyConfig string
+	// backgroundDone stops the background tasks while the plugin is enabled.
+	backgroundDone chan struct{}
 }
 
 // SetMessageHandler implements plugin.Messenger
@@ -140,11 +164,21 @@ Active user: %s`,
 
 // Enable enables the plugin.
 func (p *WebhookForwarderPlugin) Enable() error {
-	return nil
+	p.mu.Lock()
+	p.enabled = true
+	p.mu.Unlock()
+	p.startBackgroundTasks()
+	
+	// Restore a config imported via the /config endpoint
+	return p.applyConfigOverride()
 }
 
 // Disable disables the plugin.
 func (p *WebhookForwarderPlugin) Disable() error {
+	p.mu.Lock()
+	p.enabled = false
+	p.mu.Unlock()
+	p.stopBackgroundTasks()
 	return nil
 }
 
@@ -155,6 +189,23 @@ func (p *WebhookForwarderPlugin) RegisterWebhook(basePath string, g *gin.RouterG
 	
 	// Register GET endpoint for testing/info
 	g.GET("/", p.handleInfo)
+	
+	// Register ntfy compatible publish endpoints
+	g.POST("/ntfy", p.handleNtfyPublish)
+	g.PUT("/ntfy", p.handleNtfyPublish)
+	g.POST("/ntfy/:topic", p.handleNtfyPublish)
+	g.PUT("/ntfy/:topic", p.handleNtfyPublish)
+	
+	// Register PagerDuty Events API v2 compatible endpoints
+	g.POST("/pagerduty", p.handlePagerDutyEvent)
+	g.POST("/pagerduty/v2/enqueue", p.handlePagerDutyEvent)
+	
+	// Register POST endpoint for named routes defined in the config
+	g.POST("/:route", p.handleRouteMessage)
+	
+	// Register config export/import endpoints
+	g.GET("/config", p.handleGetConfig)
+	g.PUT("/config", p.handlePutConfig)
 }
 
 // handleWebhookMessage processes incoming webhook messages
@@ -169,22 +220,20 @@ func (p *WebhookForwarderPlugin) handleWebhookMessage(c *gin.Context) {
 		}
 	}()
 	
-	// Validate content type
-	contentType := c.GetHeader("Content-Type")
-	if contentType != "application/json" && contentType != "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Content-Type must be application/json",
-		})
-		return
+	// Keep the raw body to verify the signature of Grafana, GitHub and
+	// Stripe webhooks
+	var body []byte
+	if p.getConfig().Grafana.Signature.Secret != "" || (p.getConfig().GitHub.Secret != "" && isGitHubRequest(c)) ||
+		(p.getConfig().Stripe.Secret != "" && isStripeRequest(c)) {
+		var ok bool
+		if body, ok = bufferBody(c); !ok {
+			return
+		}
 	}
 	
-	// Try to detect if this is a Grafana webhook
-	var rawBody map[string]interface{}
-	if err := c.ShouldBindJSON(&rawBody); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid JSON payload",
-			"details": err.Error(),
-		})
+	// Parse the JSON, form-encoded or plain-text body
+	rawBody, ok := readPayload(c)
+	if !ok {
 		return
 	}
 	
@@ -196,14 +245,91 @@ func (p *WebhookForwarderPlugin) handleWebhookMessage(c *gin.Context) {
 		return
 	}
 	
-	// Check if this looks like a Grafana webhook (has alerts field)
-	if _, hasAlerts := rawBody["alerts"]; hasAlerts {
-		p.handleGrafanaWebhook(c, rawBody)
+	// Check for GitHub deliveries, identified by their event header, Amazon
+	// SNS messages, which wrap the payload of another service, and Renovate
+	// reports, which may be queued for a digest, then for payloads of
+	// supported services, including alerts of known Alertmanager rules,
+	// then for other Alertmanager notifications, otherwise check if this
+	// looks like a Grafana webhook (has alerts field or the legacy alerting
+	// format)
+	source := "generic"
+	hasAlerts := isGrafanaPayload(rawBody)
+	formatter := detectPayloadFormatter(rawBody, p.getConfig())
+	switch {
+	case isGitHubRequest(c):
+		source = "github"
+		formatter = nil
+	case isSNSPayload(rawBody):
+		source = "sns"
+		formatter = nil
+	case isRenovateReport(rawBody):
+		source = "renovate"
+		formatter = nil
+	case formatter != nil:
+		source = formatter.source
+	case isAlertmanagerPayload(rawBody):
+		source = "alertmanager"
+	case hasAlerts:
+		source = "grafana"
+	}
+	
+	// Reject sources disabled in the config. Alerts of known Alertmanager
+	// rules also require the alertmanager source.
+	disabled := ""
+	if !p.getConfig().sourceEnabled(source) {
+		disabled = source
+	} else if formatter != nil {
+		disabled = formatter.disabledSource(p.getConfig())
+	}
+	if disabled != "" {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": fmt.Sprintf("Webhooks of type '%s' are disabled in the plugin configuration", disabled),
+		})
 		return
 	}
 	
-	// Otherwise, treat as generic webhook
-	p.handleGenericWebhook(c, rawBody)
+	// Reject alert payloads without a valid Grafana signature if a secret is
+	// set, including those handled as Alertmanager or service alerts, so
+	// unsigned requests can't pass by imitating another alert format
+	if hasAlerts && !p.getConfig().Grafana.Signature.verify(c.Request.Header, body, timeNow()) {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Invalid or missing webhook signature",
+		})
+		return
+	}
+	
+	// Reject GitHub webhooks without a valid signature if a secret is set
+	if source == "github" && !p.getConfig().GitHub.verify(c.Request.Header, body) {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Invalid or missing webhook signature",
+		})
+		return
+	}
+	
+	// Reject Stripe webhooks without a valid signature if a secret is set
+	if source == "stripe" && !p.getConfig().Stripe.verify(c.Request.Header, body, timeNow()) {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Invalid or missing webhook signature",
+		})
+		return
+	}
+	
+	switch {
+	case source == "github":
+		p.handleGitHubWebhook(c, rawBody)
+	case source == "sns":
+		p.handleSNSWebhook(c, rawBody)
+	case source == "renovate":
+		p.handleRenovateReport(c, rawBody)
+	case formatter != nil:
+		p.handleDetectedPayload(c, formatter, rawBody)
+	case source == "alertmanager":
+		p.handleAlertmanagerWebhook(c, rawBody)
+	case hasAlerts:
+		p.handleGrafanaWebhook(c, rawBody)
+	default:
+		p.handleGenericWebhook(c, rawBody)
+	}
 }
 
 // handleGenericWebhook processes standard webhook messages
@@ -227,12 +353,8 @@ func (p *WebhookForwarderPlugin) handleGenericWebhook(c *gin.Context, rawBody ma
 	if message, ok := rawBody["message"].(string); ok {
 		webhookMsg.Message = message
 	}
-	// Handle both int and float64 for priority (JSON numbers are float64)
-	if priority, ok := rawBody["priority"].(float64); ok {
-		webhookMsg.Priority = int(priority)
-	} else if priority, ok := rawBody["priority"].(int); ok {
-		webhookMsg.Priority = priority
-	}
+	// Handle numbers and numeric strings (form-encoded bodies) for priority
+	webhookMsg.Priority = intField(rawBody, "priority")
 	if extras, ok := rawBody["extras"].(map[string]interface{}); ok {
 		webhookMsg.Extras = extras
 	}
@@ -245,144 +367,127 @@ func (p *WebhookForwarderPlugin) handleGenericWebhook(c *gin.Context, rawBody ma
 		return
 	}
 	
+	config := p.getConfig()
+	profile := sourceProfile(c, config, "generic")
+	
 	// Set default title if not provided
 	if webhookMsg.Title == "" {
-		webhookMsg.Title = "Webhook Message"
+		webhookMsg.Title = config.defaultTitle(profile, "Webhook Message")
 	}
 	
 	// Set default priority if not provided (0) or invalid
 	if webhookMsg.Priority <= 0 || webhookMsg.Priority > 10 {
 		webhookMsg.Priority = 5
+		if profile.Priority > 0 {
+			webhookMsg.Priority = profile.Priority
+		}
 	}
 	
+	// Apply user templates
+	webhookMsg.Title, webhookMsg.Message = config.renderTemplates("generic", webhookMsg, webhookMsg.Title, webhookMsg.Message)
+	
 	// Forward message to Gotify user
-	if p.msgHandler != nil {
-		err := p.msgHandler.SendMessage(plugin.Message{
-			Title:    webhookMsg.Title,
-			Message:  webhookMsg.Message,
-			Priority: webhookMsg.Priority,
-			Extras:   webhookMsg.Extras,
-		})
-		
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to forward message",
-				"details": err.Error(),
-			})
-			return
-		}
-	} else {
-		c.JSON(http.StatusServiceUnavailable, gin.H{
-			"error": "Message handler not available",
-		})
+	p.forwardMessage(c, "generic", plugin.Message{
+		Title:    webhookMsg.Title,
+		Message:  webhookMsg.Message,
+		Priority: webhookMsg.Priority,
+		Extras:   webhookMsg.Extras,
+	})
+}
+
+// skipMessage acknowledges a webhook that is intentionally not forwarded,
+// using the status code configured for the kind of skip.
+func (p *WebhookForwarderPlugin) skipMessage(c *gin.Context, source string, kind skipKind, reason string) {
+	c.JSON(p.getConfig().ResponseCodes.statusFor(kind), gin.H{
+		"success":   true,
+		"forwarded": false,
+		"message":   reason,
+		"type":      source,
+	})
+}
+
+// decodePayload converts the raw JSON body into a typed payload struct.
+// Decoding is best effort so a single malformed field does not discard the
+// whole payload.
+func decodePayload(rawBody map[string]interface{}, v interface{}) {
+	data, err := json.Marshal(rawBody)
+	if err != nil {
 		return
 	}
-	
+	_ = json.Unmarshal(data, v)
+}
+
+// forwardMessage sends a message to the Gotify user and writes the HTTP
+// response for the webhook caller.
+func (p *WebhookForwarderPlugin) forwardMessage(c *gin.Context, source string, msg plugin.Message) {
+	if p.sendMessage(c, source, msg) {
+		p.respondForwarded(c, source)
+	}
+}
+
+// respondForwarded writes the response for a forwarded message.
+func (p *WebhookForwarderPlugin) respondForwarded(c *gin.Context, source string) {
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"message": "Message forwarded successfully",
+		"type":    source,
 	})
 }
 
-// handleGrafanaWebhook processes Grafana alert webhooks
-func (p *WebhookForwarderPlugin) handleGrafanaWebhook(c *gin.Context, rawBody map[string]interface{}) {
-	// Add panic recovery for Grafana webhook processing
-	defer func() {
-		if r := recover(); r != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Error processing Grafana webhook",
-				"details": "Unexpected error in Grafana webhook processing",
-			})
-		}
-	}()
-	
-	var grafanaMsg GrafanaWebhook
-	
-	// Safely extract fields from the raw body map with type assertions
-	if title, ok := rawBody["title"].(string); ok {
-		grafanaMsg.Title = title
-	}
-	if message, ok := rawBody["message"].(string); ok {
-		grafanaMsg.Message = message
-	}
-	if status, ok := rawBody["status"].(string); ok {
-		grafanaMsg.Status = status
-	}
-	if state, ok := rawBody["state"].(string); ok {
-		grafanaMsg.State = state
-	}
-	
-	// Determine priority based on Grafana alert status
-	priority := 5
-	if grafanaMsg.Status == "firing" || grafanaMsg.State == "alerting" {
-		priority = 8  // High priority for firing alerts
-	} else if grafanaMsg.Status == "resolved" || grafanaMsg.State == "ok" {
-		priority = 3  // Lower priority for resolved alerts
-	}
-	
-	// Use Grafana's title if available, otherwise construct one
-	title := grafanaMsg.Title
-	if title == "" {
-		if grafanaMsg.Status != "" {
-			title = "Grafana Alert: " + grafanaMsg.Status
-		} else {
-			title = "Grafana Alert"
-		}
-	}
-	
-	// Use Grafana's message if available
-	message := grafanaMsg.Message
-	if message == "" {
-		message = "Alert notification from Grafana"
-	}
-	
-	// Build extras with relevant Grafana data
-	extras := make(map[string]interface{})
-	extras["source"] = "grafana"
-	if grafanaMsg.Status != "" {
-		extras["status"] = grafanaMsg.Status
+// sendMessage applies the configured post-processing to a message and sends
+// it to the Gotify user. It returns true if the message was sent; otherwise
+// the HTTP response has already been written.
+func (p *WebhookForwarderPlugin) sendMessage(c *gin.Context, source string, msg plugin.Message) bool {
+	if err := p.deliverMessage(c, msg); err != nil {
+		p.writeDeliveryError(c, source, err)
+		return false
 	}
-	if grafanaMsg.State != "" {
-		extras["state"] = grafanaMsg.State
+	return true
+}
+
+// deliverMessage applies the configured post-processing to a message and
+// sends it to the Gotify user without writing an HTTP response. Messages
+// dropped during quiet hours are reported as errQuietHours.
+func (p *WebhookForwarderPlugin) deliverMessage(c *gin.Context, msg plugin.Message) error {
+	config := p.getConfig()
+	if org := grafanaOrgFromContext(c); org != nil {
+		msg = org.label(msg)
 	}
-	if externalURL, ok := rawBody["externalURL"].(string); ok && externalURL != "" {
-		extras["externalURL"] = externalURL
+	msg.Title = config.decorateTitle(msg.Title)
+	msg.Extras = mergeExtras(config.DefaultExtras, msg.Extras)
+	if route := routeFromContext(c); route != nil {
+		msg.Extras = withExtra(msg.Extras, "route", route.Path)
 	}
-	if dashboardURL, ok := rawBody["dashboardURL"].(string); ok && dashboardURL != "" {
-		extras["dashboardURL"] = dashboardURL
+
+	priority, deliver := config.QuietHours.apply(msg.Priority, timeNow())
+	if !deliver {
+		return errQuietHours
 	}
-	if silenceURL, ok := rawBody["silenceURL"].(string); ok && silenceURL != "" {
-		extras["silenceURL"] = silenceURL
+	msg.Priority = config.clampPriority(priority)
+	msg.Message = limitMessage(msg.Message, config.MaxMessageLength, config.TruncateStrategy)
+
+	if p.msgHandler == nil {
+		return errNoMessageHandler
 	}
-	
-	// Forward message to Gotify user
-	if p.msgHandler != nil {
-		err := p.msgHandler.SendMessage(plugin.Message{
-			Title:    title,
-			Message:  message,
-			Priority: priority,
-			Extras:   extras,
-		})
-		
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to forward Grafana alert",
-				"details": err.Error(),
-			})
-			return
-		}
-	} else {
+	return p.msgHandler.SendMessage(msg)
+}
+
+// writeDeliveryError writes the HTTP response for a message that could not
+// be delivered.
+func (p *WebhookForwarderPlugin) writeDeliveryError(c *gin.Context, source string, err error) {
+	switch err {
+	case errQuietHours:
+		p.skipMessage(c, source, skipMuted, "Message dropped during quiet hours")
+	case errNoMessageHandler:
 		c.JSON(http.StatusServiceUnavailable, gin.H{
 			"error": "Message handler not available",
 		})
-		return
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to forward message",
+			"details": err.Error(),
+		})
 	}
-	
-	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"message": "Grafana alert forwarded successfully",
-		"type": "grafana",
-	})
 }
 
 // handleInfo provides information about the webhook endpoint
@@ -397,6 +502,16 @@ func (p *WebhookForwarderPlugin) handleInfo(c *gin.Context) {
 		}
 	}()
 	
+	routes := gin.H{}
+	for _, route := range p.getConfig().Routes {
+		routes[route.Path] = gin.H{
+			"method": "POST",
+			"path": c.Request.URL.Path + route.Path,
+			"title": route.Title,
+			"priority": route.Priority,
+		}
+	}
+	
 	info := gin.H{
 		"plugin": "Webhook Forwarder",
 		"version": "1.0.0", 
@@ -432,7 +547,23 @@ func (p *WebhookForwarderPlugin) handleInfo(c *gin.Context) {
 				"path": c.Request.URL.Path,
 				"description": "Get this plugin information and usage examples",
 			},
+			"ntfy": gin.H{
+				"methods": []string{"POST", "PUT"},
+				"path": c.Request.URL.Path + "ntfy/{topic}",
+				"description": "ntfy compatible publish endpoint. Plain-text body with X-Title, X-Priority (1-5), X-Tags and X-Click headers",
+			},
+			"pagerduty": gin.H{
+				"method": "POST",
+				"path": c.Request.URL.Path + "pagerduty",
+				"description": "PagerDuty Events API v2 compatible endpoint (routing_key, event_action, dedup_key, payload.summary/source/severity)",
+			},
+			"config": gin.H{
+				"methods": []string{"GET", "PUT"},
+				"path": c.Request.URL.Path + "config",
+				"description": "Export or import the YAML config. Requires configToken as 'Authorization: Bearer <token>'",
+			},
 		},
+		"routes": routes,
 	}
 	
 	c.JSON(http.StatusOK, info)
@@ -442,6 +573,7 @@ func (p *WebhookForwarderPlugin) handleInfo(c *gin.Context) {
 func NewGotifyPluginInstance(ctx plugin.UserContext) plugin.Plugin {
 	return &WebhookForwarderPlugin{
 		userCtx: ctx,
+		config:  defaultConfig(),
 	}
 }
 
@@ -30,6 +30,8 @@ func TestAPICompatibility(t *testing.T) {
 	assert.Implements(t, (*plugin.Webhooker)(nil), p)
 	assert.Implements(t, (*plugin.Messenger)(nil), p)
 	assert.Implements(t, (*plugin.Displayer)(nil), p)
+	assert.Implements(t, (*plugin.Configurer)(nil), p)
+	assert.Implements(t, (*plugin.Storager)(nil), p)
 }
 
 func TestWebhookForwarderPlugin_Enable(t *testing.T) {
@@ -48,10 +50,12 @@ func TestWebhookForwarderPlugin_Disable(t *testing.T) {
 type MockMessageHandler struct {
 	sentMessages []plugin.Message
 	shouldFail   bool
+	// failAfter fails the messages after this many were sent, when set
+	failAfter int
 }
 
 func (m *MockMessageHandler) SendMessage(msg plugin.Message) error {
-	if m.shouldFail {
+	if m.shouldFail || (m.failAfter > 0 && len(m.sentMessages) >= m.failAfter) {
 		return assert.AnError
 	}
 	m.sentMessages = append(m.sentMessages, msg)
@@ -241,4 +245,28 @@ func TestWebhookForwarderPlugin_GetDisplay(t *testing.T) {
 	display = p.GetDisplay(location)
 	assert.Contains(t, display, "https://gotify.example.com/plugin/5/message")
 	assert.Contains(t, display, "testuser")
-}
\ No newline at end of file
+}
+
+// postWebhook sends payload as JSON to the plugin's message endpoint.
+func postWebhook(p *WebhookForwarderPlugin, payload interface{}) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/message", p.handleWebhookMessage)
+
+	body, _ := json.Marshal(payload)
+	req := httptest.NewRequest("POST", "/message", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// newTestPlugin returns a plugin with in-memory storage running config, and
+// the handler receiving its messages.
+func newTestPlugin(t *testing.T, config *Config) (*WebhookForwarderPlugin, *MockMessageHandler) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+	p.SetStorageHandler(&MockStorageHandler{})
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+	return p, mockHandler
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/gotify/plugin-api"
+)
+
+// portainerEventPriorities derives the priority of Portainer events from
+// their action: failures and environments going down 8, successful
+// deployments and environments coming back 3.
+var portainerEventPriorities = keywordPriorities{
+	{[]string{"fail", "error", "down", "offline", "unhealthy", "unreachable"}, 8},
+	{[]string{"deployed", "started", "updated", "up", "online", "healthy", "created"}, 3},
+}
+
+// portainerObjects lists the event prefixes of Portainer events for stacks,
+// environments (endpoints in older versions) and containers.
+var portainerObjects = []string{"stack", "environment", "endpoint", "container", "service"}
+
+// isPortainerPayload detects Portainer stack and environment events, named
+// like "stack.deployed" or "endpoint.down".
+func isPortainerPayload(body map[string]interface{}) bool {
+	if sourceIs(body, "portainer") {
+		return true
+	}
+	object, _, ok := strings.Cut(stringField(body, "event", "type", "action"), ".")
+	if !ok || !hasAnyField(body, "environment", "endpoint", "environmentName", "endpointName") {
+		return false
+	}
+	for _, known := range portainerObjects {
+		if object == known {
+			return true
+		}
+	}
+	return false
+}
+
+// formatPortainerPayload renders a Portainer event with the object, action
+// and environment in the title, e.g. "Portainer: stack web deploy failed on
+// production".
+func formatPortainerPayload(body map[string]interface{}, _ *Config) plugin.Message {
+	event := stringField(body, "event", "type", "action")
+	object, action, ok := strings.Cut(event, ".")
+	if !ok {
+		object, action = "", event
+	}
+	action = strings.NewReplacer("_", " ", "-", " ").Replace(action)
+	environment := portainerName(body, "environment", "endpoint")
+
+	subject := object
+	switch object {
+	case "stack", "container", "service":
+		if name := portainerName(body, object); name != "" {
+			subject = object + " " + name
+		}
+	case "environment", "endpoint":
+		subject = "environment " + environment
+	}
+	title := strings.TrimSpace("Portainer: " + subject + " " + action)
+	if environment != "" && object != "environment" && object != "endpoint" {
+		title += " on " + environment
+	}
+
+	var lines []string
+	if text := strings.TrimSpace(stringField(body, "message", "details", "error")); text != "" {
+		lines = append(lines, text)
+	}
+	if status := stringField(body, "status"); status != "" {
+		lines = append(lines, "Status: "+status)
+	}
+	if user := stringField(body, "user", "username"); user != "" {
+		lines = append(lines, "User: "+user)
+	}
+	message := strings.Join(lines, "\n")
+	if message == "" {
+		message = title
+	}
+
+	extras := map[string]interface{}{"source": "portainer"}
+	for key, value := range map[string]string{
+		"event":       event,
+		"environment": environment,
+	} {
+		if value != "" {
+			extras[key] = value
+		}
+	}
+	if url := stringField(body, "url", "portainerUrl"); url != "" {
+		extras["client::notification"] = map[string]interface{}{
+			"click": map[string]interface{}{"url": url},
+		}
+	}
+
+	return plugin.Message{
+		Title:    title,
+		Message:  message,
+		Priority: portainerEventPriorities.priority(action+" "+stringField(body, "status"), 5),
+		Extras:   extras,
+	}
+}
+
+// portainerName returns the name of a Portainer object, given as an object
+// with a name, as a string or as a "<key>Name" field.
+func portainerName(body map[string]interface{}, keys ...string) string {
+	for _, key := range keys {
+		switch value := body[key].(type) {
+		case map[string]interface{}:
+			if name := stringField(value, "name", "Name"); name != "" {
+				return name
+			}
+		case string:
+			if value != "" {
+				return value
+			}
+		}
+		if name := stringField(body, key+"Name"); name != "" {
+			return name
+		}
+	}
+	return ""
+}
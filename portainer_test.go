@@ -0,0 +1,54 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gotify/plugin-api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_PortainerWebhook(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	w := postWebhook(p, map[string]interface{}{
+		"event":       "stack.deploy_failed",
+		"environment": map[string]interface{}{"id": 2.0, "name": "production"},
+		"stack":       map[string]interface{}{"id": 7.0, "name": "web"},
+		"message":     "failed to pull image nginx:1.27: manifest unknown",
+		"user":        "admin",
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title:    "Portainer: stack web deploy failed on production",
+		Message:  "failed to pull image nginx:1.27: manifest unknown\nUser: admin",
+		Priority: 8,
+		Extras: map[string]interface{}{
+			"source":      "portainer",
+			"event":       "stack.deploy_failed",
+			"environment": "production",
+		},
+	}, mockHandler.sentMessages[0])
+
+	msg := formatPortainerPayload(map[string]interface{}{
+		"event":        "endpoint.down",
+		"endpointName": "edge-01",
+		"status":       "offline",
+		"portainerUrl": "https://portainer.example.com/#!/endpoints",
+	}, defaultConfig())
+	assert.Equal(t, "Portainer: environment edge-01 down", msg.Title)
+	assert.Equal(t, 8, msg.Priority)
+
+	msg = formatPortainerPayload(map[string]interface{}{
+		"event":       "stack.deployed",
+		"environment": "production",
+		"stack":       "web",
+	}, defaultConfig())
+	assert.Equal(t, "Portainer: stack web deployed on production", msg.Title)
+	assert.Equal(t, 3, msg.Priority)
+
+	assert.False(t, isPortainerPayload(map[string]interface{}{"event": "user.login", "environment": "prod"}))
+}
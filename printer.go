@@ -0,0 +1,223 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gotify/plugin-api"
+)
+
+// PrinterConfig holds options for 3D printer notifications.
+type PrinterConfig struct {
+	// SnapshotURL is the webcam snapshot URL of the printer, e.g.
+	// http://octopi/webcam/?action=snapshot, shown as big image of finished
+	// and failed prints whose payload has no snapshot URL.
+	SnapshotURL string `yaml:"snapshotUrl"`
+}
+
+// printerPriorities maps print states onto priorities. Failed prints get 8
+// as a failing printer may need attention right away.
+var printerPriorities = map[string]int{
+	"failed":        8,
+	"needs action":  7,
+	"paused":        5,
+	"cancelled":     4,
+	"done":          4,
+	"started":       3,
+	"resumed":       3,
+	"in progress":   2,
+	"status update": 2,
+}
+
+// printJob is a print reported by a printer host.
+type printJob struct {
+	source, service string
+	printer, file   string
+	state, text     string
+	progress        int
+	elapsed, left   time.Duration
+	snapshot        string
+}
+
+// printerState normalises an event name like "Print Done" or "complete" to
+// a print state.
+func printerState(event string) string {
+	event = strings.ToLower(event)
+	switch {
+	case strings.Contains(event, "fail"), strings.Contains(event, "error"):
+		return "failed"
+	case strings.Contains(event, "action"):
+		return "needs action"
+	case strings.Contains(event, "cancel"):
+		return "cancelled"
+	case strings.Contains(event, "pause"):
+		return "paused"
+	case strings.Contains(event, "resume"):
+		return "resumed"
+	case strings.Contains(event, "done"), strings.Contains(event, "complete"), strings.Contains(event, "finish"):
+		return "done"
+	case strings.Contains(event, "start"):
+		return "started"
+	case strings.Contains(event, "progress"), strings.Contains(event, "printing"):
+		return "in progress"
+	}
+	return "status update"
+}
+
+// isOctoPrintPayload detects the default payload of the OctoPrint-Webhooks
+// plugin, which names the event in "topic".
+func isOctoPrintPayload(body map[string]interface{}) bool {
+	return stringField(body, "topic") != "" &&
+		hasAnyField(body, "deviceIdentifier", "progress", "job") && hasAnyField(body, "message", "extra", "state")
+}
+
+// formatOctoPrintPayload renders an OctoPrint event, e.g. "OctoPrint: print
+// failed — benchy.gcode".
+func formatOctoPrintPayload(body map[string]interface{}, config *Config) plugin.Message {
+	extra, _ := body["extra"].(map[string]interface{})
+	job, _ := body["job"].(map[string]interface{})
+	file, _ := job["file"].(map[string]interface{})
+	progress, _ := body["progress"].(map[string]interface{})
+
+	run := printJob{
+		source:   "octoprint",
+		service:  "OctoPrint",
+		printer:  stringField(body, "deviceIdentifier"),
+		file:     stringField(extra, "name"),
+		state:    printerState(stringField(body, "topic")),
+		text:     stringField(body, "message"),
+		progress: intField(progress, "completion"),
+		elapsed:  time.Duration(intField(progress, "printTime")) * time.Second,
+		left:     time.Duration(intField(progress, "printTimeLeft")) * time.Second,
+		snapshot: stringField(body, "snapshotUrl", "snapshot_url", "webcamUrl"),
+	}
+	if run.file == "" {
+		run.file = stringField(file, "display", "name")
+	}
+	if seconds := intField(extra, "time"); seconds > 0 {
+		run.elapsed = time.Duration(seconds) * time.Second
+	}
+	if reason := stringField(extra, "reason"); reason == "cancelled" && run.state == "failed" {
+		run.state = "cancelled"
+	}
+	return printerMessage(run, config)
+}
+
+// isMoonrakerPayload detects Moonraker notifier messages sent through the
+// Apprise JSON notifier (json://), tagged with ":source=moonraker" or
+// "klipper" as Apprise payloads carry no sender, or payloads with the
+// print_stats object of Moonraker's printer API, e.g. relayed by a script.
+func isMoonrakerPayload(body map[string]interface{}) bool {
+	if sourceIs(body, "moonraker") || sourceIs(body, "klipper") {
+		return hasAnyField(body, "message", "title", "event", "print_stats")
+	}
+	stats, ok := body["print_stats"].(map[string]interface{})
+	return ok && hasFields(stats, "state", "filename")
+}
+
+// formatMoonrakerPayload renders a Moonraker notification. The print state
+// is taken from the event, the Apprise type or the print_stats state.
+func formatMoonrakerPayload(body map[string]interface{}, config *Config) plugin.Message {
+	stats, _ := body["print_stats"].(map[string]interface{})
+	status, _ := body["display_status"].(map[string]interface{})
+
+	event := stringField(body, "event")
+	if event == "" {
+		event = stringField(stats, "state")
+	}
+	if event == "" {
+		event = stringField(body, "title")
+		if stringField(body, "type") == "failure" {
+			event = "failure"
+		}
+	}
+
+	run := printJob{
+		source:   "moonraker",
+		service:  "Moonraker",
+		printer:  stringField(body, "printer", "host"),
+		file:     stringField(body, "filename", "file"),
+		state:    printerState(event),
+		text:     stringField(body, "message", "body"),
+		snapshot: stringField(body, "snapshotUrl", "snapshot_url", "webcamUrl"),
+	}
+	if run.file == "" {
+		run.file = stringField(stats, "filename")
+	}
+	if seconds, ok := stats["print_duration"].(float64); ok {
+		run.elapsed = time.Duration(seconds) * time.Second
+	}
+	if progress, ok := status["progress"].(float64); ok {
+		run.progress = int(progress*100 + 0.5)
+	} else {
+		run.progress = intField(body, "progress")
+	}
+	if run.text == "" {
+		run.text = stringField(stats, "message")
+	}
+	return printerMessage(run, config)
+}
+
+// printerMessage renders a print titled by service, state and file. Clicking
+// opens the webcam snapshot of finished and failed prints, which is also
+// shown as big image.
+func printerMessage(run printJob, config *Config) plugin.Message {
+	title := run.service + ": print " + run.state
+	if run.file != "" {
+		title += " — " + run.file
+	}
+
+	var paragraphs, lines []string
+	if text := strings.TrimSpace(run.text); text != "" {
+		paragraphs = append(paragraphs, text)
+	}
+	if run.printer != "" {
+		lines = append(lines, "Printer: "+run.printer)
+	}
+	if run.progress > 0 && run.state != "done" {
+		lines = append(lines, fmt.Sprintf("Progress: %d%%", run.progress))
+	}
+	if run.elapsed > 0 {
+		lines = append(lines, "Print time: "+humanizeDuration(run.elapsed))
+	}
+	if run.left > 0 && run.state != "done" {
+		lines = append(lines, "Time left: "+humanizeDuration(run.left))
+	}
+	if len(lines) > 0 {
+		paragraphs = append(paragraphs, strings.Join(lines, "\n"))
+	}
+	message := strings.Join(paragraphs, "\n\n")
+	if message == "" {
+		message = title
+	}
+
+	extras := map[string]interface{}{
+		"source": run.source,
+		"state":  run.state,
+	}
+	if run.file != "" {
+		extras["file"] = run.file
+	}
+	snapshot := run.snapshot
+	if snapshot == "" && (run.state == "done" || run.state == "failed" || run.state == "needs action") {
+		snapshot = config.Printer.SnapshotURL
+	}
+	if snapshot != "" {
+		extras["client::notification"] = map[string]interface{}{
+			"bigImageUrl": snapshot,
+			"click":       map[string]interface{}{"url": snapshot},
+		}
+	}
+
+	priority, ok := printerPriorities[run.state]
+	if !ok {
+		priority = 5
+	}
+	return plugin.Message{
+		Title:    title,
+		Message:  message,
+		Priority: priority,
+		Extras:   extras,
+	}
+}
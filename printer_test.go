@@ -0,0 +1,145 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gotify/plugin-api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_OctoPrintFailed(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+	p.SetStorageHandler(&MockStorageHandler{})
+	config := defaultConfig()
+	config.Printer.SnapshotURL = "http://octopi/webcam/?action=snapshot"
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+
+	w := postWebhook(p, map[string]interface{}{
+		"deviceIdentifier": "octopi",
+		"apiSecret":        "",
+		"topic":            "Print Failed",
+		"message":          "Something went wrong and your print has failed.",
+		"extra": map[string]interface{}{
+			"name":   "benchy.gcode",
+			"origin": "local",
+			"reason": "error",
+			"time":   1530.0,
+		},
+		"progress": map[string]interface{}{
+			"completion":    42.5,
+			"printTime":     1530.0,
+			"printTimeLeft": 2100.0,
+		},
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title:    "OctoPrint: print failed — benchy.gcode",
+		Message:  "Something went wrong and your print has failed.\n\nPrinter: octopi\nProgress: 42%\nPrint time: 25m\nTime left: 35m",
+		Priority: 8,
+		Extras: map[string]interface{}{
+			"source": "octoprint",
+			"state":  "failed",
+			"file":   "benchy.gcode",
+			"client::notification": map[string]interface{}{
+				"bigImageUrl": "http://octopi/webcam/?action=snapshot",
+				"click":       map[string]interface{}{"url": "http://octopi/webcam/?action=snapshot"},
+			},
+		},
+	}, mockHandler.sentMessages[0])
+}
+
+func TestWebhookForwarderPlugin_OctoPrintProgress(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+	p.SetStorageHandler(&MockStorageHandler{})
+	config := defaultConfig()
+	config.Printer.SnapshotURL = "http://octopi/webcam/?action=snapshot"
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+
+	w := postWebhook(p, map[string]interface{}{
+		"deviceIdentifier": "octopi",
+		"topic":            "Print Progress",
+		"message":          "Your print is 50% complete.",
+		"job": map[string]interface{}{
+			"file": map[string]interface{}{"name": "benchy.gcode", "display": "Benchy.gcode"},
+		},
+		"progress": map[string]interface{}{"completion": 50.0},
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	msg := mockHandler.sentMessages[0]
+	assert.Equal(t, "OctoPrint: print in progress — Benchy.gcode", msg.Title)
+	assert.Equal(t, 2, msg.Priority)
+	assert.NotContains(t, msg.Extras, "client::notification")
+}
+
+func TestWebhookForwarderPlugin_MoonrakerPayloads(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     map[string]interface{}
+		title    string
+		message  string
+		priority int
+	}{
+		{
+			name: "apprise json",
+			body: map[string]interface{}{
+				"version": "1.0",
+				"source":  "moonraker",
+				"title":   "Print complete",
+				"message": "voron.gcode finished printing",
+				"type":    "success",
+			},
+			title:    "Moonraker: print done",
+			message:  "voron.gcode finished printing",
+			priority: 4,
+		},
+		{
+			name: "apprise failure",
+			body: map[string]interface{}{
+				"source":  "klipper",
+				"title":   "Klipper",
+				"message": "MCU 'mcu' shutdown: Timer too close",
+				"type":    "failure",
+			},
+			title:    "Moonraker: print failed",
+			message:  "MCU 'mcu' shutdown: Timer too close",
+			priority: 8,
+		},
+		{
+			name: "print stats",
+			body: map[string]interface{}{
+				"printer": "voron",
+				"print_stats": map[string]interface{}{
+					"state":          "printing",
+					"filename":       "cube.gcode",
+					"print_duration": 3725.0,
+				},
+				"display_status": map[string]interface{}{"progress": 0.314},
+			},
+			title:    "Moonraker: print in progress — cube.gcode",
+			message:  "Printer: voron\nProgress: 31%\nPrint time: 1h 2m",
+			priority: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockHandler := &MockMessageHandler{}
+			p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+			w := postWebhook(p, tt.body)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Len(t, mockHandler.sentMessages, 1)
+			assert.Equal(t, tt.title, mockHandler.sentMessages[0].Title)
+			assert.Equal(t, tt.message, mockHandler.sentMessages[0].Message)
+			assert.Equal(t, tt.priority, mockHandler.sentMessages[0].Priority)
+		})
+	}
+}
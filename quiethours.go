@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// timeNow returns the current time and is replaced in tests.
+var timeNow = time.Now
+
+// QuietHoursConfig configures a daily window during which low priority
+// messages are dropped or downgraded.
+type QuietHoursConfig struct {
+	// Start and End are "HH:MM" times; the window may span midnight.
+	// Quiet hours are disabled when either is empty.
+	Start string `yaml:"start"`
+	End   string `yaml:"end"`
+	// Timezone is an IANA zone name, defaults to the server's local time.
+	Timezone string `yaml:"timezone"`
+	// MinPriority is the lowest priority still delivered unchanged.
+	MinPriority int `yaml:"minPriority"`
+	// Action is "downgrade" (deliver with DowngradePriority) or "drop".
+	Action string `yaml:"action"`
+	// DowngradePriority is the priority (0-10) of downgraded messages.
+	DowngradePriority int `yaml:"downgradePriority"`
+
+	startMinute int
+	endMinute   int
+	location    *time.Location
+}
+
+// enabled reports whether a quiet hours window is configured.
+func (q *QuietHoursConfig) enabled() bool {
+	return q.Start != "" && q.End != ""
+}
+
+// validate parses the configured window and timezone.
+func (q *QuietHoursConfig) validate() error {
+	if !q.enabled() {
+		return nil
+	}
+
+	var err error
+	if q.startMinute, err = parseClock(q.Start); err != nil {
+		return fmt.Errorf("invalid quietHours.start: %w", err)
+	}
+	if q.endMinute, err = parseClock(q.End); err != nil {
+		return fmt.Errorf("invalid quietHours.end: %w", err)
+	}
+
+	q.location = time.Local
+	if q.Timezone != "" {
+		if q.location, err = time.LoadLocation(q.Timezone); err != nil {
+			return fmt.Errorf("invalid quietHours.timezone: %w", err)
+		}
+	}
+
+	switch strings.ToLower(q.Action) {
+	case "", "downgrade", "drop":
+	default:
+		return fmt.Errorf("invalid quietHours.action %q, must be downgrade or drop", q.Action)
+	}
+	if q.DowngradePriority < 0 || q.DowngradePriority > 10 {
+		return fmt.Errorf("invalid quietHours.downgradePriority %d, must be between 0 and 10", q.DowngradePriority)
+	}
+	return nil
+}
+
+// active reports whether t falls inside the quiet hours window.
+func (q *QuietHoursConfig) active(t time.Time) bool {
+	if !q.enabled() || q.location == nil || q.startMinute == q.endMinute {
+		return false
+	}
+	local := t.In(q.location)
+	minute := local.Hour()*60 + local.Minute()
+	if q.startMinute < q.endMinute {
+		return minute >= q.startMinute && minute < q.endMinute
+	}
+	// Window spans midnight
+	return minute >= q.startMinute || minute < q.endMinute
+}
+
+// apply returns the priority to deliver a message with during quiet hours
+// and whether the message should be delivered at all.
+func (q *QuietHoursConfig) apply(priority int, t time.Time) (int, bool) {
+	if priority >= q.MinPriority || !q.active(t) {
+		return priority, true
+	}
+	if strings.EqualFold(q.Action, "drop") {
+		return priority, false
+	}
+	return q.DowngradePriority, true
+}
+
+// parseClock parses "HH:MM" into minutes after midnight.
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("%q is not in HH:MM format", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuietHoursConfig_Active(t *testing.T) {
+	q := QuietHoursConfig{Start: "22:00", End: "07:00", Timezone: "UTC"}
+	assert.NoError(t, q.validate())
+
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	assert.True(t, q.active(day.Add(23*time.Hour)))
+	assert.True(t, q.active(day.Add(6*time.Hour+59*time.Minute)))
+	assert.False(t, q.active(day.Add(7*time.Hour)))
+	assert.False(t, q.active(day.Add(12*time.Hour)))
+
+	q = QuietHoursConfig{Start: "01:00", End: "05:00", Timezone: "UTC"}
+	assert.NoError(t, q.validate())
+	assert.True(t, q.active(day.Add(3*time.Hour)))
+	assert.False(t, q.active(day.Add(23*time.Hour)))
+}
+
+func TestQuietHoursConfig_Validate(t *testing.T) {
+	assert.NoError(t, (&QuietHoursConfig{}).validate())
+	assert.Error(t, (&QuietHoursConfig{Start: "25:00", End: "07:00"}).validate())
+	assert.Error(t, (&QuietHoursConfig{Start: "22:00", End: "07:00", Timezone: "Mars/Olympus"}).validate())
+	assert.Error(t, (&QuietHoursConfig{Start: "22:00", End: "07:00", Action: "mute"}).validate())
+	assert.Error(t, (&QuietHoursConfig{Start: "22:00", End: "07:00", DowngradePriority: 11}).validate())
+	assert.Error(t, (&QuietHoursConfig{Start: "22:00", End: "07:00", DowngradePriority: -1}).validate())
+	assert.Equal(t, 1, defaultConfig().QuietHours.DowngradePriority)
+}
+
+func TestWebhookForwarderPlugin_QuietHours(t *testing.T) {
+	defer func() { timeNow = time.Now }()
+	timeNow = func() time.Time { return time.Date(2024, 5, 1, 23, 30, 0, 0, time.UTC) }
+
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+	config := defaultConfig()
+	config.QuietHours = QuietHoursConfig{Start: "22:00", End: "07:00", Timezone: "UTC", MinPriority: 8, Action: "downgrade", DowngradePriority: 1}
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+
+	postWebhook(p, map[string]interface{}{"message": "low", "priority": 4})
+	postWebhook(p, map[string]interface{}{"message": "high", "priority": 9})
+	assert.Len(t, mockHandler.sentMessages, 2)
+	assert.Equal(t, 1, mockHandler.sentMessages[0].Priority)
+	assert.Equal(t, 9, mockHandler.sentMessages[1].Priority)
+
+	config.QuietHours.Action = "drop"
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+	w := postWebhook(p, map[string]interface{}{"message": "low", "priority": 4})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 2)
+}
@@ -0,0 +1,13 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRandomID(t *testing.T) {
+	id := randomID()
+	assert.Regexp(t, "^[0-9a-f]{12}$", id)
+	assert.NotEqual(t, id, randomID())
+}
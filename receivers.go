@@ -0,0 +1,35 @@
+package main
+
+// receiver returns the profile configured for a Grafana contact point, or
+// nil if there is none.
+func (g *GrafanaConfig) receiver(name string) *SourceConfig {
+	if name == "" {
+		return nil
+	}
+	return g.Receivers[name]
+}
+
+// withOverrides returns a copy of the profile with the settings of override
+// applied on top. Unset settings of override keep those of the profile.
+func (s *SourceConfig) withOverrides(override *SourceConfig) *SourceConfig {
+	merged := *s
+	if override == nil {
+		return &merged
+	}
+	if override.Enabled != nil {
+		merged.Enabled = override.Enabled
+	}
+	if override.Priority > 0 {
+		merged.Priority = override.Priority
+	}
+	if override.Title != "" {
+		merged.Title = override.Title
+	}
+	if override.titleTmpl != nil {
+		merged.TitleTemplate, merged.titleTmpl = override.TitleTemplate, override.titleTmpl
+	}
+	if override.messageTmpl != nil {
+		merged.MessageTemplate, merged.messageTmpl = override.MessageTemplate, override.messageTmpl
+	}
+	return &merged
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_GrafanaReceivers(t *testing.T) {
+	config, err := parseConfigYAML([]byte(`
+sources:
+  grafana:
+    priority: 6
+grafana:
+  receivers:
+    oncall:
+      priority: 10
+      titleTemplate: "PAGE: {{ .CommonLabels.alertname }}"
+    noise:
+      enabled: false
+`))
+	assert.NoError(t, err)
+	p, mockHandler := newTestPlugin(t, config)
+
+	payload := grafanaNotification(grafanaAlert("firing", "alertname", "HighCPU"))
+	payload["receiver"] = "oncall"
+	payload["commonLabels"] = map[string]interface{}{"alertname": "HighCPU"}
+	w := postWebhook(p, payload)
+	assert.Equal(t, http.StatusOK, w.Code)
+	msg := mockHandler.sentMessages[0]
+	assert.Equal(t, "PAGE: HighCPU", msg.Title)
+	assert.Equal(t, 10, msg.Priority)
+	assert.Equal(t, "oncall", msg.Extras["receiver"])
+
+	// Unknown receivers use the source profile
+	payload["receiver"] = "team-db"
+	postWebhook(p, payload)
+	msg = mockHandler.sentMessages[1]
+	assert.Equal(t, "1 firing", msg.Title)
+	assert.Equal(t, 6, msg.Priority)
+
+	payload["receiver"] = "noise"
+	w = postWebhook(p, payload)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 2)
+}
+
+func TestConfig_ValidateReceiverTemplates(t *testing.T) {
+	config := defaultConfig()
+	config.Grafana.Receivers = map[string]*SourceConfig{"oncall": {TitleTemplate: "{{ .Missing.Field }"}}
+	assert.Error(t, config.validate())
+
+	config.Grafana.Receivers = map[string]*SourceConfig{"oncall": {MessageTemplate: "{{ index .Alerts 5 }}"}}
+	assert.ErrorContains(t, config.validate(), "grafana.receivers.oncall.messageTemplate")
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gotify/plugin-api"
+)
+
+// registryActionPriorities maps registry actions onto priorities. Pulls are
+// reported at the lowest priority as registries send them for every client.
+var registryActionPriorities = map[string]int{
+	"push":   3,
+	"delete": 4,
+	"pull":   1,
+}
+
+// isDockerHubPayload detects Docker Hub repository webhooks.
+func isDockerHubPayload(body map[string]interface{}) bool {
+	_, hasPush := body["push_data"].(map[string]interface{})
+	_, hasRepo := body["repository"].(map[string]interface{})
+	return hasPush && hasRepo
+}
+
+// formatDockerHubPayload renders an image push to Docker Hub, e.g. "Image
+// pushed: octo/app:latest". Clicking opens the repository.
+func formatDockerHubPayload(body map[string]interface{}, config *Config) plugin.Message {
+	push, _ := body["push_data"].(map[string]interface{})
+	repository, _ := body["repository"].(map[string]interface{})
+
+	image := stringField(repository, "repo_name")
+	if image == "" {
+		image = strings.Trim(stringField(repository, "namespace")+"/"+stringField(repository, "name"), "/")
+	}
+	if tag := stringField(push, "tag"); tag != "" {
+		image += ":" + tag
+	}
+
+	var lines []string
+	if pusher := stringField(push, "pusher"); pusher != "" {
+		lines = append(lines, "Pusher: "+pusher)
+	}
+	if pushed := intField(push, "pushed_at"); pushed > 0 {
+		lines = append(lines, "Pushed: "+config.formatTimestamp(time.Unix(int64(pushed), 0).UTC().Format(time.RFC3339)))
+	}
+
+	extras := map[string]interface{}{
+		"source": "dockerhub",
+		"image":  image,
+	}
+	if url := stringField(repository, "repo_url"); url != "" {
+		extras["client::notification"] = map[string]interface{}{
+			"click": map[string]interface{}{"url": url},
+		}
+	}
+	return registryMessage("Image pushed: "+image, lines, 3, extras)
+}
+
+// isRegistryEventsPayload detects the notification envelope of the Docker
+// Distribution registry (and compatible OCI registries), a list of events
+// with an action and a target.
+func isRegistryEventsPayload(body map[string]interface{}) bool {
+	events := mapSlice(body["events"])
+	if len(events) == 0 {
+		return false
+	}
+	_, hasTarget := events[0]["target"].(map[string]interface{})
+	return hasTarget && stringField(events[0], "action") != ""
+}
+
+// formatRegistryEventsPayload renders the manifest events of a registry
+// notification, e.g. "Image pushed: registry.example.com/app:1.2". Layer
+// (blob) events are left out. Several images are listed in the message.
+func formatRegistryEventsPayload(body map[string]interface{}, _ *Config) plugin.Message {
+	var images, lines []string
+	action := ""
+	priority := 0
+	for _, event := range mapSlice(body["events"]) {
+		target, _ := event["target"].(map[string]interface{})
+		if !strings.Contains(stringField(target, "mediaType"), "manifest") && stringField(target, "tag") == "" {
+			continue
+		}
+		image := stringField(target, "repository")
+		request, _ := event["request"].(map[string]interface{})
+		if host := stringField(request, "host"); host != "" {
+			image = host + "/" + image
+		}
+		if tag := stringField(target, "tag"); tag != "" {
+			image += ":" + tag
+		} else if digest := stringField(target, "digest"); digest != "" {
+			image += "@" + digest
+		}
+
+		eventAction := stringField(event, "action")
+		if value := registryActionPriorities[eventAction]; value > priority || action == "" {
+			action, priority = eventAction, value
+		}
+		images = append(images, image)
+		line := fmt.Sprintf("- %s %s", eventAction, image)
+		actor, _ := event["actor"].(map[string]interface{})
+		if name := stringField(actor, "name"); name != "" {
+			line += " by " + name
+		}
+		lines = append(lines, line)
+	}
+
+	title := "Registry event"
+	switch {
+	case len(images) == 1:
+		title = fmt.Sprintf("Image %s: %s", registryActionPast(action), images[0])
+		lines[0] = strings.TrimPrefix(lines[0], "- ")
+	case len(images) > 1:
+		title = fmt.Sprintf("%d images %s", len(images), registryActionPast(action))
+	}
+	if priority == 0 {
+		priority = 2
+	}
+
+	extras := map[string]interface{}{"source": "registry"}
+	if len(images) == 1 {
+		extras["image"] = images[0]
+	}
+	return registryMessage(title, lines, priority, extras)
+}
+
+// registryActionPast returns the past tense of a registry action.
+func registryActionPast(action string) string {
+	switch action {
+	case "push":
+		return "pushed"
+	case "pull":
+		return "pulled"
+	case "delete":
+		return "deleted"
+	case "mount":
+		return "mounted"
+	}
+	return action
+}
+
+// isHarborPayload detects webhooks of the Harbor registry.
+func isHarborPayload(body map[string]interface{}) bool {
+	data, ok := body["event_data"].(map[string]interface{})
+	return ok && stringField(body, "type") != "" && hasFields(data, "repository")
+}
+
+// formatHarborPayload renders a Harbor artifact event, e.g. "Image pushed:
+// harbor.example.com/library/nginx:latest". Failed scans and replications
+// get priority 7.
+func formatHarborPayload(body map[string]interface{}, _ *Config) plugin.Message {
+	kind := stringField(body, "type")
+	data, _ := body["event_data"].(map[string]interface{})
+	repository, _ := data["repository"].(map[string]interface{})
+
+	var images []string
+	for _, resource := range mapSlice(data["resources"]) {
+		if url := stringField(resource, "resource_url"); url != "" {
+			images = append(images, url)
+		}
+	}
+	subject := stringField(repository, "repo_full_name", "name")
+	if len(images) == 1 {
+		subject = images[0]
+	}
+
+	priority := 3
+	title := strings.ToLower(strings.ReplaceAll(kind, "_", " "))
+	switch kind {
+	case "PUSH_ARTIFACT":
+		title = "Image pushed"
+	case "PULL_ARTIFACT":
+		title, priority = "Image pulled", 1
+	case "DELETE_ARTIFACT":
+		title, priority = "Image deleted", 4
+	case "SCANNING_FAILED", "REPLICATION_FAILED":
+		priority = 7
+	}
+	title = capitalize(title) + ": " + subject
+
+	var lines []string
+	if len(images) > 1 {
+		for _, image := range images {
+			lines = append(lines, "- "+image)
+		}
+	}
+	if operator := stringField(body, "operator"); operator != "" {
+		lines = append(lines, "Operator: "+operator)
+	}
+
+	extras := map[string]interface{}{
+		"source": "harbor",
+		"event":  kind,
+	}
+	return registryMessage(title, lines, priority, extras)
+}
+
+// registryMessage assembles a registry notification, using the title as
+// message if there are no details.
+func registryMessage(title string, lines []string, priority int, extras map[string]interface{}) plugin.Message {
+	message := strings.Join(lines, "\n")
+	if message == "" {
+		message = title
+	}
+	return plugin.Message{
+		Title:    title,
+		Message:  message,
+		Priority: priority,
+		Extras:   extras,
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gotify/plugin-api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_DockerHubPush(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+	p.SetStorageHandler(&MockStorageHandler{})
+	config := defaultConfig()
+	config.Timezone = "UTC"
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+
+	w := postWebhook(p, map[string]interface{}{
+		"callback_url": "https://registry.hub.docker.com/u/octo/app/hook/2141b5bi5i5b02bec211i4eeih0242eg11000a/",
+		"push_data": map[string]interface{}{
+			"pushed_at": 1709288100.0,
+			"pusher":    "alice",
+			"tag":       "1.2.0",
+		},
+		"repository": map[string]interface{}{
+			"name":      "app",
+			"namespace": "octo",
+			"repo_name": "octo/app",
+			"repo_url":  "https://hub.docker.com/r/octo/app",
+		},
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title:    "Image pushed: octo/app:1.2.0",
+		Message:  "Pusher: alice\nPushed: 2024-03-01 10:15:00 UTC",
+		Priority: 3,
+		Extras: map[string]interface{}{
+			"source": "dockerhub",
+			"image":  "octo/app:1.2.0",
+			"client::notification": map[string]interface{}{
+				"click": map[string]interface{}{"url": "https://hub.docker.com/r/octo/app"},
+			},
+		},
+	}, mockHandler.sentMessages[0])
+}
+
+func TestWebhookForwarderPlugin_RegistryEvents(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	w := postWebhook(p, map[string]interface{}{
+		"events": []interface{}{
+			map[string]interface{}{
+				"action": "push",
+				"target": map[string]interface{}{
+					"mediaType":  "application/octet-stream",
+					"digest":     "sha256:a3ed95caeb02",
+					"repository": "app",
+				},
+			},
+			map[string]interface{}{
+				"action": "push",
+				"target": map[string]interface{}{
+					"mediaType":  "application/vnd.oci.image.manifest.v1+json",
+					"digest":     "sha256:fea8895f450959fa",
+					"repository": "app",
+					"tag":        "1.2.0",
+				},
+				"request": map[string]interface{}{"host": "registry.example.com"},
+				"actor":   map[string]interface{}{"name": "ci"},
+			},
+		},
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title:    "Image pushed: registry.example.com/app:1.2.0",
+		Message:  "push registry.example.com/app:1.2.0 by ci",
+		Priority: 3,
+		Extras: map[string]interface{}{
+			"source": "registry",
+			"image":  "registry.example.com/app:1.2.0",
+		},
+	}, mockHandler.sentMessages[0])
+}
+
+func TestWebhookForwarderPlugin_HarborPush(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	postWebhook(p, map[string]interface{}{
+		"type":     "PUSH_ARTIFACT",
+		"occur_at": 1709288100.0,
+		"operator": "admin",
+		"event_data": map[string]interface{}{
+			"resources": []interface{}{
+				map[string]interface{}{"digest": "sha256:fea8895f", "tag": "latest", "resource_url": "harbor.example.com/library/nginx:latest"},
+			},
+			"repository": map[string]interface{}{"name": "nginx", "namespace": "library", "repo_full_name": "library/nginx"},
+		},
+	})
+
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, "Image pushed: harbor.example.com/library/nginx:latest", mockHandler.sentMessages[0].Title)
+	assert.Equal(t, "Operator: admin", mockHandler.sentMessages[0].Message)
+	assert.Equal(t, 3, mockHandler.sentMessages[0].Priority)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// skipKind classifies why an accepted webhook was not forwarded.
+type skipKind int
+
+const (
+	// skipFiltered is used for messages removed by a filter rule.
+	skipFiltered skipKind = iota
+	// skipDuplicate is used for repeated notifications.
+	skipDuplicate
+	// skipMuted is used for messages muted by time based rules.
+	skipMuted
+)
+
+// ResponseCodesConfig holds the HTTP status codes returned for messages that
+// are accepted but not forwarded. Senders that retry on non-2xx responses
+// should keep 200 or 202. A value of 0 means 200.
+type ResponseCodesConfig struct {
+	Filtered  int `yaml:"filtered"`
+	Duplicate int `yaml:"duplicate"`
+	Muted     int `yaml:"muted"`
+}
+
+// validate checks that only supported status codes are configured.
+func (r *ResponseCodesConfig) validate() error {
+	codes := map[string]int{"filtered": r.Filtered, "duplicate": r.Duplicate, "muted": r.Muted}
+	for name, code := range codes {
+		switch code {
+		case 0, http.StatusOK, http.StatusAccepted, http.StatusConflict:
+		default:
+			return fmt.Errorf("invalid responseCodes.%s %d, must be 200, 202 or 409", name, code)
+		}
+	}
+	return nil
+}
+
+// statusFor returns the HTTP status code for a skipped message.
+func (r *ResponseCodesConfig) statusFor(kind skipKind) int {
+	code := 0
+	switch kind {
+	case skipFiltered:
+		code = r.Filtered
+	case skipDuplicate:
+		code = r.Duplicate
+	case skipMuted:
+		code = r.Muted
+	}
+	if code == 0 {
+		return http.StatusOK
+	}
+	return code
+}
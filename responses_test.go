@@ -0,0 +1,37 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResponseCodesConfig(t *testing.T) {
+	codes := ResponseCodesConfig{Filtered: 202, Muted: 409}
+	assert.NoError(t, codes.validate())
+	assert.Equal(t, http.StatusAccepted, codes.statusFor(skipFiltered))
+	assert.Equal(t, http.StatusOK, codes.statusFor(skipDuplicate))
+	assert.Equal(t, http.StatusConflict, codes.statusFor(skipMuted))
+
+	assert.Error(t, (&ResponseCodesConfig{Duplicate: 500}).validate())
+}
+
+func TestWebhookForwarderPlugin_SkippedResponseCodes(t *testing.T) {
+	defer func() { timeNow = time.Now }()
+	timeNow = func() time.Time { return time.Date(2024, 5, 1, 23, 30, 0, 0, time.UTC) }
+
+	p := &WebhookForwarderPlugin{msgHandler: &MockMessageHandler{}}
+	config := defaultConfig()
+	config.Grafana.NotifyOnResolved = false
+	config.QuietHours = QuietHoursConfig{Start: "22:00", End: "07:00", Timezone: "UTC", MinPriority: 8, Action: "drop"}
+	config.ResponseCodes = ResponseCodesConfig{Filtered: 202, Muted: 409}
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+
+	w := postWebhook(p, map[string]interface{}{"status": "resolved", "alerts": []interface{}{}})
+	assert.Equal(t, http.StatusAccepted, w.Code)
+
+	w = postWebhook(p, map[string]interface{}{"message": "low priority", "priority": 2})
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
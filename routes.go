@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+)
+
+// routeContextKey stores the matched RouteConfig in the gin context.
+const routeContextKey = "webhookRoute"
+
+// routePathPattern restricts route paths to a single URL segment.
+var routePathPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// reservedRoutePaths are endpoints registered by the plugin itself.
+var reservedRoutePaths = map[string]bool{"message": true, "config": true, "ntfy": true, "pagerduty": true}
+
+// RouteConfig defines a named webhook endpoint at POST /{path} whose
+// defaults apply to all payloads sent to it.
+type RouteConfig struct {
+	Path string `yaml:"path"`
+	// Title and Priority override those of the source profile, see
+	// SourceConfig. For generic webhooks they are used when the payload
+	// has no title or priority.
+	Title    string `yaml:"title"`
+	Priority int    `yaml:"priority"`
+}
+
+// validateRoutes checks that route paths are valid and unique.
+func validateRoutes(routes []RouteConfig) error {
+	seen := make(map[string]bool, len(routes))
+	for _, route := range routes {
+		switch {
+		case !routePathPattern.MatchString(route.Path):
+			return fmt.Errorf("invalid route path %q, only letters, digits, '-' and '_' are allowed", route.Path)
+		case reservedRoutePaths[route.Path]:
+			return fmt.Errorf("route path %q is reserved", route.Path)
+		case seen[route.Path]:
+			return fmt.Errorf("duplicate route path %q", route.Path)
+		}
+		seen[route.Path] = true
+	}
+	return nil
+}
+
+// route returns the route configured for path, or nil.
+func (c *Config) route(path string) *RouteConfig {
+	for i := range c.Routes {
+		if c.Routes[i].Path == path {
+			return &c.Routes[i]
+		}
+	}
+	return nil
+}
+
+// routeFromContext returns the route the request was sent to, or nil for
+// the default /message endpoint.
+func routeFromContext(c *gin.Context) *RouteConfig {
+	if c == nil {
+		return nil
+	}
+	if value, ok := c.Get(routeContextKey); ok {
+		if route, ok := value.(*RouteConfig); ok {
+			return route
+		}
+	}
+	return nil
+}
+
+// sourceProfile returns the profile for source with the defaults of the
+// request's route applied on top.
+func sourceProfile(c *gin.Context, config *Config, source string) *SourceConfig {
+	profile := *config.source(source)
+	if route := routeFromContext(c); route != nil {
+		if route.Title != "" {
+			profile.Title = route.Title
+		}
+		if route.Priority > 0 {
+			profile.Priority = route.Priority
+		}
+	}
+	return &profile
+}
+
+// handleRouteMessage processes webhooks sent to a named route. Routes are
+// looked up on every request, so config changes apply immediately.
+func (p *WebhookForwarderPlugin) handleRouteMessage(c *gin.Context) {
+	route := p.getConfig().route(c.Param("route"))
+	if route == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": fmt.Sprintf("Unknown route '%s'", c.Param("route")),
+		})
+		return
+	}
+	c.Set(routeContextKey, route)
+	p.handleWebhookMessage(c)
+}
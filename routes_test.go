@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_Routes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+	config := defaultConfig()
+	config.Routes = []RouteConfig{{Path: "backup", Title: "Backup", Priority: 9}}
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+
+	router := gin.New()
+	p.RegisterWebhook("/plugin/1/custom/token", router.Group("/"))
+
+	post := func(path string, payload interface{}) int {
+		body, _ := json.Marshal(payload)
+		req := httptest.NewRequest("POST", path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, post("/backup", map[string]interface{}{"message": "nightly backup failed"}))
+	assert.Equal(t, http.StatusOK, post("/backup", map[string]interface{}{"title": "Custom", "message": "x", "priority": 4}))
+	assert.Equal(t, http.StatusOK, post("/message", map[string]interface{}{"message": "plain"}))
+	assert.Equal(t, http.StatusNotFound, post("/unknown", map[string]interface{}{"message": "x"}))
+
+	assert.Len(t, mockHandler.sentMessages, 3)
+	assert.Equal(t, "Backup", mockHandler.sentMessages[0].Title)
+	assert.Equal(t, 9, mockHandler.sentMessages[0].Priority)
+	assert.Equal(t, "backup", mockHandler.sentMessages[0].Extras["route"])
+	assert.Equal(t, "Custom", mockHandler.sentMessages[1].Title)
+	assert.Equal(t, 4, mockHandler.sentMessages[1].Priority)
+	assert.Equal(t, "Webhook Message", mockHandler.sentMessages[2].Title)
+	assert.Nil(t, mockHandler.sentMessages[2].Extras)
+}
+
+func TestValidateRoutes(t *testing.T) {
+	assert.NoError(t, validateRoutes([]RouteConfig{{Path: "backup"}, {Path: "ci_builds"}}))
+	assert.Error(t, validateRoutes([]RouteConfig{{Path: ""}}))
+	assert.Error(t, validateRoutes([]RouteConfig{{Path: "a/b"}}))
+	assert.Error(t, validateRoutes([]RouteConfig{{Path: "message"}}))
+	assert.Error(t, validateRoutes([]RouteConfig{{Path: "x"}, {Path: "x"}}))
+}
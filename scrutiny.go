@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gotify/plugin-api"
+)
+
+// scrutinyFailure describes how a Scrutiny failure type is presented.
+type scrutinyFailure struct {
+	title    string
+	priority int
+}
+
+// scrutinyFailures maps Scrutiny failure types to their presentation.
+var scrutinyFailures = map[string]scrutinyFailure{
+	"smartfail":    {title: "SMART failure", priority: 9},
+	"scrutinyfail": {title: "Failure predicted", priority: 8},
+	"bothfail":     {title: "SMART failure and failure predicted", priority: 10},
+	"emailtest":    {title: "Test notification", priority: 4},
+}
+
+// isScrutinyPayload detects Scrutiny notification webhooks.
+func isScrutinyPayload(body map[string]interface{}) bool {
+	return hasFields(body, "failure_type", "device_name") || sourceIs(body, "scrutiny")
+}
+
+// formatScrutinyPayload renders a Scrutiny disk failure notification with
+// the affected device in the title.
+func formatScrutinyPayload(body map[string]interface{}, _ *Config) plugin.Message {
+	failureType := stringField(body, "failure_type")
+	device := stringField(body, "device_name")
+	serial := stringField(body, "device_serial")
+	host := stringField(body, "host_id")
+
+	failure, known := scrutinyFailures[strings.ToLower(failureType)]
+	if !known {
+		failure = scrutinyFailure{title: "Disk failure", priority: 8}
+	}
+	if test, _ := body["test"].(bool); test {
+		failure = scrutinyFailures["emailtest"]
+	}
+
+	title := "Scrutiny: " + failure.title
+	if device != "" {
+		title += " on " + device
+	}
+	if host != "" {
+		title += " (" + host + ")"
+	}
+
+	var lines []string
+	if host != "" {
+		lines = append(lines, fmt.Sprintf("Host: %s", host))
+	}
+	if device != "" {
+		lines = append(lines, fmt.Sprintf("Device: %s", device))
+	}
+	if serial != "" {
+		lines = append(lines, fmt.Sprintf("Serial: %s", serial))
+	}
+	if deviceType := stringField(body, "device_type"); deviceType != "" {
+		lines = append(lines, fmt.Sprintf("Type: %s", deviceType))
+	}
+	if failureType != "" {
+		lines = append(lines, fmt.Sprintf("Failure type: %s", failureType))
+	}
+	if date := stringField(body, "date"); date != "" {
+		lines = append(lines, fmt.Sprintf("Date: %s", date))
+	}
+	if text := strings.TrimSpace(stringField(body, "message")); text != "" {
+		lines = append(lines, "", text)
+	}
+
+	extras := map[string]interface{}{"source": "scrutiny"}
+	if failureType != "" {
+		extras["failureType"] = failureType
+	}
+	if device != "" {
+		extras["device"] = device
+	}
+	if serial != "" {
+		extras["serial"] = serial
+	}
+
+	return plugin.Message{
+		Title:    title,
+		Message:  strings.TrimSpace(strings.Join(lines, "\n")),
+		Priority: failure.priority,
+		Extras:   extras,
+	}
+}
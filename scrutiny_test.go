@@ -0,0 +1,53 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gotify/plugin-api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_ScrutinyWebhook(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	w := postWebhook(p, map[string]interface{}{
+		"host_id":       "nas01",
+		"device_type":   "ata",
+		"device_name":   "/dev/sda",
+		"device_serial": "WD-WCC4E1234567",
+		"test":          false,
+		"date":          "2024-05-01T13:45:00Z",
+		"failure_type":  "SmartFail",
+		"subject":       "Scrutiny SMART error (SmartFail) detected on device: /dev/sda",
+		"message":       "Reallocated Sectors Count (5): 312",
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title:    "Scrutiny: SMART failure on /dev/sda (nas01)",
+		Message:  "Host: nas01\nDevice: /dev/sda\nSerial: WD-WCC4E1234567\nType: ata\nFailure type: SmartFail\nDate: 2024-05-01T13:45:00Z\n\nReallocated Sectors Count (5): 312",
+		Priority: 9,
+		Extras: map[string]interface{}{
+			"source":      "scrutiny",
+			"failureType": "SmartFail",
+			"device":      "/dev/sda",
+			"serial":      "WD-WCC4E1234567",
+		},
+	}, mockHandler.sentMessages[0])
+}
+
+func TestFormatScrutinyPayload_Test(t *testing.T) {
+	msg := formatScrutinyPayload(map[string]interface{}{
+		"failure_type": "EmailTest",
+		"device_name":  "/dev/sdb",
+		"test":         true,
+	}, defaultConfig())
+	assert.Equal(t, "Scrutiny: Test notification on /dev/sdb", msg.Title)
+	assert.Equal(t, 4, msg.Priority)
+
+	msg = formatScrutinyPayload(map[string]interface{}{"failure_type": "BothFail", "device_name": "/dev/nvme0"}, defaultConfig())
+	assert.Equal(t, 10, msg.Priority)
+}
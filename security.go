@@ -0,0 +1,347 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gotify/plugin-api"
+)
+
+// crowdsecDecisionPriorities maps CrowdSec decision types onto priorities.
+// Alerts without a decision get 7.
+var crowdsecDecisionPriorities = map[string]int{
+	"ban":      8,
+	"captcha":  6,
+	"throttle": 5,
+}
+
+// isWazuhPayload detects Wazuh alerts as posted by integrator scripts: the
+// alert JSON with the rule that fired and the reporting agent.
+func isWazuhPayload(body map[string]interface{}) bool {
+	rule, ok := body["rule"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	_, hasAgent := body["agent"].(map[string]interface{})
+	return hasAgent && hasFields(rule, "level", "description")
+}
+
+// formatWazuhPayload renders a Wazuh alert with the offending IP in the
+// title, e.g. "Wazuh: 203.0.113.7 — sshd: brute force trying to get access
+// to the system". The rule level (0-15) sets the priority.
+func formatWazuhPayload(body map[string]interface{}, config *Config) plugin.Message {
+	rule, _ := body["rule"].(map[string]interface{})
+	agent, _ := body["agent"].(map[string]interface{})
+	data, _ := body["data"].(map[string]interface{})
+
+	description := stringField(rule, "description")
+	ip := stringField(data, "srcip", "src_ip")
+	title := "Wazuh: " + description
+	if ip != "" {
+		title = "Wazuh: " + ip + " — " + description
+	}
+
+	level := intField(rule, "level")
+	var lines []string
+	if id := stringField(rule, "id"); id != "" {
+		lines = append(lines, fmt.Sprintf("Rule: %s (level %d)", id, level))
+	}
+	host := stringField(agent, "name")
+	if address := stringField(agent, "ip"); address != "" {
+		host = strings.TrimSpace(host + " (" + address + ")")
+	}
+	if host != "" {
+		lines = append(lines, "Agent: "+host)
+	}
+	if user := stringField(data, "srcuser", "dstuser"); user != "" {
+		lines = append(lines, "User: "+user)
+	}
+	if mitre, ok := rule["mitre"].(map[string]interface{}); ok {
+		if techniques := joinStrings(mitre["technique"]); techniques != "" {
+			lines = append(lines, "MITRE ATT&CK: "+techniques)
+		}
+	}
+	if groups := joinStrings(rule["groups"]); groups != "" {
+		lines = append(lines, "Groups: "+groups)
+	}
+	if location := stringField(body, "location"); location != "" {
+		lines = append(lines, "Location: "+location)
+	}
+	// Wazuh writes timestamps without the colon in the zone offset
+	timestamp := stringField(body, "timestamp")
+	if t, err := time.Parse("2006-01-02T15:04:05.999999999-0700", timestamp); err == nil {
+		timestamp = t.Format(time.RFC3339Nano)
+	}
+	if when := config.formatTimestamp(timestamp); when != "" {
+		lines = append(lines, "Time: "+when)
+	}
+	paragraphs := []string{strings.Join(lines, "\n")}
+	if log := strings.TrimSpace(stringField(body, "full_log")); log != "" {
+		paragraphs = append(paragraphs, log)
+	}
+	message := strings.TrimSpace(strings.Join(paragraphs, "\n\n"))
+	if message == "" {
+		message = title
+	}
+
+	extras := map[string]interface{}{
+		"source": "wazuh",
+		"level":  level,
+	}
+	for key, value := range map[string]string{
+		"rule":  stringField(rule, "id"),
+		"agent": stringField(agent, "name"),
+		"ip":    ip,
+	} {
+		if value != "" {
+			extras[key] = value
+		}
+	}
+
+	return plugin.Message{
+		Title:    title,
+		Message:  message,
+		Priority: wazuhPriority(level),
+		Extras:   extras,
+	}
+}
+
+// wazuhPriority maps a Wazuh rule level onto a priority. Levels 12 and above
+// are high importance events in Wazuh's classification.
+func wazuhPriority(level int) int {
+	switch {
+	case level >= 12:
+		return 9
+	case level >= 10:
+		return 8
+	case level >= 7:
+		return 6
+	case level >= 4:
+		return 4
+	}
+	return 2
+}
+
+// isCrowdSecPayload detects alerts of CrowdSec's HTTP notification plugin,
+// either the default list of alerts or a single alert.
+func isCrowdSecPayload(body map[string]interface{}) bool {
+	if items := mapSlice(body["items"]); len(items) > 0 {
+		return isCrowdSecAlert(items[0])
+	}
+	return isCrowdSecAlert(body)
+}
+
+// isCrowdSecAlert reports whether a map looks like a CrowdSec alert.
+func isCrowdSecAlert(alert map[string]interface{}) bool {
+	_, hasSource := alert["source"].(map[string]interface{})
+	return hasSource && stringField(alert, "scenario") != "" && hasAnyField(alert, "decisions", "events_count")
+}
+
+// crowdsecDecision is a decision of CrowdSec against an attacker.
+type crowdsecDecision struct {
+	value, kind, duration, scenario string
+	country, network                string
+}
+
+// formatCrowdSecPayload renders CrowdSec decisions with the offending IP in
+// the title, e.g. "CrowdSec: 203.0.113.7 banned for 4h —
+// crowdsecurity/ssh-bf". Several decisions are listed in one message. Bans
+// get priority 8.
+func formatCrowdSecPayload(body map[string]interface{}, _ *Config) plugin.Message {
+	alerts := mapSlice(body["items"])
+	if len(alerts) == 0 {
+		alerts = []map[string]interface{}{body}
+	}
+
+	var decisions []crowdsecDecision
+	for _, alert := range alerts {
+		source, _ := alert["source"].(map[string]interface{})
+		decision := crowdsecDecision{
+			scenario: stringField(alert, "scenario"),
+			country:  stringField(source, "cn"),
+			network:  stringField(source, "as_name"),
+		}
+		items := mapSlice(alert["decisions"])
+		if len(items) == 0 {
+			decision.value = stringField(source, "ip", "value")
+			decisions = append(decisions, decision)
+		}
+		for _, item := range items {
+			decision.value = stringField(item, "value")
+			decision.kind = stringField(item, "type")
+			decision.duration = stringField(item, "duration")
+			if scenario := stringField(item, "scenario"); scenario != "" {
+				decision.scenario = scenario
+			}
+			decisions = append(decisions, decision)
+		}
+	}
+
+	priority := 0
+	for _, decision := range decisions {
+		if p := decision.priority(); p > priority {
+			priority = p
+		}
+	}
+
+	extras := map[string]interface{}{"source": "crowdsec"}
+	if len(decisions) == 1 {
+		decision := decisions[0]
+		var lines []string
+		if message := stringField(alerts[0], "message"); message != "" {
+			lines = append(lines, message)
+		}
+		if origin := decision.origin(); origin != "" {
+			lines = append(lines, "Origin: "+origin)
+		}
+		extras["ip"] = decision.value
+		extras["scenario"] = decision.scenario
+		if decision.kind != "" {
+			extras["decision"] = decision.kind
+		}
+		title := "CrowdSec: " + decision.summary() + " — " + decision.scenario
+		message := strings.Join(lines, "\n")
+		if message == "" {
+			message = title
+		}
+		return plugin.Message{
+			Title:    title,
+			Message:  message,
+			Priority: priority,
+			Extras:   extras,
+		}
+	}
+
+	var lines []string
+	for _, decision := range decisions {
+		line := "- " + decision.summary() + " — " + decision.scenario
+		if origin := decision.origin(); origin != "" {
+			line += " (" + origin + ")"
+		}
+		lines = append(lines, line)
+	}
+	extras["count"] = len(decisions)
+	return plugin.Message{
+		Title:    fmt.Sprintf("CrowdSec: %d decisions", len(decisions)),
+		Message:  strings.Join(lines, "\n"),
+		Priority: priority,
+		Extras:   extras,
+	}
+}
+
+// summary describes a decision, e.g. "203.0.113.7 banned for 4h".
+// CrowdSec reports the remaining duration, e.g. "3h59m59.9s", which is
+// rounded.
+func (d crowdsecDecision) summary() string {
+	duration := d.duration
+	if parsed, err := time.ParseDuration(duration); err == nil {
+		duration = humanizeDuration(parsed)
+	}
+	switch d.kind {
+	case "":
+		return d.value + " detected"
+	case "ban":
+		return d.value + " banned for " + duration
+	}
+	return fmt.Sprintf("%s %s for %s", d.value, d.kind, duration)
+}
+
+// priority returns the priority of the decision type.
+func (d crowdsecDecision) priority() int {
+	if priority, ok := crowdsecDecisionPriorities[d.kind]; ok {
+		return priority
+	}
+	return 7
+}
+
+// origin describes where an attacker comes from, e.g. "CN, Example
+// Networks".
+func (d crowdsecDecision) origin() string {
+	var parts []string
+	for _, part := range []string{d.country, d.network} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// isFail2banPayload detects the JSON or form payloads of fail2ban webhook
+// actions, e.g. {"jail": "<name>", "ip": "<ip>", "failures": "<failures>"}.
+func isFail2banPayload(body map[string]interface{}) bool {
+	if sourceIs(body, "fail2ban") {
+		return stringField(body, "jail", "name") != ""
+	}
+	return stringField(body, "jail") != "" &&
+		(stringField(body, "ip") != "" || hasAnyField(body, "failures", "bantime", "action"))
+}
+
+// formatFail2banPayload renders a fail2ban action, e.g. "203.0.113.7 banned
+// in jail sshd". Clicking looks the IP up on AbuseIPDB. Bans get priority 5,
+// unbans 2.
+func formatFail2banPayload(body map[string]interface{}, _ *Config) plugin.Message {
+	jail := stringField(body, "jail", "name")
+	ip := stringField(body, "ip")
+	action := strings.ToLower(stringField(body, "action", "event"))
+	if action == "" && ip != "" {
+		action = "ban"
+	}
+
+	var title string
+	priority := 3
+	switch action {
+	case "ban":
+		title, priority = fmt.Sprintf("%s banned in jail %s", ip, jail), 5
+	case "unban":
+		title, priority = fmt.Sprintf("%s unbanned in jail %s", ip, jail), 2
+	case "start":
+		title = "Jail " + jail + " started"
+	case "stop":
+		title = "Jail " + jail + " stopped"
+	default:
+		title = strings.TrimSpace(fmt.Sprintf("fail2ban %s %s in jail %s", action, ip, jail))
+	}
+
+	var lines []string
+	if failures := stringField(body, "failures"); failures != "" {
+		lines = append(lines, "Failures: "+failures)
+	}
+	if bantime := stringField(body, "bantime"); bantime != "" {
+		if seconds := intField(body, "bantime"); seconds > 0 {
+			bantime = humanizeDuration(time.Duration(seconds) * time.Second)
+		}
+		lines = append(lines, "Ban time: "+bantime)
+	}
+	if host := stringField(body, "host", "hostname", "fq-hostname"); host != "" {
+		lines = append(lines, "Host: "+host)
+	}
+
+	extras := map[string]interface{}{
+		"source": "fail2ban",
+		"jail":   jail,
+	}
+	if ip != "" {
+		lookup := "https://www.abuseipdb.com/check/" + url.PathEscape(ip)
+		lines = append(lines, "AbuseIPDB: "+lookup)
+		extras["ip"] = ip
+		extras["client::notification"] = map[string]interface{}{
+			"click": map[string]interface{}{"url": lookup},
+		}
+	}
+	if matches := strings.TrimSpace(stringField(body, "matches")); matches != "" {
+		lines = append(lines, "", matches)
+	}
+	message := strings.Join(lines, "\n")
+	if message == "" {
+		message = title
+	}
+
+	return plugin.Message{
+		Title:    title,
+		Message:  message,
+		Priority: priority,
+		Extras:   extras,
+	}
+}
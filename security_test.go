@@ -0,0 +1,169 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gotify/plugin-api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_WazuhAlert(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+	p.SetStorageHandler(&MockStorageHandler{})
+	config := defaultConfig()
+	config.Timezone = "UTC"
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+
+	w := postWebhook(p, map[string]interface{}{
+		"timestamp": "2024-03-01T10:15:00.000+0000",
+		"rule": map[string]interface{}{
+			"level":       10.0,
+			"description": "sshd: brute force trying to get access to the system.",
+			"id":          "5712",
+			"groups":      []interface{}{"syslog", "sshd", "authentication_failures"},
+			"mitre":       map[string]interface{}{"technique": []interface{}{"Brute Force"}},
+		},
+		"agent":    map[string]interface{}{"id": "001", "name": "web1", "ip": "10.0.0.5"},
+		"manager":  map[string]interface{}{"name": "wazuh-manager"},
+		"data":     map[string]interface{}{"srcip": "203.0.113.7", "srcuser": "root"},
+		"location": "/var/log/auth.log",
+		"full_log": "Mar  1 10:15:00 web1 sshd[1234]: Failed password for root from 203.0.113.7 port 52144 ssh2",
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title: "Wazuh: 203.0.113.7 — sshd: brute force trying to get access to the system.",
+		Message: "Rule: 5712 (level 10)\nAgent: web1 (10.0.0.5)\nUser: root\nMITRE ATT&CK: Brute Force\n" +
+			"Groups: syslog, sshd, authentication_failures\nLocation: /var/log/auth.log\nTime: 2024-03-01 10:15:00 UTC\n\n" +
+			"Mar  1 10:15:00 web1 sshd[1234]: Failed password for root from 203.0.113.7 port 52144 ssh2",
+		Priority: 8,
+		Extras: map[string]interface{}{
+			"source": "wazuh",
+			"level":  10,
+			"rule":   "5712",
+			"agent":  "web1",
+			"ip":     "203.0.113.7",
+		},
+	}, mockHandler.sentMessages[0])
+}
+
+func crowdsecAlert(ip, scenario, kind string) map[string]interface{} {
+	return map[string]interface{}{
+		"capacity":     5.0,
+		"events_count": 6.0,
+		"machine_id":   "gateway",
+		"message":      "Ip " + ip + " performed '" + scenario + "' (6 events over 2s)",
+		"scenario":     scenario,
+		"source": map[string]interface{}{
+			"as_name": "Example Networks",
+			"cn":      "NL",
+			"ip":      ip,
+			"scope":   "Ip",
+			"value":   ip,
+		},
+		"decisions": []interface{}{
+			map[string]interface{}{
+				"duration": "3h59m59.938s",
+				"origin":   "crowdsec",
+				"scenario": scenario,
+				"scope":    "Ip",
+				"type":     kind,
+				"value":    ip,
+			},
+		},
+	}
+}
+
+func TestWebhookForwarderPlugin_CrowdSecAlerts(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	// The default format of the HTTP plugin is a list of alerts
+	w := postWebhook(p, []interface{}{crowdsecAlert("203.0.113.7", "crowdsecurity/ssh-bf", "ban")})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = postWebhook(p, map[string]interface{}{
+		"items": []interface{}{
+			crowdsecAlert("203.0.113.7", "crowdsecurity/ssh-bf", "ban"),
+			crowdsecAlert("198.51.100.23", "crowdsecurity/http-probing", "captcha"),
+		},
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	assert.Len(t, mockHandler.sentMessages, 2)
+	assert.Equal(t, plugin.Message{
+		Title:    "CrowdSec: 203.0.113.7 banned for 4h — crowdsecurity/ssh-bf",
+		Message:  "Ip 203.0.113.7 performed 'crowdsecurity/ssh-bf' (6 events over 2s)\nOrigin: NL, Example Networks",
+		Priority: 8,
+		Extras: map[string]interface{}{
+			"source":   "crowdsec",
+			"ip":       "203.0.113.7",
+			"scenario": "crowdsecurity/ssh-bf",
+			"decision": "ban",
+		},
+	}, mockHandler.sentMessages[0])
+	assert.Equal(t, "CrowdSec: 2 decisions", mockHandler.sentMessages[1].Title)
+	assert.Equal(t, "- 203.0.113.7 banned for 4h — crowdsecurity/ssh-bf (NL, Example Networks)\n"+
+		"- 198.51.100.23 captcha for 4h — crowdsecurity/http-probing (NL, Example Networks)", mockHandler.sentMessages[1].Message)
+	assert.Equal(t, 8, mockHandler.sentMessages[1].Priority)
+}
+
+func TestWebhookForwarderPlugin_Fail2banBan(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	w := postWebhook(p, map[string]interface{}{
+		"jail":     "sshd",
+		"ip":       "203.0.113.7",
+		"failures": "5",
+		"bantime":  "3600",
+		"host":     "gateway",
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title:    "203.0.113.7 banned in jail sshd",
+		Message:  "Failures: 5\nBan time: 1h\nHost: gateway\nAbuseIPDB: https://www.abuseipdb.com/check/203.0.113.7",
+		Priority: 5,
+		Extras: map[string]interface{}{
+			"source": "fail2ban",
+			"jail":   "sshd",
+			"ip":     "203.0.113.7",
+			"client::notification": map[string]interface{}{
+				"click": map[string]interface{}{"url": "https://www.abuseipdb.com/check/203.0.113.7"},
+			},
+		},
+	}, mockHandler.sentMessages[0])
+}
+
+func TestWebhookForwarderPlugin_Fail2banForm(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+	router := gin.New()
+	router.POST("/message", p.handleWebhookMessage)
+
+	send := func(form url.Values) {
+		req := httptest.NewRequest("POST", "/message", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+	send(url.Values{"jail": {"nginx-botsearch"}, "ip": {"2001:db8::1"}, "action": {"unban"}})
+	send(url.Values{"source": {"fail2ban"}, "jail": {"sshd"}, "action": {"start"}})
+
+	assert.Len(t, mockHandler.sentMessages, 2)
+	assert.Equal(t, "2001:db8::1 unbanned in jail nginx-botsearch", mockHandler.sentMessages[0].Title)
+	assert.Equal(t, 2, mockHandler.sentMessages[0].Priority)
+	assert.Equal(t, "Jail sshd started", mockHandler.sentMessages[1].Title)
+	assert.Equal(t, "Jail sshd started", mockHandler.sentMessages[1].Message)
+}
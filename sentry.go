@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gotify/plugin-api"
+)
+
+// sentryLevelPriorities maps Sentry event levels to priorities.
+var sentryLevelPriorities = map[string]int{
+	"fatal":   10,
+	"error":   8,
+	"warning": 6,
+	"info":    4,
+	"debug":   3,
+}
+
+// isSentryPayload detects Sentry webhooks: issue and alert webhooks of the
+// integration platform (action with data.issue or data.event) and the
+// payloads of the legacy webhooks plugin.
+func isSentryPayload(body map[string]interface{}) bool {
+	if data, ok := body["data"].(map[string]interface{}); ok && stringField(body, "action") != "" {
+		return hasAnyField(data, "issue", "event")
+	}
+	return hasFields(body, "project_name", "culprit", "url") || sourceIs(body, "sentry")
+}
+
+// formatSentryPayload renders a Sentry issue with its project, culprit and
+// level. The level sets the priority and the issue opens when the
+// notification is clicked.
+func formatSentryPayload(body map[string]interface{}, _ *Config) plugin.Message {
+	action := stringField(body, "action")
+	issue := body
+	if data, ok := body["data"].(map[string]interface{}); ok {
+		if item, ok := data["issue"].(map[string]interface{}); ok {
+			issue = item
+		} else if item, ok := data["event"].(map[string]interface{}); ok {
+			issue = item
+		}
+	}
+
+	project := stringField(body, "project_name", "project_slug")
+	if p, ok := issue["project"].(map[string]interface{}); ok {
+		project = stringField(p, "name", "slug")
+	} else if project == "" {
+		project = stringField(issue, "project")
+	}
+	name := stringField(issue, "title", "message")
+	if name == "" {
+		name = "Sentry issue"
+	}
+	level := strings.ToLower(stringField(issue, "level"))
+	culprit := stringField(issue, "culprit")
+	link := stringField(issue, "permalink", "web_url", "url")
+
+	title := "Sentry: " + name
+	if project != "" {
+		title = fmt.Sprintf("Sentry [%s]: %s", project, name)
+	}
+	if label := sentryAction(action); label != "" {
+		title = label + ": " + title
+	}
+
+	var lines []string
+	if culprit != "" {
+		lines = append(lines, culprit)
+	}
+	if level != "" {
+		lines = append(lines, fmt.Sprintf("Level: %s", level))
+	}
+	if shortID := stringField(issue, "shortId"); shortID != "" {
+		lines = append(lines, fmt.Sprintf("Issue: %s", shortID))
+	}
+	if count := stringField(issue, "count"); count != "" {
+		events := fmt.Sprintf("Events: %s", count)
+		if users := stringField(issue, "userCount"); users != "" {
+			events += fmt.Sprintf(" (%s users)", users)
+		}
+		lines = append(lines, events)
+	}
+	if assignee, ok := issue["assignedTo"].(map[string]interface{}); ok {
+		if name := stringField(assignee, "name", "email"); name != "" {
+			lines = append(lines, fmt.Sprintf("Assigned to: %s", name))
+		}
+	}
+	message := strings.Join(lines, "\n")
+	if message == "" {
+		message = name
+	}
+
+	priority := 5
+	if p, ok := sentryLevelPriorities[level]; ok {
+		priority = p
+	}
+	switch action {
+	case "resolved", "archived", "ignored":
+		priority = 3
+	case "assigned":
+		priority = 4
+	}
+
+	extras := map[string]interface{}{"source": "sentry"}
+	for key, value := range map[string]string{
+		"action":  action,
+		"level":   level,
+		"project": project,
+		"issueId": stringField(issue, "issue_id", "id"),
+	} {
+		if value != "" {
+			extras[key] = value
+		}
+	}
+	if link != "" {
+		extras["client::notification"] = map[string]interface{}{
+			"click": map[string]interface{}{"url": link},
+		}
+	}
+
+	return plugin.Message{
+		Title:    title,
+		Message:  message,
+		Priority: priority,
+		Extras:   extras,
+	}
+}
+
+// sentryAction returns the title prefix for an issue action, or "" for new
+// issues and triggered alerts.
+func sentryAction(action string) string {
+	switch action {
+	case "resolved":
+		return "Resolved"
+	case "assigned":
+		return "Assigned"
+	case "archived", "ignored":
+		return "Archived"
+	case "unresolved":
+		return "Unresolved"
+	}
+	return ""
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gotify/plugin-api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_SentryWebhook(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	w := postWebhook(p, map[string]interface{}{
+		"action": "created",
+		"data": map[string]interface{}{
+			"issue": map[string]interface{}{
+				"id":        "1170820242",
+				"shortId":   "API-1F",
+				"title":     "ZeroDivisionError: division by zero",
+				"culprit":   "billing.invoice in total",
+				"level":     "error",
+				"permalink": "https://sentry.example.com/organizations/acme/issues/1170820242/",
+				"count":     "12",
+				"userCount": 3,
+				"project":   map[string]interface{}{"id": "2", "name": "api", "slug": "api"},
+			},
+		},
+		"installation": map[string]interface{}{"uuid": "a8e5d37a"},
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title:    "Sentry [api]: ZeroDivisionError: division by zero",
+		Message:  "billing.invoice in total\nLevel: error\nIssue: API-1F\nEvents: 12 (3 users)",
+		Priority: 8,
+		Extras: map[string]interface{}{
+			"source":  "sentry",
+			"action":  "created",
+			"level":   "error",
+			"project": "api",
+			"issueId": "1170820242",
+			"client::notification": map[string]interface{}{
+				"click": map[string]interface{}{"url": "https://sentry.example.com/organizations/acme/issues/1170820242/"},
+			},
+		},
+	}, mockHandler.sentMessages[0])
+}
+
+func TestFormatSentryPayload(t *testing.T) {
+	// Issue alert triggered by an alert rule
+	body := map[string]interface{}{
+		"action": "triggered",
+		"data": map[string]interface{}{
+			"event": map[string]interface{}{
+				"title":    "Database unavailable",
+				"level":    "fatal",
+				"issue_id": "42",
+				"web_url":  "https://sentry.example.com/issues/42/events/abc/",
+			},
+			"triggered_rule": "Fatal errors",
+		},
+	}
+	assert.True(t, isSentryPayload(body))
+	msg := formatSentryPayload(body, defaultConfig())
+	assert.Equal(t, "Sentry: Database unavailable", msg.Title)
+	assert.Equal(t, 10, msg.Priority)
+	assert.Equal(t, "42", msg.Extras["issueId"])
+
+	// Resolved issues get a low priority
+	body = map[string]interface{}{
+		"action": "resolved",
+		"data":   map[string]interface{}{"issue": map[string]interface{}{"title": "Timeout", "level": "warning"}},
+	}
+	msg = formatSentryPayload(body, defaultConfig())
+	assert.Equal(t, "Resolved: Sentry: Timeout", msg.Title)
+	assert.Equal(t, 3, msg.Priority)
+
+	// Legacy webhooks plugin
+	body = map[string]interface{}{
+		"project_name": "web",
+		"culprit":      "app.views in index",
+		"level":        "warning",
+		"message":      "Slow response",
+		"url":          "https://sentry.example.com/acme/web/issues/7/",
+	}
+	assert.True(t, isSentryPayload(body))
+	msg = formatSentryPayload(body, defaultConfig())
+	assert.Equal(t, "Sentry [web]: Slow response", msg.Title)
+	assert.Equal(t, 6, msg.Priority)
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// signatureMaxAge is the maximum difference between a signature timestamp
+// and the current time, which prevents replaying signed requests.
+const signatureMaxAge = 5 * time.Minute
+
+// SignatureConfig verifies the HMAC-SHA256 signature Grafana adds to
+// webhook requests.
+type SignatureConfig struct {
+	// Secret is the shared secret configured in the Grafana contact point.
+	// Empty disables verification.
+	Secret string `yaml:"secret"`
+	// Header holds the hex encoded signature.
+	Header string `yaml:"header"`
+	// TimestampHeader holds the Unix timestamp included in the signature,
+	// if Grafana is configured to send one.
+	TimestampHeader string `yaml:"timestampHeader"`
+}
+
+// validate checks that the signature header is set if a secret is.
+func (s *SignatureConfig) validate() error {
+	if s.Secret != "" && s.Header == "" {
+		return errors.New("grafana.signature.header must be set when a secret is configured")
+	}
+	return nil
+}
+
+// verify reports whether the request body is signed with the secret. The
+// timestamp, if configured, must be within signatureMaxAge of now.
+func (s *SignatureConfig) verify(header http.Header, body []byte, now time.Time) bool {
+	if s.Secret == "" {
+		return true
+	}
+	signature, err := hex.DecodeString(header.Get(s.Header))
+	if err != nil || len(signature) == 0 {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(s.Secret))
+	if s.TimestampHeader != "" {
+		timestamp := header.Get(s.TimestampHeader)
+		seconds, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			return false
+		}
+		if age := now.Sub(time.Unix(seconds, 0)); age > signatureMaxAge || age < -signatureMaxAge {
+			return false
+		}
+		mac.Write([]byte(timestamp + ":"))
+	}
+	mac.Write(body)
+	return hmac.Equal(signature, mac.Sum(nil))
+}
+
+// bufferBody reads the request body and replaces it with a copy, so it can
+// be parsed after its signature is checked. On failure an error response has
+// been written and ok is false.
+func bufferBody(c *gin.Context) ([]byte, bool) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Could not read request body",
+		})
+		return nil, false
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	return body, true
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func postSignedWebhook(p *WebhookForwarderPlugin, payload interface{}, headers map[string]string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/message", p.handleWebhookMessage)
+
+	body, _ := json.Marshal(payload)
+	req := httptest.NewRequest("POST", "/message", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func sign(secret string, parts ...string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	for _, part := range parts {
+		mac.Write([]byte(part))
+	}
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestWebhookForwarderPlugin_GrafanaSignature(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+	config := defaultConfig()
+	config.Grafana.Signature.Secret = "s3cret"
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+
+	payload := splitGrafanaPayload()
+	body, _ := json.Marshal(payload)
+
+	w := postSignedWebhook(p, payload, map[string]string{"X-Grafana-Alerting-Signature": sign("s3cret", string(body))})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+
+	w = postSignedWebhook(p, payload, map[string]string{"X-Grafana-Alerting-Signature": sign("wrong", string(body))})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	w = postWebhook(p, payload)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+
+	// Unsigned alerts in the Alertmanager format are rejected as well,
+	// including those of known rules
+	for _, alertname := range []string{"HostDown", "LonghornNodeDown"} {
+		payload := grafanaNotification(grafanaAlert("firing", "alertname", alertname))
+		payload["version"] = "4"
+		w = postWebhook(p, payload)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	}
+	assert.Len(t, mockHandler.sentMessages, 1)
+
+	// Other sources are not signed
+	w = postWebhook(p, map[string]interface{}{"title": "Backup", "message": "done"})
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestSignatureConfig_Verify(t *testing.T) {
+	now := time.Unix(1714567200, 0)
+	body := []byte(`{"status":"firing"}`)
+	config := SignatureConfig{Secret: "s3cret", Header: "X-Signature", TimestampHeader: "X-Timestamp"}
+
+	header := http.Header{}
+	header.Set("X-Timestamp", strconv.FormatInt(now.Unix(), 10))
+	header.Set("X-Signature", sign("s3cret", strconv.FormatInt(now.Unix(), 10)+":", string(body)))
+	assert.True(t, config.verify(header, body, now))
+	assert.True(t, config.verify(header, body, now.Add(time.Minute)))
+	assert.False(t, config.verify(header, body, now.Add(time.Hour)))
+	assert.False(t, config.verify(header, []byte(`{"status":"resolved"}`), now))
+
+	header.Set("X-Timestamp", "not a number")
+	assert.False(t, config.verify(header, body, now))
+
+	assert.True(t, (&SignatureConfig{}).verify(http.Header{}, body, now))
+	assert.Error(t, (&SignatureConfig{Secret: "s3cret"}).validate())
+}
@@ -0,0 +1,400 @@
+package main
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gotify/plugin-api"
+)
+
+// SNSConfig holds options for Amazon SNS HTTPS subscriptions.
+type SNSConfig struct {
+	// VerifySignature rejects SNS messages without a valid signature.
+	VerifySignature bool `yaml:"verifySignature"`
+	// AutoConfirm confirms new subscriptions by visiting their SubscribeURL.
+	// Otherwise the URL is forwarded so the subscription can be confirmed
+	// by hand.
+	AutoConfirm bool `yaml:"autoConfirm"`
+	// TopicArns limits accepted messages to these topics, empty accepts all.
+	TopicArns []string `yaml:"topicArns"`
+}
+
+// allowsTopic reports whether messages of the topic are accepted.
+func (s *SNSConfig) allowsTopic(arn string) bool {
+	if len(s.TopicArns) == 0 {
+		return true
+	}
+	for _, topic := range s.TopicArns {
+		if topic == arn {
+			return true
+		}
+	}
+	return false
+}
+
+// snsHostPattern matches the hosts SNS signing certificates and
+// subscription URLs are served from.
+var snsHostPattern = regexp.MustCompile(`^sns\.[a-z0-9-]+\.amazonaws\.com(\.cn)?$`)
+
+// snsHTTPClient fetches signing certificates and confirms subscriptions.
+var snsHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// snsCertificates caches signing certificates by URL.
+var snsCertificates sync.Map
+
+// snsNotificationFormatter formats SNS notifications whose message is not a
+// payload of a supported service.
+var snsNotificationFormatter = payloadFormatter{source: "sns", detect: isSNSPayload, format: formatSNSNotification}
+
+// isSNSPayload detects messages of Amazon SNS HTTPS subscriptions.
+func isSNSPayload(body map[string]interface{}) bool {
+	if !hasFields(body, "MessageId", "TopicArn") {
+		return false
+	}
+	switch stringField(body, "Type") {
+	case "Notification", "SubscriptionConfirmation", "UnsubscribeConfirmation":
+		return true
+	}
+	return false
+}
+
+// handleSNSWebhook verifies an SNS message, confirms subscriptions and
+// forwards notifications. Notifications carrying the JSON payload of a
+// supported service, such as CloudWatch alarms, are formatted like a
+// webhook of that service.
+func (p *WebhookForwarderPlugin) handleSNSWebhook(c *gin.Context, body map[string]interface{}) {
+	config := p.getConfig()
+	if !config.SNS.allowsTopic(stringField(body, "TopicArn")) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "SNS topic is not allowed in the plugin configuration",
+		})
+		return
+	}
+	if config.SNS.VerifySignature {
+		if err := verifySNSSignature(body); err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error":   "Invalid SNS message signature",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	switch stringField(body, "Type") {
+	case "SubscriptionConfirmation":
+		if !config.SNS.AutoConfirm {
+			p.handleDetectedPayload(c, &snsNotificationFormatter, body)
+			return
+		}
+		if err := confirmSNSSubscription(stringField(body, "SubscribeURL")); err != nil {
+			c.JSON(http.StatusBadGateway, gin.H{
+				"error":   "Could not confirm SNS subscription",
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"message": "SNS subscription confirmed",
+		})
+	case "UnsubscribeConfirmation":
+		c.JSON(http.StatusOK, gin.H{
+			"message": "SNS unsubscribe confirmation received",
+		})
+	default:
+		var inner map[string]interface{}
+		if json.Unmarshal([]byte(stringField(body, "Message")), &inner) == nil && inner != nil {
+			if formatter := detectPayloadFormatter(inner, config); formatter != nil {
+				if disabled := formatter.disabledSource(config); disabled != "" {
+					c.JSON(http.StatusForbidden, gin.H{
+						"error": fmt.Sprintf("Webhooks of type '%s' are disabled in the plugin configuration", disabled),
+					})
+					return
+				}
+				p.handleDetectedPayload(c, formatter, inner)
+				return
+			}
+		}
+		p.handleDetectedPayload(c, &snsNotificationFormatter, body)
+	}
+}
+
+// formatSNSNotification renders an SNS notification with the subject as
+// title and the message as body. Subscription confirmations that are not
+// confirmed automatically link the confirmation URL.
+func formatSNSNotification(body map[string]interface{}, _ *Config) plugin.Message {
+	topic := stringField(body, "TopicArn")
+	extras := map[string]interface{}{
+		"source":    "sns",
+		"topicArn":  topic,
+		"messageId": stringField(body, "MessageId"),
+	}
+
+	if stringField(body, "Type") == "SubscriptionConfirmation" {
+		subscribe := stringField(body, "SubscribeURL")
+		extras["client::notification"] = map[string]interface{}{
+			"click": map[string]interface{}{"url": subscribe},
+		}
+		return plugin.Message{
+			Title:    "Confirm SNS subscription",
+			Message:  fmt.Sprintf("Open the link to confirm the subscription to %s:\n%s", topic, subscribe),
+			Priority: 5,
+			Extras:   extras,
+		}
+	}
+
+	title := stringField(body, "Subject")
+	if title == "" {
+		title = "AWS SNS"
+		if i := strings.LastIndex(topic, ":"); i >= 0 && i < len(topic)-1 {
+			title = "AWS SNS: " + topic[i+1:]
+		}
+	}
+	message := strings.TrimSpace(stringField(body, "Message"))
+	if message == "" {
+		message = title
+	}
+
+	return plugin.Message{
+		Title:    title,
+		Message:  message,
+		Priority: 5,
+		Extras:   extras,
+	}
+}
+
+// snsSignedFields lists the fields included in the signature of each SNS
+// message type, in signing order.
+var snsSignedFields = map[string][]string{
+	"Notification":             {"Message", "MessageId", "Subject", "Timestamp", "TopicArn", "Type"},
+	"SubscriptionConfirmation": {"Message", "MessageId", "SubscribeURL", "Timestamp", "Token", "TopicArn", "Type"},
+	"UnsubscribeConfirmation":  {"Message", "MessageId", "SubscribeURL", "Timestamp", "Token", "TopicArn", "Type"},
+}
+
+// snsStringToSign builds the string SNS signs for a message: the name and
+// value of each signed field on separate lines. The subject of
+// notifications is only included if present.
+func snsStringToSign(body map[string]interface{}) (string, error) {
+	fields, ok := snsSignedFields[stringField(body, "Type")]
+	if !ok {
+		return "", errors.New("unknown message type")
+	}
+	var b strings.Builder
+	for _, field := range fields {
+		value, ok := body[field].(string)
+		if !ok {
+			if field == "Subject" {
+				continue
+			}
+			return "", fmt.Errorf("missing field %s", field)
+		}
+		b.WriteString(field + "\n" + value + "\n")
+	}
+	return b.String(), nil
+}
+
+// verifySNSSignature checks the signature of an SNS message against the
+// signing certificate, which must be served by SNS over HTTPS.
+func verifySNSSignature(body map[string]interface{}) error {
+	var hash crypto.Hash
+	switch stringField(body, "SignatureVersion") {
+	case "1":
+		hash = crypto.SHA1
+	case "2":
+		hash = crypto.SHA256
+	default:
+		return errors.New("unsupported signature version")
+	}
+	signature, err := base64.StdEncoding.DecodeString(stringField(body, "Signature"))
+	if err != nil || len(signature) == 0 {
+		return errors.New("invalid signature encoding")
+	}
+	message, err := snsStringToSign(body)
+	if err != nil {
+		return err
+	}
+	certificate, err := snsCertificate(stringField(body, "SigningCertURL"))
+	if err != nil {
+		return err
+	}
+	key, ok := certificate.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return errors.New("signing certificate has no RSA key")
+	}
+
+	var digest []byte
+	if hash == crypto.SHA1 {
+		sum := sha1.Sum([]byte(message))
+		digest = sum[:]
+	} else {
+		sum := sha256.Sum256([]byte(message))
+		digest = sum[:]
+	}
+	if err := rsa.VerifyPKCS1v15(key, hash, digest, signature); err != nil {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
+
+// snsCertificate returns the signing certificate at certURL, fetching it on
+// first use.
+func snsCertificate(certURL string) (*x509.Certificate, error) {
+	if !snsURLAllowed(certURL) {
+		return nil, errors.New("signing certificate URL is not an SNS URL")
+	}
+	if certificate, ok := snsCertificates.Load(certURL); ok {
+		return certificate.(*x509.Certificate), nil
+	}
+
+	resp, err := snsHTTPClient.Get(certURL)
+	if err != nil {
+		return nil, fmt.Errorf("fetching signing certificate: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching signing certificate: status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxPayloadSize))
+	if err != nil {
+		return nil, fmt.Errorf("fetching signing certificate: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("signing certificate is not PEM encoded")
+	}
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parsing signing certificate: %w", err)
+	}
+	snsCertificates.Store(certURL, certificate)
+	return certificate, nil
+}
+
+// confirmSNSSubscription visits the SubscribeURL of a subscription
+// confirmation.
+func confirmSNSSubscription(subscribeURL string) error {
+	if !snsURLAllowed(subscribeURL) {
+		return errors.New("subscribe URL is not an SNS URL")
+	}
+	resp, err := snsHTTPClient.Get(subscribeURL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxPayloadSize))
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// snsURLAllowed reports whether raw is an HTTPS URL of SNS, so messages
+// cannot make the plugin fetch arbitrary URLs.
+func snsURLAllowed(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && u.Scheme == "https" && snsHostPattern.MatchString(u.Hostname())
+}
+
+// isCloudWatchAlarmPayload detects CloudWatch alarm state changes, which
+// are published to SNS topics as JSON.
+func isCloudWatchAlarmPayload(body map[string]interface{}) bool {
+	return hasFields(body, "AlarmName", "NewStateValue")
+}
+
+// cloudWatchStatePriorities maps alarm states to priorities.
+var cloudWatchStatePriorities = map[string]int{
+	"ALARM":             8,
+	"INSUFFICIENT_DATA": 5,
+	"OK":                3,
+}
+
+// formatCloudWatchAlarmPayload renders a CloudWatch alarm with its state and
+// name in the title and the reason and metric as message.
+func formatCloudWatchAlarmPayload(body map[string]interface{}, config *Config) plugin.Message {
+	name := stringField(body, "AlarmName")
+	state := stringField(body, "NewStateValue")
+	title := fmt.Sprintf("[%s] %s", state, name)
+
+	var paragraphs, lines []string
+	if description := strings.TrimSpace(stringField(body, "AlarmDescription")); description != "" {
+		paragraphs = append(paragraphs, description)
+	}
+	if reason := strings.TrimSpace(stringField(body, "NewStateReason")); reason != "" {
+		paragraphs = append(paragraphs, reason)
+	}
+	if trigger, ok := body["Trigger"].(map[string]interface{}); ok {
+		metric := stringField(trigger, "MetricName")
+		if namespace := stringField(trigger, "Namespace"); namespace != "" && metric != "" {
+			metric = namespace + " " + metric
+		}
+		var dimensions []string
+		for _, dimension := range mapSlice(trigger["Dimensions"]) {
+			dimensions = append(dimensions, stringField(dimension, "name")+"="+stringField(dimension, "value"))
+		}
+		if len(dimensions) > 0 {
+			metric += " (" + strings.Join(dimensions, ", ") + ")"
+		}
+		if metric != "" {
+			lines = append(lines, "Metric: "+metric)
+		}
+	}
+	if previous := stringField(body, "OldStateValue"); previous != "" {
+		lines = append(lines, "Previous state: "+previous)
+	}
+	if region := stringField(body, "Region"); region != "" {
+		lines = append(lines, "Region: "+region)
+	}
+	if account := stringField(body, "AWSAccountId"); account != "" {
+		lines = append(lines, "Account: "+account)
+	}
+	if changed := stringField(body, "StateChangeTime"); changed != "" {
+		// CloudWatch uses a numeric zone offset without colon
+		if t, err := time.Parse("2006-01-02T15:04:05.000-0700", changed); err == nil {
+			changed = config.formatTimestamp(t.Format(time.RFC3339Nano))
+		}
+		lines = append(lines, "Changed: "+changed)
+	}
+	if len(lines) > 0 {
+		paragraphs = append(paragraphs, strings.Join(lines, "\n"))
+	}
+	message := strings.Join(paragraphs, "\n\n")
+	if message == "" {
+		message = title
+	}
+
+	priority, ok := cloudWatchStatePriorities[state]
+	if !ok {
+		priority = 5
+	}
+
+	extras := map[string]interface{}{
+		"source":    "cloudwatch",
+		"alarmName": name,
+		"state":     state,
+	}
+	if arn := stringField(body, "AlarmArn"); arn != "" {
+		extras["alarmArn"] = arn
+	}
+
+	return plugin.Message{
+		Title:    title,
+		Message:  message,
+		Priority: priority,
+		Extras:   extras,
+	}
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/gotify/plugin-api"
+	"github.com/stretchr/testify/assert"
+)
+
+// snsTestServer serves a signing certificate and subscription URLs like SNS
+// and returns a function signing messages with the certificate's key.
+func snsTestServer(t *testing.T) (*httptest.Server, *int, func(map[string]interface{})) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sns.amazonaws.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	certificate := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+
+	confirmations := 0
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/cert.pem":
+			_, _ = w.Write(certificate)
+		case "/subscribe":
+			confirmations++
+			_, _ = w.Write([]byte("<ConfirmSubscriptionResponse/>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	client, pattern := snsHTTPClient, snsHostPattern
+	snsHTTPClient = server.Client()
+	snsHostPattern = regexp.MustCompile(`^127\.0\.0\.1$`)
+	t.Cleanup(func() {
+		server.Close()
+		snsHTTPClient, snsHostPattern = client, pattern
+		snsCertificates.Delete(server.URL + "/cert.pem")
+	})
+
+	sign := func(body map[string]interface{}) {
+		body["SignatureVersion"] = "2"
+		body["SigningCertURL"] = server.URL + "/cert.pem"
+		message, err := snsStringToSign(body)
+		assert.NoError(t, err)
+		digest := sha256.Sum256([]byte(message))
+		signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		assert.NoError(t, err)
+		body["Signature"] = base64.StdEncoding.EncodeToString(signature)
+	}
+	return server, &confirmations, sign
+}
+
+func TestWebhookForwarderPlugin_SNSCloudWatchAlarm(t *testing.T) {
+	_, _, sign := snsTestServer(t)
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+	p.SetStorageHandler(&MockStorageHandler{})
+	config := defaultConfig()
+	config.Timezone = "UTC"
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+
+	alarm, err := json.Marshal(map[string]interface{}{
+		"AlarmName":        "api-high-cpu",
+		"AlarmDescription": "CPU above 90% on the API",
+		"AWSAccountId":     "123456789012",
+		"NewStateValue":    "ALARM",
+		"NewStateReason":   "Threshold Crossed: 1 datapoint [97.5] was greater than the threshold (90.0).",
+		"StateChangeTime":  "2024-03-01T10:15:00.000+0000",
+		"Region":           "EU (Ireland)",
+		"AlarmArn":         "arn:aws:cloudwatch:eu-west-1:123456789012:alarm:api-high-cpu",
+		"OldStateValue":    "OK",
+		"Trigger": map[string]interface{}{
+			"MetricName": "CPUUtilization",
+			"Namespace":  "AWS/EC2",
+			"Dimensions": []interface{}{
+				map[string]interface{}{"name": "InstanceId", "value": "i-0abc"},
+			},
+		},
+	})
+	assert.NoError(t, err)
+	body := map[string]interface{}{
+		"Type":      "Notification",
+		"MessageId": "22b80b92-fdea-4c2c-8f9d-bdfb0c7bf324",
+		"TopicArn":  "arn:aws:sns:eu-west-1:123456789012:alarms",
+		"Subject":   `ALARM: "api-high-cpu" in EU (Ireland)`,
+		"Message":   string(alarm),
+		"Timestamp": "2024-03-01T10:15:00.123Z",
+	}
+	sign(body)
+
+	w := postWebhook(p, body)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title:    "[ALARM] api-high-cpu",
+		Message:  "CPU above 90% on the API\n\nThreshold Crossed: 1 datapoint [97.5] was greater than the threshold (90.0).\n\nMetric: AWS/EC2 CPUUtilization (InstanceId=i-0abc)\nPrevious state: OK\nRegion: EU (Ireland)\nAccount: 123456789012\nChanged: 2024-03-01 10:15:00 UTC",
+		Priority: 8,
+		Extras: map[string]interface{}{
+			"source":    "cloudwatch",
+			"alarmName": "api-high-cpu",
+			"state":     "ALARM",
+			"alarmArn":  "arn:aws:cloudwatch:eu-west-1:123456789012:alarm:api-high-cpu",
+		},
+	}, mockHandler.sentMessages[0])
+}
+
+func TestWebhookForwarderPlugin_SNSNotification(t *testing.T) {
+	_, _, sign := snsTestServer(t)
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	body := map[string]interface{}{
+		"Type":      "Notification",
+		"MessageId": "da41e39f-ea4d-435a-b922-c6aae3915ebe",
+		"TopicArn":  "arn:aws:sns:us-east-1:123456789012:deployments",
+		"Message":   "Deployment of api finished",
+		"Timestamp": "2024-03-01T10:15:00.123Z",
+	}
+	sign(body)
+
+	w := postWebhook(p, body)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title:    "AWS SNS: deployments",
+		Message:  "Deployment of api finished",
+		Priority: 5,
+		Extras: map[string]interface{}{
+			"source":    "sns",
+			"topicArn":  "arn:aws:sns:us-east-1:123456789012:deployments",
+			"messageId": "da41e39f-ea4d-435a-b922-c6aae3915ebe",
+		},
+	}, mockHandler.sentMessages[0])
+}
+
+func TestWebhookForwarderPlugin_SNSInvalidSignature(t *testing.T) {
+	_, _, sign := snsTestServer(t)
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	body := map[string]interface{}{
+		"Type":      "Notification",
+		"MessageId": "da41e39f-ea4d-435a-b922-c6aae3915ebe",
+		"TopicArn":  "arn:aws:sns:us-east-1:123456789012:deployments",
+		"Message":   "Deployment of api finished",
+		"Timestamp": "2024-03-01T10:15:00.123Z",
+	}
+	sign(body)
+	body["Message"] = "Deployment of api failed"
+
+	w := postWebhook(p, body)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Empty(t, mockHandler.sentMessages)
+}
+
+func TestWebhookForwarderPlugin_SNSSubscriptionConfirmation(t *testing.T) {
+	server, confirmations, sign := snsTestServer(t)
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	body := map[string]interface{}{
+		"Type":         "SubscriptionConfirmation",
+		"MessageId":    "165545c9-2a5c-472c-8df2-7ff2be2b3b1b",
+		"TopicArn":     "arn:aws:sns:us-east-1:123456789012:alarms",
+		"Token":        "2336412f37",
+		"Message":      "You have chosen to subscribe to the topic arn:aws:sns:us-east-1:123456789012:alarms.",
+		"SubscribeURL": server.URL + "/subscribe",
+		"Timestamp":    "2024-03-01T10:15:00.123Z",
+	}
+	sign(body)
+
+	w := postWebhook(p, body)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1, *confirmations)
+	assert.Empty(t, mockHandler.sentMessages)
+}
+
+func TestWebhookForwarderPlugin_SNSTopicNotAllowed(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+	p.SetStorageHandler(&MockStorageHandler{})
+	config := defaultConfig()
+	config.SNS.TopicArns = []string{"arn:aws:sns:us-east-1:123456789012:alarms"}
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+
+	w := postWebhook(p, map[string]interface{}{
+		"Type":      "Notification",
+		"MessageId": "da41e39f-ea4d-435a-b922-c6aae3915ebe",
+		"TopicArn":  "arn:aws:sns:us-east-1:123456789012:other",
+		"Message":   "hello",
+	})
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Empty(t, mockHandler.sentMessages)
+}
+
+func TestSNSURLAllowed(t *testing.T) {
+	assert.True(t, snsURLAllowed("https://sns.us-east-1.amazonaws.com/SimpleNotificationService-abc.pem"))
+	assert.True(t, snsURLAllowed("https://sns.cn-north-1.amazonaws.com.cn/cert.pem"))
+	assert.False(t, snsURLAllowed("http://sns.us-east-1.amazonaws.com/cert.pem"))
+	assert.False(t, snsURLAllowed("https://sns.us-east-1.amazonaws.com.evil.example/cert.pem"))
+	assert.False(t, snsURLAllowed("https://example.com/cert.pem"))
+}
@@ -0,0 +1,292 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gotify/plugin-api"
+)
+
+// payloadFormatter recognises the webhook payload of a specific service and
+// converts it into a Gotify message.
+type payloadFormatter struct {
+	source string
+	detect func(body map[string]interface{}) bool
+	// detectConfigured replaces detect for services whose payload fields
+	// are configurable.
+	detectConfigured func(body map[string]interface{}, config *Config) bool
+	format           func(body map[string]interface{}, config *Config) plugin.Message
+	// respond optionally replaces the default success response, for senders
+	// that expect a specific reply.
+	respond func(c *gin.Context)
+	// check optionally identifies the monitored check of an up/down
+	// notification, whether it is down and when its state changed (zero if
+	// unknown), so recoveries report how long the check was down.
+	check func(body map[string]interface{}) (id string, down bool, changed time.Time)
+	// skip optionally returns why a detected payload is not forwarded, for
+	// services with their own event filter.
+	skip func(body map[string]interface{}, config *Config) string
+	// alertmanager marks formatters of Alertmanager notifications of known
+	// rules, which are subject to the alertmanager source and settings.
+	alertmanager bool
+}
+
+// disabledSource returns the source of the formatter's payloads disabled
+// in config, or "" if they are accepted.
+func (f *payloadFormatter) disabledSource(config *Config) string {
+	if !config.sourceEnabled(f.source) {
+		return f.source
+	}
+	if f.alertmanager && !config.sourceEnabled("alertmanager") {
+		return "alertmanager"
+	}
+	return ""
+}
+
+// payloadFormatters lists the supported services in detection order.
+var payloadFormatters = []payloadFormatter{
+	{source: "oncall", detect: isOnCallPayload, format: formatOnCallPayload},
+	{source: "grafana-incident", detect: isIncidentPayload, format: formatIncidentPayload},
+	{source: "authelia", detect: isAutheliaPayload, format: formatAutheliaPayload},
+	{source: "mattermost", detect: isOutgoingChatPayload, format: formatOutgoingChatPayload, respond: respondOutgoingChat},
+	{source: "teams", detect: isTeamsPayload, format: formatTeamsPayload},
+	{source: "gatus", detect: isGatusPayload, format: formatGatusPayload},
+	{source: "googlechat", detect: isGoogleChatPayload, format: formatGoogleChatPayload},
+	{source: "ifttt", detectConfigured: isIFTTTPayload, format: formatIFTTTPayload},
+	// Flux events look like Kubernetes events with a severity
+	{source: "flux", detect: isFluxPayload, format: formatFluxPayload},
+	{source: "kubernetes", detect: isKubernetesEventPayload, format: formatKubernetesEventPayload},
+	{source: "longhorn", detect: isLonghornPayload, format: formatLonghornPayload, alertmanager: true},
+	{source: "rabbitmq", detect: isRabbitMQPayload, format: formatBrokerPayload, alertmanager: true},
+	{source: "kafka", detect: isKafkaPayload, format: formatBrokerPayload, alertmanager: true},
+	{source: "scrutiny", detect: isScrutinyPayload, format: formatScrutinyPayload},
+	{source: "zammad", detect: isZammadPayload, format: formatZammadPayload},
+	{source: "freshdesk", detect: isFreshdeskPayload, format: formatFreshdeskPayload},
+	{source: "icinga", detect: isIcingaPayload, format: formatIcingaPayload},
+	{source: "sentry", detect: isSentryPayload, format: formatSentryPayload},
+	{source: "kibana", detect: isKibanaPayload, format: formatKibanaPayload},
+	{source: "influxdb", detect: isInfluxDBPayload, format: formatInfluxDBPayload},
+	{source: "kapacitor", detect: isKapacitorPayload, format: formatKapacitorPayload},
+	{source: "uptimerobot", detect: isUptimeRobotPayload, format: formatUptimeRobotPayload},
+	{source: "statuscake", detect: isStatusCakePayload, format: formatStatusCakePayload},
+	{source: "pingdom", detect: isPingdomPayload, format: formatPingdomPayload, check: pingdomCheck},
+	{source: "deadmanssnitch", detect: isSnitchPayload, format: formatSnitchPayload, check: snitchCheck},
+	{source: "cronitor", detect: isCronitorPayload, format: formatCronitorPayload, check: cronitorCheck},
+	{source: "homeassistant", detect: isHomeAssistantPayload, format: formatHomeAssistantPayload},
+	{source: "unifi-protect", detect: isUniFiProtectPayload, format: formatUniFiProtectPayload},
+	{source: "unifi", detect: isUniFiNetworkPayload, format: formatUniFiNetworkPayload},
+	{source: "synology", detect: isSynologyPayload, format: formatSynologyPayload},
+	{source: "monit", detect: isMonitPayload, format: formatMonitPayload},
+	{source: "frigate", detect: isFrigatePayload, format: formatFrigatePayload},
+	{source: "arr", detect: isArrPayload, format: formatArrPayload},
+	{source: "overseerr", detect: isOverseerrPayload, format: formatOverseerrPayload},
+	{source: "tautulli", detect: isTautulliPayload, format: formatTautulliPayload},
+	{source: "watchtower", detect: isWatchtowerPayload, format: formatWatchtowerPayload},
+	{source: "diun", detect: isDiunPayload, format: formatDiunPayload},
+	{source: "portainer", detect: isPortainerPayload, format: formatPortainerPayload},
+	{source: "falco", detect: isFalcoPayload, format: formatFalcoPayload},
+	{source: "trivy", detect: isTrivyPayload, format: formatTrivyPayload},
+	{source: "cloudwatch", detect: isCloudWatchAlarmPayload, format: formatCloudWatchAlarmPayload},
+	{source: "jenkins", detect: isJenkinsPayload, format: formatJenkinsPayload},
+	{source: "drone", detect: isDronePayload, format: formatDronePayload},
+	{source: "woodpecker", detect: isWoodpeckerPayload, format: formatWoodpeckerPayload},
+	{source: "circleci", detect: isCircleCIPayload, format: formatCircleCIPayload},
+	{source: "travis", detect: isTravisPayload, format: formatTravisPayload},
+	{source: "argocd", detect: isArgoCDPayload, format: formatArgoCDPayload},
+	{source: "atlantis", detect: isAtlantisPayload, format: formatAtlantisPayload},
+	{source: "awx", detect: isAWXPayload, format: formatAWXPayload},
+	{source: "rundeck", detect: isRundeckPayload, format: formatRundeckPayload},
+	{source: "airflow", detect: isAirflowPayload, format: formatAirflowPayload},
+	{source: "wazuh", detect: isWazuhPayload, format: formatWazuhPayload},
+	{source: "crowdsec", detect: isCrowdSecPayload, format: formatCrowdSecPayload},
+	{source: "fail2ban", detect: isFail2banPayload, format: formatFail2banPayload},
+	{source: "stripe", detect: isStripePayload, format: formatStripePayload, skip: skipStripePayload},
+	{source: "dockerhub", detect: isDockerHubPayload, format: formatDockerHubPayload},
+	{source: "registry", detect: isRegistryEventsPayload, format: formatRegistryEventsPayload},
+	{source: "harbor", detect: isHarborPayload, format: formatHarborPayload},
+	{source: "duplicati", detect: isDuplicatiPayload, format: formatDuplicatiPayload},
+	{source: "backup", detect: isBackupToolPayload, format: formatBackupToolPayload},
+	{source: "syncthing", detect: isSyncthingPayload, format: formatSyncthingPayload, check: syncthingCheck},
+	{source: "octoprint", detect: isOctoPrintPayload, format: formatOctoPrintPayload},
+	{source: "moonraker", detect: isMoonrakerPayload, format: formatMoonrakerPayload},
+	{source: "minio", detect: isMinIOPayload, format: formatMinIOPayload, skip: skipMinIOPayload},
+}
+
+// detectPayloadFormatter returns the formatter matching the payload, or nil
+// if the payload is not recognised.
+func detectPayloadFormatter(body map[string]interface{}, config *Config) *payloadFormatter {
+	for i := range payloadFormatters {
+		formatter := &payloadFormatters[i]
+		if formatter.detectConfigured != nil {
+			if formatter.detectConfigured(body, config) {
+				return formatter
+			}
+		} else if formatter.detect(body) {
+			return formatter
+		}
+	}
+	return nil
+}
+
+// handleDetectedPayload formats and forwards the payload of a recognised service.
+func (p *WebhookForwarderPlugin) handleDetectedPayload(c *gin.Context, formatter *payloadFormatter, body map[string]interface{}) {
+	config := p.getConfig()
+	if formatter.skip != nil {
+		if reason := formatter.skip(body, config); reason != "" {
+			p.skipMessage(c, formatter.source, skipFiltered, reason)
+			return
+		}
+	}
+	if formatter.alertmanager {
+		p.forwardRuleAlerts(c, formatter, body)
+		return
+	}
+	msg := p.detectedMessage(c, formatter, body)
+
+	if formatter.respond == nil {
+		p.forwardMessage(c, formatter.source, msg)
+		return
+	}
+	if p.sendMessage(c, formatter.source, msg) {
+		formatter.respond(c)
+	}
+}
+
+// detectedMessage formats the payload of a recognised service and applies
+// its source profile.
+func (p *WebhookForwarderPlugin) detectedMessage(c *gin.Context, formatter *payloadFormatter, body map[string]interface{}) plugin.Message {
+	config := p.getConfig()
+	msg := formatter.format(body, config)
+
+	// Apply the source profile, including its templates
+	profile := sourceProfile(c, config, formatter.source)
+	if profile.Title != "" {
+		msg.Title = profile.Title
+	}
+	if profile.Priority > 0 {
+		msg.Priority = profile.Priority
+	}
+	msg.Title, msg.Message = profile.renderTemplates(body, msg.Title, msg.Message)
+
+	if formatter.check != nil {
+		if id, down, changed := formatter.check(body); id != "" {
+			if changed.IsZero() {
+				changed = timeNow()
+			}
+			if downtime, ok := p.trackDowntime(formatter.source+":"+id, down, changed); ok {
+				withDowntime(&msg, downtime)
+			}
+		}
+	}
+	return msg
+}
+
+// withExtra returns extras with key set to value, allocating the map if
+// needed. The given map is not modified.
+func withExtra(extras map[string]interface{}, key string, value interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(extras)+1)
+	for k, v := range extras {
+		result[k] = v
+	}
+	result[key] = value
+	return result
+}
+
+// stringField returns the first non-empty string value found under keys.
+func stringField(body map[string]interface{}, keys ...string) string {
+	for _, key := range keys {
+		switch v := body[key].(type) {
+		case string:
+			if v != "" {
+				return v
+			}
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		}
+	}
+	return ""
+}
+
+// mapSlice converts a decoded JSON array into its object elements.
+func mapSlice(value interface{}) []map[string]interface{} {
+	items, _ := value.([]interface{})
+	result := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		if m, ok := item.(map[string]interface{}); ok {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// joinStrings joins a list of strings such as tags with commas. A single
+// string is returned as is.
+func joinStrings(value interface{}) string {
+	switch items := value.(type) {
+	case string:
+		return items
+	case []interface{}:
+		values := make([]string, 0, len(items))
+		for _, item := range items {
+			if s, ok := item.(string); ok && s != "" {
+				values = append(values, s)
+			}
+		}
+		return strings.Join(values, ", ")
+	}
+	return ""
+}
+
+// intField returns the first numeric value found under keys. Numeric strings
+// are accepted to support loosely typed and form-encoded payloads.
+func intField(body map[string]interface{}, keys ...string) int {
+	for _, key := range keys {
+		switch v := body[key].(type) {
+		case float64:
+			return int(v)
+		case int:
+			return v
+		case string:
+			if n, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+				return int(n)
+			}
+		}
+	}
+	return 0
+}
+
+// hasFields reports whether all keys are present in the payload.
+func hasFields(body map[string]interface{}, keys ...string) bool {
+	for _, key := range keys {
+		if _, ok := body[key]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// sourceIs reports whether the payload declares itself as coming from name
+// via a "source" or "app" field.
+func sourceIs(body map[string]interface{}, name string) bool {
+	return strings.EqualFold(stringField(body, "source", "app"), name)
+}
+
+// matchesEvent reports whether an event type is listed in patterns, where a
+// trailing "*" matches any event with that prefix. Empty patterns match all
+// events.
+func matchesEvent(patterns []string, event string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok && strings.HasPrefix(event, prefix) {
+			return true
+		}
+		if pattern == event {
+			return true
+		}
+	}
+	return false
+}
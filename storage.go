@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gotify/plugin-api"
+)
+
+// pluginStorage is the state persisted through the Gotify storage handler.
+type pluginStorage struct {
+	// ConfigOverride is the YAML config imported via PUT /config. It is
+	// restored on Enable until the config is changed in the Gotify UI.
+	ConfigOverride string `json:"configOverride,omitempty"`
+	// ConfigOverrideBase fingerprints the Gotify UI config the override was
+	// imported over, to drop the override once that config was changed.
+	ConfigOverrideBase string `json:"configOverrideBase,omitempty"`
+	// AlertGroups tracks the notified Grafana alerts per alert group.
+	AlertGroups map[string]*alertGroupState `json:"alertGroups,omitempty"`
+	// Downtimes holds when monitored checks went down, keyed by source and
+	// check, to report the downtime when they recover.
+	Downtimes map[string]time.Time `json:"downtimes,omitempty"`
+	// DependencyUpdates holds the dependency pull requests waiting for the
+	// next digest.
+	DependencyUpdates []dependencyUpdate `json:"dependencyUpdates,omitempty"`
+	// ReportedPullRequests holds the keys of the pull requests listed in the
+	// last Renovate report per repository, as every report lists all open
+	// pull requests and only new ones are notified.
+	ReportedPullRequests map[string][]string `json:"reportedPullRequests,omitempty"`
+}
+
+// SetStorageHandler implements plugin.Storager
+func (p *WebhookForwarderPlugin) SetStorageHandler(h plugin.StorageHandler) {
+	p.storageHandler = h
+}
+
+// loadStorage reads the persisted state. Missing or unreadable state
+// results in an empty pluginStorage.
+func (p *WebhookForwarderPlugin) loadStorage() (pluginStorage, error) {
+	var storage pluginStorage
+	if p.storageHandler == nil {
+		return storage, nil
+	}
+	data, err := p.storageHandler.Load()
+	if err != nil {
+		return storage, err
+	}
+	if len(data) == 0 {
+		return storage, nil
+	}
+	err = json.Unmarshal(data, &storage)
+	return storage, err
+}
+
+// updateStorage applies update to the persisted state and saves it.
+func (p *WebhookForwarderPlugin) updateStorage(update func(storage *pluginStorage)) error {
+	if p.storageHandler == nil {
+		return nil
+	}
+
+	p.storageMu.Lock()
+	defer p.storageMu.Unlock()
+
+	storage, err := p.loadStorage()
+	if err != nil {
+		return err
+	}
+	update(&storage)
+	data, err := json.Marshal(storage)
+	if err != nil {
+		return err
+	}
+	return p.storageHandler.Save(data)
+}
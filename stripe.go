@@ -0,0 +1,239 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gotify/plugin-api"
+)
+
+// StripeConfig holds options for Stripe webhooks.
+type StripeConfig struct {
+	// Secret is the signing secret of the Stripe webhook endpoint
+	// ("whsec_..."). If set, requests must carry a valid Stripe-Signature
+	// header.
+	Secret string `yaml:"secret"`
+	// Events lists the forwarded event types, e.g. "charge.dispute.created".
+	// A trailing "*" matches any event with that prefix, "*" all events.
+	// Empty forwards all events.
+	Events []string `yaml:"events"`
+}
+
+// defaultStripeEvents returns the event types forwarded by default: failed
+// payments and disputes.
+func defaultStripeEvents() []string {
+	return []string{
+		"invoice.payment_failed",
+		"payment_intent.payment_failed",
+		"charge.failed",
+		"charge.dispute.created",
+	}
+}
+
+// verify reports whether the body is signed with the secret, as given by
+// the "t=<timestamp>,v1=<hex>" Stripe-Signature header. The timestamp must
+// be within signatureMaxAge of now.
+func (s *StripeConfig) verify(header http.Header, body []byte, now time.Time) bool {
+	if s.Secret == "" {
+		return true
+	}
+	var timestamp string
+	var signatures [][]byte
+	for _, part := range strings.Split(header.Get("Stripe-Signature"), ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			if signature, err := hex.DecodeString(value); err == nil && len(signature) > 0 {
+				signatures = append(signatures, signature)
+			}
+		}
+	}
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return false
+	}
+	if age := now.Sub(time.Unix(seconds, 0)); age > signatureMaxAge || age < -signatureMaxAge {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(s.Secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	expected := mac.Sum(nil)
+	for _, signature := range signatures {
+		if hmac.Equal(signature, expected) {
+			return true
+		}
+	}
+	return false
+}
+
+// isStripeRequest detects Stripe webhook deliveries by their signature
+// header.
+func isStripeRequest(c *gin.Context) bool {
+	return c.GetHeader("Stripe-Signature") != ""
+}
+
+// stripeEventPriorities maps Stripe event types onto priorities. Disputes
+// and fraud warnings need a response within a deadline.
+var stripeEventPriorities = map[string]int{
+	"charge.dispute.created":            8,
+	"radar.early_fraud_warning.created": 8,
+	"invoice.payment_failed":            7,
+	"payment_intent.payment_failed":     7,
+	"charge.failed":                     7,
+	"customer.subscription.deleted":     5,
+}
+
+// stripeZeroDecimalCurrencies lists currencies whose amounts are not given
+// in cents.
+var stripeZeroDecimalCurrencies = map[string]bool{
+	"bif": true, "clp": true, "djf": true, "gnf": true, "jpy": true,
+	"kmf": true, "krw": true, "mga": true, "pyg": true, "rwf": true,
+	"ugx": true, "vnd": true, "vuv": true, "xaf": true, "xof": true, "xpf": true,
+}
+
+// isStripePayload detects Stripe event envelopes.
+func isStripePayload(body map[string]interface{}) bool {
+	data, ok := body["data"].(map[string]interface{})
+	return ok && stringField(body, "object") == "event" && stringField(body, "type") != "" && hasFields(data, "object")
+}
+
+// skipStripePayload drops events not listed in stripe.events.
+func skipStripePayload(body map[string]interface{}, config *Config) string {
+	if !matchesEvent(config.Stripe.Events, stringField(body, "type")) {
+		return "Stripe event type not listed in stripe.events"
+	}
+	return ""
+}
+
+// formatStripePayload renders a Stripe event titled by its type, amount and
+// customer, e.g. "Stripe: invoice payment failed — 12.00 EUR from
+// alice@example.com". Clicking opens the event in the Stripe dashboard.
+func formatStripePayload(body map[string]interface{}, config *Config) plugin.Message {
+	kind := stringField(body, "type")
+	data, _ := body["data"].(map[string]interface{})
+	object, _ := data["object"].(map[string]interface{})
+
+	summary := strings.NewReplacer(".", " ", "_", " ").Replace(kind)
+	if kind == "charge.dispute.created" {
+		summary = "dispute opened"
+	}
+	title := "Stripe: " + summary
+	amount := stripeAmount(object)
+	customer := stripeCustomer(object)
+	if amount != "" {
+		title += " — " + amount
+		if customer != "" {
+			title += " from " + customer
+		}
+	}
+
+	var lines []string
+	lastError, _ := object["last_payment_error"].(map[string]interface{})
+	if reason := stringField(object, "failure_message"); reason != "" {
+		lines = append(lines, "Reason: "+reason)
+	} else if reason := stringField(lastError, "message"); reason != "" {
+		lines = append(lines, "Reason: "+reason)
+	} else if reason := stringField(object, "reason"); reason != "" {
+		lines = append(lines, "Reason: "+strings.ReplaceAll(reason, "_", " "))
+	}
+	if customer != "" && amount == "" {
+		lines = append(lines, "Customer: "+customer)
+	}
+	if description := stringField(object, "description"); description != "" {
+		lines = append(lines, "Description: "+description)
+	}
+	if number := stringField(object, "number"); number != "" {
+		lines = append(lines, "Invoice: "+number)
+	}
+	if attempts := intField(object, "attempt_count"); attempts > 0 {
+		lines = append(lines, fmt.Sprintf("Attempts: %d", attempts))
+	}
+	if evidence, ok := object["evidence_details"].(map[string]interface{}); ok {
+		if due := intField(evidence, "due_by"); due > 0 {
+			lines = append(lines, "Respond by: "+config.formatTimestamp(time.Unix(int64(due), 0).UTC().Format(time.RFC3339)))
+		}
+	}
+	if url := stringField(object, "hosted_invoice_url"); url != "" {
+		lines = append(lines, "Invoice URL: "+url)
+	}
+	if live, ok := body["livemode"].(bool); ok && !live {
+		lines = append(lines, "Test mode")
+	}
+	message := strings.Join(lines, "\n")
+	if message == "" {
+		message = title
+	}
+
+	priority, ok := stripeEventPriorities[kind]
+	if !ok {
+		priority = 3
+		if strings.Contains(kind, "failed") {
+			priority = 7
+		}
+	}
+
+	extras := map[string]interface{}{
+		"source": "stripe",
+		"event":  kind,
+	}
+	if id := stringField(body, "id"); id != "" {
+		dashboard := "https://dashboard.stripe.com/"
+		if live, ok := body["livemode"].(bool); ok && !live {
+			dashboard += "test/"
+		}
+		extras["client::notification"] = map[string]interface{}{
+			"click": map[string]interface{}{"url": dashboard + "events/" + id},
+		}
+	}
+
+	return plugin.Message{
+		Title:    title,
+		Message:  message,
+		Priority: priority,
+		Extras:   extras,
+	}
+}
+
+// stripeAmount formats the amount of a Stripe object, e.g. "12.00 EUR".
+func stripeAmount(object map[string]interface{}) string {
+	currency := strings.ToLower(stringField(object, "currency"))
+	value, ok := object["amount_due"].(float64)
+	if !ok {
+		value, ok = object["amount"].(float64)
+	}
+	if !ok || currency == "" {
+		return ""
+	}
+	if stripeZeroDecimalCurrencies[currency] {
+		return fmt.Sprintf("%d %s", int64(value), strings.ToUpper(currency))
+	}
+	return fmt.Sprintf("%.2f %s", math.Round(value)/100, strings.ToUpper(currency))
+}
+
+// stripeCustomer identifies the customer of a Stripe object by email or
+// name, falling back to the customer ID.
+func stripeCustomer(object map[string]interface{}) string {
+	billing, _ := object["billing_details"].(map[string]interface{})
+	if email := stringField(object, "customer_email", "receipt_email"); email != "" {
+		return email
+	}
+	if email := stringField(billing, "email"); email != "" {
+		return email
+	}
+	if name := stringField(object, "customer_name"); name != "" {
+		return name
+	}
+	return stringField(object, "customer")
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gotify/plugin-api"
+	"github.com/stretchr/testify/assert"
+)
+
+func stripeEvent(kind string, object map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"id":          "evt_1OqZ2xLkdIwHu7ix",
+		"object":      "event",
+		"api_version": "2023-10-16",
+		"created":     1709288100.0,
+		"livemode":    true,
+		"type":        kind,
+		"data":        map[string]interface{}{"object": object},
+	}
+}
+
+func TestWebhookForwarderPlugin_StripeSignedPaymentFailure(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+	p.SetStorageHandler(&MockStorageHandler{})
+	config := defaultConfig()
+	config.Stripe.Secret = "whsec_test"
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+
+	now := time.Date(2024, 3, 1, 10, 15, 0, 0, time.UTC)
+	defer func() { timeNow = time.Now }()
+	timeNow = func() time.Time { return now }
+
+	event := stripeEvent("invoice.payment_failed", map[string]interface{}{
+		"object":             "invoice",
+		"amount_due":         1200.0,
+		"currency":           "eur",
+		"customer":           "cus_PfQm",
+		"customer_email":     "alice@example.com",
+		"number":             "A1B2C3-0007",
+		"attempt_count":      2.0,
+		"hosted_invoice_url": "https://invoice.stripe.com/i/acct_1/test_YWNjdA",
+	})
+	body, _ := json.Marshal(event)
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+
+	// Unsigned and wrongly signed deliveries are rejected
+	w := postSignedWebhook(p, event, map[string]string{"Stripe-Signature": "t=" + timestamp + ",v1=" + sign("wrong", timestamp, ".", string(body))})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	w = postWebhook(p, event)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	stale := strconv.FormatInt(now.Add(-10*time.Minute).Unix(), 10)
+	w = postSignedWebhook(p, event, map[string]string{"Stripe-Signature": "t=" + stale + ",v1=" + sign("whsec_test", stale, ".", string(body))})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Empty(t, mockHandler.sentMessages)
+
+	w = postSignedWebhook(p, event, map[string]string{
+		"Stripe-Signature": "t=" + timestamp + ",v1=" + sign("whsec_test", timestamp, ".", string(body)) + ",v0=6ffbb59b2300aae63f2723",
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title:    "Stripe: invoice payment failed — 12.00 EUR from alice@example.com",
+		Message:  "Invoice: A1B2C3-0007\nAttempts: 2\nInvoice URL: https://invoice.stripe.com/i/acct_1/test_YWNjdA",
+		Priority: 7,
+		Extras: map[string]interface{}{
+			"source": "stripe",
+			"event":  "invoice.payment_failed",
+			"client::notification": map[string]interface{}{
+				"click": map[string]interface{}{"url": "https://dashboard.stripe.com/events/evt_1OqZ2xLkdIwHu7ix"},
+			},
+		},
+	}, mockHandler.sentMessages[0])
+}
+
+func TestWebhookForwarderPlugin_StripeEventAllowlist(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	w := postWebhook(p, stripeEvent("customer.created", map[string]interface{}{"object": "customer", "email": "bob@example.com"}))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "not listed in stripe.events")
+	assert.Empty(t, mockHandler.sentMessages)
+
+	dispute := stripeEvent("charge.dispute.created", map[string]interface{}{
+		"object":           "dispute",
+		"amount":           5000.0,
+		"currency":         "jpy",
+		"reason":           "product_not_received",
+		"evidence_details": map[string]interface{}{"due_by": 1710115199.0},
+	})
+	dispute["livemode"] = false
+	w = postWebhook(p, dispute)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	msg := mockHandler.sentMessages[0]
+	assert.Equal(t, "Stripe: dispute opened — 5000 JPY", msg.Title)
+	assert.Contains(t, msg.Message, "Reason: product not received\n")
+	assert.Contains(t, msg.Message, "Test mode")
+	assert.Equal(t, 8, msg.Priority)
+	assert.Equal(t, map[string]interface{}{
+		"click": map[string]interface{}{"url": "https://dashboard.stripe.com/test/events/evt_1OqZ2xLkdIwHu7ix"},
+	}, msg.Extras["client::notification"])
+}
+
+func TestMatchesEvent(t *testing.T) {
+	assert.True(t, matchesEvent(nil, "charge.failed"))
+	assert.True(t, matchesEvent([]string{"*"}, "charge.failed"))
+	assert.True(t, matchesEvent([]string{"charge.*"}, "charge.failed"))
+	assert.False(t, matchesEvent([]string{"charge.dispute.created"}, "charge.failed"))
+}
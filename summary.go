@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// groupName returns a readable name for the alert group of a notification,
+// preferring the alertname group label.
+func (w GrafanaWebhook) groupName() string {
+	if name := w.GroupLabels["alertname"]; name != "" {
+		return name
+	}
+	values := make([]string, 0, len(w.GroupLabels))
+	for _, value := range w.GroupLabels {
+		if value != "" {
+			values = append(values, value)
+		}
+	}
+	if len(values) > 0 {
+		sort.Strings(values)
+		return strings.Join(values, ", ")
+	}
+	return w.groupKey()
+}
+
+// summaryTitle returns a title counting the alerts of a notification by
+// status, followed by the group name, e.g. "3 firing, 1 resolved — node-alerts".
+func summaryTitle(webhook GrafanaWebhook) string {
+	firing, resolved := 0, 0
+	for _, alert := range webhook.Alerts {
+		if webhook.alertStatus(alert) == "resolved" {
+			resolved++
+		} else {
+			firing++
+		}
+	}
+	var counts []string
+	if firing > 0 {
+		counts = append(counts, fmt.Sprintf("%d firing", firing))
+	}
+	if resolved > 0 {
+		counts = append(counts, fmt.Sprintf("%d resolved", resolved))
+	}
+	title := strings.Join(counts, ", ")
+	if len(webhook.GroupLabels) > 0 {
+		title += " — " + webhook.groupName()
+	}
+	return title
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_GrafanaSummaryTitle(t *testing.T) {
+	p, mockHandler := newTestPlugin(t, defaultConfig())
+
+	// Without a title from Grafana the alerts are counted
+	payload := allClearPayload("firing", "firing", "firing", "resolved")
+	postWebhook(p, payload)
+	assert.Equal(t, "3 firing, 1 resolved — node-alerts", mockHandler.sentMessages[0].Title)
+
+	// Grafana's title is kept unless summaryTitle is enabled
+	payload["title"] = "[FIRING:3] node-alerts"
+	postWebhook(p, payload)
+	assert.Equal(t, "[FIRING:3] node-alerts", mockHandler.sentMessages[1].Title)
+
+	config := defaultConfig()
+	config.Grafana.SummaryTitle = true
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+	postWebhook(p, payload)
+	assert.Equal(t, "3 firing, 1 resolved — node-alerts", mockHandler.sentMessages[2].Title)
+}
+
+func TestSummaryTitle(t *testing.T) {
+	webhook := GrafanaWebhook{
+		Status: "resolved",
+		Alerts: []GrafanaAlert{{}, {}},
+	}
+	assert.Equal(t, "2 resolved", summaryTitle(webhook))
+
+	webhook.GroupLabels = map[string]string{"team": "db"}
+	webhook.Alerts[0].Status = "firing"
+	assert.Equal(t, "1 firing, 1 resolved — db", summaryTitle(webhook))
+}
+
+func TestGrafanaWebhook_GroupName(t *testing.T) {
+	assert.Equal(t, "HighCPU", GrafanaWebhook{GroupLabels: map[string]string{"alertname": "HighCPU"}}.groupName())
+	assert.Equal(t, "db, prod", GrafanaWebhook{GroupLabels: map[string]string{"env": "prod", "team": "db"}}.groupName())
+	assert.Equal(t, "{}:{}", GrafanaWebhook{GroupKey: "{}:{}"}.groupName())
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gotify/plugin-api"
+)
+
+// syncthingEvents lists the event types of Syncthing's events API that are
+// recognised without a "source" field.
+var syncthingEvents = map[string]bool{
+	"FolderCompletion":      true,
+	"FolderErrors":          true,
+	"FolderPaused":          true,
+	"FolderResumed":         true,
+	"DeviceConnected":       true,
+	"DeviceDisconnected":    true,
+	"DevicePaused":          true,
+	"DeviceResumed":         true,
+	"DeviceRejected":        true,
+	"PendingDevicesChanged": true,
+	"StateChanged":          true,
+	"ItemFinished":          true,
+	"Failure":               true,
+}
+
+// isSyncthingPayload detects events of Syncthing's REST events API
+// (/rest/events) forwarded by a relay, either a single event or a batch
+// under "events".
+func isSyncthingPayload(body map[string]interface{}) bool {
+	if events := mapSlice(body["events"]); len(events) > 0 {
+		return isSyncthingEvent(events[0])
+	}
+	return isSyncthingEvent(body) || (sourceIs(body, "syncthing") && stringField(body, "type") != "")
+}
+
+// isSyncthingEvent reports whether a map looks like a Syncthing event.
+func isSyncthingEvent(event map[string]interface{}) bool {
+	return syncthingEvents[stringField(event, "type")] &&
+		hasFields(event, "data") && hasAnyField(event, "globalID", "time")
+}
+
+// formatSyncthingPayload renders Syncthing events, e.g. "Syncthing: Folder
+// photos has 2 sync errors". A batch of events is listed in one message
+// with the priority of the most important event.
+func formatSyncthingPayload(body map[string]interface{}, config *Config) plugin.Message {
+	events := mapSlice(body["events"])
+	if len(events) == 0 {
+		events = []map[string]interface{}{body}
+	}
+
+	type described struct {
+		summary  string
+		lines    []string
+		priority int
+	}
+	var all []described
+	for _, event := range events {
+		summary, lines, priority := syncthingEvent(event)
+		if when := config.formatTimestamp(stringField(event, "time")); when != "" && len(events) == 1 {
+			lines = append(lines, "Time: "+when)
+		}
+		all = append(all, described{summary, lines, priority})
+	}
+
+	extras := map[string]interface{}{"source": "syncthing"}
+	if len(all) == 1 {
+		extras["event"] = stringField(events[0], "type")
+		message := strings.Join(all[0].lines, "\n")
+		if message == "" {
+			message = all[0].summary
+		}
+		return plugin.Message{
+			Title:    "Syncthing: " + all[0].summary,
+			Message:  message,
+			Priority: all[0].priority,
+			Extras:   extras,
+		}
+	}
+
+	// List the most important events first
+	sort.SliceStable(all, func(i, j int) bool { return all[i].priority > all[j].priority })
+	var lines []string
+	for _, event := range all {
+		lines = append(lines, "- "+event.summary)
+		for _, line := range event.lines {
+			lines = append(lines, "  "+line)
+		}
+	}
+	extras["count"] = len(all)
+	return plugin.Message{
+		Title:    fmt.Sprintf("Syncthing: %d events", len(all)),
+		Message:  strings.Join(lines, "\n"),
+		Priority: all[0].priority,
+		Extras:   extras,
+	}
+}
+
+// syncthingEvent describes a single event with a summary, detail lines and
+// its priority. Sync errors and failures get priority 7, disconnects 5 and
+// routine progress 1.
+func syncthingEvent(event map[string]interface{}) (string, []string, int) {
+	kind := stringField(event, "type")
+	data, _ := event["data"].(map[string]interface{})
+	folder := "Folder " + stringField(data, "label", "folderLabel", "folder")
+	device := syncthingDevice(data)
+
+	switch kind {
+	case "FolderCompletion":
+		completion := intField(data, "completion")
+		if completion >= 100 {
+			return fmt.Sprintf("%s up to date on %s", folder, device), nil, 2
+		}
+		var lines []string
+		if need := intField(data, "needBytes"); need > 0 {
+			lines = append(lines, "Remaining: "+humanizeBytes(int64(need)))
+		}
+		return fmt.Sprintf("%s syncing to %s (%d%%)", folder, device, completion), lines, 1
+	case "FolderErrors":
+		var lines []string
+		for _, item := range mapSlice(data["errors"]) {
+			lines = append(lines, strings.TrimSpace(stringField(item, "path")+": "+stringField(item, "error")))
+		}
+		count := fmt.Sprintf("%d sync errors", len(lines))
+		if len(lines) == 1 {
+			count = "1 sync error"
+		}
+		return fmt.Sprintf("%s has %s", folder, count), lines, 7
+	case "StateChanged":
+		if to := stringField(data, "to"); to != "error" {
+			return fmt.Sprintf("%s %s", folder, to), nil, 1
+		}
+		return folder + " stopped", []string{"Error: " + stringField(data, "error")}, 7
+	case "ItemFinished":
+		item := stringField(data, "item")
+		if text := stringField(data, "error"); text != "" {
+			return fmt.Sprintf("%s failed to sync %s", folder, item), []string{"Error: " + text}, 6
+		}
+		return fmt.Sprintf("%s synced %s", folder, item), nil, 1
+	case "DeviceConnected":
+		var lines []string
+		if address := stringField(data, "addr"); address != "" {
+			lines = append(lines, "Address: "+address)
+		}
+		if client := strings.TrimSpace(stringField(data, "clientName") + " " + stringField(data, "clientVersion")); client != "" {
+			lines = append(lines, "Client: "+client)
+		}
+		return device + " connected", lines, 2
+	case "DeviceDisconnected":
+		var lines []string
+		if text := stringField(data, "error"); text != "" {
+			lines = append(lines, "Error: "+text)
+		}
+		return device + " disconnected", lines, 5
+	case "DeviceRejected":
+		return device + " wants to connect", nil, 5
+	case "Failure":
+		return "failure", []string{looseString(event["data"])}, 7
+	}
+
+	switch {
+	case strings.HasPrefix(kind, "Folder"):
+		return folder + " " + fluxReason(strings.TrimPrefix(kind, "Folder")), nil, 3
+	case strings.HasPrefix(kind, "Device"):
+		return device + " " + fluxReason(strings.TrimPrefix(kind, "Device")), nil, 3
+	}
+	return fluxReason(kind), nil, 3
+}
+
+// syncthingDevice names the device of an event by its name or the first
+// block of its ID, as shown in the Syncthing GUI.
+func syncthingDevice(data map[string]interface{}) string {
+	if name := stringField(data, "deviceName", "name"); name != "" {
+		return "Device " + name
+	}
+	id := stringField(data, "device", "id")
+	if block := strings.Index(id, "-"); block > 0 {
+		id = id[:block]
+	}
+	return "Device " + id
+}
+
+// syncthingCheck tracks connections of Syncthing devices, so reconnects
+// report how long a device was away.
+func syncthingCheck(body map[string]interface{}) (string, bool, time.Time) {
+	var changed time.Time
+	if t, err := time.Parse(time.RFC3339Nano, stringField(body, "time")); err == nil {
+		changed = t
+	}
+	data, _ := body["data"].(map[string]interface{})
+	switch stringField(body, "type") {
+	case "DeviceConnected":
+		return stringField(data, "id", "device"), false, changed
+	case "DeviceDisconnected":
+		return stringField(data, "id", "device"), true, changed
+	}
+	return "", false, changed
+}
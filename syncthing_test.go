@@ -0,0 +1,124 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gotify/plugin-api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_SyncthingFolderErrors(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+	p.SetStorageHandler(&MockStorageHandler{})
+	config := defaultConfig()
+	config.Timezone = "UTC"
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+
+	w := postWebhook(p, map[string]interface{}{
+		"id":       42.0,
+		"globalID": 1337.0,
+		"type":     "FolderErrors",
+		"time":     "2024-03-01T10:15:00.123456789Z",
+		"data": map[string]interface{}{
+			"folder": "abcd-1234",
+			"errors": []interface{}{
+				map[string]interface{}{"path": "photos/IMG_0001.jpg", "error": "permission denied"},
+				map[string]interface{}{"path": "photos/IMG_0002.jpg", "error": "file modified but not rescanned"},
+			},
+		},
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title: "Syncthing: Folder abcd-1234 has 2 sync errors",
+		Message: "photos/IMG_0001.jpg: permission denied\n" +
+			"photos/IMG_0002.jpg: file modified but not rescanned\n" +
+			"Time: 2024-03-01 10:15:00 UTC",
+		Priority: 7,
+		Extras: map[string]interface{}{
+			"source": "syncthing",
+			"event":  "FolderErrors",
+		},
+	}, mockHandler.sentMessages[0])
+}
+
+func TestWebhookForwarderPlugin_SyncthingDeviceReconnect(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+	p.SetStorageHandler(&MockStorageHandler{})
+
+	postWebhook(p, map[string]interface{}{
+		"globalID": 1.0,
+		"type":     "DeviceDisconnected",
+		"time":     "2024-03-01T10:00:00Z",
+		"data": map[string]interface{}{
+			"id":    "P56IOI7-MZJNU2Y-IQGDREY-DM2MGTI-MGL3BXN-PQ6W5BM-TBBZ4TJ-XZWICQ2",
+			"error": "read timeout",
+		},
+	})
+	w := postWebhook(p, map[string]interface{}{
+		"globalID": 2.0,
+		"type":     "DeviceConnected",
+		"time":     "2024-03-01T12:30:00Z",
+		"data": map[string]interface{}{
+			"id":            "P56IOI7-MZJNU2Y-IQGDREY-DM2MGTI-MGL3BXN-PQ6W5BM-TBBZ4TJ-XZWICQ2",
+			"deviceName":    "laptop",
+			"addr":          "192.168.1.20:22000",
+			"clientName":    "syncthing",
+			"clientVersion": "v1.27.4",
+		},
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 2)
+	assert.Equal(t, "Syncthing: Device P56IOI7 disconnected", mockHandler.sentMessages[0].Title)
+	assert.Equal(t, 5, mockHandler.sentMessages[0].Priority)
+	assert.Equal(t, "Syncthing: Device laptop connected", mockHandler.sentMessages[1].Title)
+	assert.Contains(t, mockHandler.sentMessages[1].Message, "Client: syncthing v1.27.4")
+	assert.Contains(t, mockHandler.sentMessages[1].Message, "Down for 2h 30m")
+}
+
+func TestWebhookForwarderPlugin_SyncthingBatch(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	w := postWebhook(p, map[string]interface{}{
+		"events": []interface{}{
+			map[string]interface{}{
+				"globalID": 10.0,
+				"type":     "FolderCompletion",
+				"data": map[string]interface{}{
+					"folder":     "docs",
+					"device":     "MFZWI3D-BONSGYC-YLTMRWG-C43ENR5-QXGZDMM-FZWI3DP-BONSGYY-LTMRWAD",
+					"completion": 100.0,
+				},
+			},
+			map[string]interface{}{
+				"globalID": 11.0,
+				"type":     "StateChanged",
+				"data": map[string]interface{}{
+					"folder": "music",
+					"from":   "scanning",
+					"to":     "error",
+					"error":  "folder marker missing",
+				},
+			},
+			map[string]interface{}{
+				"globalID": 12.0,
+				"type":     "FolderPaused",
+				"data":     map[string]interface{}{"id": "docs", "label": "Documents"},
+			},
+		},
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, "Syncthing: 3 events", mockHandler.sentMessages[0].Title)
+	assert.Equal(t, "- Folder music stopped\n  Error: folder marker missing\n"+
+		"- Folder Documents paused\n"+
+		"- Folder docs up to date on Device MFZWI3D", mockHandler.sentMessages[0].Message)
+	assert.Equal(t, 7, mockHandler.sentMessages[0].Priority)
+}
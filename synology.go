@@ -0,0 +1,80 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/gotify/plugin-api"
+)
+
+// keywordPriorities derives priorities from words in a text. The groups are
+// checked in order and the first group with a matching word wins.
+type keywordPriorities []struct {
+	words    []string
+	priority int
+}
+
+// priority returns the priority of the first group with a word contained in
+// text, ignoring case, or fallback if none matches.
+func (k keywordPriorities) priority(text string, fallback int) int {
+	text = strings.ToLower(text)
+	for _, group := range k {
+		for _, word := range group.words {
+			if strings.Contains(text, word) {
+				return group.priority
+			}
+		}
+	}
+	return fallback
+}
+
+// synologyKeywords raises the priority of Synology DSM notifications by the
+// words in their text.
+var synologyKeywords = keywordPriorities{
+	{[]string{"crashed", "failed", "failure", "critical", "not accessible"}, 9},
+	{[]string{"degraded", "abnormal", "bad sector", "error", "overheat", "warning"}, 7},
+	{[]string{"back to normal", "recovered", "repaired", "successfully", "completed"}, 3},
+}
+
+// isSynologyPayload detects Synology DSM custom webhook notifications: a
+// single text along with the hostname of the NAS.
+func isSynologyPayload(body map[string]interface{}) bool {
+	if sourceIs(body, "synology") {
+		return true
+	}
+	return hasAnyField(body, "hostname", "nas") && stringField(body, "text", "message", "msg") != "" &&
+		!hasAnyField(body, "title", "alerts", "extras")
+}
+
+// formatSynologyPayload renders a Synology DSM notification with the NAS in
+// the title and the notification text as message.
+func formatSynologyPayload(body map[string]interface{}, _ *Config) plugin.Message {
+	text := strings.TrimSpace(stringField(body, "text", "message", "msg"))
+	hostname := stringField(body, "hostname", "nas")
+
+	title := "Synology"
+	if hostname != "" {
+		title = "Synology: " + hostname
+	}
+	message := text
+	if message == "" {
+		message = title
+	}
+
+	extras := map[string]interface{}{"source": "synology"}
+	if hostname != "" {
+		extras["hostname"] = hostname
+	}
+
+	return plugin.Message{
+		Title:    title,
+		Message:  message,
+		Priority: synologyPriority(text),
+		Extras:   extras,
+	}
+}
+
+// synologyPriority derives the priority from severity keywords in the text,
+// 5 if none matches.
+func synologyPriority(text string) int {
+	return synologyKeywords.priority(text, 5)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gotify/plugin-api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_SynologyWebhook(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+	router := gin.New()
+	router.POST("/message", p.handleWebhookMessage)
+
+	form := url.Values{
+		"hostname": {"DiskStation"},
+		"text":     {"Storage Pool 1 on DiskStation has degraded. Please repair it."},
+	}
+	req := httptest.NewRequest("POST", "/message", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title:    "Synology: DiskStation",
+		Message:  "Storage Pool 1 on DiskStation has degraded. Please repair it.",
+		Priority: 7,
+		Extras: map[string]interface{}{
+			"source":   "synology",
+			"hostname": "DiskStation",
+		},
+	}, mockHandler.sentMessages[0])
+
+	postWebhook(p, map[string]interface{}{
+		"hostname": "DiskStation",
+		"message":  "Drive 2 in DiskStation has failed.",
+	})
+	assert.Len(t, mockHandler.sentMessages, 2)
+	assert.Equal(t, 9, mockHandler.sentMessages[1].Priority)
+
+	assert.Equal(t, 3, synologyPriority("Hyper Backup task completed successfully"))
+	assert.Equal(t, 5, synologyPriority("DSM update 7.2.1 is available"))
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gotify/plugin-api"
+)
+
+// TautulliConfig holds options for Tautulli webhook notifications.
+type TautulliConfig struct {
+	// ActionPriorities maps notification actions (play, created, intdown,
+	// ...) to priorities. Unknown actions get priority 5.
+	ActionPriorities map[string]int `yaml:"actionPriorities"`
+}
+
+// defaultTautulliPriorities are the default priorities of the notification
+// actions of Tautulli. Plex going down is urgent, playback is not.
+func defaultTautulliPriorities() map[string]int {
+	return map[string]int{
+		"play":         3,
+		"stop":         2,
+		"pause":        2,
+		"resume":       2,
+		"change":       2,
+		"buffer":       4,
+		"error":        7,
+		"watched":      3,
+		"created":      5,
+		"newdevice":    6,
+		"concurrent":   6,
+		"intdown":      9,
+		"intup":        4,
+		"extdown":      8,
+		"extup":        4,
+		"pmsupdate":    4,
+		"plexpyupdate": 3,
+	}
+}
+
+// validate checks the action priorities.
+func (t *TautulliConfig) validate() error {
+	for action, priority := range t.ActionPriorities {
+		if priority < 1 || priority > 10 {
+			return fmt.Errorf("invalid tautulli.actionPriorities.%s: priority must be between 1 and 10", action)
+		}
+	}
+	return nil
+}
+
+// isTautulliPayload detects Tautulli webhook notifications sending the
+// notification action along with the subject and body text.
+func isTautulliPayload(body map[string]interface{}) bool {
+	if !hasAnyField(body, "subject", "body", "poster_url") {
+		return false
+	}
+	if sourceIs(body, "tautulli") {
+		return true
+	}
+	_, known := defaultTautulliPriorities()[strings.ToLower(stringField(body, "action"))]
+	return known
+}
+
+// formatTautulliPayload renders a Tautulli notification with the subject as
+// title, the body as message and the poster as big image.
+func formatTautulliPayload(body map[string]interface{}, config *Config) plugin.Message {
+	action := strings.ToLower(stringField(body, "action"))
+	title := strings.TrimSpace(stringField(body, "subject"))
+	if title == "" {
+		title = "Tautulli: " + action
+	}
+	message := strings.TrimSpace(stringField(body, "body", "message"))
+	if message == "" {
+		message = title
+	}
+
+	extras := map[string]interface{}{"source": "tautulli"}
+	if action != "" {
+		extras["action"] = action
+	}
+	notification := map[string]interface{}{}
+	if poster := stringField(body, "poster_url"); poster != "" {
+		notification["bigImageUrl"] = poster
+	}
+	if url := stringField(body, "plex_url", "url"); url != "" {
+		notification["click"] = map[string]interface{}{"url": url}
+	}
+	if len(notification) > 0 {
+		extras["client::notification"] = notification
+	}
+
+	priority := 5
+	if p, ok := config.Tautulli.ActionPriorities[action]; ok {
+		priority = p
+	}
+
+	return plugin.Message{
+		Title:    title,
+		Message:  message,
+		Priority: priority,
+		Extras:   extras,
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gotify/plugin-api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_TautulliWebhook(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	w := postWebhook(p, map[string]interface{}{
+		"action":     "created",
+		"subject":    "Recently Added: Dune (2021)",
+		"body":       "Dune was added to Movies.",
+		"poster_url": "https://i.imgur.com/poster.jpg",
+		"plex_url":   "https://app.plex.tv/desktop#!/server/abc/details?key=%2Flibrary%2Fmetadata%2F42",
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title:    "Recently Added: Dune (2021)",
+		Message:  "Dune was added to Movies.",
+		Priority: 5,
+		Extras: map[string]interface{}{
+			"source": "tautulli",
+			"action": "created",
+			"client::notification": map[string]interface{}{
+				"bigImageUrl": "https://i.imgur.com/poster.jpg",
+				"click":       map[string]interface{}{"url": "https://app.plex.tv/desktop#!/server/abc/details?key=%2Flibrary%2Fmetadata%2F42"},
+			},
+		},
+	}, mockHandler.sentMessages[0])
+
+	// The Plex server going down is urgent, the priorities are configurable
+	down := map[string]interface{}{"action": "intdown", "subject": "Plex Media Server is down"}
+	assert.Equal(t, 9, formatTautulliPayload(down, defaultConfig()).Priority)
+
+	config := defaultConfig()
+	config.Tautulli.ActionPriorities["play"] = 1
+	play := map[string]interface{}{"action": "play", "subject": "alice started playing Dune"}
+	assert.Equal(t, 1, formatTautulliPayload(play, config).Priority)
+
+	config.Tautulli.ActionPriorities["play"] = 11
+	assert.EqualError(t, config.validate(), "invalid tautulli.actionPriorities.play: priority must be between 1 and 10")
+
+	assert.False(t, isTautulliPayload(map[string]interface{}{"action": "restart", "subject": "Hello"}))
+}
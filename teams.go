@@ -0,0 +1,205 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gotify/plugin-api"
+)
+
+// isTeamsPayload detects Microsoft Teams MessageCards and Adaptive Cards,
+// either sent directly or wrapped in a message with attachments.
+func isTeamsPayload(body map[string]interface{}) bool {
+	if strings.EqualFold(stringField(body, "@type"), "MessageCard") {
+		return true
+	}
+	return teamsAdaptiveCard(body) != nil
+}
+
+// teamsAdaptiveCard returns the Adaptive Card contained in the payload.
+func teamsAdaptiveCard(body map[string]interface{}) map[string]interface{} {
+	switch strings.ToLower(stringField(body, "type")) {
+	case "adaptivecard":
+		return body
+	case "message":
+		attachments, _ := body["attachments"].([]interface{})
+		for _, a := range attachments {
+			attachment, _ := a.(map[string]interface{})
+			content, _ := attachment["content"].(map[string]interface{})
+			if strings.EqualFold(stringField(content, "type"), "AdaptiveCard") {
+				return content
+			}
+		}
+	}
+	return nil
+}
+
+// formatTeamsPayload flattens a Teams card into a markdown message.
+func formatTeamsPayload(body map[string]interface{}, _ *Config) plugin.Message {
+	var title string
+	var lines []string
+	priority := 5
+
+	if card := teamsAdaptiveCard(body); card != nil {
+		var color string
+		title, color, lines = flattenAdaptiveElements(mapSlice(card["body"]), "", "", nil)
+		lines = append(lines, adaptiveActionLinks(mapSlice(card["actions"]))...)
+		priority = adaptiveColorPriority(color)
+	} else {
+		title, lines = flattenMessageCard(body)
+		priority = themeColorPriority(stringField(body, "themeColor"))
+	}
+
+	if title == "" {
+		title = "Teams Notification"
+	}
+
+	return plugin.Message{
+		Title:    title,
+		Message:  strings.TrimSpace(strings.Join(lines, "\n")),
+		Priority: priority,
+		Extras: map[string]interface{}{
+			"source":          "teams",
+			"client::display": map[string]interface{}{"contentType": "text/markdown"},
+		},
+	}
+}
+
+// flattenMessageCard renders the legacy connector MessageCard format.
+func flattenMessageCard(card map[string]interface{}) (string, []string) {
+	title := stringField(card, "title", "summary")
+	var lines []string
+	if text := stringField(card, "text"); text != "" {
+		lines = append(lines, text, "")
+	}
+
+	for _, section := range mapSlice(card["sections"]) {
+		heading := stringField(section, "activityTitle", "title")
+		if title == "" {
+			title = heading
+		} else if heading != "" && heading != title {
+			lines = append(lines, "**"+heading+"**")
+		}
+		for _, key := range []string{"activitySubtitle", "activityText", "text"} {
+			if text := stringField(section, key); text != "" {
+				lines = append(lines, text)
+			}
+		}
+		lines = append(lines, markdownFacts(mapSlice(section["facts"]), "name", "value")...)
+		lines = append(lines, "")
+	}
+
+	for _, action := range mapSlice(card["potentialAction"]) {
+		name := stringField(action, "name")
+		for _, target := range mapSlice(action["targets"]) {
+			if uri := stringField(target, "uri"); uri != "" {
+				lines = append(lines, fmt.Sprintf("[%s](%s)", name, uri))
+				break
+			}
+		}
+	}
+	return title, lines
+}
+
+// flattenAdaptiveElements walks Adaptive Card elements. The first TextBlock
+// becomes the title, the most severe TextBlock color is returned as color.
+func flattenAdaptiveElements(elements []map[string]interface{}, title, color string, lines []string) (string, string, []string) {
+	for _, element := range elements {
+		switch stringField(element, "type") {
+		case "TextBlock":
+			text := stringField(element, "text")
+			if title == "" {
+				title = text
+			} else if text != "" {
+				if strings.EqualFold(stringField(element, "weight"), "bolder") {
+					text = "**" + text + "**"
+				}
+				lines = append(lines, text)
+			}
+			color = moreSevereColor(color, stringField(element, "color"))
+		case "FactSet":
+			lines = append(lines, markdownFacts(mapSlice(element["facts"]), "title", "value")...)
+		case "Image":
+			if url := stringField(element, "url"); url != "" {
+				lines = append(lines, fmt.Sprintf("![%s](%s)", stringField(element, "altText"), url))
+			}
+		case "Container":
+			title, color, lines = flattenAdaptiveElements(mapSlice(element["items"]), title, color, lines)
+		case "ColumnSet":
+			for _, column := range mapSlice(element["columns"]) {
+				title, color, lines = flattenAdaptiveElements(mapSlice(column["items"]), title, color, lines)
+			}
+		case "ActionSet":
+			lines = append(lines, adaptiveActionLinks(mapSlice(element["actions"]))...)
+		}
+	}
+	return title, color, lines
+}
+
+// adaptiveActionLinks renders Action.OpenUrl actions as markdown links.
+func adaptiveActionLinks(actions []map[string]interface{}) []string {
+	var links []string
+	for _, action := range actions {
+		if url := stringField(action, "url"); url != "" {
+			links = append(links, fmt.Sprintf("[%s](%s)", stringField(action, "title"), url))
+		}
+	}
+	return links
+}
+
+// markdownFacts renders name/value pairs as a markdown list.
+func markdownFacts(facts []map[string]interface{}, nameKey, valueKey string) []string {
+	var lines []string
+	for _, fact := range facts {
+		lines = append(lines, fmt.Sprintf("- **%s**: %s", stringField(fact, nameKey), stringField(fact, valueKey)))
+	}
+	return lines
+}
+
+// adaptiveColorSeverity ranks Adaptive Card text colors.
+var adaptiveColorSeverity = map[string]int{"good": 1, "warning": 2, "attention": 3}
+
+// moreSevereColor returns the more severe of two Adaptive Card colors.
+func moreSevereColor(a, b string) string {
+	if adaptiveColorSeverity[strings.ToLower(b)] > adaptiveColorSeverity[strings.ToLower(a)] {
+		return strings.ToLower(b)
+	}
+	return a
+}
+
+// adaptiveColorPriority maps an Adaptive Card color to a Gotify priority.
+func adaptiveColorPriority(color string) int {
+	switch color {
+	case "attention":
+		return 8
+	case "warning":
+		return 6
+	case "good":
+		return 3
+	}
+	return 5
+}
+
+// themeColorPriority maps a MessageCard themeColor (hex RGB) to a priority:
+// red is high, orange/yellow elevated and green low.
+func themeColorPriority(themeColor string) int {
+	hex := strings.TrimPrefix(themeColor, "#")
+	if len(hex) != 6 {
+		return 5
+	}
+	rgb, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return 5
+	}
+	r, g, b := rgb>>16&0xff, rgb>>8&0xff, rgb&0xff
+	switch {
+	case r >= 0xa0 && g < 0x80 && b < 0x80:
+		return 8
+	case r >= 0xa0 && g >= 0x80 && b < 0x80:
+		return 6
+	case g >= 0x80 && r < 0x80 && b < 0xa0:
+		return 3
+	}
+	return 5
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_TeamsMessageCard(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	w := postWebhook(p, map[string]interface{}{
+		"@type":      "MessageCard",
+		"@context":   "http://schema.org/extensions",
+		"themeColor": "D70000",
+		"summary":    "Build failed",
+		"sections": []interface{}{
+			map[string]interface{}{
+				"activityTitle":    "Build #42 failed",
+				"activitySubtitle": "on main",
+				"facts": []interface{}{
+					map[string]interface{}{"name": "Repository", "value": "api"},
+				},
+			},
+		},
+		"potentialAction": []interface{}{
+			map[string]interface{}{
+				"@type":   "OpenUri",
+				"name":    "View build",
+				"targets": []interface{}{map[string]interface{}{"os": "default", "uri": "https://ci.example.com/42"}},
+			},
+		},
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	msg := mockHandler.sentMessages[0]
+	assert.Equal(t, "Build failed", msg.Title)
+	assert.Equal(t, "**Build #42 failed**\non main\n- **Repository**: api\n\n[View build](https://ci.example.com/42)", msg.Message)
+	assert.Equal(t, 8, msg.Priority)
+	assert.Equal(t, "teams", msg.Extras["source"])
+}
+
+func TestWebhookForwarderPlugin_TeamsAdaptiveCard(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	postWebhook(p, map[string]interface{}{
+		"type": "message",
+		"attachments": []interface{}{
+			map[string]interface{}{
+				"contentType": "application/vnd.microsoft.card.adaptive",
+				"content": map[string]interface{}{
+					"type": "AdaptiveCard",
+					"body": []interface{}{
+						map[string]interface{}{"type": "TextBlock", "text": "Disk almost full", "weight": "Bolder"},
+						map[string]interface{}{
+							"type": "Container",
+							"items": []interface{}{
+								map[string]interface{}{"type": "TextBlock", "text": "95% used", "color": "Warning"},
+								map[string]interface{}{
+									"type":  "FactSet",
+									"facts": []interface{}{map[string]interface{}{"title": "Host", "value": "db1"}},
+								},
+							},
+						},
+					},
+					"actions": []interface{}{
+						map[string]interface{}{"type": "Action.OpenUrl", "title": "Open", "url": "https://grafana.example.com"},
+					},
+				},
+			},
+		},
+	})
+
+	assert.Len(t, mockHandler.sentMessages, 1)
+	msg := mockHandler.sentMessages[0]
+	assert.Equal(t, "Disk almost full", msg.Title)
+	assert.Equal(t, "95% used\n- **Host**: db1\n[Open](https://grafana.example.com)", msg.Message)
+	assert.Equal(t, 6, msg.Priority)
+}
+
+func TestThemeColorPriority(t *testing.T) {
+	assert.Equal(t, 8, themeColorPriority("#FF0000"))
+	assert.Equal(t, 6, themeColorPriority("FFA500"))
+	assert.Equal(t, 3, themeColorPriority("2DC72D"))
+	assert.Equal(t, 5, themeColorPriority("0076D7"))
+	assert.Equal(t, 5, themeColorPriority("invalid"))
+}
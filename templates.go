@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+)
+
+// templateFuncs are the helper functions available in user templates.
+var templateFuncs = template.FuncMap{
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"title": capitalize,
+	"join":  strings.Join,
+	"default": func(fallback, value interface{}) interface{} {
+		if value == nil || value == "" {
+			return fallback
+		}
+		return value
+	},
+}
+
+// capitalize upper-cases the first letter of s.
+func capitalize(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if size == 0 {
+		return s
+	}
+	return string(unicode.ToUpper(r)) + s[size:]
+}
+
+// compileTemplates parses the configured title and message templates,
+// including those of the source profiles.
+func (c *Config) compileTemplates() error {
+	funcs := template.FuncMap{
+		"formatTime": c.formatTimestamp,
+	}
+	var err error
+	if c.titleTmpl, err = parseTemplate("titleTemplate", c.TitleTemplate, funcs); err != nil {
+		return err
+	}
+	if c.messageTmpl, err = parseTemplate("messageTemplate", c.MessageTemplate, funcs); err != nil {
+		return err
+	}
+	for name, profile := range c.Sources {
+		if profile == nil {
+			continue
+		}
+		if profile.titleTmpl, err = parseTemplate("sources."+name+".titleTemplate", profile.TitleTemplate, funcs); err != nil {
+			return err
+		}
+		if profile.messageTmpl, err = parseTemplate("sources."+name+".messageTemplate", profile.MessageTemplate, funcs); err != nil {
+			return err
+		}
+	}
+	for name, profile := range c.Grafana.Receivers {
+		if profile == nil {
+			continue
+		}
+		if profile.titleTmpl, err = parseTemplate("grafana.receivers."+name+".titleTemplate", profile.TitleTemplate, funcs); err != nil {
+			return err
+		}
+		if profile.messageTmpl, err = parseTemplate("grafana.receivers."+name+".messageTemplate", profile.MessageTemplate, funcs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseTemplate compiles text, returning nil for an empty template.
+// Config-dependent helpers are passed in funcs.
+func parseTemplate(name, text string, funcs template.FuncMap) (*template.Template, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, nil
+	}
+	tmpl, err := template.New(name).Funcs(templateFuncs).Funcs(funcs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+// renderTemplates applies the configured templates to the decoded payload,
+// preferring the templates of the source profile over the global ones.
+// The given title and message are kept when no template is configured or
+// rendering fails.
+func (c *Config) renderTemplates(source string, data interface{}, title, message string) (string, string) {
+	return c.renderProfileTemplates(c.source(source), data, title, message)
+}
+
+// renderProfileTemplates applies the templates of profile, falling back to
+// the global ones.
+func (c *Config) renderProfileTemplates(profile *SourceConfig, data interface{}, title, message string) (string, string) {
+	titleTmpl, messageTmpl := c.titleTmpl, c.messageTmpl
+	if profile.titleTmpl != nil {
+		titleTmpl = profile.titleTmpl
+	}
+	if profile.messageTmpl != nil {
+		messageTmpl = profile.messageTmpl
+	}
+	return renderWith(titleTmpl, messageTmpl, data, title, message)
+}
+
+// renderTemplates applies the templates of the source profile only. It is
+// used for services whose payloads do not match the global template context.
+func (s *SourceConfig) renderTemplates(data interface{}, title, message string) (string, string) {
+	return renderWith(s.titleTmpl, s.messageTmpl, data, title, message)
+}
+
+// renderWith renders the title and message templates, keeping the given
+// values for missing templates or rendering errors.
+func renderWith(titleTmpl, messageTmpl *template.Template, data interface{}, title, message string) (string, string) {
+	if rendered, err := executeTemplate(titleTmpl, data); err == nil && rendered != "" {
+		title = rendered
+	}
+	if rendered, err := executeTemplate(messageTmpl, data); err == nil && rendered != "" {
+		message = rendered
+	}
+	return title, message
+}
+
+// executeTemplate renders tmpl with data and trims surrounding whitespace.
+func executeTemplate(tmpl *template.Template, data interface{}) (string, error) {
+	if tmpl == nil {
+		return "", nil
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_Templates(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+	config := defaultConfig()
+	config.TitleTemplate = `{{ if .Status }}[{{ upper .Status }}] {{ end }}{{ .Title }}`
+	config.MessageTemplate = `{{ range .Alerts }}{{ .Labels.alertname }}: {{ .Annotations.summary }}
+{{ end }}`
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+
+	postWebhook(p, map[string]interface{}{
+		"title":   "CPU alerts",
+		"status":  "firing",
+		"message": "verbose default message",
+		"alerts": []interface{}{
+			map[string]interface{}{
+				"status":      "firing",
+				"labels":      map[string]interface{}{"alertname": "HighCPU"},
+				"annotations": map[string]interface{}{"summary": "CPU above 90%"},
+			},
+		},
+	})
+	postWebhook(p, map[string]interface{}{"title": "Backup", "message": "done"})
+
+	assert.Len(t, mockHandler.sentMessages, 2)
+	assert.Equal(t, "[FIRING] CPU alerts", mockHandler.sentMessages[0].Title)
+	assert.Equal(t, "HighCPU: CPU above 90%", mockHandler.sentMessages[0].Message)
+	assert.Equal(t, "Backup", mockHandler.sentMessages[1].Title)
+	// Generic payloads have no Alerts or Status, so the defaults are kept
+	assert.Equal(t, "done", mockHandler.sentMessages[1].Message)
+}
+
+func TestWebhookForwarderPlugin_InvalidTemplate(t *testing.T) {
+	p := &WebhookForwarderPlugin{}
+	config := defaultConfig()
+	config.MessageTemplate = "{{ .Title"
+	err := p.ValidateAndSetConfig(config)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "messageTemplate")
+}
+
+func TestCapitalize(t *testing.T) {
+	assert.Equal(t, "", capitalize(""))
+	assert.Equal(t, "Person detected", capitalize("person detected"))
+	assert.Equal(t, "Élan", capitalize("élan"))
+	assert.Equal(t, "1 alert", capitalize("1 alert"))
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// defaultTimeFormat is the Go time layout used when none is configured.
+const defaultTimeFormat = "2006-01-02 15:04:05 MST"
+
+// validateTimeSettings loads the configured timezone.
+func (c *Config) validateTimeSettings() error {
+	c.location = time.Local
+	if c.Timezone == "" {
+		return nil
+	}
+	location, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return fmt.Errorf("invalid timezone: %w", err)
+	}
+	c.location = location
+	return nil
+}
+
+// formatTimestamp converts an RFC3339 timestamp as sent by Grafana into the
+// configured timezone and layout. Grafana's zero time ("0001-01-01T00:00:00Z")
+// renders as an empty string; unparsable values are returned unchanged.
+func (c *Config) formatTimestamp(value string) string {
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return value
+	}
+	if t.IsZero() || t.Year() <= 1 {
+		return ""
+	}
+
+	location := c.location
+	if location == nil {
+		location = time.Local
+	}
+	layout := c.TimeFormat
+	if layout == "" {
+		layout = defaultTimeFormat
+	}
+	return t.In(location).Format(layout)
+}
+
+// humanizeDuration renders a duration with its two most significant units,
+// e.g. "2h 14m" or "3d 4h".
+func humanizeDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	if d < time.Minute {
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	}
+	units := []struct {
+		suffix string
+		value  int64
+	}{
+		{"d", int64(d / (24 * time.Hour))},
+		{"h", int64(d % (24 * time.Hour) / time.Hour)},
+		{"m", int64(d % time.Hour / time.Minute)},
+	}
+	var parts []string
+	for _, unit := range units {
+		if unit.value > 0 || len(parts) > 0 {
+			parts = append(parts, fmt.Sprintf("%d%s", unit.value, unit.suffix))
+		}
+		if len(parts) == 2 {
+			break
+		}
+	}
+	if len(parts) == 2 && strings.HasPrefix(parts[1], "0") {
+		parts = parts[:1]
+	}
+	return strings.Join(parts, " ")
+}
+
+// timestampDuration returns the time between two RFC3339 timestamps, or false
+// if either is missing or they are out of order.
+func timestampDuration(start, end string) (time.Duration, bool) {
+	startTime, err := time.Parse(time.RFC3339Nano, start)
+	if err != nil || startTime.Year() <= 1 {
+		return 0, false
+	}
+	endTime, err := time.Parse(time.RFC3339Nano, end)
+	if err != nil || endTime.Year() <= 1 || endTime.Before(startTime) {
+		return 0, false
+	}
+	return endTime.Sub(startTime), true
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig_FormatTimestamp(t *testing.T) {
+	config := defaultConfig()
+	config.Timezone = "UTC"
+	config.TimeFormat = "02.01.2006 15:04"
+	assert.NoError(t, config.validateTimeSettings())
+
+	assert.Equal(t, "01.05.2024 13:45", config.formatTimestamp("2024-05-01T13:45:12.345Z"))
+	assert.Equal(t, "01.05.2024 11:45", config.formatTimestamp("2024-05-01T13:45:00+02:00"))
+	assert.Equal(t, "", config.formatTimestamp("0001-01-01T00:00:00Z"))
+	assert.Equal(t, "not a time", config.formatTimestamp("not a time"))
+
+	config.Timezone = "Nowhere/Special"
+	assert.Error(t, config.validateTimeSettings())
+}
+
+func TestWebhookForwarderPlugin_FormatTimeTemplate(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+	config := defaultConfig()
+	config.Timezone = "UTC"
+	config.TimeFormat = "15:04 MST"
+	config.MessageTemplate = `{{ range .Alerts }}since {{ formatTime .StartsAt }}{{ end }}`
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+
+	postWebhook(p, map[string]interface{}{
+		"status": "firing",
+		"alerts": []interface{}{
+			map[string]interface{}{"status": "firing", "startsAt": "2024-05-01T13:45:00Z"},
+		},
+	})
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, "since 13:45 UTC", mockHandler.sentMessages[0].Message)
+}
+
+func TestHumanizeDuration(t *testing.T) {
+	assert.Equal(t, "45s", humanizeDuration(45*time.Second))
+	assert.Equal(t, "5m", humanizeDuration(5*time.Minute+20*time.Second))
+	assert.Equal(t, "2h 14m", humanizeDuration(2*time.Hour+14*time.Minute))
+	assert.Equal(t, "1h", humanizeDuration(time.Hour))
+	assert.Equal(t, "3d 4h", humanizeDuration(76*time.Hour+30*time.Minute))
+	assert.Equal(t, "2d", humanizeDuration(48*time.Hour+10*time.Minute))
+}
+
+func TestTimestampDuration(t *testing.T) {
+	d, ok := timestampDuration("2024-05-01T12:00:00Z", "2024-05-01T14:14:00Z")
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Hour+14*time.Minute, d)
+
+	_, ok = timestampDuration("2024-05-01T12:00:00Z", "0001-01-01T00:00:00Z")
+	assert.False(t, ok)
+	_, ok = timestampDuration("2024-05-01T12:00:00Z", "2024-05-01T11:00:00Z")
+	assert.False(t, ok)
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gotify/plugin-api"
+)
+
+// trivyMaxListed limits the vulnerabilities listed in a scan report message.
+const trivyMaxListed = 10
+
+// trivySeverities lists the vulnerability severities from worst to least
+// severe with the priority of reports whose worst finding has it.
+var trivySeverities = []struct {
+	name     string
+	priority int
+}{
+	{"CRITICAL", 9},
+	{"HIGH", 7},
+	{"MEDIUM", 5},
+	{"LOW", 3},
+	{"UNKNOWN", 3},
+}
+
+// trivyVulnerability is a finding of a vulnerability report.
+type trivyVulnerability struct {
+	id, severity, pkg, installed, fixed string
+}
+
+// isTrivyPayload detects vulnerability reports of the Trivy Operator webhook
+// and Trivy's JSON scan report.
+func isTrivyPayload(body map[string]interface{}) bool {
+	if stringField(body, "kind") == "VulnerabilityReport" {
+		_, ok := body["report"].(map[string]interface{})
+		return ok
+	}
+	return stringField(body, "ArtifactName") != "" && hasFields(body, "Results")
+}
+
+// formatTrivyPayload renders a vulnerability report as a digest titled by
+// the counts per severity, e.g. "3 CRITICAL, 12 HIGH in image foo:1.2",
+// listing the most severe vulnerabilities. The worst severity found sets
+// the priority.
+func formatTrivyPayload(body map[string]interface{}, _ *Config) plugin.Message {
+	var image string
+	var vulnerabilities []trivyVulnerability
+	counts := map[string]int{}
+	var context []string
+
+	if report, ok := body["report"].(map[string]interface{}); ok {
+		// Trivy Operator VulnerabilityReport
+		artifact, _ := report["artifact"].(map[string]interface{})
+		image = stringField(artifact, "repository")
+		if tag := stringField(artifact, "tag"); tag != "" {
+			image += ":" + tag
+		}
+		if registry, ok := report["registry"].(map[string]interface{}); ok {
+			if server := stringField(registry, "server"); server != "" && image != "" {
+				image = server + "/" + image
+			}
+		}
+		for _, vulnerability := range mapSlice(report["vulnerabilities"]) {
+			vulnerabilities = append(vulnerabilities, trivyVulnerability{
+				id:        stringField(vulnerability, "vulnerabilityID"),
+				severity:  strings.ToUpper(stringField(vulnerability, "severity")),
+				pkg:       stringField(vulnerability, "resource"),
+				installed: stringField(vulnerability, "installedVersion"),
+				fixed:     stringField(vulnerability, "fixedVersion"),
+			})
+		}
+		if summary, ok := report["summary"].(map[string]interface{}); ok {
+			for _, severity := range trivySeverities {
+				counts[severity.name] = intField(summary, strings.ToLower(severity.name)+"Count")
+			}
+		}
+		metadata, _ := body["metadata"].(map[string]interface{})
+		labels, _ := metadata["labels"].(map[string]interface{})
+		if kind, name := stringField(labels, "trivy-operator.resource.kind"), stringField(labels, "trivy-operator.resource.name"); name != "" {
+			workload := strings.TrimSpace(kind + " " + name)
+			if namespace := stringField(metadata, "namespace"); namespace != "" {
+				workload = strings.TrimSpace(kind + " " + namespace + "/" + name)
+			}
+			context = append(context, "Workload: "+workload)
+		}
+		if container := stringField(labels, "trivy-operator.container.name"); container != "" {
+			context = append(context, "Container: "+container)
+		}
+	} else {
+		// Trivy JSON report
+		image = stringField(body, "ArtifactName")
+		for _, result := range mapSlice(body["Results"]) {
+			for _, vulnerability := range mapSlice(result["Vulnerabilities"]) {
+				vulnerabilities = append(vulnerabilities, trivyVulnerability{
+					id:        stringField(vulnerability, "VulnerabilityID"),
+					severity:  strings.ToUpper(stringField(vulnerability, "Severity")),
+					pkg:       stringField(vulnerability, "PkgName"),
+					installed: stringField(vulnerability, "InstalledVersion"),
+					fixed:     stringField(vulnerability, "FixedVersion"),
+				})
+			}
+		}
+	}
+
+	// Count the listed vulnerabilities if the report has no summary
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+	if total == 0 {
+		for _, vulnerability := range vulnerabilities {
+			counts[vulnerability.severity]++
+		}
+	}
+
+	var parts []string
+	priority := 2
+	for _, severity := range trivySeverities {
+		if count := counts[severity.name]; count > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", count, severity.name))
+			if priority == 2 {
+				priority = severity.priority
+			}
+		}
+	}
+	if image == "" {
+		image = "image"
+	}
+	title := "No vulnerabilities in image " + image
+	if len(parts) > 0 {
+		title = strings.Join(parts, ", ") + " in image " + image
+	}
+
+	// List the most severe vulnerabilities first
+	rank := make(map[string]int, len(trivySeverities))
+	for i, severity := range trivySeverities {
+		rank[severity.name] = i
+	}
+	sort.SliceStable(vulnerabilities, func(i, j int) bool {
+		return trivySeverityRank(rank, vulnerabilities[i]) < trivySeverityRank(rank, vulnerabilities[j])
+	})
+	var lines []string
+	for i, vulnerability := range vulnerabilities {
+		if i == trivyMaxListed {
+			lines = append(lines, fmt.Sprintf("… and %d more", len(vulnerabilities)-trivyMaxListed))
+			break
+		}
+		line := fmt.Sprintf("- %s %s", vulnerability.severity, vulnerability.id)
+		if vulnerability.pkg != "" {
+			line += fmt.Sprintf(" in %s %s", vulnerability.pkg, vulnerability.installed)
+		}
+		if vulnerability.fixed != "" {
+			line += " (fixed in " + vulnerability.fixed + ")"
+		}
+		lines = append(lines, strings.TrimSpace(line))
+	}
+
+	var paragraphs []string
+	if len(context) > 0 {
+		paragraphs = append(paragraphs, strings.Join(context, "\n"))
+	}
+	if len(lines) > 0 {
+		paragraphs = append(paragraphs, strings.Join(lines, "\n"))
+	}
+	message := strings.Join(paragraphs, "\n\n")
+	if message == "" {
+		message = title
+	}
+
+	extras := map[string]interface{}{
+		"source": "trivy",
+		"image":  image,
+	}
+	for _, severity := range trivySeverities {
+		if count := counts[severity.name]; count > 0 {
+			extras[strings.ToLower(severity.name)] = count
+		}
+	}
+
+	return plugin.Message{
+		Title:    title,
+		Message:  message,
+		Priority: priority,
+		Extras:   extras,
+	}
+}
+
+// trivySeverityRank orders vulnerabilities by severity, unknown severities
+// last.
+func trivySeverityRank(rank map[string]int, vulnerability trivyVulnerability) int {
+	if r, ok := rank[vulnerability.severity]; ok {
+		return r
+	}
+	return len(rank)
+}
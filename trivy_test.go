@@ -0,0 +1,76 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gotify/plugin-api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_TrivyOperatorWebhook(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	w := postWebhook(p, map[string]interface{}{
+		"apiVersion": "aquasecurity.github.io/v1alpha1",
+		"kind":       "VulnerabilityReport",
+		"metadata": map[string]interface{}{
+			"name":      "replicaset-web-7c9-nginx",
+			"namespace": "shop",
+			"labels": map[string]interface{}{
+				"trivy-operator.resource.kind":  "ReplicaSet",
+				"trivy-operator.resource.name":  "web-7c9",
+				"trivy-operator.container.name": "nginx",
+			},
+		},
+		"report": map[string]interface{}{
+			"artifact": map[string]interface{}{"repository": "library/nginx", "tag": "1.16"},
+			"registry": map[string]interface{}{"server": "index.docker.io"},
+			"summary": map[string]interface{}{
+				"criticalCount": 1.0,
+				"highCount":     2.0,
+				"mediumCount":   0.0,
+				"lowCount":      0.0,
+				"unknownCount":  0.0,
+			},
+			"vulnerabilities": []interface{}{
+				map[string]interface{}{"vulnerabilityID": "CVE-2023-0002", "severity": "HIGH", "resource": "libc6", "installedVersion": "2.28-10"},
+				map[string]interface{}{"vulnerabilityID": "CVE-2023-0001", "severity": "CRITICAL", "resource": "openssl", "installedVersion": "1.1.1d", "fixedVersion": "1.1.1n"},
+				map[string]interface{}{"vulnerabilityID": "CVE-2023-0003", "severity": "HIGH", "resource": "zlib1g", "installedVersion": "1.2.11"},
+			},
+		},
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title:    "1 CRITICAL, 2 HIGH in image index.docker.io/library/nginx:1.16",
+		Message:  "Workload: ReplicaSet shop/web-7c9\nContainer: nginx\n\n- CRITICAL CVE-2023-0001 in openssl 1.1.1d (fixed in 1.1.1n)\n- HIGH CVE-2023-0002 in libc6 2.28-10\n- HIGH CVE-2023-0003 in zlib1g 1.2.11",
+		Priority: 9,
+		Extras: map[string]interface{}{
+			"source":   "trivy",
+			"image":    "index.docker.io/library/nginx:1.16",
+			"critical": 1,
+			"high":     2,
+		},
+	}, mockHandler.sentMessages[0])
+}
+
+func TestWebhookForwarderPlugin_TrivyReport(t *testing.T) {
+	vulnerabilities := make([]interface{}, 0, 12)
+	for i := 0; i < 12; i++ {
+		vulnerabilities = append(vulnerabilities, map[string]interface{}{"VulnerabilityID": "CVE-1", "Severity": "MEDIUM", "PkgName": "pkg", "InstalledVersion": "1.0"})
+	}
+	msg := formatTrivyPayload(map[string]interface{}{
+		"ArtifactName": "foo:1.2",
+		"Results":      []interface{}{map[string]interface{}{"Target": "foo:1.2 (alpine 3.19)", "Vulnerabilities": vulnerabilities}},
+	}, defaultConfig())
+	assert.Equal(t, "12 MEDIUM in image foo:1.2", msg.Title)
+	assert.Equal(t, 5, msg.Priority)
+	assert.Contains(t, msg.Message, "\n… and 2 more")
+
+	msg = formatTrivyPayload(map[string]interface{}{"ArtifactName": "foo:1.3", "Results": []interface{}{}}, defaultConfig())
+	assert.Equal(t, "No vulnerabilities in image foo:1.3", msg.Title)
+	assert.Equal(t, 2, msg.Priority)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Message truncation strategies for Config.TruncateStrategy.
+const (
+	truncatePlain    = "truncate"
+	truncateEllipsis = "truncate-with-ellipsis"
+	truncateSummary  = "summary-only"
+)
+
+// validateTruncateStrategy checks the configured truncation strategy.
+func validateTruncateStrategy(strategy string) error {
+	switch strategy {
+	case "", truncatePlain, truncateEllipsis, truncateSummary:
+		return nil
+	}
+	return fmt.Errorf("invalid truncateStrategy %q, must be one of %s, %s or %s",
+		strategy, truncatePlain, truncateEllipsis, truncateSummary)
+}
+
+// limitMessage shortens message to at most maxLength characters using the
+// given strategy. A maxLength of zero or less disables the limit.
+func limitMessage(message string, maxLength int, strategy string) string {
+	if maxLength <= 0 || len([]rune(message)) <= maxLength {
+		return message
+	}
+
+	switch strategy {
+	case truncatePlain:
+		return truncateRunes(message, maxLength)
+	case truncateSummary:
+		// Keep only the first paragraph, which usually holds the summary
+		summary := strings.TrimSpace(message)
+		if idx := strings.Index(summary, "\n\n"); idx >= 0 {
+			summary = strings.TrimSpace(summary[:idx])
+		}
+		return limitMessage(summary, maxLength, truncateEllipsis)
+	default:
+		if maxLength <= 3 {
+			return truncateRunes(message, maxLength)
+		}
+		return truncateRunes(message, maxLength-3) + "..."
+	}
+}
+
+// truncateRunes cuts s after n characters without splitting UTF-8 sequences.
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
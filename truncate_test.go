@@ -0,0 +1,35 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimitMessage(t *testing.T) {
+	long := "First paragraph.\n\nSecond paragraph with lots of detail."
+
+	assert.Equal(t, long, limitMessage(long, 0, truncateEllipsis))
+	assert.Equal(t, long, limitMessage(long, 100, truncateEllipsis))
+	assert.Equal(t, "First para", limitMessage(long, 10, truncatePlain))
+	assert.Equal(t, "First p...", limitMessage(long, 10, truncateEllipsis))
+	assert.Equal(t, "First paragraph.", limitMessage(long, 20, truncateSummary))
+	assert.Equal(t, "First...", limitMessage(long, 8, truncateSummary))
+	assert.Equal(t, "ääää", limitMessage(strings.Repeat("ä", 10), 4, truncatePlain))
+}
+
+func TestWebhookForwarderPlugin_MaxMessageLength(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+	config := defaultConfig()
+	config.MaxMessageLength = 10
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+
+	postWebhook(p, map[string]interface{}{"message": "This message is too long"})
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, "This me...", mockHandler.sentMessages[0].Message)
+
+	config.TruncateStrategy = "cut"
+	assert.Error(t, p.ValidateAndSetConfig(config))
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gotify/plugin-api"
+)
+
+// UniFiConfig holds options for UniFi Network and Protect alarms.
+type UniFiConfig struct {
+	// Devices maps device MAC addresses or IDs to names shown instead of
+	// them, for alarms that only identify the device.
+	Devices map[string]string `yaml:"devices"`
+}
+
+// deviceName returns the configured name of a device, fallback if there is
+// none or id itself without a fallback. MAC addresses match regardless of
+// case and separators.
+func (u UniFiConfig) deviceName(id, fallback string) string {
+	normalized := normalizeMAC(id)
+	for device, name := range u.Devices {
+		if name != "" && normalizeMAC(device) == normalized {
+			return name
+		}
+	}
+	if fallback != "" {
+		return fallback
+	}
+	return id
+}
+
+// normalizeMAC lowercases a MAC address and removes its separators.
+func normalizeMAC(mac string) string {
+	return strings.NewReplacer(":", "", "-", "", ".", "").Replace(strings.ToLower(mac))
+}
+
+// unifiProtectTriggers describes the trigger keys of UniFi Protect alarms:
+// the title ("%s" is the camera) and the priority. Motion is common and low
+// priority, smart detections and doorbell rings are more important and
+// smoke or CO alarms are urgent.
+var unifiProtectTriggers = map[string]struct {
+	title    string
+	priority int
+}{
+	"motion":              {"Motion detected on %s", 4},
+	"person":              {"Person detected on %s", 6},
+	"vehicle":             {"Vehicle detected on %s", 6},
+	"animal":              {"Animal detected on %s", 5},
+	"package":             {"Package detected on %s", 6},
+	"face":                {"Face detected on %s", 6},
+	"licenseplate":        {"License plate detected on %s", 6},
+	"line_crossed":        {"Line crossed on %s", 6},
+	"ring":                {"Doorbell ring at %s", 7},
+	"audio_alarm_smoke":   {"Smoke alarm heard by %s", 10},
+	"audio_alarm_co":      {"CO alarm heard by %s", 10},
+	"audio_alarm_siren":   {"Siren heard by %s", 8},
+	"audio_alarm_baby":    {"Baby crying heard by %s", 7},
+	"audio_alarm_bark":    {"Barking heard by %s", 5},
+	"audio_alarm_glass":   {"Glass break heard by %s", 9},
+	"device_offline":      {"%s is offline", 8},
+	"device_disconnected": {"%s is offline", 8},
+}
+
+// isUniFiProtectPayload detects webhooks of the UniFi Protect alarm manager.
+func isUniFiProtectPayload(body map[string]interface{}) bool {
+	alarm, ok := body["alarm"].(map[string]interface{})
+	return ok && hasAnyField(alarm, "triggers", "sources")
+}
+
+// formatUniFiProtectPayload renders a UniFi Protect alarm with the detection
+// and camera in the title, e.g. "Person detected on Driveway".
+func formatUniFiProtectPayload(body map[string]interface{}, config *Config) plugin.Message {
+	alarm, _ := body["alarm"].(map[string]interface{})
+	triggers := mapSlice(alarm["triggers"])
+	name := stringField(alarm, "name")
+
+	var trigger map[string]interface{}
+	if len(triggers) > 0 {
+		trigger = triggers[0]
+	}
+	key := strings.ToLower(stringField(trigger, "key"))
+	device := stringField(trigger, "device")
+	if device == "" {
+		if sources := mapSlice(alarm["sources"]); len(sources) > 0 {
+			device = stringField(sources[0], "device")
+		}
+	}
+	camera := config.UniFi.deviceName(device, stringField(trigger, "deviceName", "camera"))
+	if camera == "" {
+		camera = "camera"
+	}
+
+	title := "UniFi Protect: " + name
+	priority := 5
+	if description, ok := unifiProtectTriggers[key]; ok {
+		title = fmt.Sprintf(description.title, camera)
+		priority = description.priority
+	} else if key != "" {
+		title = capitalize(fmt.Sprintf("%s on %s", strings.ReplaceAll(key, "_", " "), camera))
+	}
+
+	var lines []string
+	if name != "" {
+		lines = append(lines, "Alarm: "+name)
+	}
+	if device != "" {
+		lines = append(lines, "Camera: "+camera)
+	}
+	if len(triggers) > 1 {
+		var keys []string
+		for _, trigger := range triggers {
+			keys = append(keys, stringField(trigger, "key"))
+		}
+		lines = append(lines, "Triggers: "+strings.Join(keys, ", "))
+	}
+	if timestamp := intField(body, "timestamp"); timestamp > 0 {
+		when := time.UnixMilli(int64(timestamp)).UTC().Format(time.RFC3339)
+		lines = append(lines, "Time: "+config.formatTimestamp(when))
+	}
+	message := strings.Join(lines, "\n")
+	if message == "" {
+		message = title
+	}
+
+	extras := map[string]interface{}{"source": "unifi-protect"}
+	for field, value := range map[string]string{
+		"alarm":   name,
+		"trigger": key,
+		"device":  device,
+		"eventId": stringField(trigger, "eventId"),
+	} {
+		if value != "" {
+			extras[field] = value
+		}
+	}
+	if link := stringField(alarm, "eventLocalLink", "eventLink"); link != "" {
+		extras["client::notification"] = map[string]interface{}{
+			"click": map[string]interface{}{"url": link},
+		}
+	}
+
+	return plugin.Message{
+		Title:    title,
+		Message:  message,
+		Priority: priority,
+		Extras:   extras,
+	}
+}
+
+// unifiDeviceFields lists the MAC and name fields of UniFi Network events
+// by device type: access points, switches, gateways and clients.
+var unifiDeviceFields = []struct{ mac, name string }{
+	{"ap", "ap_name"},
+	{"sw", "sw_name"},
+	{"gw", "gw_name"},
+	{"dev", "dev_name"},
+	{"user", "hostname"},
+	{"guest", "hostname"},
+}
+
+// isUniFiNetworkPayload detects alarms and events of the UniFi Network
+// controller, identified by their EVT_ key.
+func isUniFiNetworkPayload(body map[string]interface{}) bool {
+	return strings.HasPrefix(stringField(body, "key"), "EVT_") && hasAnyField(body, "msg", "message")
+}
+
+// formatUniFiNetworkPayload renders a UniFi Network alarm with the device
+// names resolved in the message. Devices going offline get priority 8,
+// intrusion detections 9 and reconnected devices 3.
+func formatUniFiNetworkPayload(body map[string]interface{}, config *Config) plugin.Message {
+	key := stringField(body, "key")
+	message := strings.TrimSpace(stringField(body, "msg", "message"))
+
+	device := ""
+	for _, field := range unifiDeviceFields {
+		mac := stringField(body, field.mac)
+		if mac == "" {
+			continue
+		}
+		name := config.UniFi.deviceName(mac, stringField(body, field.name))
+		message = strings.ReplaceAll(message, mac, name)
+		if device == "" {
+			device = name
+		}
+	}
+
+	event := strings.ToLower(key)
+	priority := 5
+	state := ""
+	switch {
+	case strings.HasPrefix(event, "evt_ips_") || strings.Contains(event, "threat"):
+		priority = 9
+	case strings.Contains(event, "lost_contact") || strings.Contains(event, "disconnected") || strings.Contains(event, "offline"):
+		priority, state = 8, "offline"
+	case strings.Contains(event, "connected") || strings.Contains(event, "restored"):
+		priority, state = 3, "online"
+	}
+
+	title := "UniFi: " + strings.TrimPrefix(key, "EVT_")
+	switch {
+	case device != "" && state != "":
+		title = fmt.Sprintf("UniFi: %s is %s", device, state)
+	case device != "":
+		title = "UniFi: " + device
+	}
+
+	var lines []string
+	if message != "" {
+		lines = append(lines, message)
+	}
+	if when := config.formatTimestamp(stringField(body, "datetime")); when != "" {
+		lines = append(lines, "Time: "+when)
+	}
+	if len(lines) == 0 {
+		lines = append(lines, title)
+	}
+
+	extras := map[string]interface{}{
+		"source": "unifi",
+		"key":    key,
+	}
+	if site := stringField(body, "site_name", "site_id"); site != "" {
+		extras["site"] = site
+	}
+
+	return plugin.Message{
+		Title:    title,
+		Message:  strings.Join(lines, "\n"),
+		Priority: priority,
+		Extras:   extras,
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gotify/plugin-api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_UniFiProtectWebhook(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+	config := defaultConfig()
+	config.Timezone = "UTC"
+	config.UniFi.Devices = map[string]string{"e0:63:da:00:11:22": "Driveway"}
+	assert.NoError(t, p.ValidateAndSetConfig(config))
+
+	w := postWebhook(p, map[string]interface{}{
+		"alarm": map[string]interface{}{
+			"name":    "Person at the driveway",
+			"sources": []interface{}{map[string]interface{}{"device": "E063DA001122", "type": "include"}},
+			"triggers": []interface{}{
+				map[string]interface{}{"key": "person", "device": "E063DA001122", "eventId": "66de0f4a"},
+			},
+			"eventLocalLink": "https://unifi.local/protect/events/event/66de0f4a",
+		},
+		"timestamp": 1725883107267.0,
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title:    "Person detected on Driveway",
+		Message:  "Alarm: Person at the driveway\nCamera: Driveway\nTime: 2024-09-09 11:58:27 UTC",
+		Priority: 6,
+		Extras: map[string]interface{}{
+			"source":  "unifi-protect",
+			"alarm":   "Person at the driveway",
+			"trigger": "person",
+			"device":  "E063DA001122",
+			"eventId": "66de0f4a",
+			"client::notification": map[string]interface{}{
+				"click": map[string]interface{}{"url": "https://unifi.local/protect/events/event/66de0f4a"},
+			},
+		},
+	}, mockHandler.sentMessages[0])
+
+	// Motion is less important than smart detections
+	msg := formatUniFiProtectPayload(map[string]interface{}{
+		"alarm": map[string]interface{}{
+			"name":     "Motion",
+			"triggers": []interface{}{map[string]interface{}{"key": "motion", "device": "AABBCCDDEEFF"}},
+		},
+	}, defaultConfig())
+	assert.Equal(t, "Motion detected on AABBCCDDEEFF", msg.Title)
+	assert.Equal(t, 4, msg.Priority)
+}
+
+func TestWebhookForwarderPlugin_UniFiNetworkWebhook(t *testing.T) {
+	config := defaultConfig()
+	config.UniFi.Devices = map[string]string{"F0-9F-C2-AA-BB-CC": "Office AP"}
+
+	msg := formatUniFiNetworkPayload(map[string]interface{}{
+		"key":     "EVT_AP_Lost_Contact",
+		"msg":     "AP[f0:9f:c2:aa:bb:cc] was disconnected",
+		"ap":      "f0:9f:c2:aa:bb:cc",
+		"site_id": "default",
+	}, config)
+	assert.Equal(t, plugin.Message{
+		Title:    "UniFi: Office AP is offline",
+		Message:  "AP[Office AP] was disconnected",
+		Priority: 8,
+		Extras: map[string]interface{}{
+			"source": "unifi",
+			"key":    "EVT_AP_Lost_Contact",
+			"site":   "default",
+		},
+	}, msg)
+
+	msg = formatUniFiNetworkPayload(map[string]interface{}{
+		"key":     "EVT_SW_Connected",
+		"msg":     "Switch[00:11:22:33:44:55] was connected",
+		"sw":      "00:11:22:33:44:55",
+		"sw_name": "Core switch",
+	}, config)
+	assert.Equal(t, "UniFi: Core switch is online", msg.Title)
+	assert.Equal(t, "Switch[Core switch] was connected", msg.Message)
+	assert.Equal(t, 3, msg.Priority)
+}
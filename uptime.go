@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gotify/plugin-api"
+)
+
+// isUptimeRobotPayload detects UptimeRobot webhook alert contacts, which
+// send the monitor and alert variables as JSON or form fields.
+func isUptimeRobotPayload(body map[string]interface{}) bool {
+	return hasAnyField(body, "monitorFriendlyName", "monitorID") &&
+		hasAnyField(body, "alertType", "alertTypeFriendlyName")
+}
+
+// formatUptimeRobotPayload renders an UptimeRobot up/down alert. Down
+// alerts get priority 8, SSL expiry alerts 6 and up alerts 3 with the
+// downtime duration.
+func formatUptimeRobotPayload(body map[string]interface{}, _ *Config) plugin.Message {
+	name := stringField(body, "monitorFriendlyName", "monitorURL", "monitorID")
+	if name == "" {
+		name = "Monitor"
+	}
+	state := stringField(body, "alertTypeFriendlyName")
+	priority := 5
+	switch stringField(body, "alertType") {
+	case "1":
+		state, priority = "Down", 8
+	case "2":
+		state, priority = "Up", 3
+	case "3":
+		priority = 6
+		if state == "" {
+			state = "SSL expiry"
+		}
+	}
+
+	title := "UptimeRobot: " + name
+	if state != "" {
+		title = fmt.Sprintf("UptimeRobot: %s is %s", name, strings.ToUpper(state))
+	}
+
+	var lines []string
+	if details := strings.TrimSpace(stringField(body, "alertDetails")); details != "" {
+		lines = append(lines, details)
+	}
+	if seconds := intField(body, "alertDuration"); seconds > 0 && priority == 3 {
+		lines = append(lines, fmt.Sprintf("Down for %s", humanizeDuration(time.Duration(seconds)*time.Second)))
+	}
+	url := stringField(body, "monitorURL")
+	if url != "" {
+		lines = append(lines, "URL: "+url)
+	}
+	if expiry := stringField(body, "sslExpiryDate"); expiry != "" {
+		lines = append(lines, "SSL expiry: "+expiry)
+	}
+	if date := stringField(body, "alertFriendlyDateTime", "alertDateTime"); date != "" {
+		lines = append(lines, "Time: "+date)
+	}
+
+	return uptimeMessage("uptimerobot", title, lines, priority, map[string]string{
+		"monitorId": stringField(body, "monitorID"),
+		"state":     state,
+	}, url)
+}
+
+// isStatusCakePayload detects StatusCake uptime webhooks, which post the
+// test name and status as form fields.
+func isStatusCakePayload(body map[string]interface{}) bool {
+	return hasFields(body, "Name", "Status") && hasAnyField(body, "StatusCode", "Token", "URL")
+}
+
+// formatStatusCakePayload renders a StatusCake up/down alert. Down alerts
+// get priority 8, up alerts 3.
+func formatStatusCakePayload(body map[string]interface{}, _ *Config) plugin.Message {
+	name := stringField(body, "Name")
+	state := stringField(body, "Status")
+	priority := 5
+	switch strings.ToLower(state) {
+	case "down":
+		priority = 8
+	case "up":
+		priority = 3
+	}
+
+	title := fmt.Sprintf("StatusCake: %s is %s", name, strings.ToUpper(state))
+
+	var lines []string
+	if code := stringField(body, "StatusCode"); code != "" {
+		lines = append(lines, "Status code: "+code)
+	}
+	url := stringField(body, "URL")
+	if url != "" {
+		lines = append(lines, "URL: "+url)
+	}
+	if ip := stringField(body, "IP"); ip != "" {
+		lines = append(lines, "IP: "+ip)
+	}
+	if tags := stringField(body, "Tags"); tags != "" {
+		lines = append(lines, "Tags: "+tags)
+	}
+
+	return uptimeMessage("statuscake", title, lines, priority, map[string]string{
+		"state": state,
+	}, url)
+}
+
+// uptimeMessage builds the message of an uptime monitoring alert, opening
+// the monitored URL when the notification is clicked.
+func uptimeMessage(source, title string, lines []string, priority int, fields map[string]string, url string) plugin.Message {
+	message := strings.Join(lines, "\n")
+	if message == "" {
+		message = title
+	}
+	extras := map[string]interface{}{"source": source}
+	for key, value := range fields {
+		if value != "" {
+			extras[key] = value
+		}
+	}
+	if url != "" {
+		extras["client::notification"] = map[string]interface{}{
+			"click": map[string]interface{}{"url": url},
+		}
+	}
+	return plugin.Message{
+		Title:    title,
+		Message:  message,
+		Priority: priority,
+		Extras:   extras,
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gotify/plugin-api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookForwarderPlugin_UptimeRobotWebhook(t *testing.T) {
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+
+	w := postWebhook(p, map[string]interface{}{
+		"monitorID":             "777712827",
+		"monitorURL":            "https://example.com",
+		"monitorFriendlyName":   "Website",
+		"alertType":             "1",
+		"alertTypeFriendlyName": "Down",
+		"alertDetails":          "Connection Timeout",
+		"alertDuration":         "",
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	assert.Equal(t, plugin.Message{
+		Title:    "UptimeRobot: Website is DOWN",
+		Message:  "Connection Timeout\nURL: https://example.com",
+		Priority: 8,
+		Extras: map[string]interface{}{
+			"source":    "uptimerobot",
+			"monitorId": "777712827",
+			"state":     "Down",
+			"client::notification": map[string]interface{}{
+				"click": map[string]interface{}{"url": "https://example.com"},
+			},
+		},
+	}, mockHandler.sentMessages[0])
+
+	msg := formatUptimeRobotPayload(map[string]interface{}{
+		"monitorFriendlyName": "Website",
+		"alertType":           2.0,
+		"alertDuration":       "754",
+	}, defaultConfig())
+	assert.Equal(t, "UptimeRobot: Website is UP", msg.Title)
+	assert.Equal(t, "Down for 12m", msg.Message)
+	assert.Equal(t, 3, msg.Priority)
+}
+
+func TestWebhookForwarderPlugin_StatusCakeWebhook(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockHandler := &MockMessageHandler{}
+	p := &WebhookForwarderPlugin{msgHandler: mockHandler}
+	router := gin.New()
+	router.POST("/message", p.handleWebhookMessage)
+
+	form := url.Values{
+		"URL":        {"https://shop.example.com"},
+		"Token":      {"a1b2c3"},
+		"Name":       {"Shop"},
+		"StatusCode": {"503"},
+		"Status":     {"Down"},
+		"IP":         {"203.0.113.7"},
+	}
+	req := httptest.NewRequest("POST", "/message", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockHandler.sentMessages, 1)
+	msg := mockHandler.sentMessages[0]
+	assert.Equal(t, "StatusCake: Shop is DOWN", msg.Title)
+	assert.Equal(t, "Status code: 503\nURL: https://shop.example.com\nIP: 203.0.113.7", msg.Message)
+	assert.Equal(t, 8, msg.Priority)
+	assert.Equal(t, "statuscake", msg.Extras["source"])
+
+	assert.Equal(t, 3, formatStatusCakePayload(map[string]interface{}{"Name": "Shop", "Status": "Up"}, defaultConfig()).Priority)
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// grafanaValuePattern matches one entry of Grafana's valueString, e.g.
+// "[ var='A' labels={instance=web1} value=93.5 ]". Older Grafana versions
+// use metric= instead of var=.
+var grafanaValuePattern = regexp.MustCompile(`\[\s*(?:var|metric)='([^']*)'\s+labels=\{([^}]*)\}\s+value=([^\s\]]*)\s*\]`)
+
+// grafanaValue is a single evaluated expression from a valueString.
+type grafanaValue struct {
+	Name   string
+	Labels string
+	Value  string
+}
+
+// String renders the value as "A = 93.5 (instance=web1)".
+func (v grafanaValue) String() string {
+	s := fmt.Sprintf("%s = %s", v.Name, v.Value)
+	if v.Labels != "" {
+		s += " (" + v.Labels + ")"
+	}
+	return s
+}
+
+// parseValueString extracts the expressions of a Grafana valueString. It
+// returns nil if the string is not in the expected format.
+func parseValueString(valueString string) []grafanaValue {
+	matches := grafanaValuePattern.FindAllStringSubmatch(valueString, -1)
+	values := make([]grafanaValue, 0, len(matches))
+	for _, match := range matches {
+		values = append(values, grafanaValue{
+			Name:   match[1],
+			Labels: strings.TrimSpace(match[2]),
+			Value:  match[3],
+		})
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	return values
+}
+
+// humanizeValueString renders a Grafana valueString as one "A = 93.5
+// (instance=web1)" line per expression. Unrecognised strings are returned
+// unchanged.
+func humanizeValueString(valueString string) []string {
+	values := parseValueString(valueString)
+	if values == nil {
+		return []string{strings.TrimSpace(valueString)}
+	}
+	lines := make([]string, len(values))
+	for i, value := range values {
+		lines[i] = value.String()
+	}
+	return lines
+}
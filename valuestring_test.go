@@ -0,0 +1,35 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseValueString(t *testing.T) {
+	assert.Equal(t, []grafanaValue{
+		{Name: "A", Labels: "instance=web1, job=node", Value: "93.5"},
+		{Name: "B", Labels: "", Value: "1"},
+	}, parseValueString("[ var='A' labels={instance=web1, job=node} value=93.5 ], [ var='B' labels={} value=1 ]"))
+
+	assert.Equal(t, []grafanaValue{
+		{Name: "cpu_usage", Labels: "host=db1", Value: "0.97"},
+	}, parseValueString("[ metric='cpu_usage' labels={host=db1} value=0.97 ]"))
+
+	assert.Nil(t, parseValueString(""))
+	assert.Nil(t, parseValueString("[no value]"))
+}
+
+func TestHumanizeValueString(t *testing.T) {
+	assert.Equal(t, []string{"A = 93.5 (instance=web1)", "B = 1"},
+		humanizeValueString("[ var='A' labels={instance=web1} value=93.5 ], [ var='B' labels={} value=1 ]"))
+	assert.Equal(t, []string{"[no value]"}, humanizeValueString("[no value]"))
+}
+
+func TestGrafanaAlertBody_MultipleValues(t *testing.T) {
+	config := defaultConfig()
+	body := config.grafanaAlertBody(GrafanaAlert{
+		ValueString: "[ var='A' labels={instance=web1} value=93.5 ], [ var='C' labels={instance=web1} value=1 ]",
+	})
+	assert.Equal(t, "- **Values**:\n  - A = 93.5 (instance=web1)\n  - C = 1 (instance=web1)", body)
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Verbosity presets for Grafana messages.
+const (
+	verbosityMinimal  = "minimal"
+	verbosityStandard = "standard"
+	verbosityDetailed = "detailed"
+	verbosityRaw      = "raw"
+)
+
+// validateVerbosity checks the verbosity preset.
+func (g *GrafanaConfig) validateVerbosity() error {
+	switch g.Verbosity {
+	case "", verbosityMinimal, verbosityStandard, verbosityDetailed, verbosityRaw:
+		return nil
+	}
+	return fmt.Errorf("invalid grafana.verbosity %q, expected minimal, standard, detailed or raw", g.Verbosity)
+}
+
+// rawMessage reports whether Grafana's message text is forwarded instead of
+// the markdown rendering of the alerts.
+func (g *GrafanaConfig) rawMessage() bool {
+	return g.RawMessage || g.Verbosity == verbosityRaw
+}
+
+// annotationsOnly reports whether messages are built from the annotations
+// of the alerts only.
+func (g *GrafanaConfig) annotationsOnly() bool {
+	return g.AnnotationsOnly || g.Verbosity == verbosityMinimal
+}
+
+// detailed reports whether messages include all annotations and the link
+// to the alert rule.
+func (g *GrafanaConfig) detailed() bool {
+	return g.Verbosity == verbosityDetailed
+}
+
+// extraAnnotations renders the annotations of an alert other than the
+// summary, description and runbook as a markdown list, leaving out Grafana's
+// internal annotations such as __dashboardUid__.
+func (c *Config) extraAnnotations(alert GrafanaAlert) string {
+	names := make([]string, 0, len(alert.Annotations))
+	for name, value := range alert.Annotations {
+		switch name {
+		case "summary", "description", "runbook_url":
+			continue
+		}
+		if strings.HasPrefix(name, "__") || strings.TrimSpace(value) == "" || !c.Labels.allows(name) || c.logAnnotation(name) {
+			continue
+		}
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	sort.Strings(names)
+	items := []string{"**Annotations**:"}
+	for _, name := range names {
+		items = append(items, fmt.Sprintf("- **%s**: %s", name, strings.TrimSpace(alert.Annotations[name])))
+	}
+	return strings.Join(items, "\n")
+}
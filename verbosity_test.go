@@ -0,0 +1,40 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func verbosityPayload() map[string]interface{} {
+	alert := grafanaAlert("firing", "alertname", "HighCPU", "instance", "web1")
+	alert["generatorURL"] = "https://grafana.example.com/alerting/grafana/abc/view"
+	alert["annotations"] = map[string]interface{}{
+		"summary":          "CPU usage above 90%",
+		"team":             "infra",
+		"__dashboardUid__": "abc",
+	}
+	payload := grafanaNotification(alert)
+	payload["message"] = "Grafana's own text"
+	return payload
+}
+
+func TestWebhookForwarderPlugin_GrafanaVerbosity(t *testing.T) {
+	config := defaultConfig()
+	p, mockHandler := newTestPlugin(t, config)
+
+	send := func(verbosity string) string {
+		config.Grafana.Verbosity = verbosity
+		assert.NoError(t, p.ValidateAndSetConfig(config))
+		postWebhook(p, verbosityPayload())
+		return mockHandler.sentMessages[len(mockHandler.sentMessages)-1].Message
+	}
+
+	assert.Equal(t, "CPU usage above 90%", send(verbosityMinimal))
+	assert.Equal(t, "Grafana's own text", send(verbosityRaw))
+	assert.Equal(t, "CPU usage above 90%\n\n- **alertname**: HighCPU\n- **instance**: web1", send(verbosityStandard))
+	assert.Equal(t, "CPU usage above 90%\n\n- **alertname**: HighCPU\n- **instance**: web1\n\n**Annotations**:\n- **team**: infra\n\n[Alert rule](https://grafana.example.com/alerting/grafana/abc/view)", send(verbosityDetailed))
+
+	config.Grafana.Verbosity = "chatty"
+	assert.Error(t, config.validate())
+}